@@ -0,0 +1,5 @@
+data "denobridge_config" "current" {}
+
+output "deno_version" {
+  value = data.denobridge_config.current.deno_version
+}
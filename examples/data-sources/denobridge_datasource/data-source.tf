@@ -15,7 +15,7 @@ data "denobridge_datasource" "deno_ip" {
   # config file relative to the script path. This is to ensure that things like
   # import maps work as expected.
   #
-  # If you wish to opt out of this automatic config discovery, supply the path "/dev/null".
+  # If you wish to opt out of this automatic config discovery, set no_config_discovery = true.
   config_file = "/path/to/deno.json"
 
   # Optionally set any runtime permissions that the deno script may require.
@@ -15,7 +15,7 @@ resource "denobridge_resource" "quote_of_the_day" {
   # config file relative to the script path. This is to ensure that things like
   # import maps work as expected.
   #
-  # If you wish to opt out of this automatic config discovery, supply the path "/dev/null".
+  # If you wish to opt out of this automatic config discovery, set no_config_discovery = true.
   config_file = "/path/to/deno.json"
 
   # Optionally set any runtime permissions that the deno script may require.
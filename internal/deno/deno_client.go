@@ -2,7 +2,9 @@ package deno
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -10,11 +12,18 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/brad-jones/terraform-provider-denobridge/internal/jsocket"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/sourcegraph/jsonrpc2"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // DenoClient manages a Deno child process and communication via JSON-RPC with it.
@@ -23,59 +32,197 @@ type DenoClient struct {
 	scriptPath     string
 	configPath     string
 	permissions    *Permissions
+	options        *ClientOptions
 	denoBinaryPath string
 	process        *exec.Cmd
 	rpcMethods     func(ctx context.Context, c *jsonrpc2.Conn) map[string]any
+	redactor       *logRedactor
+	metrics        *Metrics
 	Socket         *jsocket.JSocket
+
+	// secretsMu guards secrets, as a daemon is shared by concurrent operations that each add their own.
+	secretsMu sync.Mutex
+	secrets   []string
+
+	// declaredPermissions are the permissions the script declared it needs in the health
+	// handshake, nil when it did not declare any.
+	declaredPermissions []string
+
+	// denoVersion is the version of Deno reported in the health handshake, empty for older bridge libraries.
+	denoVersion string
+
+	// exitOnce starts the single goroutine that waits for the child process to exit,
+	// exitCh is closed once it has exited after which exitErr holds the result of Wait,
+	// as an *ExitError when the process did not exit cleanly.
+	exitOnce sync.Once
+	exitCh   chan struct{}
+	exitErr  error
+
+	// exitReported is set once a call has failed with a *ProcessExitedError, so that
+	// Stop does not report the same exit again.
+	exitReported atomic.Bool
+
+	// stderrMu guards the details collected from the child processes stderr.
+	stderrMu           sync.Mutex
+	missingPermissions []MissingPermission
+	outOfMemory        bool
+	scriptErrors       scriptErrorParser
+	stderrTail         lineRing
+
+	// stderrDone is closed once everything the child process wrote to stderr has been read.
+	stderrDone chan struct{}
 }
 
 // NewDenoClient creates a new Deno client for the given script.
-func NewDenoClient(denoBinaryPath, scriptPath, configPath string, permissions *Permissions, rpcMethods func(ctx context.Context, c *jsonrpc2.Conn) map[string]any) *DenoClient {
+func NewDenoClient(denoBinaryPath, scriptPath, configPath string, permissions *Permissions, options *ClientOptions, rpcMethods func(ctx context.Context, c *jsonrpc2.Conn) map[string]any) *DenoClient {
 	return &DenoClient{
 		scriptPath:     scriptPath,
 		configPath:     configPath,
 		permissions:    permissions,
+		options:        options,
 		denoBinaryPath: denoBinaryPath,
 		rpcMethods:     rpcMethods,
+		metrics:        SharedMetrics(),
 	}
 }
 
 // Start launches the Deno JSON-RPC process.
+//
+// If it fails, anything that was already started is torn down so that Start may be called again.
 func (c *DenoClient) Start(ctx context.Context) error {
+	spanCtx, span := c.startSpan(ctx, "deno.start")
+	if err := c.start(spanCtx); err != nil {
+		c.abortStart()
+		EndSpan(span, err)
+		return err
+	}
+	EndSpan(span, nil)
+
+	// Later work on the client, eg: stopping it, belongs to the operation that started it rather than the start
+	c.ctx = ctx
+	return nil
+}
+
+// StartRetries returns how many times a failed Start should be retried.
+func (c *DenoClient) StartRetries() int64 {
+	return c.options.startRetries()
+}
+
+// abortStart tears down a partially started client, leaving it ready to be started again.
+func (c *DenoClient) abortStart() {
+	if c.Socket != nil {
+		_ = c.Socket.Close()
+		c.Socket = nil
+	}
+	if c.process != nil && c.process.Process != nil {
+		_ = c.process.Process.Kill()
+		<-c.exited()
+	}
+	c.process = nil
+	c.exitOnce = sync.Once{}
+	c.exitCh = nil
+	c.exitErr = nil
+	c.exitReported.Store(false)
+}
+
+// start does the work of Start.
+func (c *DenoClient) start(ctx context.Context) error {
 	// Store context for logging
 	c.ctx = ctx
 
+	// Compile the patterns that are scrubbed from log output
+	var patterns []string
+	if c.options != nil {
+		patterns = c.options.LogRedactPatterns
+	}
+	redactor, err := newLogRedactor(patterns)
+	if err != nil {
+		return err
+	}
+	c.secretsMu.Lock()
+	redactor.addSecrets(c.secrets...)
+	c.secretsMu.Unlock()
+	c.redactor = redactor
+
+	// Nothing collected from the stderr of an earlier attempt explains this one
+	c.resetStderrDetails()
+
 	// Build Deno command arguments
-	args := []string{"run", "-q"}
+	// --no-prompt ensures Deno fails fast with a permission error instead of
+	// waiting for an interactive answer that will never come.
+	args := []string{"run", "-q", "--no-prompt"}
+
+	// Limit the V8 heap so a runaway script fails with an out of memory error
+	// instead of consuming all the memory on the host.
+	if c.options != nil && c.options.MaxHeapMB > 0 {
+		args = append(args, fmt.Sprintf("--v8-flags=--max-old-space-size=%d", c.options.MaxHeapMB))
+	}
+
+	// Read the script path from the environment when asked to, and resolve relative
+	// script paths against the script base dir, when one is configured
+	scriptPath, err := c.options.resolveScriptPath(c.scriptPath)
+	if err != nil {
+		return err
+	}
 
-	// Attempt to locate a deno config file if none given
+	// Attempt to locate a deno config file if none given, unless discovery has been disabled
 	configPath := c.configPath
-	if configPath == "" {
-		configPath = locateDenoConfigFile(c.scriptPath)
+	if configPath == "" && !c.options.noConfigDiscovery() {
+		configPath = locateDenoConfigFile(scriptPath)
 	}
 	if configPath != "" && configPath != "/dev/null" {
 		args = append(args, "-c", configPath)
+	} else if c.options.noConfigDiscovery() {
+		args = append(args, "--no-config")
 	}
 
-	// Add permissions
-	if c.permissions != nil {
-		if c.permissions.All {
-			args = append(args, "--allow-all")
-		} else {
-			for _, perm := range c.permissions.Allow {
-				args = append(args, fmt.Sprintf("--allow-%s", perm))
-			}
-			for _, perm := range c.permissions.Deny {
-				args = append(args, fmt.Sprintf("--deny-%s", perm))
+	// Load environment variables from a .env file
+	if c.options != nil && c.options.EnvFile != "" {
+		if _, err := os.Stat(c.options.EnvFile); err != nil {
+			return fmt.Errorf("failed to read env file: %w", err)
+		}
+		args = append(args, fmt.Sprintf("--env-file=%s", c.options.EnvFile))
+	}
+
+	// Trust a private CA, eg: for internal services behind TLS interception
+	if c.options != nil && c.options.CertFile != "" {
+		if _, err := os.Stat(c.options.CertFile); err != nil {
+			return fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		args = append(args, fmt.Sprintf("--cert=%s", c.options.CertFile))
+	}
+
+	// Check remote dependencies against a lockfile, refusing to change it when frozen
+	if c.options != nil && c.options.LockFile != "" {
+		args = append(args, fmt.Sprintf("--lock=%s", c.options.LockFile))
+		if c.options.LockFrozen {
+			args = append(args, "--frozen")
+		}
+	}
+
+	// Run the setup shared by every script before the script itself is imported
+	if c.options != nil && c.options.PreloadModule != "" {
+		args = append(args, fmt.Sprintf("--preload=%s", c.options.PreloadModule))
+	}
+
+	// Add permissions, scoping module imports to the trusted hosts when configured
+	args = append(args, c.permissions.withImportHosts(c.options.importHosts()).Args()...)
+
+	// Add any extra flags, eg: for npm compatibility, refusing those that conflict with the flags set above
+	if c.options != nil {
+		for _, flag := range c.options.DenoFlags {
+			if err := ValidateDenoFlag(flag); err != nil {
+				return fmt.Errorf("invalid deno flag: %w", err)
 			}
+			args = append(args, flag)
 		}
 	}
 
 	// Handle script path - support file:// URLs and remote URLs
 	var scriptArg string
-	if strings.Contains(c.scriptPath, "://") {
+	if strings.Contains(scriptPath, "://") {
 		// Parse URL
-		parsedURL, err := url.Parse(c.scriptPath)
+		parsedURL, err := url.Parse(scriptPath)
 		if err != nil {
 			return fmt.Errorf("failed to parse script URL: %w", err)
 		}
@@ -96,11 +243,11 @@ func (c *DenoClient) Start(ctx context.Context) error {
 			scriptArg = absPath
 		} else {
 			// Remote URL (http://, https://, etc.) - pass as-is
-			scriptArg = c.scriptPath
+			scriptArg = scriptPath
 		}
 	} else {
 		// Local file path - convert to absolute path
-		absPath, err := filepath.Abs(c.scriptPath)
+		absPath, err := filepath.Abs(scriptPath)
 		if err != nil {
 			return fmt.Errorf("failed to resolve script path: %w", err)
 		}
@@ -111,15 +258,39 @@ func (c *DenoClient) Start(ctx context.Context) error {
 	// Create command
 	c.process = exec.CommandContext(ctx, c.denoBinaryPath, args...)
 
+	// When the context is cancelled the script is sent a cancel notification by Call,
+	// so rather than killing the process straight away it is given the stop grace
+	// period to roll back any partial work before it is killed.
+	c.process.Cancel = func() error { return nil }
+	c.process.WaitDelay = c.options.stopGrace()
+
+	// Set the configured environment variables, and the registry mirror, nil inherits the providers environment
+	c.process.Env = c.options.environ()
+
 	// Log the full command being executed
 	fullCmd := append([]string{c.denoBinaryPath}, args...)
-	cmdStr := strings.Join(fullCmd, " ")
+	cmdStr := c.redactor.Redact(strings.Join(fullCmd, " "))
 	if isTestContext() {
 		log.Printf("[DEBUG] Executing Deno command: %s", cmdStr)
 	} else {
 		tflog.Debug(ctx, fmt.Sprintf("Executing Deno command: %s", cmdStr))
 	}
 
+	// Keep a copy of what is about to be run for later inspection, without failing the start if it can not be written
+	if c.options != nil && c.options.DebugDir != "" {
+		if recordPath, err := writeDebugRecord(c.options.DebugDir, scriptArg, cmdStr, time.Now()); err != nil {
+			if isTestContext() {
+				log.Printf("[WARN] Failed to write debug record: %s", err)
+			} else {
+				tflog.Warn(ctx, fmt.Sprintf("Failed to write debug record: %s", err))
+			}
+		} else if isTestContext() {
+			log.Printf("[DEBUG] Wrote debug record: %s", recordPath)
+		} else {
+			tflog.Debug(ctx, fmt.Sprintf("Wrote debug record: %s", recordPath))
+		}
+	}
+
 	// Get pipes to the child proc stdio
 	stdin, err := c.process.StdinPipe()
 	if err != nil {
@@ -131,38 +302,312 @@ func (c *DenoClient) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
-	stderr, err := c.process.StderrPipe()
+	// Unlike StderrPipe, which Wait closes as soon as the process exits dropping anything not yet read,
+	// this pipe is read to the end so that the last lines written before a crash can be reported
+	stderr, stderrWriter, err := os.Pipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
+	c.process.Stderr = stderrWriter
 
 	// Start the process
-	if err := c.process.Start(); err != nil {
+	spawned := time.Now()
+	err = c.process.Start()
+	_ = stderrWriter.Close()
+	if err != nil {
+		_ = stderr.Close()
 		return fmt.Errorf("failed to start Deno process: %w", err)
 	}
+	c.metrics.recordSpawn()
 
-	// Pipe stderr to tflog
-	go pipeToDebugLog(ctx, stderr, "[deno stderr] ")
+	// Limit the CPU time the process may consume
+	if c.options != nil && c.options.MaxCPUSeconds > 0 {
+		if err := limitCPU(c.process.Process, c.options.MaxCPUSeconds); err != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Failed to limit the CPU time of the deno child proc: %s", err.Error()))
+		}
+	}
 
-	// Create the jsocket
-	c.Socket = jsocket.New(ctx, stdout, stdin, c.rpcMethods)
+	// Pipe stderr to tflog
+	c.pipeStderr(ctx, stderr)
+
+	// Create the jsocket, logging every message at trace level
+	c.Socket = jsocket.New(ctx, stdout, stdin, c.rpcMethods,
+		jsonrpc2.LogMessages(&rpcLogger{ctx: ctx, redactor: c.redactor}),
+	)
+	if c.options != nil {
+		c.Socket.CallTimeout = c.options.CallTimeout
+	}
+	c.Socket.CancelMethod = "cancel"
+	c.closeOnExit(c.Socket)
 
 	// Wait for the server to be ready
-	var response struct {
-		Ok bool `json:"ok"`
+	if err := c.handshake(ctx); err != nil {
+		return err
 	}
-	if err := c.Socket.Call(ctx, "health", nil, &response); err != nil {
+	c.warnSlowStart(ctx, time.Since(spawned))
+	return nil
+}
+
+// healthRequest is the params of the health method, called by handshake.
+type healthRequest struct {
+	// Meta describes the environment the script is run in
+	Meta Meta `json:"meta"`
+	// ProtocolVersion is the version of the JSON-RPC protocol spoken by the provider
+	ProtocolVersion int `json:"protocolVersion"`
+}
+
+// healthResponse is the result of the health method, called by handshake.
+type healthResponse struct {
+	// Ok is true when the script is ready to be called
+	Ok bool `json:"ok"`
+	// DenoVersion is the version of Deno the script runs under, empty for older bridge libraries
+	DenoVersion string `json:"denoVersion"`
+	// ProtocolVersion is the version of the JSON-RPC protocol spoken by the bridge library
+	ProtocolVersion int `json:"protocolVersion"`
+	// Permissions are the permissions the script declared it needs, if any
+	Permissions []string `json:"permissions"`
+}
+
+// handshake calls the scripts health method, confirming that it is ready and compatible with this provider.
+func (c *DenoClient) handshake(ctx context.Context) error {
+	var response healthResponse
+	params := &healthRequest{Meta: *newMeta(c.options), ProtocolVersion: ProtocolVersion}
+	if err := c.Call(ctx, "health", params, &response); err != nil {
+		var rpcErr *jsonrpc2.Error
+		if errors.As(err, &rpcErr) && rpcErr.Code == codeProtocolVersionMismatch {
+			return fmt.Errorf("%w: %s", ErrProtocolVersionMismatch, rpcErr.Message)
+		}
 		return fmt.Errorf("failed to call the Deno JSON-RPC servers health method: %w", err)
 	}
 	if !response.Ok {
-		return fmt.Errorf("deno process unhealthy: %w", err)
+		return fmt.Errorf("deno process unhealthy")
 	}
 
-	return nil
+	c.declaredPermissions = response.Permissions
+
+	// Older versions of the denobridge lib do not report the runtime or protocol version
+	if response.DenoVersion != "" {
+		c.denoVersion = response.DenoVersion
+		trace.SpanFromContext(ctx).SetAttributes(AttrDenoVersion.String(response.DenoVersion))
+		tflog.Debug(ctx, fmt.Sprintf("Deno child proc is running under Deno %s", response.DenoVersion))
+		if err := checkDenoVersion(response.DenoVersion); err != nil {
+			return err
+		}
+	}
+	return checkProtocolVersion(response.ProtocolVersion)
+}
+
+// warnSlowStart warns when the script took longer than the slow start threshold to answer the health handshake.
+//
+// Everything a script does at the top level, before it starts answering calls, is done again by every Deno process
+// started for it, which without max_idle_processes or daemon is one per operation.
+func (c *DenoClient) warnSlowStart(ctx context.Context, elapsed time.Duration) {
+	threshold := c.options.slowStartWarning()
+	if threshold <= 0 || elapsed <= threshold {
+		return
+	}
+	msg := fmt.Sprintf(
+		"The Deno script %s took %s to start, longer than the %s slow start warning. This is expected while Deno downloads its imports for the first time, otherwise check for slow top-level code that runs when the script is imported, which is run again for every Deno process started. Move it into the methods that need it, or reuse processes with max_idle_processes or daemon.",
+		c.scriptPath, elapsed.Round(time.Millisecond), threshold,
+	)
+	if isTestContext() {
+		log.Printf("[WARN] %s", msg)
+	} else {
+		tflog.Warn(ctx, msg)
+	}
+}
+
+// Call invokes a method on the Deno JSON-RPC server and waits for the response.
+//
+// If ctx is cancelled while waiting, a "cancel" notification is sent to the script
+// so that well behaved scripts can abort long running work and roll back.
+//
+// If the process exits while waiting, the call is given the response grace period to
+// receive a response that was written just before the exit. After that it fails with an
+// error explaining that the script never responded, rather than a bare connection error.
+func (c *DenoClient) Call(ctx context.Context, method string, params, result any) (err error) {
+	defer c.metrics.recordCall(method, time.Now())
+
+	ctx, span := c.startSpan(ctx, "deno.call "+method, semconv.RPCSystemKey.String("jsonrpc"), semconv.RPCMethod(method))
+	defer func() { EndSpan(span, err) }()
+
+	// A process that died since the last call, eg: while idle in the pool, can not answer this one
+	if c.process != nil && c.hasExited() {
+		c.exitReported.Store(true)
+		return newProcessExitedError(c, method, true)
+	}
+
+	// The socket is replaced when a failed start is torn down, so hold on to the one this call is made over
+	socket := c.Socket
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// The operation context is already done so it can't be used to send the notification
+			if err := socket.Notify(context.Background(), "cancel", map[string]string{"method": method}); err != nil {
+				tflog.Warn(ctx, fmt.Sprintf("Failed to notify deno child proc of cancellation: %s", err.Error()))
+			}
+		case <-done:
+		}
+	}()
+
+	if c.process == nil {
+		return socket.Call(ctx, method, params, result)
+	}
+
+	called := make(chan error, 1)
+	go func() {
+		called <- socket.Call(ctx, method, params, result)
+	}()
+
+	select {
+	case err := <-called:
+		// The connection is closed, or writing to it fails, when the process exits, confirm that is why
+		if !connectionLost(err) {
+			return err
+		}
+		select {
+		case <-c.exited():
+			return c.noResponseError(method)
+		case <-time.After(c.options.responseGrace()):
+			return err
+		}
+	case <-c.exited():
+		select {
+		case err := <-called:
+			if connectionLost(err) {
+				return c.noResponseError(method)
+			}
+			return err
+		case <-time.After(c.options.responseGrace()):
+			return c.noResponseError(method)
+		}
+	}
+}
+
+// connectionLost reports whether err is how a call fails when the other end of the connection has gone away.
+//
+// Reading finds the connection closed, while writing fails with a broken pipe. Deno child processes on Windows
+// do not produce EPIPE, their pipes report being closed instead.
+func connectionLost(err error) bool {
+	return errors.Is(err, jsonrpc2.ErrClosed) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, os.ErrClosed) ||
+		errors.Is(err, io.ErrClosedPipe)
+}
+
+// exited returns a channel that is closed once the child process has exited.
+//
+// The process may only be waited on once, so a single goroutine is shared by everything that needs
+// to know when it exits. Only call this once the process has been started.
+func (c *DenoClient) exited() <-chan struct{} {
+	c.exitOnce.Do(func() {
+		c.exitCh = make(chan struct{})
+		go func() {
+			c.exitErr = newExitError(c.process.Wait())
+			close(c.exitCh)
+		}()
+	})
+	return c.exitCh
+}
+
+// closeOnExit closes socket once the child process has exited, whenever that is, so that nothing is left waiting on
+// a process that can never respond, eg: when a grandchild process keeps its stdout open. A response written just
+// before the exit is first given the response grace period to be read.
+func (c *DenoClient) closeOnExit(socket *jsocket.JSocket) {
+	exited := c.exited()
+	grace := c.options.responseGrace()
+	go func() {
+		<-exited
+		time.Sleep(grace)
+		_ = socket.Close()
+	}()
+}
+
+// AddSecrets adds values, eg: those of write-only props, that are scrubbed from everything this client
+// logs, including the JSON-RPC messages logged at trace level, and from the output of Redact.
+//
+// Secrets may be added before or after the client is started and are kept for the life of the process.
+func (c *DenoClient) AddSecrets(values ...string) {
+	c.secretsMu.Lock()
+	defer c.secretsMu.Unlock()
+	c.secrets = append(c.secrets, values...)
+	if c.redactor != nil {
+		c.redactor.addSecrets(values...)
+	}
+}
+
+// Redact returns s with the secrets added by AddSecrets and any matches of the log redact patterns replaced.
+// Use it to scrub messages that originate from the script before they are shown, eg: in diagnostics.
+func (c *DenoClient) Redact(s string) string {
+	if c.redactor == nil {
+		redactor := &logRedactor{}
+		c.secretsMu.Lock()
+		redactor.addSecrets(c.secrets...)
+		c.secretsMu.Unlock()
+		return redactor.Redact(s)
+	}
+	return c.redactor.Redact(s)
+}
+
+// hasExited reports whether the child process has exited, or was never started.
+func (c *DenoClient) hasExited() bool {
+	if c.process == nil || c.process.Process == nil {
+		return true
+	}
+	select {
+	case <-c.exited():
+		return true
+	default:
+		return false
+	}
+}
+
+// noResponseError explains that the process exited before responding to a call of method.
+func (c *DenoClient) noResponseError(method string) error {
+	c.exitReported.Store(true)
+	return newProcessExitedError(c, method, false)
+}
+
+// Wait waits for the child process to exit and returns its exit code.
+//
+// The error is nil when the process exited cleanly, otherwise it is the *ExitError describing how it exited,
+// followed by the last lines it wrote to stderr. A process ended by a signal has the exit code -1.
+// When ctx is done first the exit code is -1 and the error is the cause of ctx.
+func (c *DenoClient) Wait(ctx context.Context) (int, error) {
+	if c.process == nil || c.process.Process == nil {
+		return -1, errors.New("the Deno process has not been started")
+	}
+	select {
+	case <-c.exited():
+	case <-ctx.Done():
+		return -1, context.Cause(ctx)
+	}
+	if c.exitErr == nil {
+		return 0, nil
+	}
+	code := -1
+	var exitErr *ExitError
+	if errors.As(c.exitErr, &exitErr) {
+		code = exitErr.Code
+	}
+	return code, fmt.Errorf("%w%s", c.exitErr, c.describeStderrTail())
 }
 
 // Stop terminates the Deno child process.
-func (c *DenoClient) Stop() error {
+//
+// The process is first notified to shutdown gracefully. If it has not exited within the
+// configured stop grace period it is sent SIGTERM, giving any cleanup logic in the script
+// another grace period to complete, after which it is forcefully killed.
+func (c *DenoClient) Stop() (err error) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, span := c.startSpan(ctx, "deno.stop")
+	defer func() { EndSpan(span, err) }()
+
 	if c.Socket != nil {
 		if err := c.Socket.Notify(c.ctx, "shutdown", nil); err != nil {
 			return fmt.Errorf("failed to notify deno child proc to shutdown gracefully: %v", err)
@@ -172,13 +617,223 @@ func (c *DenoClient) Stop() error {
 		}
 	}
 	if c.process != nil {
-		if err := c.process.Wait(); err != nil {
-			return fmt.Errorf("deno child proc died: %w", err)
+		grace := c.options.stopGrace()
+		exited := c.exited()
+
+		// Give the process a chance to exit on its own
+		select {
+		case <-exited:
+			if c.exitErr != nil && !c.exitReported.Load() {
+				return fmt.Errorf("deno child proc died: %w%s", c.exitErr, c.describeStderrTail())
+			}
+			return nil
+		case <-time.After(grace):
 		}
+
+		// Then ask it to terminate, a non-zero exit status is expected at this point
+		if err := terminateProcess(c.process.Process); err != nil {
+			return fmt.Errorf("failed to terminate deno child proc: %w", err)
+		}
+		select {
+		case <-exited:
+			return nil
+		case <-time.After(grace):
+		}
+
+		// Finally kill it
+		if err := c.process.Process.Kill(); err != nil {
+			return fmt.Errorf("failed to kill deno child proc: %w", err)
+		}
+		<-exited
+		return fmt.Errorf("deno child proc did not exit within %s of being terminated and was killed", grace)
 	}
 	return nil
 }
 
+// resetStderrDetails discards the details collected from the child processes stderr so far,
+// eg: before a pooled client is reused for another operation.
+func (c *DenoClient) resetStderrDetails() {
+	c.stderrMu.Lock()
+	defer c.stderrMu.Unlock()
+	c.missingPermissions = nil
+	c.outOfMemory = false
+	c.scriptErrors = scriptErrorParser{}
+	c.stderrTail = lineRing{}
+}
+
+// pipeStderr logs and records everything the child process writes to the given stderr pipe,
+// closing the pipe and then stderrDone once it has been read to the end.
+func (c *DenoClient) pipeStderr(ctx context.Context, stderr io.ReadCloser) {
+	done := make(chan struct{})
+	c.stderrDone = done
+	go func() {
+		defer close(done)
+		defer stderr.Close()
+		pipeToDebugLog(ctx, stderr, "[deno stderr] ", c.redactor, c.recordStderrLine)
+	}()
+}
+
+// recordStderrLine inspects a line written to stderr by the Deno child process,
+// collecting details that help explain why an operation failed.
+func (c *DenoClient) recordStderrLine(line string) {
+	c.stderrMu.Lock()
+	c.scriptErrors.parseLine(line)
+	c.stderrTail.add(line)
+	c.stderrMu.Unlock()
+
+	if isOutOfMemory(line) {
+		c.stderrMu.Lock()
+		c.outOfMemory = true
+		c.stderrMu.Unlock()
+		return
+	}
+	perms := parseMissingPermissions(line)
+	if len(perms) == 0 {
+		return
+	}
+	c.stderrMu.Lock()
+	defer c.stderrMu.Unlock()
+	for _, perm := range perms {
+		if !slices.Contains(c.missingPermissions, perm) {
+			c.missingPermissions = append(c.missingPermissions, perm)
+		}
+	}
+}
+
+// MissingPermissions returns the permissions that the Deno runtime refused to grant the script.
+//
+// The permission errors are gathered from both the child processes stderr and the given error (if any).
+// Because stderr is read asynchronously, when neither names a permission this waits for stderr to be
+// read to the end, for at most missingPermissionsWait as that only happens once the process has exited.
+// It is intended to be called after an operation has failed.
+func (c *DenoClient) MissingPermissions(err error) []MissingPermission {
+	var perms []MissingPermission
+	if err != nil {
+		perms = parseMissingPermissions(err.Error())
+	}
+
+	c.stderrMu.Lock()
+	collected := len(c.missingPermissions) > 0
+	c.stderrMu.Unlock()
+	if len(perms) == 0 && !collected {
+		c.waitStderr(missingPermissionsWait)
+	}
+
+	c.stderrMu.Lock()
+	defer c.stderrMu.Unlock()
+	for _, perm := range c.missingPermissions {
+		if !slices.Contains(perms, perm) {
+			perms = append(perms, perm)
+		}
+	}
+	return perms
+}
+
+// SuggestAllowList returns a least-privilege allow list that grants the given missing permissions on top
+// of the currently allowed ones. It returns nil unless learn mode is enabled and permissions are missing.
+func (c *DenoClient) SuggestAllowList(missing []MissingPermission) []string {
+	if c.permissions == nil || !c.permissions.Learn || c.permissions.All || len(missing) == 0 {
+		return nil
+	}
+	return suggestAllowList(c.permissions.Allow, missing)
+}
+
+// DeclaredPermissions returns the permissions the script declared it needs, or nil when it
+// did not declare any. Only populated once the client has been started.
+func (c *DenoClient) DeclaredPermissions() []string {
+	return c.declaredPermissions
+}
+
+// OutOfMemory reports whether the Deno runtime has reported running out of heap memory.
+//
+// Call this after an operation has failed, ideally after MissingPermissions which gives
+// stderr a moment to be collected.
+func (c *DenoClient) OutOfMemory() bool {
+	c.stderrMu.Lock()
+	defer c.stderrMu.Unlock()
+	return c.outOfMemory
+}
+
+// ScriptError returns the most recent error the script failed to handle, or nil if none has been reported.
+//
+// Call this after an operation has failed, ideally after MissingPermissions which gives
+// stderr a moment to be collected.
+func (c *DenoClient) ScriptError() *ScriptError {
+	c.stderrMu.Lock()
+	defer c.stderrMu.Unlock()
+	if c.scriptErrors.last == nil {
+		return nil
+	}
+	scriptError := *c.scriptErrors.last
+	return &scriptError
+}
+
+// stderrTailLines is how many of the last lines written to stderr are kept, see StderrTail.
+const stderrTailLines = 20
+
+// stderrTailWait is how long StderrTail waits for the rest of stderr to be read once the process has exited.
+var stderrTailWait = 250 * time.Millisecond
+
+// missingPermissionsWait is how long MissingPermissions waits for the rest of stderr to be read, kept short
+// as a process that is still running, eg: a daemon whose operation failed, does not close its stderr.
+var missingPermissionsWait = 50 * time.Millisecond
+
+// waitStderr waits for everything the child process wrote to stderr to be read, for at most the given time.
+func (c *DenoClient) waitStderr(limit time.Duration) {
+	if c.stderrDone == nil {
+		return
+	}
+	select {
+	case <-c.stderrDone:
+	case <-time.After(limit):
+	}
+}
+
+// StderrTail returns the last lines the child process wrote to stderr, oldest first and scrubbed by Redact.
+//
+// Because stderr is read asynchronously, this waits a short moment for the output written just before the
+// process exited to be read. It is intended to be called after the process has exited or an operation failed.
+func (c *DenoClient) StderrTail() []string {
+	c.waitStderr(stderrTailWait)
+	c.stderrMu.Lock()
+	lines := c.stderrTail.lines()
+	c.stderrMu.Unlock()
+	for i, line := range lines {
+		lines[i] = c.Redact(line)
+	}
+	return lines
+}
+
+// describeStderrTail formats StderrTail to be appended to an error, empty when nothing was written to stderr.
+func (c *DenoClient) describeStderrTail() string {
+	lines := c.StderrTail()
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n\nThe last lines written to stderr were:\n" + strings.Join(lines, "\n")
+}
+
+// lineRing keeps the last stderrTailLines lines added to it.
+type lineRing struct {
+	buf  []string
+	next int
+}
+
+// add adds a line, dropping the oldest once the ring is full.
+func (r *lineRing) add(line string) {
+	if len(r.buf) < stderrTailLines {
+		r.buf = append(r.buf, line)
+		return
+	}
+	r.buf[r.next] = line
+	r.next = (r.next + 1) % stderrTailLines
+}
+
+// lines returns a copy of the lines in the ring, oldest first.
+func (r *lineRing) lines() []string {
+	return append(slices.Clone(r.buf[r.next:]), r.buf[:r.next]...)
+}
+
 // isTestContext returns true if running in a test context.
 func isTestContext() bool {
 	// Check if TF_LOG_PROVIDER_DENO_TOFU_BRIDGE is not set (typical in tests)
@@ -186,24 +841,116 @@ func isTestContext() bool {
 	return os.Getenv("DENO_TOFU_BRIDGE_TEST_MODE") == "true"
 }
 
-// pipeToDebugLog reads from a reader and logs each line as debug.
-func pipeToDebugLog(ctx context.Context, reader io.Reader, prefix string) {
-	scanner := bufio.NewScanner(reader)
+// logInfo logs a message from the script at info level, scrubbed by the clients redactor.
+func (c *DenoClient) logInfo(ctx context.Context, msg string) {
+	msg = c.redactor.Redact(msg)
 	if isTestContext() {
-		// In test context, write directly to stdout
-		for scanner.Scan() {
-			log.Printf("[DEBUG] %s%s", prefix, scanner.Text())
-		}
+		log.Printf("[INFO] %s", msg)
 	} else {
-		// In Terraform context, use tflog
-		for scanner.Scan() {
-			tflog.Debug(ctx, prefix+scanner.Text())
+		tflog.Info(ctx, msg)
+	}
+}
+
+// pipeToDebugLog reads from a reader and logs each line as debug, scrubbed by the given redactor.
+// If onLine is not nil it is also called with every line that is read, before it is redacted.
+//
+// Lines end at a newline or a carriage return, so that progress bars redrawn in place with \r
+// are logged as they are drawn. A partial line is logged once nothing more has been written for
+// stderrIdleFlush, so that output without any line endings still surfaces promptly.
+func pipeToDebugLog(ctx context.Context, reader io.Reader, prefix string, redactor *logRedactor, onLine func(line string)) {
+	readLines(reader, stderrIdleFlush, func(line string) {
+		if isTestContext() {
+			// In test context, write directly to stdout
+			log.Printf("[DEBUG] %s%s", prefix, redactor.Redact(line))
+		} else {
+			// In Terraform context, use tflog
+			tflog.Debug(ctx, prefix+redactor.Redact(line))
+		}
+		if onLine != nil {
+			onLine(line)
+		}
+	})
+}
+
+// stderrIdleFlush is how long a partial line may wait for its line ending before it is logged anyway.
+var stderrIdleFlush = 500 * time.Millisecond
+
+// maxLineLength is the length at which a line is emitted even though it has not ended, matching bufio.Scanner.
+const maxLineLength = bufio.MaxScanTokenSize
+
+// readLines calls emit with every line read from reader until it is exhausted.
+//
+// A line ends at \n, \r or \r\n. A partial line is emitted once nothing more has been read for idle,
+// the rest of that line is then emitted as a line of its own once it ends.
+func readLines(reader io.Reader, idle time.Duration, emit func(line string)) {
+	chunks := make(chan []byte)
+	go func() {
+		defer close(chunks)
+		buf := make([]byte, 4096)
+		for {
+			n, err := reader.Read(buf)
+			if n > 0 {
+				chunks <- bytes.Clone(buf[:n])
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var partial []byte
+	afterCR := false // a \r was just read, so a following \n ends the same line
+	flushed := false // the start of the current line was already emitted by the idle timer
+	timer := time.NewTimer(idle)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				if len(partial) > 0 {
+					emit(string(partial))
+				}
+				return
+			}
+			for _, b := range chunk {
+				if b == '\n' && afterCR {
+					afterCR = false
+					continue
+				}
+				afterCR = b == '\r'
+				if b != '\n' && b != '\r' {
+					partial = append(partial, b)
+					if len(partial) < maxLineLength {
+						continue
+					}
+				}
+				if len(partial) > 0 || !flushed {
+					emit(string(partial))
+				}
+				partial = partial[:0]
+				flushed = false
+			}
+			if len(partial) > 0 {
+				timer.Reset(idle)
+			} else {
+				timer.Stop()
+			}
+		case <-timer.C:
+			if len(partial) > 0 {
+				emit(string(partial))
+				partial = partial[:0]
+				flushed = true
+			}
 		}
 	}
 }
 
 // cachedConfigLookups stores config file paths to avoid repeated filesystem lookups.
-var cachedConfigLookups = make(map[string]string)
+// It maps a script path to the config file path found for it, and is shared by every
+// client so that concurrent starts for the same script can safely use it.
+var cachedConfigLookups sync.Map
 
 // locateDenoConfigFile searches for a Deno configuration file (deno.json or deno.jsonc)
 // starting from the script file's directory and traversing upward through parent
@@ -233,8 +980,8 @@ func locateDenoConfigFile(scriptPath string) string {
 	}
 
 	// Check cache first
-	if cached, ok := cachedConfigLookups[scriptPath]; ok {
-		return cached
+	if cached, ok := cachedConfigLookups.Load(scriptPath); ok {
+		return cached.(string)
 	}
 
 	// Start from the directory containing the script
@@ -246,14 +993,14 @@ func locateDenoConfigFile(scriptPath string) string {
 		// Check for deno.json
 		denoJsonPath := filepath.Join(currentDir, "deno.json")
 		if _, err := os.Stat(denoJsonPath); err == nil {
-			cachedConfigLookups[scriptPath] = denoJsonPath
+			cachedConfigLookups.Store(scriptPath, denoJsonPath)
 			return denoJsonPath
 		}
 
 		// Check for deno.jsonc
 		denoJsoncPath := filepath.Join(currentDir, "deno.jsonc")
 		if _, err := os.Stat(denoJsoncPath); err == nil {
-			cachedConfigLookups[scriptPath] = denoJsoncPath
+			cachedConfigLookups.Store(scriptPath, denoJsoncPath)
 			return denoJsoncPath
 		}
 
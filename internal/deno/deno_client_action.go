@@ -7,6 +7,7 @@ import (
 
 	"github.com/brad-jones/terraform-provider-denobridge/internal/jsocket"
 	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // DenoClientAction is a client for executing Terraform actions using a Deno runtime.
@@ -25,16 +26,18 @@ type DenoClientAction struct {
 //   - scriptPath: The path to the TypeScript/JavaScript action script to execute
 //   - configPath: The path to the Deno configuration file (deno.json)
 //   - permissions: The Deno security permissions to grant the runtime
+//   - options: Optional settings that tune how the Deno process is run (may be nil)
 //   - resp: The Terraform action InvokeResponse for sending progress updates
 //
 // Returns a configured DenoClientAction ready to invoke actions.
-func NewDenoClientAction(denoBinaryPath, scriptPath, configPath string, permissions *Permissions, resp *action.InvokeResponse) *DenoClientAction {
+func NewDenoClientAction(denoBinaryPath, scriptPath, configPath string, permissions *Permissions, options *ClientOptions, resp *action.InvokeResponse) *DenoClientAction {
 	return &DenoClientAction{
 		NewDenoClient(
 			denoBinaryPath,
 			scriptPath,
 			configPath,
 			permissions,
+			options,
 			jsocket.TypedServerMethods(&DenoClientActionServerMethods{resp}),
 		),
 	}
@@ -50,8 +53,8 @@ type InvokeRequest struct {
 // InvokeResponse represents the response from invoking a Terraform action.
 // It indicates whether the action has completed successfully.
 type InvokeResponse struct {
-	// Done indicates whether the action invocation completed successfully
-	Done bool `json:"done"`
+	// Done indicates whether the action invocation completed successfully, see IsDone for when it is omitted
+	Done *bool `json:"done"`
 	// Diagnostics contains any warnings or errors to display to the user
 	Diagnostics *[]struct {
 		// Severity indicates the diagnostic level ("error" or "warning")
@@ -65,6 +68,20 @@ type InvokeResponse struct {
 	} `json:"diagnostics,omitempty"`
 }
 
+// IsDone reports whether the script reported the action as done. A response that omits done is treated as done,
+// making done: false an opt-in failure signal, unless strict is set in which case done: true is required.
+func (r *InvokeResponse) IsDone(strict bool) bool {
+	return isDone(r.Done, strict)
+}
+
+// isDone interprets the done field of a response, which is only required when strict is set.
+func isDone(done *bool, strict bool) bool {
+	if done == nil {
+		return !strict
+	}
+	return *done
+}
+
 // Invoke executes the Terraform action by calling the "invoke" method via JSON-RPC.
 // It sends the action properties to the Deno runtime and waits for completion.
 //
@@ -72,11 +89,22 @@ type InvokeResponse struct {
 //   - ctx: The context for the operation, used for cancellation and timeouts
 //   - params: The invoke request containing the action properties
 //
-// Returns an error if the JSON-RPC call fails or the action does not complete successfully.
+// Invoke then waits, for at most the progress flush timeout, until the progress updates the script sent before it
+// responded have been delivered, so that none are lost or sent after the action has completed.
+//
+// Returns an error if the JSON-RPC call fails, use InvokeResponse.IsDone to check that the action completed.
 func (c *DenoClientAction) Invoke(ctx context.Context, params *InvokeRequest) (*InvokeResponse, error) {
 	var response *InvokeResponse
-	if err := c.Client.Socket.Call(ctx, "invoke", params, &response); err != nil {
-		return nil, fmt.Errorf("failed to call invoke method over JSON-RPC: %v", err)
+	if err := c.Client.Call(ctx, "invoke", params, &response); err != nil {
+		return nil, fmt.Errorf("failed to call invoke method over JSON-RPC: %w", err)
+	}
+	if err := c.Client.Socket.Flush(ctx, c.Client.options.progressFlushTimeout()); err != nil {
+		// The action itself is done, only some of its progress updates may be missing
+		tflog.Warn(ctx, fmt.Sprintf("Not every progress update of the action was delivered: %s", err.Error()))
+	}
+	if response == nil {
+		// A script that returns nothing at all has not said the action failed
+		response = &InvokeResponse{}
 	}
 	return response, nil
 }
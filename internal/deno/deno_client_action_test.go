@@ -0,0 +1,107 @@
+package deno
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func TestInvokeResponse_IsDone(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   string
+		strict   bool
+		expected bool
+	}{
+		{name: "omitted", result: `{}`, strict: false, expected: true},
+		{name: "omitted strict", result: `{}`, strict: true, expected: false},
+		{name: "diagnostics only", result: `{"diagnostics":[{"severity":"warning","summary":"slow","detail":""}]}`, strict: false, expected: true},
+		{name: "diagnostics only strict", result: `{"diagnostics":[{"severity":"warning","summary":"slow","detail":""}]}`, strict: true, expected: false},
+		{name: "true", result: `{"done":true}`, strict: false, expected: true},
+		{name: "true strict", result: `{"done":true}`, strict: true, expected: true},
+		{name: "false", result: `{"done":false}`, strict: false, expected: false},
+		{name: "false strict", result: `{"done":false}`, strict: true, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response InvokeResponse
+			if err := json.Unmarshal([]byte(tt.result), &response); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if done := response.IsDone(tt.strict); done != tt.expected {
+				t.Errorf("Expected IsDone to be %v, got %v", tt.expected, done)
+			}
+		})
+	}
+}
+
+// connectFakeAction connects a DenoClientAction to a fake script whose invoke method sends count progress updates
+// as fast as it can before responding.
+func connectFakeAction(t *testing.T, count int, options *ClientOptions, resp *action.InvokeResponse) *DenoClientAction {
+	t.Helper()
+	c := NewDenoClientAction("deno", "script.ts", "", nil, options, resp)
+	connectClient(t, c.Client, func(ctx context.Context, conn *jsonrpc2.Conn) map[string]any {
+		return map[string]any{
+			"invoke": func() (map[string]any, error) {
+				for i := range count {
+					if err := conn.Notify(ctx, "invokeProgress", map[string]string{"message": fmt.Sprintf("step %d", i)}); err != nil {
+						return nil, err
+					}
+				}
+				return map[string]any{"done": true}, nil
+			},
+		}
+	})
+	return c
+}
+
+func TestDenoClientAction_Invoke_DeliversAllProgress(t *testing.T) {
+	var mu sync.Mutex
+	var messages []string
+	resp := &action.InvokeResponse{
+		SendProgress: func(event action.InvokeProgressEvent) {
+			// Slow enough that the response arrives while progress is still being delivered
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			messages = append(messages, event.Message)
+		},
+	}
+	c := connectFakeAction(t, 100, &ClientOptions{ProgressFlushTimeout: 5 * time.Second}, resp)
+
+	response, err := c.Invoke(t.Context(), &InvokeRequest{Props: map[string]any{}})
+	assert.NoError(t, err)
+	assert.True(t, response.IsDone(true))
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := make([]string, 100)
+	for i := range expected {
+		expected[i] = fmt.Sprintf("step %d\r", i)
+	}
+	assert.Equal(t, expected, messages)
+}
+
+func TestDenoClientAction_Invoke_ProgressFlushTimeout(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	resp := &action.InvokeResponse{
+		SendProgress: func(event action.InvokeProgressEvent) {
+			<-release
+		},
+	}
+	c := connectFakeAction(t, 1, &ClientOptions{ProgressFlushTimeout: 50 * time.Millisecond}, resp)
+
+	start := time.Now()
+	response, err := c.Invoke(t.Context(), &InvokeRequest{Props: map[string]any{}})
+	assert.NoError(t, err)
+	assert.True(t, response.IsDone(true))
+	assert.True(t, time.Since(start) < 2*time.Second, "Expected Invoke to stop waiting for the progress update")
+}
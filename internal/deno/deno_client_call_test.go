@@ -0,0 +1,143 @@
+package deno
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/brad-jones/terraform-provider-denobridge/internal/jsocket"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// connectFakeScript connects a DenoClient to an in-memory JSON-RPC server standing in for a Deno script.
+func connectFakeScript(t *testing.T, methods map[string]any) *DenoClient {
+	t.Helper()
+	c := NewDenoClient("deno", "script.ts", "", nil, nil, func(ctx context.Context, c *jsonrpc2.Conn) map[string]any {
+		return map[string]any{}
+	})
+	connectClient(t, c, func(ctx context.Context, c *jsonrpc2.Conn) map[string]any {
+		return methods
+	})
+	return c
+}
+
+// connectClient connects c, with its own server methods, to an in-memory JSON-RPC server standing in for a Deno script.
+func connectClient(t *testing.T, c *DenoClient, methods func(ctx context.Context, c *jsonrpc2.Conn) map[string]any) {
+	t.Helper()
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	server := jsocket.New(t.Context(), serverReader, serverWriter, methods)
+	t.Cleanup(func() { _ = server.Close() })
+
+	c.Socket = jsocket.New(t.Context(), clientReader, clientWriter, c.rpcMethods)
+	t.Cleanup(func() { _ = c.Socket.Close() })
+}
+
+func TestDenoClient_Call_NotifiesCancel(t *testing.T) {
+	cancelled := make(chan string, 1)
+	release := make(chan struct{})
+	c := connectFakeScript(t, map[string]any{
+		"create": func() map[string]any {
+			select {
+			case <-release:
+			case <-time.After(5 * time.Second):
+			}
+			return map[string]any{}
+		},
+		"cancel": func(params map[string]string) {
+			cancelled <- params["method"]
+			close(release)
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	err := c.Call(ctx, "create", nil, nil)
+	assert.IsError(t, err, context.DeadlineExceeded)
+
+	select {
+	case method := <-cancelled:
+		assert.Equal(t, "create", method)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a cancel notification to be sent to the script")
+	}
+}
+
+func TestDenoClient_Call_NoCancelOnSuccess(t *testing.T) {
+	cancelled := make(chan string, 1)
+	c := connectFakeScript(t, map[string]any{
+		"read": func() map[string]any {
+			return map[string]any{"ok": true}
+		},
+		"cancel": func(params map[string]string) {
+			cancelled <- params["method"]
+		},
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	var result map[string]any
+	assert.NoError(t, c.Call(ctx, "read", nil, &result))
+	assert.Equal(t, true, result["ok"])
+	cancel()
+
+	select {
+	case method := <-cancelled:
+		t.Fatalf("Expected no cancel notification, got one for %s", method)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestDenoClient_Call_Timeout(t *testing.T) {
+	cancelled := make(chan string, 1)
+	release := make(chan struct{})
+	c := connectFakeScript(t, map[string]any{
+		"create": func() map[string]any {
+			select {
+			case <-release:
+			case <-time.After(5 * time.Second):
+			}
+			return map[string]any{}
+		},
+		"cancel": func(params map[string]string) {
+			cancelled <- params["method"]
+			close(release)
+		},
+	})
+	c.Socket.CallTimeout = 100 * time.Millisecond
+	c.Socket.CancelMethod = "cancel"
+
+	err := c.Call(t.Context(), "create", nil, nil)
+	assert.IsError(t, err, jsocket.ErrCallTimeout)
+	assert.EqualError(t, err, "create() did not respond within 100ms")
+
+	var timeoutErr *jsocket.CallTimeoutError
+	assert.True(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, "create", timeoutErr.Method)
+
+	select {
+	case method := <-cancelled:
+		assert.Equal(t, "create", method)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the script to be notified of the timeout")
+	}
+}
+
+func TestConnectionLost(t *testing.T) {
+	for _, err := range []error{
+		jsonrpc2.ErrClosed,
+		fmt.Errorf("write: %w", syscall.EPIPE),
+		fmt.Errorf("write |1: %w", os.ErrClosed),
+		io.ErrClosedPipe,
+	} {
+		assert.True(t, connectionLost(err), "%v", err)
+	}
+	assert.False(t, connectionLost(errors.New("boom")))
+}
@@ -0,0 +1,36 @@
+package deno
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestLocateDenoConfigFile_Concurrent tests that concurrent starts for the same script
+// locate the same config file without racing on the shared lookup cache.
+func TestLocateDenoConfigFile_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "deno.json")
+	if err := os.WriteFile(configPath, []byte("{}"), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %s", err)
+	}
+	scriptPath := filepath.Join(dir, "scripts", "script.ts")
+
+	var wg sync.WaitGroup
+	found := make([]string, 50)
+	for i := range found {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found[i] = locateDenoConfigFile(scriptPath)
+		}()
+	}
+	wg.Wait()
+
+	for i, got := range found {
+		if got != configPath {
+			t.Errorf("Lookup %d: expected '%s', got '%s'", i, configPath, got)
+		}
+	}
+}
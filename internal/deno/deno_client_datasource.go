@@ -21,15 +21,17 @@ type DenoClientDatasource struct {
 //   - scriptPath: The path to the TypeScript/JavaScript data source script to execute
 //   - configPath: The path to the Deno configuration file (deno.json)
 //   - permissions: The Deno security permissions to grant the runtime
+//   - options: Optional settings that tune how the Deno process is run (may be nil)
 //
 // Returns a configured DenoClientDatasource ready to read data.
-func NewDenoClientDatasource(denoBinaryPath, scriptPath, configPath string, permissions *Permissions) *DenoClientDatasource {
+func NewDenoClientDatasource(denoBinaryPath, scriptPath, configPath string, permissions *Permissions, options *ClientOptions) *DenoClientDatasource {
 	return &DenoClientDatasource{
 		NewDenoClient(
 			denoBinaryPath,
 			scriptPath,
 			configPath,
 			permissions,
+			options,
 			nil,
 		),
 	}
@@ -49,6 +51,8 @@ type ReadResponse struct {
 	Result any `json:"result"`
 	// SensitiveResult contains the data source sensitive data (marked as sensitive in Terraform)
 	SensitiveResult any `json:"sensitiveResult"`
+	// Skip indicates nothing has changed and the result of the previous identical read should be reused
+	Skip bool `json:"skip,omitempty"`
 	// Diagnostics contains any warnings or errors to display to the user
 	Diagnostics *[]struct {
 		// Severity indicates the diagnostic level ("error" or "warning")
@@ -72,8 +76,8 @@ type ReadResponse struct {
 // Returns the read response containing the retrieved data, or an error if the JSON-RPC call fails.
 func (c *DenoClientDatasource) Read(ctx context.Context, params *ReadRequest) (*ReadResponse, error) {
 	var response *ReadResponse
-	if err := c.Client.Socket.Call(ctx, "read", params, &response); err != nil {
-		return nil, fmt.Errorf("failed to call read method over JSON-RPC: %v", err)
+	if err := c.Client.Call(ctx, "read", params, &response); err != nil {
+		return nil, fmt.Errorf("failed to call read method over JSON-RPC: %w", err)
 	}
 	return response, nil
 }
@@ -24,15 +24,17 @@ type DenoClientEphemeralResource struct {
 //   - scriptPath: The path to the TypeScript/JavaScript ephemeral resource script to execute
 //   - configPath: The path to the Deno configuration file (deno.json)
 //   - permissions: The Deno security permissions to grant the runtime
+//   - options: Optional settings that tune how the Deno process is run (may be nil)
 //
 // Returns a configured DenoClientEphemeralResource ready to manage ephemeral resources.
-func NewDenoClientEphemeralResource(denoBinaryPath, scriptPath, configPath string, permissions *Permissions) *DenoClientEphemeralResource {
+func NewDenoClientEphemeralResource(denoBinaryPath, scriptPath, configPath string, permissions *Permissions, options *ClientOptions) *DenoClientEphemeralResource {
 	return &DenoClientEphemeralResource{
 		NewDenoClient(
 			denoBinaryPath,
 			scriptPath,
 			configPath,
 			permissions,
+			options,
 			nil,
 		),
 	}
@@ -79,8 +81,8 @@ type OpenResponse struct {
 // Returns the open response containing the resource data and optional renewal time, or an error if the JSON-RPC call fails.
 func (c *DenoClientEphemeralResource) Open(ctx context.Context, params *OpenRequest) (*OpenResponse, error) {
 	var response *OpenResponse
-	if err := c.Client.Socket.Call(ctx, "open", params, &response); err != nil {
-		return nil, fmt.Errorf("failed to call open method over JSON-RPC: %v", err)
+	if err := c.Client.Call(ctx, "open", params, &response); err != nil {
+		return nil, fmt.Errorf("failed to call open method over JSON-RPC: %w", err)
 	}
 	return response, nil
 }
@@ -122,8 +124,8 @@ type RenewResponse struct {
 // Returns the renew response containing the next renewal time, or an error if the JSON-RPC call fails.
 func (c *DenoClientEphemeralResource) Renew(ctx context.Context, params *RenewRequest) (*RenewResponse, error) {
 	var response *RenewResponse
-	if err := c.Client.Socket.Call(ctx, "renew", params, &response); err != nil {
-		return nil, fmt.Errorf("failed to call renew method over JSON-RPC: %v", err)
+	if err := c.Client.Call(ctx, "renew", params, &response); err != nil {
+		return nil, fmt.Errorf("failed to call renew method over JSON-RPC: %w", err)
 	}
 	return response, nil
 }
@@ -165,7 +167,7 @@ type CloseResponse struct {
 // Returns nil if the close method is not implemented (CodeMethodNotFound).
 func (c *DenoClientEphemeralResource) Close(ctx context.Context, params *CloseRequest) (*CloseResponse, error) {
 	var response *CloseResponse
-	if err := c.Client.Socket.Call(ctx, "close", params, &response); err != nil {
+	if err := c.Client.Call(ctx, "close", params, &response); err != nil {
 
 		// Close method is optional - return nil if not implemented
 		var rpcErr *jsonrpc2.Error
@@ -173,7 +175,7 @@ func (c *DenoClientEphemeralResource) Close(ctx context.Context, params *CloseRe
 			return nil, nil
 		}
 
-		return nil, fmt.Errorf("failed to call close method over JSON-RPC: %v", err)
+		return nil, fmt.Errorf("failed to call close method over JSON-RPC: %w", err)
 	}
 	return response, nil
 }
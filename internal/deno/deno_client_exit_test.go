@@ -0,0 +1,179 @@
+//go:build !windows
+
+package deno
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+// connectExitingScript connects a DenoClient to a fake script that never responds to create,
+// with a shell process standing in for the Deno process and its stderr piped as start does.
+func connectExitingScript(t *testing.T, script string) *DenoClient {
+	t.Helper()
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	c := connectFakeScript(t, map[string]any{
+		"create": func() map[string]any {
+			<-release
+			return map[string]any{}
+		},
+	})
+	c.options = &ClientOptions{ResponseGrace: 100 * time.Millisecond}
+	c.process = exec.Command("sh", "-c", script)
+	stderr, stderrWriter, err := os.Pipe()
+	assert.NoError(t, err)
+	c.process.Stderr = stderrWriter
+	assert.NoError(t, c.process.Start())
+	assert.NoError(t, stderrWriter.Close())
+	c.pipeStderr(t.Context(), stderr)
+	return c
+}
+
+func TestDenoClient_Call_ExitWithoutResponse(t *testing.T) {
+	c := connectExitingScript(t, `sleep 0.1; exit 0`)
+
+	err := c.Call(t.Context(), "create", nil, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "the Deno script's create() returned but sent no response")
+}
+
+func TestDenoClient_Call_CrashWithoutResponse(t *testing.T) {
+	c := connectExitingScript(t, `sleep 0.1; exit 3`)
+
+	err := c.Call(t.Context(), "create", nil, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "the Deno script exited before responding to create(): the process exited with code 3")
+
+	var exitErr *ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, exitErr.Code)
+
+	var exitedErr *ProcessExitedError
+	assert.True(t, errors.As(err, &exitedErr))
+	assert.Equal(t, 3, exitedErr.Code)
+	assert.False(t, exitedErr.BeforeCall)
+	assert.IsError(t, err, ErrProcessExited)
+
+	// The exit has been reported by the call, so stopping does not report it again
+	assert.NoError(t, c.Stop())
+}
+
+func TestDenoClient_Call_AfterExit(t *testing.T) {
+	c := connectExitingScript(t, `echo "background task failed" >&2; exit 5`)
+	_, _ = c.Wait(t.Context())
+
+	err := c.Call(t.Context(), "read", nil, nil)
+	assert.IsError(t, err, ErrProcessExited)
+	assert.Contains(t, err.Error(), "the Deno script had already exited before read() was called: the process exited with code 5")
+
+	var exitedErr *ProcessExitedError
+	assert.True(t, errors.As(err, &exitedErr))
+	assert.True(t, exitedErr.BeforeCall)
+	assert.Equal(t, 5, exitedErr.Code)
+	assert.Equal(t, []string{"background task failed"}, exitedErr.Stderr)
+}
+
+func TestDenoClient_CloseOnExit(t *testing.T) {
+	c := connectExitingScript(t, `exit 0`)
+	c.closeOnExit(c.Socket)
+	_, _ = c.Wait(t.Context())
+
+	// The socket is closed once the response grace period has passed
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Socket.Notify(t.Context(), "ping", nil) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the socket to be closed after the process exited")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDenoClient_Call_ExitFailureModes(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+		killed bool
+	}{
+		{name: "uncaught error", script: `sleep 0.1; exit 1`, want: "the process exited with code 1 (uncaught error)"},
+		{name: "signal", script: `sleep 0.1; kill -TERM $$`, want: "the process was ended by signal SIGTERM"},
+		{name: "killed", script: `sleep 0.1; kill -KILL $$`, want: "the process was killed (SIGKILL)", killed: true},
+		{name: "out of memory", script: `sleep 0.1; exit 137`, want: "the process was killed (exit code 137)", killed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := connectExitingScript(t, tt.script)
+
+			err := c.Call(t.Context(), "create", nil, nil)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+
+			var exitErr *ExitError
+			assert.True(t, errors.As(err, &exitErr))
+			assert.Equal(t, tt.killed, exitErr.Killed())
+		})
+	}
+}
+
+func TestDenoClient_Start_CanRetryAfterFailure(t *testing.T) {
+	// sh fails to find the "run" script that Deno would be given, so exits before becoming healthy
+	c := NewDenoClient("sh", "script.ts", "/dev/null", nil, &ClientOptions{ResponseGrace: 50 * time.Millisecond}, nil)
+
+	for range 2 {
+		err := c.Start(t.Context())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "the Deno script exited before responding to health()")
+		assert.Zero(t, c.process)
+		assert.Zero(t, c.Socket)
+	}
+}
+
+func TestDenoClient_Call_CrashIncludesStderr(t *testing.T) {
+	c := connectExitingScript(t, `echo "error: Uncaught (in promise) Error: boom" >&2; echo "    at file:///script.ts:3:9" >&2; sleep 0.1; exit 1`)
+
+	err := c.Call(t.Context(), "create", nil, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "the process exited with code 1 (uncaught error)\n\n"+
+		"The last lines written to stderr were:\n"+
+		"error: Uncaught (in promise) Error: boom\n"+
+		"    at file:///script.ts:3:9")
+
+	var exitErr *ExitError
+	assert.True(t, errors.As(err, &exitErr))
+}
+
+func TestDenoClient_Wait(t *testing.T) {
+	c := connectExitingScript(t, `echo "the secret is hunter2" >&2; exit 4`)
+	c.AddSecrets("hunter2")
+
+	code, err := c.Wait(t.Context())
+	assert.Equal(t, 4, code)
+	assert.EqualError(t, err, "the process exited with code 4\n\nThe last lines written to stderr were:\nthe secret is ***")
+
+	c = connectExitingScript(t, `exit 0`)
+	code, err = c.Wait(t.Context())
+	assert.Equal(t, 0, code)
+	assert.NoError(t, err)
+}
+
+func TestDenoClient_StderrTail_KeepsLastLines(t *testing.T) {
+	c := &DenoClient{}
+	for i := range stderrTailLines + 5 {
+		c.recordStderrLine(fmt.Sprintf("line %d", i))
+	}
+
+	tail := c.StderrTail()
+	assert.Equal(t, stderrTailLines, len(tail))
+	assert.Equal(t, "line 5", tail[0])
+	assert.Equal(t, fmt.Sprintf("line %d", stderrTailLines+4), tail[len(tail)-1])
+
+	c.resetStderrDetails()
+	assert.Zero(t, c.StderrTail())
+}
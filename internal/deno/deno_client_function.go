@@ -0,0 +1,86 @@
+package deno
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// DenoClientFunction is a client for evaluating Terraform provider functions using a Deno runtime.
+// Unlike the other clients it is not tied to a single provider type, the methods it calls
+// may be implemented by any denobridge script.
+type DenoClientFunction struct {
+	// Client is the underlying Deno client used for JSON-RPC communication
+	Client *DenoClient
+}
+
+// NewDenoClientFunction creates a new DenoClientFunction with the specified configuration.
+// It initializes a Deno runtime process with the given script and permissions.
+//
+// Parameters:
+//   - denoBinaryPath: The path to the Deno executable
+//   - scriptPath: The path to the TypeScript/JavaScript script to execute
+//   - configPath: The path to the Deno configuration file (deno.json)
+//   - permissions: The Deno security permissions to grant the runtime
+//   - options: Optional settings that tune how the Deno process is run (may be nil)
+//
+// Returns a configured DenoClientFunction ready to evaluate functions.
+func NewDenoClientFunction(denoBinaryPath, scriptPath, configPath string, permissions *Permissions, options *ClientOptions) *DenoClientFunction {
+	return &DenoClientFunction{
+		NewDenoClient(
+			denoBinaryPath,
+			scriptPath,
+			configPath,
+			permissions,
+			options,
+			nil,
+		),
+	}
+}
+
+// ValidateRequest represents the request payload for validating a scripts props.
+type ValidateRequest struct {
+	// Props contains the configuration properties to validate
+	Props any `json:"props"`
+}
+
+// ValidateResponse represents the response from validating a scripts props.
+type ValidateResponse struct {
+	// Diagnostics contains any warnings or errors found while validating the props
+	Diagnostics *[]struct {
+		// Severity indicates the diagnostic level ("error" or "warning")
+		Severity string `json:"severity"`
+		// Summary is a short description of the diagnostic
+		Summary string `json:"summary"`
+		// Detail provides additional context about the diagnostic
+		Detail string `json:"detail"`
+		// PropPath optionally specifies which property the diagnostic relates to
+		PropPath *[]string `json:"propPath,omitempty"`
+	} `json:"diagnostics,omitempty"`
+}
+
+// Validate checks the given props against the scripts expectations by calling the "validate" method via JSON-RPC.
+// The script must not perform any side effects while validating.
+//
+// Parameters:
+//   - ctx: The context for the operation, used for cancellation and timeouts
+//   - params: The validate request containing the props to check
+//
+// Returns the validate response, or nil if the script does not implement the validate method.
+// Returns an error if the JSON-RPC call fails for any other reason.
+func (c *DenoClientFunction) Validate(ctx context.Context, params *ValidateRequest) (*ValidateResponse, error) {
+	var response *ValidateResponse
+	if err := c.Client.Call(ctx, "validate", params, &response); err != nil {
+
+		// Validate method is optional - return nil if not implemented
+		var rpcErr *jsonrpc2.Error
+		if errors.As(err, &rpcErr) && rpcErr.Code == jsonrpc2.CodeMethodNotFound {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to call validate method over JSON-RPC: %w", err)
+	}
+	return response, nil
+}
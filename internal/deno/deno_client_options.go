@@ -0,0 +1,272 @@
+package deno
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultStopGrace is how long Stop waits at each stage of shutdown when no grace period is configured.
+const defaultStopGrace = 5 * time.Second
+
+// bridgeLibraryHost is the host the bridge library is imported from when no JSR registry mirror is configured.
+const bridgeLibraryHost = "jsr.io"
+
+// defaultResponseGrace is how long a call waits for a response after the process has exited when no grace period is configured.
+const defaultResponseGrace = time.Second
+
+// envScriptPathPrefix marks a script path that names the environment variable holding the actual path, eg: env:DENO_SCRIPT.
+const envScriptPathPrefix = "env:"
+
+// defaultProgressFlushTimeout is how long an action waits for its progress updates to be delivered when no timeout is configured.
+const defaultProgressFlushTimeout = time.Second
+
+// ClientOptions holds optional settings that tune how the Deno child process is run.
+//
+// The zero value (and a nil pointer) is valid and applies the defaults.
+// Options are JSON serializable so they can be persisted alongside
+// other client configuration, eg: in ephemeral resource private data.
+type ClientOptions struct {
+	// StopGrace is how long Stop waits for the process to exit on its own, and then again
+	// after asking it to terminate, before the process is forcefully killed.
+	StopGrace time.Duration `json:"stopGrace,omitempty"`
+
+	// ResponseGrace is how long a call waits for a response that was written just before the
+	// process exited, before failing with an error explaining that the script never responded.
+	ResponseGrace time.Duration `json:"responseGrace,omitempty"`
+
+	// CallTimeout limits how long a call waits for the script to respond, after which it fails with a
+	// *jsocket.CallTimeoutError and the script is sent a cancel notification. Zero means no limit.
+	CallTimeout time.Duration `json:"callTimeout,omitempty"`
+
+	// ProgressFlushTimeout is how long an action waits, once the script has responded, for the progress
+	// updates it sent before responding to be delivered to Terraform.
+	ProgressFlushTimeout time.Duration `json:"progressFlushTimeout,omitempty"`
+
+	// NoConfigDiscovery disables locating the closest deno config file relative to the script
+	// when no config path is given. The script is then run with --no-config so that Deno itself
+	// does not pick up a config file from a parent directory either.
+	NoConfigDiscovery bool `json:"noConfigDiscovery,omitempty"`
+
+	// MaxHeapMB limits the size of the V8 heap in megabytes, via --max-old-space-size.
+	// Zero leaves the V8 default in place.
+	MaxHeapMB int64 `json:"maxHeapMb,omitempty"`
+
+	// MaxCPUSeconds limits the CPU time the process may consume via RLIMIT_CPU, it is
+	// killed once exceeded. The limit covers the whole life of the process, so a limited
+	// process is never reused, see Reusable. Only supported on Linux. Zero means no limit.
+	MaxCPUSeconds int64 `json:"maxCpuSeconds,omitempty"`
+
+	// LogRedactPatterns are regular expressions matching values that are replaced with "***"
+	// in the child processes stderr and the JSON-RPC messages before they are logged.
+	LogRedactPatterns []string `json:"logRedactPatterns,omitempty"`
+
+	// CertFile is the path to a PEM encoded CA bundle that Deno trusts, via --cert, for the HTTPS requests and
+	// remote imports of scripts, eg: to reach internal services signed by a private CA.
+	CertFile string `json:"certFile,omitempty"`
+
+	// PreloadModule is an absolute path or remote URL of a module that Deno imports, via --preload, before each
+	// script, eg: to run the setup shared by many scripts. It is subject to the same import permissions as the script.
+	PreloadModule string `json:"preloadModule,omitempty"`
+
+	// LockFile is the path to a lockfile, passed via --lock, that the integrity of every remote dependency is checked
+	// against. New dependencies are added to it unless LockFrozen is set, in which case any change fails the script.
+	LockFile   string `json:"lockFile,omitempty"`
+	LockFrozen bool   `json:"lockFrozen,omitempty"`
+
+	// DenoFlags are extra flags passed to deno run after the permissions, eg: --node-modules-dir for scripts that
+	// import npm packages. Each must pass ValidateDenoFlag.
+	DenoFlags []string `json:"denoFlags,omitempty"`
+
+	// EnvFile is the path to a .env file whose variables are loaded into the
+	// scripts environment via --env-file.
+	EnvFile string `json:"envFile,omitempty"`
+
+	// Env are environment variables set in the scripts environment, on top of the providers own
+	// environment unless NoInheritEnv is set. The script needs the env permission to read them.
+	Env map[string]string `json:"env,omitempty"`
+
+	// NoInheritEnv starts the script from an empty environment rather than the providers, so that
+	// only the variables in Env, and those Deno itself needs (see runtimeEnvVars), are set.
+	NoInheritEnv bool `json:"noInheritEnv,omitempty"`
+
+	// TargetPlatform is passed to scripts in their Meta, for scripts that generate
+	// platform specific artifacts for a platform other than the one they run on.
+	TargetPlatform string `json:"targetPlatform,omitempty"`
+
+	// SlowStartWarning is how long a script may take from its process being started to answering the health
+	// handshake before a warning suggests moving slow top-level code, which runs again in every process, into
+	// the methods that need it. Zero disables the warning.
+	SlowStartWarning time.Duration `json:"slowStartWarning,omitempty"`
+
+	// StartRetries is how many more times a Deno process that failed to start is tried
+	// again, as start up failures are often transient under heavy parallel load.
+	StartRetries int64 `json:"startRetries,omitempty"`
+
+	// JSRRegistryURL is the URL of a JSR registry mirror that jsr: imports, including
+	// the bridge library itself, are resolved through. It is given to Deno as JSR_URL.
+	JSRRegistryURL string `json:"jsrRegistryUrl,omitempty"`
+
+	// ScriptBaseDir is the directory that relative local script paths are resolved against,
+	// instead of the current working directory, eg: the directory of a module that bundles
+	// its own scripts.
+	ScriptBaseDir string `json:"scriptBaseDir,omitempty"`
+
+	// DebugDir is a directory that every script is copied into when it is started, alongside a
+	// .cmd file holding the command line it was run with, whether or not it then succeeds.
+	DebugDir string `json:"debugDir,omitempty"`
+
+	// TrustedImportHosts are the hosts scripts may import remote modules from. When set, scripts are
+	// run with --allow-import scoped to these hosts and the host the bridge library is imported from.
+	TrustedImportHosts []string `json:"trustedImportHosts,omitempty"`
+}
+
+// runtimeEnvVars are the variables kept when the providers environment is not inherited, as Deno itself
+// needs them to locate its cache, create temporary files and reach the network.
+var runtimeEnvVars = []string{
+	"DENO_DIR", "DENO_CERT", "DENO_AUTH_TOKENS", "HOME", "XDG_CACHE_HOME", "TMPDIR",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"APPDATA", "LOCALAPPDATA", "SYSTEMROOT", "TEMP", "TMP", "USERPROFILE",
+}
+
+// stopGrace returns the configured stop grace period or the default.
+func (o *ClientOptions) stopGrace() time.Duration {
+	if o == nil || o.StopGrace <= 0 {
+		return defaultStopGrace
+	}
+	return o.StopGrace
+}
+
+// responseGrace returns the configured response grace period or the default.
+func (o *ClientOptions) responseGrace() time.Duration {
+	if o == nil || o.ResponseGrace <= 0 {
+		return defaultResponseGrace
+	}
+	return o.ResponseGrace
+}
+
+// progressFlushTimeout returns the configured progress flush timeout or the default.
+func (o *ClientOptions) progressFlushTimeout() time.Duration {
+	if o == nil || o.ProgressFlushTimeout <= 0 {
+		return defaultProgressFlushTimeout
+	}
+	return o.ProgressFlushTimeout
+}
+
+// slowStartWarning returns how long a script may take to start before it is warned about, zero when disabled.
+func (o *ClientOptions) slowStartWarning() time.Duration {
+	if o == nil {
+		return 0
+	}
+	return o.SlowStartWarning
+}
+
+// startRetries returns the configured number of start retries, zero when unset.
+func (o *ClientOptions) startRetries() int64 {
+	if o == nil || o.StartRetries < 0 {
+		return 0
+	}
+	return o.StartRetries
+}
+
+// Reusable reports whether a process started with these options may serve more than one operation,
+// eg: by being pooled or run as a daemon. Those with a CPU limit may not, as the CPU time of every
+// operation counts towards it and a healthy process would eventually be killed mid-operation.
+func (o *ClientOptions) Reusable() bool {
+	return o == nil || o.MaxCPUSeconds <= 0
+}
+
+// noConfigDiscovery reports whether config file auto-discovery has been disabled.
+func (o *ClientOptions) noConfigDiscovery() bool {
+	return o != nil && o.NoConfigDiscovery
+}
+
+// scriptLocation returns scriptPath joined to the script base dir when it is a relative local path.
+// Absolute paths and URLs are returned unchanged.
+func (o *ClientOptions) scriptLocation(scriptPath string) string {
+	if o == nil || o.ScriptBaseDir == "" || strings.Contains(scriptPath, "://") || filepath.IsAbs(scriptPath) {
+		return scriptPath
+	}
+	return filepath.Join(o.ScriptBaseDir, scriptPath)
+}
+
+// resolveScriptPath returns the location of the script to run, see scriptLocation. A path of the form env:NAME is
+// first replaced by the value of the environment variable NAME, read when the script is started, which must be set.
+func (o *ClientOptions) resolveScriptPath(scriptPath string) (string, error) {
+	if name, ok := strings.CutPrefix(scriptPath, envScriptPathPrefix); ok {
+		if name == "" {
+			return "", fmt.Errorf("script path %q does not name an environment variable", scriptPath)
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return "", fmt.Errorf("script path %q refers to the environment variable %s, which is not set", scriptPath, name)
+		}
+		scriptPath = value
+	}
+	return o.scriptLocation(scriptPath), nil
+}
+
+// DefaultPermissionArgs returns the permission flags a script is run with when it is given no permissions,
+// eg: --allow-import scoped to the trusted import hosts when they are configured.
+func (o *ClientOptions) DefaultPermissionArgs() []string {
+	var permissions *Permissions
+	return permissions.withImportHosts(o.importHosts()).Args()
+}
+
+// importHosts returns the hosts that --allow-import is scoped to, nil when no trusted import hosts are configured.
+// The host the bridge library is imported from always comes first, so that scripts keep working.
+func (o *ClientOptions) importHosts() []string {
+	if o == nil || len(o.TrustedImportHosts) == 0 {
+		return nil
+	}
+	hosts := []string{bridgeLibraryHost}
+	if o.JSRRegistryURL != "" {
+		if u, err := url.Parse(o.JSRRegistryURL); err == nil && u.Host != "" {
+			hosts[0] = u.Host
+		}
+	}
+	for _, host := range o.TrustedImportHosts {
+		if !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// environ returns the environment the Deno process is started with, or nil when it simply inherits the providers.
+func (o *ClientOptions) environ() []string {
+	if o == nil || (len(o.Env) == 0 && !o.NoInheritEnv && o.JSRRegistryURL == "") {
+		return nil
+	}
+
+	env := []string{}
+	if o.NoInheritEnv {
+		for _, name := range runtimeEnvVars {
+			if value, ok := os.LookupEnv(name); ok {
+				env = append(env, name+"="+value)
+			}
+		}
+	} else {
+		env = append(env, os.Environ()...)
+	}
+
+	// Later entries win when a variable is set twice
+	names := make([]string, 0, len(o.Env))
+	for name := range o.Env {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		env = append(env, name+"="+o.Env[name])
+	}
+
+	// Resolve jsr: imports through a registry mirror, eg: behind a corporate proxy
+	if o.JSRRegistryURL != "" {
+		env = append(env, "JSR_URL="+o.JSRRegistryURL)
+	}
+	return env
+}
@@ -0,0 +1,109 @@
+package deno
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestClientOptions_ScriptLocation tests resolving script paths against the script base dir.
+func TestClientOptions_ScriptLocation(t *testing.T) {
+	baseDir := filepath.Join(t.TempDir(), "module")
+	absScript := filepath.Join(t.TempDir(), "script.ts")
+
+	tests := []struct {
+		name       string
+		options    *ClientOptions
+		scriptPath string
+		expected   string
+	}{
+		{"nil options", nil, "script.ts", "script.ts"},
+		{"no base dir", &ClientOptions{}, "script.ts", "script.ts"},
+		{"relative path", &ClientOptions{ScriptBaseDir: baseDir}, "scripts/script.ts", filepath.Join(baseDir, "scripts", "script.ts")},
+		{"parent path", &ClientOptions{ScriptBaseDir: baseDir}, "../script.ts", filepath.Join(filepath.Dir(baseDir), "script.ts")},
+		{"absolute path", &ClientOptions{ScriptBaseDir: baseDir}, absScript, absScript},
+		{"remote url", &ClientOptions{ScriptBaseDir: baseDir}, "https://example.com/script.ts", "https://example.com/script.ts"},
+		{"file url", &ClientOptions{ScriptBaseDir: baseDir}, "file:///tmp/script.ts", "file:///tmp/script.ts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.scriptLocation(tt.scriptPath); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestClientOptions_ResolveScriptPath tests reading script paths from the environment.
+func TestClientOptions_ResolveScriptPath(t *testing.T) {
+	baseDir := filepath.Join(t.TempDir(), "module")
+	t.Setenv("DENOBRIDGE_TEST_SCRIPT", "scripts/script.ts")
+	t.Setenv("DENOBRIDGE_TEST_EMPTY", "")
+
+	tests := []struct {
+		name       string
+		options    *ClientOptions
+		scriptPath string
+		expected   string
+		err        string
+	}{
+		{"plain path", nil, "script.ts", "script.ts", ""},
+		{"env", nil, "env:DENOBRIDGE_TEST_SCRIPT", "scripts/script.ts", ""},
+		{"env with base dir", &ClientOptions{ScriptBaseDir: baseDir}, "env:DENOBRIDGE_TEST_SCRIPT", filepath.Join(baseDir, "scripts", "script.ts"), ""},
+		{"env not set", nil, "env:DENOBRIDGE_TEST_MISSING", "", `script path "env:DENOBRIDGE_TEST_MISSING" refers to the environment variable DENOBRIDGE_TEST_MISSING, which is not set`},
+		{"env empty", nil, "env:DENOBRIDGE_TEST_EMPTY", "", "which is not set"},
+		{"no name", nil, "env:", "", `script path "env:" does not name an environment variable`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.options.resolveScriptPath(tt.scriptPath)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected an error containing '%s', got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestClientOptions_Environ tests building the environment the Deno process is started with.
+func TestClientOptions_Environ(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	if env := (&ClientOptions{}).environ(); env != nil {
+		t.Errorf("Expected the environment to be inherited unchanged, got %v", env)
+	}
+
+	env := (&ClientOptions{Env: map[string]string{"AWS_REGION": "us-east-1", "HOME": "/override"}}).environ()
+	if !slices.Contains(env, "AWS_SECRET_ACCESS_KEY=secret") {
+		t.Errorf("Expected the providers environment to be inherited, got %v", env)
+	}
+	if !slices.Contains(env, "AWS_REGION=us-east-1") || slices.Index(env, "HOME=/override") < slices.Index(env, "HOME=/home/test") {
+		t.Errorf("Expected env to be set after the inherited variables, got %v", env)
+	}
+
+	env = (&ClientOptions{NoInheritEnv: true, Env: map[string]string{"AWS_REGION": "us-east-1"}, JSRRegistryURL: "https://jsr.example.com"}).environ()
+	if slices.Contains(env, "AWS_SECRET_ACCESS_KEY=secret") {
+		t.Errorf("Expected the providers environment not to be inherited, got %v", env)
+	}
+	for _, want := range []string{"HOME=/home/test", "AWS_REGION=us-east-1", "JSR_URL=https://jsr.example.com"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("Expected %s to be set, got %v", want, env)
+		}
+	}
+
+	if env := (&ClientOptions{NoInheritEnv: true}).environ(); env == nil {
+		t.Error("Expected an empty environment rather than nil, which would inherit the providers")
+	}
+}
@@ -2,9 +2,11 @@ package deno
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
+	"github.com/brad-jones/terraform-provider-denobridge/internal/jsocket"
 	"github.com/sourcegraph/jsonrpc2"
 )
 
@@ -24,18 +26,20 @@ type DenoClientResource struct {
 //   - scriptPath: The path to the TypeScript/JavaScript resource script to execute
 //   - configPath: The path to the Deno configuration file (deno.json)
 //   - permissions: The Deno security permissions to grant the runtime
+//   - options: Optional settings that tune how the Deno process is run (may be nil)
 //
 // Returns a configured DenoClientResource ready to manage resources.
-func NewDenoClientResource(denoBinaryPath, scriptPath, configPath string, permissions *Permissions) *DenoClientResource {
-	return &DenoClientResource{
-		NewDenoClient(
-			denoBinaryPath,
-			scriptPath,
-			configPath,
-			permissions,
-			nil,
-		),
-	}
+func NewDenoClientResource(denoBinaryPath, scriptPath, configPath string, permissions *Permissions, options *ClientOptions) *DenoClientResource {
+	client := NewDenoClient(
+		denoBinaryPath,
+		scriptPath,
+		configPath,
+		permissions,
+		options,
+		nil,
+	)
+	client.rpcMethods = jsocket.TypedServerMethods(&DenoClientResourceServerMethods{client})
+	return &DenoClientResource{client}
 }
 
 // CreateRequest represents the request payload for creating a Terraform resource.
@@ -45,17 +49,56 @@ type CreateRequest struct {
 	Props any `json:"props"`
 	// WriteOnlyProps contains any write-only properties that should be passed to the Deno script but not stored in state
 	WriteOnlyProps any `json:"writeOnlyProps,omitempty"`
+	// IdempotencyKey is identical for every attempt at creating the same resource, so a create that is
+	// retried after its response was lost can be recognised and deduplicated by the script
+	IdempotencyKey string `json:"idempotencyKey"`
+	// DryRun is always false, creates are only made during apply
+	DryRun bool `json:"dryRun"`
+}
+
+// Advisory is a notice about a resource that is shown to the user even when an operation succeeds,
+// eg: that the API it is managed through is deprecated.
+type Advisory struct {
+	// Summary is a short description of the advisory
+	Summary string `json:"summary"`
+	// Detail provides additional context about the advisory
+	Detail string `json:"detail"`
+}
+
+// PollInstruction asks the provider to wait for a resource that is not ready yet, eg: one whose backend
+// provisions it asynchronously, by calling the script's poll method until it reports the resource is ready.
+type PollInstruction struct {
+	// IntervalMs is how long to wait before the first poll, the wait doubles after each poll
+	IntervalMs int64 `json:"intervalMs,omitempty"`
+	// MaxIntervalMs caps how long to wait between polls
+	MaxIntervalMs int64 `json:"maxIntervalMs,omitempty"`
+	// TimeoutMs is how long to keep polling for before giving up
+	TimeoutMs int64 `json:"timeoutMs,omitempty"`
 }
 
 // CreateResponse represents the response from creating a Terraform resource.
 // It contains the resource's unique identifier and state data.
 type CreateResponse struct {
-	// ID is the unique identifier for the created resource
-	ID string `json:"id"`
+	// ID is the unique identifier for the created resource, either a string or a structured id
+	ID ResourceID `json:"id"`
 	// State contains the resource's state data to be stored in Terraform state
 	State any `json:"state"`
 	// SensitiveState contains the resource's sensitive state data to be stored in Terraform state (marked as sensitive)
 	SensitiveState any `json:"sensitiveState"`
+	// SensitivePaths lists paths within State whose values should be moved into SensitiveState
+	SensitivePaths [][]string `json:"sensitivePaths,omitempty"`
+	// TypedPaths lists paths within State whose lists and objects are stored as a list or map with a single element type
+	TypedPaths [][]string `json:"typedPaths,omitempty"`
+	// Identifiers contains any additional identifiers of the resource, such as an ARN or URN
+	Identifiers map[string]string `json:"identifiers,omitempty"`
+	// Advisories contains notices to show to the user even though the operation succeeded
+	Advisories []Advisory `json:"advisories,omitempty"`
+	// NextSteps lists manual steps the user must take to finish the change, eg: updating DNS records
+	NextSteps []string `json:"nextSteps,omitempty"`
+	// PollUntilReady asks the provider to wait for the resource to become ready before the operation completes
+	PollUntilReady *PollInstruction `json:"pollUntilReady,omitempty"`
+	// Private is kept in Terraform's private state, out of sight, and given back to the script in later operations
+	Private json.RawMessage `json:"private,omitempty"`
 	// Diagnostics contains any warnings or errors to display to the user
 	Diagnostics *[]struct {
 		// Severity indicates the diagnostic level ("error" or "warning")
@@ -79,8 +122,8 @@ type CreateResponse struct {
 // Returns the create response containing the resource ID and state, or an error if the JSON-RPC call fails.
 func (c *DenoClientResource) Create(ctx context.Context, params *CreateRequest) (*CreateResponse, error) {
 	var response *CreateResponse
-	if err := c.Client.Socket.Call(ctx, "create", params, &response); err != nil {
-		return nil, fmt.Errorf("failed to call create method over JSON-RPC: %v", err)
+	if err := c.Client.Call(ctx, "create", params, &response); err != nil {
+		return nil, fmt.Errorf("failed to call create method over JSON-RPC: %w", err)
 	}
 	return response, nil
 }
@@ -89,9 +132,13 @@ func (c *DenoClientResource) Create(ctx context.Context, params *CreateRequest)
 // It contains the resource ID and configuration properties.
 type CreateReadRequest struct {
 	// ID is the unique identifier of the resource to read
-	ID string `json:"id"`
+	ID ResourceID `json:"id"`
 	// Props contains the resource configuration properties
 	Props any `json:"props"`
+	// Private is the private state the script last returned for the resource, if any
+	Private json.RawMessage `json:"private,omitempty"`
+	// DryRun is true as reads happen while refreshing state during plan and must not make changes
+	DryRun bool `json:"dryRun"`
 }
 
 // CreateReadResponse represents the response from reading a Terraform resource.
@@ -103,6 +150,14 @@ type CreateReadResponse struct {
 	State *any `json:"state"`
 	// SensitiveState contains the updated resource sensitive state data
 	SensitiveState *any `json:"sensitiveState"`
+	// SensitivePaths lists paths within State whose values should be moved into SensitiveState
+	SensitivePaths [][]string `json:"sensitivePaths,omitempty"`
+	// TypedPaths lists paths within State whose lists and objects are stored as a list or map with a single element type
+	TypedPaths [][]string `json:"typedPaths,omitempty"`
+	// Identifiers contains any additional identifiers of the resource, such as an ARN or URN
+	Identifiers map[string]string `json:"identifiers,omitempty"`
+	// Advisories contains notices to show to the user even though the operation succeeded
+	Advisories []Advisory `json:"advisories,omitempty"`
 	// Exists indicates whether the resource still exists in the external system
 	Exists *bool `json:"exists"`
 	// Diagnostics contains any warnings or errors to display to the user
@@ -128,8 +183,8 @@ type CreateReadResponse struct {
 // Returns the read response with updated properties and state, or an error if the JSON-RPC call fails.
 func (c *DenoClientResource) Read(ctx context.Context, params *CreateReadRequest) (*CreateReadResponse, error) {
 	var response *CreateReadResponse
-	if err := c.Client.Socket.Call(ctx, "read", params, &response); err != nil {
-		return nil, fmt.Errorf("failed to call read method over JSON-RPC: %v", err)
+	if err := c.Client.Call(ctx, "read", params, &response); err != nil {
+		return nil, fmt.Errorf("failed to call read method over JSON-RPC: %w", err)
 	}
 	return response, nil
 }
@@ -138,7 +193,7 @@ func (c *DenoClientResource) Read(ctx context.Context, params *CreateReadRequest
 // It contains the resource ID, next configuration, and current configuration and state.
 type UpdateRequest struct {
 	// ID is the unique identifier of the resource to update
-	ID string `json:"id"`
+	ID ResourceID `json:"id"`
 	// NextProps contains the desired resource configuration properties from Terraform
 	NextProps any `json:"nextProps"`
 	// NextWriteOnlyProps contains any desired write-only properties from Terraform that should be passed to the Deno script but not stored in state
@@ -149,6 +204,10 @@ type UpdateRequest struct {
 	CurrentState any `json:"currentState"`
 	// CurrentSensitiveState contains the current resource sensitive state data
 	CurrentSensitiveState any `json:"currentSensitiveState"`
+	// Private is the private state the script last returned for the resource, if any
+	Private json.RawMessage `json:"private,omitempty"`
+	// DryRun is always false, updates are only made during apply
+	DryRun bool `json:"dryRun"`
 }
 
 // UpdateResponse represents the response from updating a Terraform resource.
@@ -158,6 +217,20 @@ type UpdateResponse struct {
 	State *any `json:"state"`
 	// SensitiveState contains the updated resource sensitive state data after the update operation
 	SensitiveState *any `json:"sensitiveState"`
+	// SensitivePaths lists paths within State whose values should be moved into SensitiveState
+	SensitivePaths [][]string `json:"sensitivePaths,omitempty"`
+	// TypedPaths lists paths within State whose lists and objects are stored as a list or map with a single element type
+	TypedPaths [][]string `json:"typedPaths,omitempty"`
+	// Advisories contains notices to show to the user even though the operation succeeded
+	Advisories []Advisory `json:"advisories,omitempty"`
+	// NextSteps lists manual steps the user must take to finish the change, eg: updating DNS records
+	NextSteps []string `json:"nextSteps,omitempty"`
+	// PollUntilReady asks the provider to wait for the resource to become ready before the operation completes
+	PollUntilReady *PollInstruction `json:"pollUntilReady,omitempty"`
+	// NoChanges indicates that the update changed nothing the state records, keeping the prior state and ignoring State
+	NoChanges *bool `json:"noChanges,omitempty"`
+	// Private replaces the private state kept for the resource, it is kept as is when omitted and cleared when null
+	Private json.RawMessage `json:"private,omitempty"`
 	// Diagnostics contains any warnings or errors to display to the user
 	Diagnostics *[]struct {
 		// Severity indicates the diagnostic level ("error" or "warning")
@@ -181,8 +254,8 @@ type UpdateResponse struct {
 // Returns the update response with the new resource state, or an error if the JSON-RPC call fails.
 func (c *DenoClientResource) Update(ctx context.Context, params *UpdateRequest) (*UpdateResponse, error) {
 	var response *UpdateResponse
-	if err := c.Client.Socket.Call(ctx, "update", params, &response); err != nil {
-		return nil, fmt.Errorf("failed to call update method over JSON-RPC: %v", err)
+	if err := c.Client.Call(ctx, "update", params, &response); err != nil {
+		return nil, fmt.Errorf("failed to call update method over JSON-RPC: %w", err)
 	}
 	return response, nil
 }
@@ -191,13 +264,17 @@ func (c *DenoClientResource) Update(ctx context.Context, params *UpdateRequest)
 // It contains the resource ID, configuration properties, and state data.
 type DeleteRequest struct {
 	// ID is the unique identifier of the resource to delete
-	ID string `json:"id"`
+	ID ResourceID `json:"id"`
 	// Props contains the resource configuration properties
 	Props any `json:"props"`
 	// State contains the resource state data
 	State any `json:"state"`
 	// SensitiveState contains the resource sensitive state data
 	SensitiveState any `json:"sensitiveState"`
+	// Private is the private state the script last returned for the resource, if any
+	Private json.RawMessage `json:"private,omitempty"`
+	// DryRun is always false, deletes are only made during apply
+	DryRun bool `json:"dryRun"`
 	// Diagnostics contains any warnings or errors to display to the user
 	Diagnostics *[]struct {
 		// Severity indicates the diagnostic level ("error" or "warning")
@@ -211,11 +288,23 @@ type DeleteRequest struct {
 	} `json:"diagnostics,omitempty"`
 }
 
+// DeleteStep is one of several things a script cleans up while deleting a resource.
+type DeleteStep struct {
+	// Name describes what the step cleans up
+	Name string `json:"name"`
+	// Done indicates whether the step completed
+	Done bool `json:"done"`
+	// Detail optionally explains why the step did not complete
+	Detail string `json:"detail,omitempty"`
+}
+
 // DeleteResponse represents the response from deleting a Terraform resource.
 // It indicates whether the delete operation completed successfully.
 type DeleteResponse struct {
-	// Done indicates whether the delete operation completed successfully
-	Done bool `json:"done"`
+	// Done indicates whether the delete operation completed successfully, see IsDone for when it is omitted
+	Done *bool `json:"done"`
+	// Steps optionally reports each of the things cleaned up by the delete, all of which must be done
+	Steps []DeleteStep `json:"steps,omitempty"`
 	// Diagnostics contains any warnings or errors to display to the user
 	Diagnostics *[]struct {
 		// Severity indicates the diagnostic level ("error" or "warning")
@@ -229,6 +318,28 @@ type DeleteResponse struct {
 	} `json:"diagnostics,omitempty"`
 }
 
+// IsDone reports whether the script reported the delete as done. A response that omits done is treated as done,
+// making done: false an opt-in failure signal, unless strict is set in which case done: true is required.
+func (r *DeleteResponse) IsDone(strict bool) bool {
+	return isDone(r.Done, strict)
+}
+
+// IncompleteSteps describes each of the steps that the script did not report as done.
+func (r *DeleteResponse) IncompleteSteps() []string {
+	var incomplete []string
+	for _, step := range r.Steps {
+		if step.Done {
+			continue
+		}
+		if step.Detail != "" {
+			incomplete = append(incomplete, fmt.Sprintf("%s: %s", step.Name, step.Detail))
+		} else {
+			incomplete = append(incomplete, step.Name)
+		}
+	}
+	return incomplete
+}
+
 // Delete executes the resource deletion operation by calling the "delete" method via JSON-RPC.
 // It sends the resource information to the Deno runtime to remove the external resource.
 //
@@ -236,11 +347,15 @@ type DeleteResponse struct {
 //   - ctx: The context for the operation, used for cancellation and timeouts
 //   - params: The delete request containing the resource ID, properties, and state
 //
-// Returns an error if the JSON-RPC call fails or the delete operation is not complete.
+// Returns an error if the JSON-RPC call fails, use DeleteResponse.IsDone to check that the delete completed.
 func (c *DenoClientResource) Delete(ctx context.Context, params *DeleteRequest) (*DeleteResponse, error) {
 	var response *DeleteResponse
-	if err := c.Client.Socket.Call(ctx, "delete", params, &response); err != nil {
-		return nil, fmt.Errorf("failed to call delete method over JSON-RPC: %v", err)
+	if err := c.Client.Call(ctx, "delete", params, &response); err != nil {
+		return nil, fmt.Errorf("failed to call delete method over JSON-RPC: %w", err)
+	}
+	if response == nil {
+		// A script that returns nothing at all has not said the delete failed
+		response = &DeleteResponse{}
 	}
 	return response, nil
 }
@@ -249,7 +364,7 @@ func (c *DenoClientResource) Delete(ctx context.Context, params *DeleteRequest)
 // It contains the plan type and configuration information for plan customization.
 type ModifyPlanRequest struct {
 	// ID is the unique identifier of the resource (optional, not present during create operations)
-	ID *string `json:"id,omitempty"`
+	ID *ResourceID `json:"id,omitempty"`
 	// PlanType indicates the type of operation being planned ("create", "update", or "delete")
 	PlanType string `json:"planType"`
 	// NextProps contains the desired resource configuration properties
@@ -260,6 +375,8 @@ type ModifyPlanRequest struct {
 	CurrentState any `json:"currentState,omitempty"`
 	// CurrentSensitiveState contains the current resource sensitive state data (not present during create)
 	CurrentSensitiveState any `json:"currentSensitiveState,omitempty"`
+	// DryRun is always true, plans must not make changes
+	DryRun bool `json:"dryRun"`
 }
 
 // ModifyPlanResponse represents the response from modifying a Terraform plan.
@@ -271,6 +388,14 @@ type ModifyPlanResponse struct {
 	ModifiedProps *any `json:"modifiedProps,omitempty"`
 	// RequiresReplacement indicates that the resource must be replaced (destroy and recreate)
 	RequiresReplacement *bool `json:"requiresReplacement,omitempty"`
+	// ReplacementReason optionally explains to the user why the resource must be replaced
+	ReplacementReason *string `json:"replacementReason,omitempty"`
+	// Replacements marks individual props as requiring replacement, each with its own reason
+	Replacements *[]PropReplacement `json:"replacements,omitempty"`
+	// RequireConfirmation blocks the replacement until the user sets confirm_replace on the resource
+	RequireConfirmation *bool `json:"requireConfirmation,omitempty"`
+	// Summary optionally describes what the plan will do in human terms, it is purely informational
+	Summary *string `json:"summary,omitempty"`
 	// Diagnostics contains any warnings or errors to display to the user
 	Diagnostics *[]struct {
 		// Severity indicates the diagnostic level ("error" or "warning")
@@ -284,6 +409,14 @@ type ModifyPlanResponse struct {
 	} `json:"diagnostics,omitempty"`
 }
 
+// PropReplacement marks a single prop as requiring the resource to be replaced when it changes.
+type PropReplacement struct {
+	// Path is the path to the prop, relative to the props, eg: ["network", "cidr"]
+	Path []string `json:"path"`
+	// Reason explains to the user why a change to the prop requires a replacement
+	Reason string `json:"reason"`
+}
+
 // ModifyPlan executes the plan modification operation by calling the "modifyPlan" method via JSON-RPC.
 // It allows the resource to customize the Terraform plan before execution.
 // Note: The modifyPlan method is optional; if not implemented in the script, this method returns nil.
@@ -296,7 +429,7 @@ type ModifyPlanResponse struct {
 // Returns an error if the JSON-RPC call fails.
 func (c *DenoClientResource) ModifyPlan(ctx context.Context, params *ModifyPlanRequest) (*ModifyPlanResponse, error) {
 	var response *ModifyPlanResponse
-	if err := c.Client.Socket.Call(ctx, "modifyPlan", params, &response); err != nil {
+	if err := c.Client.Call(ctx, "modifyPlan", params, &response); err != nil {
 
 		// ModifyPlan method is optional - return nil if not implemented
 		var rpcErr *jsonrpc2.Error
@@ -304,8 +437,264 @@ func (c *DenoClientResource) ModifyPlan(ctx context.Context, params *ModifyPlanR
 			return nil, nil
 		}
 
-		return nil, fmt.Errorf("failed to call modifyPlan method over JSON-RPC: %v", err)
+		return nil, fmt.Errorf("failed to call modifyPlan method over JSON-RPC: %w", err)
+	}
+
+	return response, nil
+}
+
+// CheckRequest represents the request payload for checking an existing Terraform resource.
+type CheckRequest struct {
+	// ID is the unique identifier of the resource to check
+	ID ResourceID `json:"id"`
+	// Props contains the resource configuration properties
+	Props any `json:"props"`
+	// State contains the current resource state data
+	State any `json:"state"`
+	// SensitiveState contains the current resource sensitive state data
+	SensitiveState any `json:"sensitiveState"`
+	// DryRun is always true, checks run while refreshing state during plan and must not make changes
+	DryRun bool `json:"dryRun"`
+}
+
+// CheckResponse represents the response from checking an existing Terraform resource.
+type CheckResponse struct {
+	// Diagnostics contains the assertions that failed, they are only ever shown as warnings
+	Diagnostics *[]struct {
+		// Severity indicates the diagnostic level ("error" or "warning")
+		Severity string `json:"severity"`
+		// Summary is a short description of the diagnostic
+		Summary string `json:"summary"`
+		// Detail provides additional context about the diagnostic
+		Detail string `json:"detail"`
+		// PropPath optionally specifies which property the diagnostic relates to
+		PropPath *[]string `json:"propPath,omitempty"`
+	} `json:"diagnostics,omitempty"`
+}
+
+// Check runs read-only assertions against an existing resource by calling the "check" method via JSON-RPC.
+// The script must not modify the resource or its state while checking.
+// Note: The check method is optional; if not implemented in the script, this method returns nil.
+//
+// Parameters:
+//   - ctx: The context for the operation, used for cancellation and timeouts
+//   - params: The check request containing the resource ID, props and state
+//
+// Returns the check response with any failed assertions, or nil if the method is not implemented.
+// Returns an error if the JSON-RPC call fails.
+func (c *DenoClientResource) Check(ctx context.Context, params *CheckRequest) (*CheckResponse, error) {
+	var response *CheckResponse
+	if err := c.Client.Call(ctx, "check", params, &response); err != nil {
+
+		// Check method is optional - return nil if not implemented
+		var rpcErr *jsonrpc2.Error
+		if errors.As(err, &rpcErr) && rpcErr.Code == jsonrpc2.CodeMethodNotFound {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to call check method over JSON-RPC: %w", err)
+	}
+
+	return response, nil
+}
+
+// PreconditionRequest represents the request payload for checking the environment before a resource is created or updated.
+type PreconditionRequest struct {
+	// ID is the unique identifier of the resource, omitted when it is being created
+	ID *ResourceID `json:"id,omitempty"`
+	// PlanType is the operation being planned, either "create" or "update"
+	PlanType string `json:"planType"`
+	// Props contains the planned resource configuration properties
+	Props any `json:"props"`
+	// DryRun is always true, preconditions are checked while planning and must not make changes
+	DryRun bool `json:"dryRun"`
+}
+
+// PreconditionResponse represents the response from checking the environment before a resource is created or updated.
+type PreconditionResponse struct {
+	// Diagnostics contains the preconditions that are not met, errors block the plan
+	Diagnostics *[]struct {
+		// Severity indicates the diagnostic level ("error" or "warning")
+		Severity string `json:"severity"`
+		// Summary is a short description of the diagnostic
+		Summary string `json:"summary"`
+		// Detail provides additional context about the diagnostic
+		Detail string `json:"detail"`
+		// PropPath optionally specifies which property the diagnostic relates to
+		PropPath *[]string `json:"propPath,omitempty"`
+	} `json:"diagnostics,omitempty"`
+}
+
+// Precondition asserts that the environment allows a resource to be created or updated by calling the "precondition"
+// method via JSON-RPC, eg: that enough quota is available or a dependency exists, so that the plan fails rather than
+// the apply. The script must not make any changes.
+// Note: The precondition method is optional; if not implemented in the script, this method returns nil.
+//
+// Parameters:
+//   - ctx: The context for the operation, used for cancellation and timeouts
+//   - params: The precondition request containing the resource ID, plan type and planned props
+//
+// Returns the precondition response with any preconditions that are not met, or nil if the method is not implemented.
+// Returns an error if the JSON-RPC call fails.
+func (c *DenoClientResource) Precondition(ctx context.Context, params *PreconditionRequest) (*PreconditionResponse, error) {
+	var response *PreconditionResponse
+	if err := c.Client.Call(ctx, "precondition", params, &response); err != nil {
+
+		// Precondition method is optional - return nil if not implemented
+		var rpcErr *jsonrpc2.Error
+		if errors.As(err, &rpcErr) && rpcErr.Code == jsonrpc2.CodeMethodNotFound {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to call precondition method over JSON-RPC: %w", err)
+	}
+
+	return response, nil
+}
+
+// CanImportRequest represents the request payload for confirming that an existing resource can be imported.
+type CanImportRequest struct {
+	// ID is the unique identifier of the resource being imported
+	ID ResourceID `json:"id"`
+	// Props contains the resource configuration properties given with the import, if any
+	Props any `json:"props,omitempty"`
+}
+
+// CanImportResponse represents the response from confirming that an existing resource can be imported.
+type CanImportResponse struct {
+	// Diagnostics explains why the resource can not be imported yet, errors fail the import
+	Diagnostics *[]struct {
+		// Severity indicates the diagnostic level ("error" or "warning")
+		Severity string `json:"severity"`
+		// Summary is a short description of the diagnostic
+		Summary string `json:"summary"`
+		// Detail provides additional context about the diagnostic
+		Detail string `json:"detail"`
+		// PropPath optionally specifies which property the diagnostic relates to
+		PropPath *[]string `json:"propPath,omitempty"`
+	} `json:"diagnostics,omitempty"`
+}
+
+// CanImport confirms that an existing resource is ready to be imported by calling the "canImport" method via JSON-RPC,
+// eg: that it exists and has finished provisioning. The script must not modify the resource.
+// Note: The canImport method is optional; if not implemented in the script, this method returns nil.
+//
+// Parameters:
+//   - ctx: The context for the operation, used for cancellation and timeouts
+//   - params: The can import request containing the resource ID and any props given with the import
+//
+// Returns the can import response with any reasons the resource can not be imported, or nil if the method is not implemented.
+// Returns an error if the JSON-RPC call fails.
+func (c *DenoClientResource) CanImport(ctx context.Context, params *CanImportRequest) (*CanImportResponse, error) {
+	var response *CanImportResponse
+	if err := c.Client.Call(ctx, "canImport", params, &response); err != nil {
+
+		// CanImport method is optional - return nil if not implemented
+		var rpcErr *jsonrpc2.Error
+		if errors.As(err, &rpcErr) && rpcErr.Code == jsonrpc2.CodeMethodNotFound {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to call canImport method over JSON-RPC: %w", err)
+	}
+
+	return response, nil
+}
+
+// PollRequest represents the request payload for checking whether a resource has become ready.
+type PollRequest struct {
+	// ID is the unique identifier of the resource being waited on
+	ID ResourceID `json:"id"`
+	// Props contains the resource configuration properties the resource was created or updated with
+	Props any `json:"props"`
+	// State contains the resource state data returned by the create or update
+	State any `json:"state"`
+	// SensitiveState contains the resource sensitive state data returned by the create or update
+	SensitiveState any `json:"sensitiveState"`
+	// DryRun is always true, polls only observe the resource and must not make changes
+	DryRun bool `json:"dryRun"`
+}
+
+// PollResponse represents the response from checking whether a resource has become ready.
+type PollResponse struct {
+	// Ready reports whether the resource has become ready
+	Ready bool `json:"ready"`
+	// State optionally replaces the state data returned by the create or update, once the resource is ready
+	State *any `json:"state"`
+	// SensitiveState optionally replaces the sensitive state data returned by the create or update
+	SensitiveState *any `json:"sensitiveState"`
+	// SensitivePaths lists paths within State whose values should be moved into SensitiveState
+	SensitivePaths [][]string `json:"sensitivePaths,omitempty"`
+	// TypedPaths lists paths within State whose lists and objects are stored as a list or map with a single element type
+	TypedPaths [][]string `json:"typedPaths,omitempty"`
+	// Diagnostics contains any warnings or errors to display to the user, an error stops the polling
+	Diagnostics *[]struct {
+		// Severity indicates the diagnostic level ("error" or "warning")
+		Severity string `json:"severity"`
+		// Summary is a short description of the diagnostic
+		Summary string `json:"summary"`
+		// Detail provides additional context about the diagnostic
+		Detail string `json:"detail"`
+		// PropPath optionally specifies which property the diagnostic relates to
+		PropPath *[]string `json:"propPath,omitempty"`
+	} `json:"diagnostics,omitempty"`
+}
+
+// Poll checks whether a resource has become ready by calling the "poll" method via JSON-RPC.
+// It is called repeatedly after a create or update that returned a PollInstruction.
+//
+// Parameters:
+//   - ctx: The context for the operation, used for cancellation and timeouts
+//   - params: The poll request containing the resource ID, props and state
+//
+// Returns the poll response reporting whether the resource is ready, or an error if the JSON-RPC call fails.
+func (c *DenoClientResource) Poll(ctx context.Context, params *PollRequest) (*PollResponse, error) {
+	var response *PollResponse
+	if err := c.Client.Call(ctx, "poll", params, &response); err != nil {
+
+		// Unlike other optional methods, poll is required once the script has asked to be polled
+		var rpcErr *jsonrpc2.Error
+		if errors.As(err, &rpcErr) && rpcErr.Code == jsonrpc2.CodeMethodNotFound {
+			return nil, fmt.Errorf("the script asked to poll until the resource is ready but does not implement poll")
+		}
+
+		return nil, fmt.Errorf("failed to call poll method over JSON-RPC: %w", err)
 	}
 
 	return response, nil
 }
+
+// DenoClientResourceServerMethods implements the server-side JSON-RPC methods that
+// the Deno runtime can call back to the provider. It handles progress updates
+// during long running creates, updates and deletes.
+type DenoClientResourceServerMethods struct {
+	// client is used to log the progress updates
+	client *DenoClient
+}
+
+// ResourceProgressRequest represents a progress update request from the Deno runtime.
+// It is sent during a create, update or delete to provide status updates to the user.
+type ResourceProgressRequest struct {
+	// Message is the progress message to display to the user
+	Message string `json:"message"`
+}
+
+// CreateProgress handles progress update requests from the Deno runtime while a resource is created.
+//
+// Resources have no native progress API, so unlike actions the message can not be shown in the
+// Terraform UI. Instead it is logged at info level, which is visible with TF_LOG=INFO.
+func (c *DenoClientResourceServerMethods) CreateProgress(ctx context.Context, params *ResourceProgressRequest) {
+	c.client.logInfo(ctx, "[deno create progress] "+params.Message)
+}
+
+// UpdateProgress handles progress update requests from the Deno runtime while a resource is updated.
+// Like CreateProgress the message is logged at info level.
+func (c *DenoClientResourceServerMethods) UpdateProgress(ctx context.Context, params *ResourceProgressRequest) {
+	c.client.logInfo(ctx, "[deno update progress] "+params.Message)
+}
+
+// DeleteProgress handles progress update requests from the Deno runtime while a resource is deleted,
+// eg: as each of several cleanup steps completes. Like CreateProgress the message is logged at info level.
+func (c *DenoClientResourceServerMethods) DeleteProgress(ctx context.Context, params *ResourceProgressRequest) {
+	c.client.logInfo(ctx, "[deno delete progress] "+params.Message)
+}
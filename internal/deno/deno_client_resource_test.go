@@ -0,0 +1,87 @@
+package deno
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDenoClientResourceServerMethods_CreateProgress(t *testing.T) {
+	t.Setenv("DENO_TOFU_BRIDGE_TEST_MODE", "true")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	redactor, err := newLogRedactor([]string{`token=(\S+)`})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	c := NewDenoClientResource("deno", "script.ts", "", nil, nil)
+	c.Client.redactor = redactor
+
+	methods := c.Client.rpcMethods(t.Context(), nil)
+	if _, ok := methods["createProgress"]; !ok {
+		t.Fatal("Expected a createProgress server method")
+	}
+	if _, ok := methods["updateProgress"]; !ok {
+		t.Fatal("Expected an updateProgress server method")
+	}
+	if _, ok := methods["deleteProgress"]; !ok {
+		t.Fatal("Expected a deleteProgress server method")
+	}
+
+	server := &DenoClientResourceServerMethods{c.Client}
+	server.CreateProgress(t.Context(), &ResourceProgressRequest{Message: "waiting for cluster, token=abc"})
+
+	if !strings.Contains(buf.String(), "[INFO] [deno create progress] waiting for cluster, token=***") {
+		t.Errorf("Expected the redacted progress to be logged at info level, got '%s'", buf.String())
+	}
+}
+
+func TestDeleteResponse_IncompleteSteps(t *testing.T) {
+	response := &DeleteResponse{
+		Steps: []DeleteStep{
+			{Name: "remove dns record", Done: true},
+			{Name: "drain load balancer", Done: false, Detail: "timed out waiting for connections"},
+			{Name: "delete bucket", Done: false},
+		},
+	}
+
+	incomplete := response.IncompleteSteps()
+	if len(incomplete) != 2 {
+		t.Fatalf("Expected 2 incomplete steps, got %v", incomplete)
+	}
+	if incomplete[0] != "drain load balancer: timed out waiting for connections" || incomplete[1] != "delete bucket" {
+		t.Errorf("Unexpected incomplete steps: %v", incomplete)
+	}
+
+	if incomplete := (&DeleteResponse{}).IncompleteSteps(); incomplete != nil {
+		t.Errorf("Expected no incomplete steps without any steps, got %v", incomplete)
+	}
+}
+
+func TestDeleteResponse_IsDone(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name     string
+		done     *bool
+		strict   bool
+		expected bool
+	}{
+		{name: "omitted", done: nil, strict: false, expected: true},
+		{name: "omitted strict", done: nil, strict: true, expected: false},
+		{name: "true", done: &yes, strict: false, expected: true},
+		{name: "true strict", done: &yes, strict: true, expected: true},
+		{name: "false", done: &no, strict: false, expected: false},
+		{name: "false strict", done: &no, strict: true, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if done := (&DeleteResponse{Done: tt.done}).IsDone(tt.strict); done != tt.expected {
+				t.Errorf("Expected IsDone to be %v, got %v", tt.expected, done)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package deno
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+// reportArgs is a fake Deno binary that fails to start, reporting the arguments it was run with on stderr.
+const reportArgs = `echo "$@" >&2; exit 3`
+
+// writeFakeDeno writes a shell script that stands in for the Deno binary, returning its path.
+func writeFakeDeno(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake Deno binary is a shell script")
+	}
+	fakeDeno := filepath.Join(t.TempDir(), "deno")
+	assert.NoError(t, os.WriteFile(fakeDeno, []byte("#!/bin/sh\n"+script+"\n"), 0o755))
+	return fakeDeno
+}
+
+func TestDenoClient_WarnSlowStart(t *testing.T) {
+	t.Setenv("DENO_TOFU_BRIDGE_TEST_MODE", "true")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		name     string
+		options  *ClientOptions
+		elapsed  time.Duration
+		expected bool
+	}{
+		{name: "slow", options: &ClientOptions{SlowStartWarning: 2 * time.Second}, elapsed: 3 * time.Second, expected: true},
+		{name: "fast", options: &ClientOptions{SlowStartWarning: 2 * time.Second}, elapsed: time.Second, expected: false},
+		{name: "disabled", options: &ClientOptions{}, elapsed: time.Minute, expected: false},
+		{name: "no options", options: nil, elapsed: time.Minute, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			c := NewDenoClient("deno", "script.ts", "", nil, tt.options, nil)
+			c.warnSlowStart(t.Context(), tt.elapsed)
+
+			warned := strings.Contains(buf.String(), "[WARN] The Deno script script.ts took 3s to start, longer than the 2s slow start warning.")
+			if tt.expected && !warned {
+				t.Errorf("Expected a slow start warning, got '%s'", buf.String())
+			}
+			if !tt.expected && buf.Len() > 0 {
+				t.Errorf("Expected no warning, got '%s'", buf.String())
+			}
+		})
+	}
+}
+
+func TestDenoClient_Start_PassesCert(t *testing.T) {
+	fakeDeno := writeFakeDeno(t, reportArgs)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "ca.pem")
+	assert.NoError(t, os.WriteFile(certFile, []byte("-----BEGIN CERTIFICATE-----\n"), 0o644))
+
+	options := &ClientOptions{CertFile: certFile, NoConfigDiscovery: true, ResponseGrace: 50 * time.Millisecond}
+	err := NewDenoClient(fakeDeno, "script.ts", "", nil, options, nil).Start(t.Context())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "--cert="+certFile+" ")
+
+	// A missing bundle fails before Deno is started
+	options.CertFile = filepath.Join(dir, "missing.pem")
+	err = NewDenoClient(fakeDeno, "script.ts", "", nil, options, nil).Start(t.Context())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read CA bundle")
+}
+
+func TestDenoClient_Start_PassesPreload(t *testing.T) {
+	fakeDeno := writeFakeDeno(t, reportArgs)
+
+	options := &ClientOptions{PreloadModule: "https://example.com/setup.ts", NoConfigDiscovery: true, ResponseGrace: 50 * time.Millisecond}
+	err := NewDenoClient(fakeDeno, "script.ts", "", nil, options, nil).Start(t.Context())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "--preload=https://example.com/setup.ts ")
+}
+
+func TestDenoClient_Start_PassesLockFile(t *testing.T) {
+	fakeDeno := writeFakeDeno(t, reportArgs)
+	dir := t.TempDir()
+	lockFile := filepath.Join(dir, "deno.lock")
+
+	options := &ClientOptions{LockFile: lockFile, NoConfigDiscovery: true, ResponseGrace: 50 * time.Millisecond}
+	err := NewDenoClient(fakeDeno, "script.ts", "", nil, options, nil).Start(t.Context())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "--lock="+lockFile+" ")
+	assert.NotContains(t, err.Error(), "--frozen")
+
+	options.LockFrozen = true
+	err = NewDenoClient(fakeDeno, "script.ts", "", nil, options, nil).Start(t.Context())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "--lock="+lockFile+" --frozen ")
+}
+
+func TestDenoClient_Start_PassesDenoFlags(t *testing.T) {
+	fakeDeno := writeFakeDeno(t, reportArgs)
+
+	options := &ClientOptions{DenoFlags: []string{"--node-modules-dir=auto", "--allow-scripts"}, NoConfigDiscovery: true, ResponseGrace: 50 * time.Millisecond}
+	err := NewDenoClient(fakeDeno, "script.ts", "", &Permissions{Allow: []string{"read"}}, options, nil).Start(t.Context())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "--allow-read --node-modules-dir=auto --allow-scripts ")
+
+	// Conflicting flags fail before Deno is started
+	options.DenoFlags = []string{"--allow-all"}
+	err = NewDenoClient(fakeDeno, "script.ts", "", nil, options, nil).Start(t.Context())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid deno flag: --allow-all can not be set by deno_flags")
+}
+
+func TestDenoClient_Start_ScriptPathFromEnv(t *testing.T) {
+	fakeDeno := writeFakeDeno(t, reportArgs)
+	dir := t.TempDir()
+	script := filepath.Join(dir, "from-env.ts")
+	t.Setenv("DENOBRIDGE_TEST_SCRIPT", script)
+
+	options := &ClientOptions{NoConfigDiscovery: true, ResponseGrace: 50 * time.Millisecond}
+	err := NewDenoClient(fakeDeno, "env:DENOBRIDGE_TEST_SCRIPT", "", nil, options, nil).Start(t.Context())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), script)
+
+	// An unset variable fails before Deno is started
+	err = NewDenoClient(fakeDeno, "env:DENOBRIDGE_TEST_MISSING", "", nil, options, nil).Start(t.Context())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "refers to the environment variable DENOBRIDGE_TEST_MISSING, which is not set")
+}
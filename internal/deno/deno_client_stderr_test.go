@@ -0,0 +1,73 @@
+package deno
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPipeToDebugLog_CarriageReturns(t *testing.T) {
+	t.Setenv("DENO_TOFU_BRIDGE_TEST_MODE", "true")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	var seen []string
+	pipeToDebugLog(context.Background(), strings.NewReader("10%\r20%\r100%\r\ndone\n\nbye"), "[deno stderr] ", nil, func(line string) {
+		seen = append(seen, line)
+	})
+
+	if expected := []string{"10%", "20%", "100%", "done", "", "bye"}; !slices.Equal(seen, expected) {
+		t.Errorf("Expected lines %q, got %q", expected, seen)
+	}
+	if !strings.Contains(buf.String(), "[deno stderr] 20%") {
+		t.Errorf("Expected progress redrawn with \\r to be logged, got '%s'", buf.String())
+	}
+}
+
+func TestReadLines_IdleFlush(t *testing.T) {
+	reader, writer := io.Pipe()
+	lines := make(chan string, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		readLines(reader, 20*time.Millisecond, func(line string) { lines <- line })
+	}()
+
+	expectLine := func(expected string) {
+		t.Helper()
+		select {
+		case line := <-lines:
+			if line != expected {
+				t.Errorf("Expected line '%s', got '%s'", expected, line)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for line '%s'", expected)
+		}
+	}
+
+	// A partial line is emitted once the writer goes idle
+	if _, err := writer.Write([]byte("waiting for cluster")); err != nil {
+		t.Fatalf("Failed to write: %s", err)
+	}
+	expectLine("waiting for cluster")
+
+	// The rest of the line is emitted once it ends, without an empty line for the already flushed start
+	if _, err := writer.Write([]byte("... ready\nnext\n")); err != nil {
+		t.Fatalf("Failed to write: %s", err)
+	}
+	expectLine("... ready")
+	expectLine("next")
+
+	writer.Close()
+	<-done
+	if len(lines) != 0 {
+		t.Errorf("Expected no further lines, got '%s'", <-lines)
+	}
+}
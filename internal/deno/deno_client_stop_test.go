@@ -0,0 +1,57 @@
+//go:build !windows
+
+package deno
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+// startFakeProcess starts a shell script in place of a real Deno process.
+func startFakeProcess(t *testing.T, options *ClientOptions, script string) *DenoClient {
+	t.Helper()
+	c := NewDenoClient("deno", "script.ts", "", nil, options, nil)
+	c.process = exec.Command("sh", "-c", script)
+	assert.NoError(t, c.process.Start())
+	return c
+}
+
+func TestDenoClient_Stop_TrapsSigterm(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "cleaned")
+	c := startFakeProcess(t, &ClientOptions{StopGrace: 500 * time.Millisecond},
+		`trap 'echo done > "`+marker+`"; exit 0' TERM; while :; do sleep 0.05; done`,
+	)
+
+	start := time.Now()
+	assert.NoError(t, c.Stop())
+	elapsed := time.Since(start)
+
+	content, err := os.ReadFile(marker)
+	assert.NoError(t, err)
+	assert.Equal(t, "done\n", string(content))
+	assert.True(t, elapsed < time.Second, "expected cleanup to complete within the grace period, took %s", elapsed)
+}
+
+func TestDenoClient_Stop_KillsAfterGrace(t *testing.T) {
+	c := startFakeProcess(t, &ClientOptions{StopGrace: 200 * time.Millisecond},
+		`trap '' TERM; while :; do sleep 0.05; done`,
+	)
+
+	start := time.Now()
+	err := c.Stop()
+	elapsed := time.Since(start)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "was killed")
+	assert.True(t, elapsed < 2*time.Second, "expected the process to be killed promptly, took %s", elapsed)
+}
+
+func TestDenoClient_Stop_ExitsOnItsOwn(t *testing.T) {
+	c := startFakeProcess(t, nil, `exit 0`)
+	assert.NoError(t, c.Stop())
+}
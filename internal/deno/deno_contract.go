@@ -0,0 +1,175 @@
+package deno
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// ContractMethod describes a single method of the JSON-RPC contract between the provider and a Deno script.
+type ContractMethod struct {
+	// Name is the JSON-RPC method name, eg: "create"
+	Name string `json:"name"`
+	// Direction is "call" for methods the provider calls on the script
+	// and "callback" for methods the script calls on the provider.
+	Direction string `json:"direction"`
+	// Optional methods may be left unimplemented by scripts
+	Optional bool `json:"optional,omitempty"`
+	// Params is the shape of the params sent with the method
+	Params any `json:"params"`
+	// Result is the shape of the result returned by the method, nil when it returns nothing
+	Result any `json:"result,omitempty"`
+}
+
+// Directions of a ContractMethod.
+const (
+	contractCall     = "call"
+	contractCallback = "callback"
+)
+
+// RPCContract returns the JSON-RPC methods, and the shapes of their params and results, that the provider expects of
+// each provider type. Methods shared by every provider type, eg: health, are listed under "common".
+//
+// The shapes are derived from the request and response structs of this package, so they always match what is sent
+// and decoded. Objects map each JSON field to its shape, a field name ending in "?" is optional. Scalars are "string",
+// "number", "boolean" or "any", lists are a single element list holding the shape of their elements and maps are an
+// object with a single "[key]" field.
+//
+// It is maintainer tooling, for pinning the contract with the bridge library and generating its types.
+func RPCContract() map[string][]ContractMethod {
+	call := func(name string, optional bool, params, result any) ContractMethod {
+		return ContractMethod{
+			Name:      name,
+			Direction: contractCall,
+			Optional:  optional,
+			Params:    contractShape(reflect.TypeOf(params)),
+			Result:    contractShape(reflect.TypeOf(result)),
+		}
+	}
+
+	return map[string][]ContractMethod{
+		"common": {
+			call("health", false, healthRequest{}, healthResponse{}),
+			{Name: "cancel", Direction: contractCall, Params: map[string]any{"method": "string"}},
+			{Name: "shutdown", Direction: contractCall, Params: nil},
+		},
+		"resource": append([]ContractMethod{
+			call("create", false, CreateRequest{}, CreateResponse{}),
+			call("read", false, CreateReadRequest{}, CreateReadResponse{}),
+			call("update", false, UpdateRequest{}, UpdateResponse{}),
+			call("delete", false, DeleteRequest{}, DeleteResponse{}),
+			call("modifyPlan", true, ModifyPlanRequest{}, ModifyPlanResponse{}),
+			call("check", true, CheckRequest{}, CheckResponse{}),
+			call("precondition", true, PreconditionRequest{}, PreconditionResponse{}),
+			call("canImport", true, CanImportRequest{}, CanImportResponse{}),
+			call("poll", true, PollRequest{}, PollResponse{}),
+		}, contractCallbacks(&DenoClientResourceServerMethods{})...),
+		"datasource": {
+			call("read", false, ReadRequest{}, ReadResponse{}),
+		},
+		"action": append([]ContractMethod{
+			call("invoke", false, InvokeRequest{}, InvokeResponse{}),
+		}, contractCallbacks(&DenoClientActionServerMethods{})...),
+		"ephemeral": {
+			call("open", false, OpenRequest{}, OpenResponse{}),
+			call("renew", false, RenewRequest{}, RenewResponse{}),
+			call("close", true, CloseRequest{}, CloseResponse{}),
+		},
+		"function": {
+			call("validate", false, ValidateRequest{}, ValidateResponse{}),
+		},
+	}
+}
+
+// contractCallbacks describes the server methods the script may call on the provider, named the same way
+// as jsocket.TypedServerMethods names them.
+func contractCallbacks(methods any) []ContractMethod {
+	typ := reflect.TypeOf(methods)
+	callbacks := make([]ContractMethod, 0, typ.NumMethod())
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		// The receiver is the first input and the context the second
+		callback := ContractMethod{Name: lowerFirst(method.Name), Direction: contractCallback}
+		if method.Type.NumIn() > 2 {
+			callback.Params = contractShape(method.Type.In(2))
+		}
+		for j := 0; j < method.Type.NumOut(); j++ {
+			if out := method.Type.Out(j); out != reflect.TypeFor[error]() {
+				callback.Result = contractShape(out)
+			}
+		}
+		callbacks = append(callbacks, callback)
+	}
+	return callbacks
+}
+
+// contractShape describes the JSON shape of typ, see RPCContract.
+func contractShape(typ reflect.Type) any {
+	return contractShapeOf(typ, map[reflect.Type]bool{})
+}
+
+// contractShapeOf describes the JSON shape of typ, seen holds the structs being described to stop recursive types.
+func contractShapeOf(typ reflect.Type, seen map[reflect.Type]bool) any {
+	if typ == nil {
+		return nil
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeFor[json.RawMessage]() {
+		// Raw JSON is passed through untouched, so may be any value
+		return "any"
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return []any{contractShapeOf(typ.Elem(), seen)}
+	case reflect.Map:
+		return map[string]any{"[key]": contractShapeOf(typ.Elem(), seen)}
+	case reflect.Struct:
+		if seen[typ] {
+			return typ.Name()
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+
+		shape := map[string]any{}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			tag := field.Tag.Get("json")
+			if !field.IsExported() || tag == "-" {
+				continue
+			}
+			name, options, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = field.Name
+			}
+			if field.Type.Kind() == reflect.Pointer || strings.Contains(options, "omitempty") {
+				name += "?"
+			}
+			shape[name] = contractShapeOf(field.Type, seen)
+		}
+		return shape
+	default:
+		return "any"
+	}
+}
+
+// lowerFirst lower cases the first letter of s, eg: "CreateProgress" becomes "createProgress".
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,80 @@
+package deno
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/jsocket"
+)
+
+// TestRPCContract_Methods tests that every provider type lists the methods the provider calls and accepts.
+func TestRPCContract_Methods(t *testing.T) {
+	expected := map[string][]string{
+		"common":     {"health", "cancel", "shutdown"},
+		"resource":   {"create", "read", "update", "delete", "modifyPlan", "check", "precondition", "canImport", "poll", "createProgress", "deleteProgress", "updateProgress"},
+		"datasource": {"read"},
+		"action":     {"invoke", "invokeProgress"},
+		"ephemeral":  {"open", "renew", "close"},
+		"function":   {"validate"},
+	}
+
+	contract := RPCContract()
+	if len(contract) != len(expected) {
+		t.Errorf("Expected %d provider types, got %d", len(expected), len(contract))
+	}
+	for providerType, names := range expected {
+		var got []string
+		for _, method := range contract[providerType] {
+			got = append(got, method.Name)
+		}
+		if !slices.Equal(got, names) {
+			t.Errorf("%s: expected methods %v, got %v", providerType, names, got)
+		}
+	}
+}
+
+// TestRPCContract_Callbacks tests that callbacks are named the same way the server methods are registered.
+func TestRPCContract_Callbacks(t *testing.T) {
+	for _, methods := range []any{&DenoClientResourceServerMethods{}, &DenoClientActionServerMethods{}} {
+		registered := jsocket.TypedServerMethods(methods)(context.Background(), nil)
+		for _, callback := range contractCallbacks(methods) {
+			if _, ok := registered[callback.Name]; !ok {
+				t.Errorf("Expected callback %s to be a registered server method", callback.Name)
+			}
+		}
+	}
+}
+
+// TestRPCContract_Shapes tests the shapes derived from the request and response structs.
+func TestRPCContract_Shapes(t *testing.T) {
+	var create ContractMethod
+	for _, method := range RPCContract()["resource"] {
+		if method.Name == "create" {
+			create = method
+		}
+	}
+
+	data, err := json.Marshal(create.Params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if string(data) != `{"dryRun":"boolean","idempotencyKey":"string","props":"any","writeOnlyProps?":"any"}` {
+		t.Errorf("Unexpected create params shape: %s", data)
+	}
+
+	result, ok := create.Result.(map[string]any)
+	if !ok {
+		t.Fatalf("Expected the create result to be an object, got %T", create.Result)
+	}
+	if result["id"] != "string" {
+		t.Errorf("Expected the create result id to be a string, got %v", result["id"])
+	}
+	if _, ok := result["identifiers?"].(map[string]any); !ok {
+		t.Errorf("Expected the create result to have optional identifiers, got %v", result["identifiers?"])
+	}
+	if result["private?"] != "any" {
+		t.Errorf("Expected the create result to have an optional private value of any shape, got %v", result["private?"])
+	}
+}
@@ -0,0 +1,208 @@
+package deno
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errDaemonsClosed is returned by Acquire once the daemons have been closed, eg: as the provider shuts down.
+var errDaemonsClosed = errors.New("the provider is shutting down, no more Deno daemons may be started")
+
+// ClientDaemons keeps a single long lived resource client, a daemon, running for each script for the life of the
+// provider. Unlike a ClientPool, whose clients are used by one operation at a time, every operation on the same
+// script shares the daemon concurrently. The JSON-RPC connection gives each call its own request ID, so responses
+// are matched to their calls however they interleave, and the bridge library handles each call as it arrives.
+//
+// A daemon is retired when an operation using it fails, or its process exits, so that whatever state the failure
+// left the script in does not leak into later operations. The next operation starts a new daemon, the retired one
+// is stopped once the operations still using it are done.
+//
+// All methods are safe for concurrent use and a nil *ClientDaemons starts no daemons.
+type ClientDaemons struct {
+	// mu guards everything below, and the users and retired fields of every daemon.
+	mu      sync.Mutex
+	daemons map[string]*clientDaemon
+	closed  bool
+}
+
+// clientDaemon is the daemon of a single script.
+type clientDaemon struct {
+	client *DenoClientResource
+
+	// ready is closed once the daemon has been started, after which err holds why it failed to start.
+	ready chan struct{}
+	err   error
+
+	// users counts the operations using the daemon, which is stopped once it has been retired and is no longer used.
+	users   int
+	retired bool
+}
+
+// NewClientDaemons creates the daemons of a provider, or nil when daemon mode is not enabled.
+func NewClientDaemons(enabled bool) *ClientDaemons {
+	if !enabled {
+		return nil
+	}
+	return &ClientDaemons{daemons: map[string]*clientDaemon{}}
+}
+
+// Acquire returns the running daemon with the given key, see PoolKey. When there is none, newClient creates one
+// that is started with start. Operations that acquire a daemon while it is starting wait for it to be started.
+//
+// The returned release func must be called once the operation is done with the daemon, reporting whether the
+// operation failed. It returns the error from stopping the daemon, when the daemon was stopped. When the daemon
+// failed to start its client is returned alongside the error, so that the failure can be explained.
+func (d *ClientDaemons) Acquire(ctx context.Context, key string, newClient func() *DenoClientResource, start func(c *DenoClient) error) (*DenoClientResource, func(failed bool) error, error) {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil, nil, errDaemonsClosed
+	}
+	daemon := d.daemons[key]
+	var exited *clientDaemon
+	if daemon != nil && daemon.exited() {
+		d.retire(key, daemon)
+		if daemon.users == 0 {
+			exited = daemon
+		}
+		daemon = nil
+	}
+	if daemon == nil {
+		daemon = &clientDaemon{client: newClient(), ready: make(chan struct{})}
+		d.daemons[key] = daemon
+		daemon.users++
+		d.mu.Unlock()
+
+		// Release what is left of a daemon that exited while it was not in use
+		if exited != nil {
+			_ = exited.client.Client.Stop()
+		}
+
+		daemon.err = start(daemon.client.Client)
+		if daemon.err != nil {
+			d.mu.Lock()
+			daemon.users--
+			if d.daemons[key] == daemon {
+				delete(d.daemons, key)
+			}
+			d.mu.Unlock()
+		}
+		close(daemon.ready)
+		if daemon.err != nil {
+			return daemon.client, nil, daemon.err
+		}
+	} else {
+		daemon.users++
+		d.mu.Unlock()
+
+		select {
+		case <-daemon.ready:
+		case <-ctx.Done():
+			d.mu.Lock()
+			daemon.users--
+			d.mu.Unlock()
+			return nil, nil, context.Cause(ctx)
+		}
+		if daemon.err != nil {
+			d.mu.Lock()
+			daemon.users--
+			d.mu.Unlock()
+			return daemon.client, nil, daemon.err
+		}
+	}
+
+	var once sync.Once
+	release := func(failed bool) error {
+		var err error
+		once.Do(func() { err = d.release(key, daemon, failed) })
+		return err
+	}
+	return daemon.client, release, nil
+}
+
+// release is called once an operation is done with a daemon.
+func (d *ClientDaemons) release(key string, daemon *clientDaemon, failed bool) error {
+	d.mu.Lock()
+	daemon.users--
+	if failed || daemon.client.Client.hasExited() {
+		d.retire(key, daemon)
+	}
+	stop := daemon.retired && daemon.users == 0
+	if !stop && daemon.users == 0 {
+		// Nothing collected from stderr so far can be reported against a later operation
+		daemon.client.Client.resetStderrDetails()
+	}
+	d.mu.Unlock()
+
+	if stop {
+		return daemon.client.Client.Stop()
+	}
+	return nil
+}
+
+// retire removes a daemon so that it is no longer acquired, it is stopped once no longer used.
+// The caller must hold d.mu.
+func (d *ClientDaemons) retire(key string, daemon *clientDaemon) {
+	daemon.retired = true
+	if d.daemons[key] == daemon {
+		delete(d.daemons, key)
+	}
+}
+
+// exited reports whether the daemon has been started and its process has since exited.
+// The caller must hold the lock of the daemons it belongs to.
+func (c *clientDaemon) exited() bool {
+	select {
+	case <-c.ready:
+		return c.err == nil && c.client.Client.hasExited()
+	default:
+		return false
+	}
+}
+
+// Close stops every daemon, concurrently so that shutdown is not held up by each stop grace period in turn.
+// Daemons still in use are stopped once the operations using them are done, and no more are started afterwards.
+// The first error is returned.
+func (d *ClientDaemons) Close() error {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	d.closed = true
+	var idle []*clientDaemon
+	for key, daemon := range d.daemons {
+		d.retire(key, daemon)
+		if daemon.users == 0 {
+			idle = append(idle, daemon)
+		}
+	}
+	d.mu.Unlock()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for _, daemon := range idle {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := daemon.client.Client.Stop(); err != nil {
+				errOnce.Do(func() { firstErr = err })
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// Running returns how many daemons are running, or starting, and may be acquired.
+func (d *ClientDaemons) Running() int {
+	if d == nil {
+		return 0
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.daemons)
+}
@@ -0,0 +1,145 @@
+//go:build !windows
+
+package deno
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+// fakeDaemonStarter creates fake resource clients in place of real Deno daemons, counting how many were started.
+func fakeDaemonStarter(t *testing.T) (func() *DenoClientResource, func(c *DenoClient) error, *atomic.Int64) {
+	var starts atomic.Int64
+	newClient := func() *DenoClientResource {
+		return startFakeResourceClient(t)
+	}
+	start := func(c *DenoClient) error {
+		starts.Add(1)
+		return nil
+	}
+	return newClient, start, &starts
+}
+
+func TestClientDaemons_SharedConcurrently(t *testing.T) {
+	daemons := NewClientDaemons(true)
+	newClient, start, starts := fakeDaemonStarter(t)
+
+	// Every operation on the same script shares a single daemon, however many run at once
+	var wg sync.WaitGroup
+	clients := make([]*DenoClientResource, 10)
+	releases := make([]func(bool) error, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			clients[i], releases[i], err = daemons.Acquire(context.Background(), "a", newClient, start)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), starts.Load())
+	for _, c := range clients {
+		assert.Equal(t, clients[0], c)
+	}
+
+	// The daemon keeps running once every operation is done with it
+	for _, release := range releases {
+		assert.NoError(t, release(false))
+	}
+	assert.False(t, clients[0].Client.hasExited())
+	assert.Equal(t, 1, daemons.Running())
+
+	// Other scripts get their own daemon
+	other, release, err := daemons.Acquire(context.Background(), "b", newClient, start)
+	assert.NoError(t, err)
+	assert.NoError(t, release(false))
+	assert.NotEqual(t, clients[0], other)
+	assert.Equal(t, 2, daemons.Running())
+}
+
+func TestClientDaemons_RetiredAfterFailure(t *testing.T) {
+	daemons := NewClientDaemons(true)
+	newClient, start, starts := fakeDaemonStarter(t)
+
+	first, releaseFirst, err := daemons.Acquire(context.Background(), "a", newClient, start)
+	assert.NoError(t, err)
+	second, releaseSecond, err := daemons.Acquire(context.Background(), "a", newClient, start)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	// The failed daemon is no longer handed out, but is only stopped once the other operation is done with it
+	assert.NoError(t, releaseFirst(true))
+	assert.False(t, first.Client.hasExited())
+	replacement, releaseReplacement, err := daemons.Acquire(context.Background(), "a", newClient, start)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, replacement)
+	assert.Equal(t, int64(2), starts.Load())
+
+	assert.NoError(t, releaseSecond(false))
+	assert.True(t, first.Client.hasExited())
+	assert.NoError(t, releaseReplacement(false))
+	assert.False(t, replacement.Client.hasExited())
+}
+
+func TestClientDaemons_ReplacesExitedDaemon(t *testing.T) {
+	daemons := NewClientDaemons(true)
+	newClient, start, starts := fakeDaemonStarter(t)
+
+	c, release, err := daemons.Acquire(context.Background(), "a", newClient, start)
+	assert.NoError(t, err)
+	assert.NoError(t, release(false))
+
+	assert.NoError(t, c.Client.process.Process.Kill())
+	<-c.Client.exited()
+	replacement, release, err := daemons.Acquire(context.Background(), "a", newClient, start)
+	assert.NoError(t, err)
+	assert.NoError(t, release(false))
+	assert.NotEqual(t, c, replacement)
+	assert.Equal(t, int64(2), starts.Load())
+}
+
+func TestClientDaemons_StartFailure(t *testing.T) {
+	daemons := NewClientDaemons(true)
+	newClient, _, _ := fakeDaemonStarter(t)
+
+	boom := errors.New("boom")
+	c, release, err := daemons.Acquire(context.Background(), "a", newClient, func(c *DenoClient) error { return boom })
+	assert.IsError(t, err, boom)
+	assert.NotZero(t, c)
+	assert.Zero(t, release)
+	assert.Equal(t, 0, daemons.Running())
+}
+
+func TestClientDaemons_Close(t *testing.T) {
+	daemons := NewClientDaemons(true)
+	newClient, start, _ := fakeDaemonStarter(t)
+
+	idle, release, err := daemons.Acquire(context.Background(), "a", newClient, start)
+	assert.NoError(t, err)
+	assert.NoError(t, release(false))
+	inUse, release, err := daemons.Acquire(context.Background(), "b", newClient, start)
+	assert.NoError(t, err)
+
+	// Daemons still in use are stopped once the operation using them is done
+	assert.NoError(t, daemons.Close())
+	assert.True(t, idle.Client.hasExited())
+	assert.False(t, inUse.Client.hasExited())
+	assert.NoError(t, release(false))
+	assert.True(t, inUse.Client.hasExited())
+
+	_, _, err = daemons.Acquire(context.Background(), "a", newClient, start)
+	assert.IsError(t, err, errDaemonsClosed)
+}
+
+func TestClientDaemons_Disabled(t *testing.T) {
+	daemons := NewClientDaemons(false)
+	assert.Zero(t, daemons)
+	assert.Equal(t, 0, daemons.Running())
+	assert.NoError(t, daemons.Close())
+}
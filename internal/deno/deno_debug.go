@@ -0,0 +1,47 @@
+package deno
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// writeDebugRecord copies the script about to be run into debugDir, alongside a .cmd sidecar
+// holding the time and the (redacted) command line it was run with, for later inspection.
+//
+// Files are named after the time the process was started and the script, eg:
+// 20240506T070809.123456789Z-resource.ts and 20240506T070809.123456789Z-resource.ts.cmd.
+// Remote scripts can not be copied, so only the sidecar is written for them.
+//
+// Returns the path of the sidecar file. The records are never cleaned up by the provider.
+func writeDebugRecord(debugDir, scriptArg, cmdLine string, now time.Time) (string, error) {
+	if err := os.MkdirAll(debugDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create debug dir: %w", err)
+	}
+
+	timestamp := now.UTC().Format("20060102T150405.000000000Z")
+	remote := strings.Contains(scriptArg, "://")
+	name := timestamp + "-" + filepath.Base(scriptArg)
+	if remote {
+		name = timestamp + "-" + filepath.Base(strings.SplitN(scriptArg, "?", 2)[0])
+	}
+	recordPath := filepath.Join(debugDir, name)
+
+	if !remote {
+		script, err := os.ReadFile(scriptArg)
+		if err != nil {
+			return "", fmt.Errorf("failed to read script: %w", err)
+		}
+		if err := os.WriteFile(recordPath, script, 0o644); err != nil {
+			return "", fmt.Errorf("failed to copy script to debug dir: %w", err)
+		}
+	}
+
+	sidecar := fmt.Sprintf("# %s\n# %s\n%s\n", now.UTC().Format(time.RFC3339Nano), scriptArg, cmdLine)
+	if err := os.WriteFile(recordPath+".cmd", []byte(sidecar), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write command to debug dir: %w", err)
+	}
+	return recordPath + ".cmd", nil
+}
@@ -0,0 +1,51 @@
+package deno
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteDebugRecord_LocalScript(t *testing.T) {
+	scriptPath := filepath.Join(t.TempDir(), "resource.ts")
+	if err := os.WriteFile(scriptPath, []byte("console.log('hi');\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write script: %s", err)
+	}
+	debugDir := filepath.Join(t.TempDir(), "debug")
+	now := time.Date(2024, 5, 6, 7, 8, 9, 123, time.UTC)
+
+	sidecar, err := writeDebugRecord(debugDir, scriptPath, "deno run -q "+scriptPath, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := filepath.Join(debugDir, "20240506T070809.000000123Z-resource.ts")
+	if sidecar != expected+".cmd" {
+		t.Errorf("Expected sidecar '%s', got '%s'", expected+".cmd", sidecar)
+	}
+	if script, err := os.ReadFile(expected); err != nil || string(script) != "console.log('hi');\n" {
+		t.Errorf("Expected the script to be copied, got '%s' (%v)", script, err)
+	}
+	cmd, err := os.ReadFile(sidecar)
+	if err != nil {
+		t.Fatalf("Failed to read sidecar: %s", err)
+	}
+	if !strings.Contains(string(cmd), "2024-05-06T07:08:09.000000123Z") || !strings.Contains(string(cmd), "deno run -q "+scriptPath) {
+		t.Errorf("Expected the sidecar to hold the time and command line, got '%s'", cmd)
+	}
+}
+
+func TestWriteDebugRecord_RemoteScript(t *testing.T) {
+	debugDir := t.TempDir()
+	sidecar, err := writeDebugRecord(debugDir, "https://example.com/mod.ts?v=1", "deno run https://example.com/mod.ts?v=1", time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	entries, _ := os.ReadDir(debugDir)
+	if len(entries) != 1 || !strings.HasSuffix(sidecar, "-mod.ts.cmd") {
+		t.Errorf("Expected only a sidecar for a remote script, got %v", entries)
+	}
+}
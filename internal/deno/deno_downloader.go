@@ -8,6 +8,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,6 +18,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -26,11 +28,45 @@ const (
 	maxVersionsToKeep = 3
 	githubAPIBase     = "https://api.github.com"
 	denoRepo          = "denoland/deno"
+
+	// defaultVersionListConcurrency is how many pages of releases ListVersions fetches at once by default.
+	defaultVersionListConcurrency = 4
+
+	// cacheDirEnvVar overrides the directory Deno binaries are cached in, when no cache dir has been set.
+	cacheDirEnvVar = "DENOBRIDGE_CACHE_DIR"
 )
 
 // DenoDownloader manages downloading and caching Deno binaries.
+//
+// A single DenoDownloader is safe for concurrent use. Calls for the same version are
+// serialized so a binary is only ever downloaded once, while calls for different
+// versions are able to proceed in parallel.
 type DenoDownloader struct {
-	mu sync.Mutex
+	// mu guards versionLocks, resolvedVersions, cacheDir and downloadBaseURL, and serializes the cleanup of old versions.
+	mu           sync.Mutex
+	versionLocks map[string]*sync.Mutex
+
+	// resolvedVersions pins the release that "latest" or a version constraint first resolved to, see resolveVersion.
+	resolvedVersions map[string]string
+
+	// cacheDir is the directory Deno binaries are cached in, see SetCacheDir.
+	cacheDir string
+
+	// metrics records how long downloads take, it is nil unless metrics are enabled.
+	metrics *Metrics
+
+	// VersionListConcurrency is how many pages of releases ListVersions fetches from the
+	// GitHub API at once. Zero or less uses the default of 4.
+	VersionListConcurrency int
+
+	// downloadBaseURL is the mirror Deno is downloaded from instead of GitHub, see SetDownloadBaseURL.
+	downloadBaseURL string
+
+	// apiBase overrides the GitHub API base URL, eg: in tests.
+	apiBase string
+
+	// retryDelay overrides how long to wait before the first retry of a failed request, eg: in tests.
+	retryDelay time.Duration
 }
 
 // githubRelease represents a GitHub release response.
@@ -48,46 +84,99 @@ type githubAsset struct {
 
 // NewDenoDownloader creates a new Deno downloader.
 func NewDenoDownloader() *DenoDownloader {
-	return &DenoDownloader{}
+	return &DenoDownloader{
+		versionLocks:     make(map[string]*sync.Mutex),
+		resolvedVersions: make(map[string]string),
+		metrics:          SharedMetrics(),
+	}
+}
+
+// versionLock returns the mutex that guards the cache entry for the given version.
+func (d *DenoDownloader) versionLock(version string) *sync.Mutex {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	lock, ok := d.versionLocks[version]
+	if !ok {
+		lock = &sync.Mutex{}
+		d.versionLocks[version] = lock
+	}
+	return lock
 }
 
 // GetDenoBinary returns the path to a Deno binary for the specified version.
 // It checks the cache first, and downloads if necessary.
-// version can be "latest" or a specific version like "v2.1.4".
+// version can be "latest", a specific version like "v2.1.4" or a constraint like ">=2.1.0, <3.0.0".
 func (d *DenoDownloader) GetDenoBinary(ctx context.Context, version string) (string, error) {
-	// Lock to prevent concurrent downloads
-	d.mu.Lock()
-	defer d.mu.Unlock()
+	paths, err := d.GetReleaseFiles(ctx, version, denoBinaryName())
+	if err != nil {
+		return "", err
+	}
+	return paths[denoBinaryName()], nil
+}
 
+// GetDenoBinaryWithFallbacks returns the path to a Deno binary for the specified version, trying each of
+// the fallback versions in order when it can not be had, eg: the tag does not exist or has no asset for
+// this platform. Which version was used is logged. When none can be had the error for each is returned.
+func (d *DenoDownloader) GetDenoBinaryWithFallbacks(ctx context.Context, version string, fallbacks []string) (string, error) {
+	var errs []error
+	for i, candidate := range append([]string{version}, fallbacks...) {
+		path, err := d.GetDenoBinary(ctx, candidate)
+		if err == nil {
+			if i > 0 {
+				tflog.Warn(ctx, fmt.Sprintf("Using fallback Deno version %s as %s could not be downloaded", candidate, version))
+			}
+			return path, nil
+		}
+		if len(fallbacks) == 0 || ctx.Err() != nil {
+			return "", err
+		}
+		tflog.Warn(ctx, fmt.Sprintf("Failed to get Deno %s: %s", candidate, err.Error()))
+		errs = append(errs, fmt.Errorf("deno %s: %w", candidate, err))
+	}
+	return "", errors.Join(errs...)
+}
+
+// GetReleaseFiles returns the paths to the named files extracted from the Deno release archive
+// for the specified version, keyed by file name. This allows companion tools shipped alongside
+// the deno binary to be cached in the same version directory.
+// It checks the cache first, and downloads if any of the files are missing.
+// version can be "latest", a specific version like "v2.1.4" or a constraint like ">=2.1.0, <3.0.0".
+func (d *DenoDownloader) GetReleaseFiles(ctx context.Context, version string, fileNames ...string) (map[string]string, error) {
 	// Get the cache directory
 	cacheDir, err := d.getCacheDir()
 	if err != nil {
-		return "", fmt.Errorf("failed to get cache directory: %w", err)
+		return nil, fmt.Errorf("failed to get cache directory: %w", err)
 	}
 
-	// Resolve version if "latest"
-	resolvedVersion := version
-	if version == "latest" {
-		tflog.Info(ctx, "Resolving latest Deno version")
-		resolved, err := d.getLatestVersion(ctx)
-		if err != nil {
-			return "", fmt.Errorf("failed to resolve latest version: %w", err)
-		}
-		resolvedVersion = resolved
-		tflog.Info(ctx, fmt.Sprintf("Resolved latest version to %s", resolvedVersion))
+	// Resolve version if "latest" or a constraint
+	resolvedVersion, err := d.resolveVersion(ctx, version)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if binary already exists in cache
-	binaryPath := filepath.Join(cacheDir, resolvedVersion, denoBinaryName())
-	if _, err := os.Stat(binaryPath); err == nil {
-		tflog.Info(ctx, fmt.Sprintf("Using cached Deno binary at %s", binaryPath))
-		return binaryPath, nil
+	// Lock to prevent concurrent downloads of the same version
+	lock := d.versionLock(resolvedVersion)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Check if all the files already exist in cache
+	paths := make(map[string]string, len(fileNames))
+	cached := true
+	for _, name := range fileNames {
+		paths[name] = filepath.Join(cacheDir, resolvedVersion, name)
+		if _, err := os.Stat(paths[name]); err != nil {
+			cached = false
+		}
+	}
+	if cached {
+		tflog.Info(ctx, fmt.Sprintf("Using cached Deno %s files %s", resolvedVersion, strings.Join(fileNames, ", ")))
+		return paths, nil
 	}
 
-	// Download and install the binary
+	// Download and install the files
 	tflog.Info(ctx, fmt.Sprintf("Downloading Deno version %s", resolvedVersion))
-	if err := d.downloadAndInstall(ctx, resolvedVersion, cacheDir); err != nil {
-		return "", fmt.Errorf("failed to download Deno: %w", err)
+	if err := d.downloadAndInstall(ctx, resolvedVersion, cacheDir, fileNames); err != nil {
+		return nil, fmt.Errorf("failed to download Deno: %w", err)
 	}
 
 	// Cleanup old versions
@@ -95,7 +184,7 @@ func (d *DenoDownloader) GetDenoBinary(ctx context.Context, version string) (str
 		tflog.Warn(ctx, fmt.Sprintf("Failed to cleanup old Deno versions: %s", err.Error()))
 	}
 
-	return binaryPath, nil
+	return paths, nil
 }
 
 // denoBinaryName returns the platform-specific binary name.
@@ -106,18 +195,59 @@ func denoBinaryName() string {
 	return "deno"
 }
 
-// getCacheDir returns the cache directory for Deno binaries.
+// CacheDir returns the directory that downloaded Deno binaries are cached in.
+func (d *DenoDownloader) CacheDir() (string, error) {
+	return d.getCacheDir()
+}
+
+// SetCacheDir sets the directory Deno binaries are cached in, eg: a persistent per-user location rather than
+// the temp dir, which CI systems often wipe between steps. Old versions are cleaned up from this directory.
+// An empty dir restores the default, which is the DENOBRIDGE_CACHE_DIR environment variable when set, otherwise
+// a directory in the temp dir.
+func (d *DenoDownloader) SetCacheDir(dir string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cacheDir = dir
+}
+
+// getCacheDir returns the cache directory for Deno binaries, creating it if needed.
 func (d *DenoDownloader) getCacheDir() (string, error) {
-	cacheDir := filepath.Join(os.TempDir(), "terraform-provider-denobridge")
+	d.mu.Lock()
+	cacheDir := d.cacheDir
+	d.mu.Unlock()
+	if cacheDir == "" {
+		cacheDir = os.Getenv(cacheDirEnvVar)
+	}
+	if cacheDir == "" {
+		cacheDir = filepath.Join(os.TempDir(), "terraform-provider-denobridge")
+	}
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create cache directory: %w", err)
 	}
 	return cacheDir, nil
 }
 
-// getLatestVersion fetches the latest stable release version from GitHub.
+// githubAPI returns the base URL of the GitHub API, or of the mirror when one is set.
+func (d *DenoDownloader) githubAPI() string {
+	if d.apiBase != "" {
+		return d.apiBase
+	}
+	if baseURL := d.getDownloadBaseURL(); baseURL != "" {
+		return baseURL
+	}
+	return githubAPIBase
+}
+
+// getLatestVersion fetches the latest stable release version from GitHub, retrying transient failures.
 func (d *DenoDownloader) getLatestVersion(ctx context.Context) (string, error) {
-	url := fmt.Sprintf("%s/repos/%s/releases/latest", githubAPIBase, denoRepo)
+	return withRetries(ctx, d, "fetch the latest Deno release", func() (string, error) {
+		return d.fetchLatestVersion(ctx)
+	})
+}
+
+// fetchLatestVersion makes a single attempt at fetching the latest stable release version from GitHub.
+func (d *DenoDownloader) fetchLatestVersion(ctx context.Context) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", d.githubAPI(), denoRepo)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -125,9 +255,7 @@ func (d *DenoDownloader) getLatestVersion(ctx context.Context) (string, error) {
 	}
 
 	// Add GitHub token if available
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	}
+	d.authorize(req)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -138,7 +266,7 @@ func (d *DenoDownloader) getLatestVersion(ctx context.Context) (string, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(body))
+		return "", &httpStatusError{resp.StatusCode, fmt.Sprintf("GitHub API returned status %d: %s", resp.StatusCode, string(body))}
 	}
 
 	var release struct {
@@ -155,8 +283,10 @@ func (d *DenoDownloader) getLatestVersion(ctx context.Context) (string, error) {
 	return release.TagName, nil
 }
 
-// downloadAndInstall downloads and installs a specific version of Deno.
-func (d *DenoDownloader) downloadAndInstall(ctx context.Context, version string, cacheDir string) error {
+// downloadAndInstall downloads and installs the named files from a specific version of Deno.
+func (d *DenoDownloader) downloadAndInstall(ctx context.Context, version string, cacheDir string, fileNames []string) error {
+	defer d.metrics.recordDownload(time.Now())
+
 	// Get platform-specific asset name
 	assetName, err := d.getPlatformAsset()
 	if err != nil {
@@ -175,28 +305,26 @@ func (d *DenoDownloader) downloadAndInstall(ctx context.Context, version string,
 		return err
 	}
 
-	// Find the asset and extract checksum from API
-	var assetURL, expectedChecksum string
-	for _, asset := range releaseInfo.Assets {
-		if asset.Name == assetName {
-			assetURL = asset.BrowserDownloadURL
-			// Extract SHA256 hash from digest (format: "sha256:hash")
-			if after, ok := strings.CutPrefix(asset.Digest, "sha256:"); ok {
-				expectedChecksum = after
-			}
+	// Find the asset to download
+	var asset *githubAsset
+	for i := range releaseInfo.Assets {
+		if releaseInfo.Assets[i].Name == assetName {
+			asset = &releaseInfo.Assets[i]
 			break
 		}
 	}
-
-	if assetURL == "" {
+	if asset == nil || asset.BrowserDownloadURL == "" {
 		return fmt.Errorf("asset %s not found in release %s", assetName, version)
 	}
-	if expectedChecksum == "" {
-		return fmt.Errorf("checksum not provided by GitHub API for asset %s in release %s", assetName, version)
+	assetURL := asset.BrowserDownloadURL
+
+	expectedChecksum, checksumSource, err := d.expectedChecksum(ctx, releaseInfo, *asset)
+	if err != nil {
+		return err
 	}
 
 	tflog.Info(ctx, fmt.Sprintf("Downloading asset: %s", assetURL))
-	tflog.Info(ctx, fmt.Sprintf("Expected checksum from GitHub API: %s", expectedChecksum))
+	tflog.Info(ctx, fmt.Sprintf("Expected checksum from %s: %s", checksumSource, expectedChecksum))
 
 	// Download the binary archive
 	archivePath := filepath.Join(versionDir, assetName)
@@ -213,8 +341,7 @@ func (d *DenoDownloader) downloadAndInstall(ctx context.Context, version string,
 	tflog.Info(ctx, "Checksum verified successfully")
 
 	// Extract the archive
-	binaryPath := filepath.Join(versionDir, denoBinaryName())
-	if err := d.extractArchive(archivePath, binaryPath); err != nil {
+	if err := d.extractArchive(archivePath, versionDir, fileNames); err != nil {
 		os.Remove(archivePath)
 		return fmt.Errorf("failed to extract archive: %w", err)
 	}
@@ -222,14 +349,16 @@ func (d *DenoDownloader) downloadAndInstall(ctx context.Context, version string,
 	// Remove the archive after extraction
 	os.Remove(archivePath)
 
-	// Make the binary executable on Unix systems
+	// Make the binaries executable on Unix systems
 	if runtime.GOOS != "windows" {
-		if err := os.Chmod(binaryPath, 0755); err != nil {
-			return fmt.Errorf("failed to make binary executable: %w", err)
+		for _, name := range fileNames {
+			if err := os.Chmod(filepath.Join(versionDir, name), 0755); err != nil {
+				return fmt.Errorf("failed to make %s executable: %w", name, err)
+			}
 		}
 	}
 
-	tflog.Info(ctx, fmt.Sprintf("Successfully installed Deno %s to %s", version, binaryPath))
+	tflog.Info(ctx, fmt.Sprintf("Successfully installed Deno %s to %s", version, versionDir))
 
 	return nil
 }
@@ -256,9 +385,21 @@ func (d *DenoDownloader) getPlatformAsset() (string, error) {
 	return fmt.Sprintf("deno-%s%s", platform, ".zip"), nil
 }
 
-// getReleaseInfo fetches release information from GitHub.
+// getReleaseInfo fetches release information from GitHub, retrying transient failures.
 func (d *DenoDownloader) getReleaseInfo(ctx context.Context, version string) (*githubRelease, error) {
-	url := fmt.Sprintf("%s/repos/%s/releases/tags/%s", githubAPIBase, denoRepo, version)
+	release, err := withRetries(ctx, d, fmt.Sprintf("fetch Deno release %s", version), func() (*githubRelease, error) {
+		return d.fetchReleaseInfo(ctx, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.mirrorAssetURLs(release)
+	return release, nil
+}
+
+// fetchReleaseInfo makes a single attempt at fetching release information from GitHub.
+func (d *DenoDownloader) fetchReleaseInfo(ctx context.Context, version string) (*githubRelease, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/tags/%s", d.githubAPI(), denoRepo, version)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -266,9 +407,7 @@ func (d *DenoDownloader) getReleaseInfo(ctx context.Context, version string) (*g
 	}
 
 	// Add GitHub token if available
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	}
+	d.authorize(req)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -279,7 +418,7 @@ func (d *DenoDownloader) getReleaseInfo(ctx context.Context, version string) (*g
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, &httpStatusError{resp.StatusCode, fmt.Sprintf("GitHub API returned status %d: %s", resp.StatusCode, string(body))}
 	}
 
 	var release githubRelease
@@ -290,8 +429,16 @@ func (d *DenoDownloader) getReleaseInfo(ctx context.Context, version string) (*g
 	return &release, nil
 }
 
-// downloadFile downloads a file from a URL.
+// downloadFile downloads a file from a URL, retrying transient failures.
 func (d *DenoDownloader) downloadFile(ctx context.Context, url, destPath string) error {
+	_, err := withRetries(ctx, d, fmt.Sprintf("download %s", url), func() (struct{}, error) {
+		return struct{}{}, d.downloadFileOnce(ctx, url, destPath)
+	})
+	return err
+}
+
+// downloadFileOnce makes a single attempt at downloading a file from a URL, replacing any partial download.
+func (d *DenoDownloader) downloadFileOnce(ctx context.Context, url, destPath string) error {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -305,7 +452,7 @@ func (d *DenoDownloader) downloadFile(ctx context.Context, url, destPath string)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status %d", resp.StatusCode)
+		return &httpStatusError{resp.StatusCode, fmt.Sprintf("download failed with status %d", resp.StatusCode)}
 	}
 
 	out, err := os.Create(destPath)
@@ -314,7 +461,11 @@ func (d *DenoDownloader) downloadFile(ctx context.Context, url, destPath string)
 	}
 	defer out.Close()
 
-	if _, err := io.Copy(out, resp.Body); err != nil {
+	// Report progress as the archive is tens of MB, which takes a while on a slow link
+	body := newProgressReader(resp.Body, filepath.Base(destPath), resp.ContentLength, func(msg string) {
+		tflog.Info(ctx, msg)
+	})
+	if _, err := io.Copy(out, body); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -342,52 +493,90 @@ func (d *DenoDownloader) verifyChecksum(filePath, expectedChecksum string) error
 	return nil
 }
 
-// extractArchive extracts the Deno binary from a zip or tar.gz archive.
-func (d *DenoDownloader) extractArchive(archivePath, destPath string) error {
+// extractArchive extracts the named files from a zip or tar.gz archive into destDir.
+// Every file must be present in the archive, otherwise an error naming the missing files is returned.
+func (d *DenoDownloader) extractArchive(archivePath, destDir string, fileNames []string) error {
 	if strings.HasSuffix(archivePath, ".zip") {
-		return d.extractZip(archivePath, destPath)
+		return d.extractZip(archivePath, destDir, fileNames)
 	} else if strings.HasSuffix(archivePath, ".tar.gz") {
-		return d.extractTarGz(archivePath, destPath)
+		return d.extractTarGz(archivePath, destDir, fileNames)
 	}
 	return fmt.Errorf("unsupported archive format: %s", archivePath)
 }
 
-// extractZip extracts the deno binary from a zip file.
-func (d *DenoDownloader) extractZip(zipPath, destPath string) error {
+// archiveTarget returns which of the wanted file names an archive entry should be extracted as.
+// An entry without the .exe suffix also satisfies a wanted name with it, as some archives omit it.
+func archiveTarget(entryName string, fileNames []string) (string, bool) {
+	for _, name := range fileNames {
+		if entryName == name || entryName == strings.TrimSuffix(name, ".exe") {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// missingArchiveFiles returns an error naming any of the wanted files that were not extracted.
+func missingArchiveFiles(fileNames []string, extracted map[string]bool, archiveKind string) error {
+	var missing []string
+	for _, name := range fileNames {
+		if !extracted[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s not found in %s archive", strings.Join(missing, ", "), archiveKind)
+	}
+	return nil
+}
+
+// extractFile writes the contents of r to destPath.
+func extractFile(r io.Reader, destPath string) error {
+	out, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return fmt.Errorf("failed to extract file: %w", err)
+	}
+
+	return nil
+}
+
+// extractZip extracts the named files from a zip file.
+func (d *DenoDownloader) extractZip(zipPath, destDir string, fileNames []string) error {
 	r, err := zip.OpenReader(zipPath)
 	if err != nil {
 		return fmt.Errorf("failed to open zip: %w", err)
 	}
 	defer r.Close()
 
-	// Find the deno binary in the zip
+	// Find the wanted files in the zip
+	extracted := make(map[string]bool, len(fileNames))
 	for _, f := range r.File {
-		if f.Name == denoBinaryName() || f.Name == "deno" {
-			rc, err := f.Open()
-			if err != nil {
-				return fmt.Errorf("failed to open file in zip: %w", err)
-			}
-			defer rc.Close()
-
-			out, err := os.Create(destPath)
-			if err != nil {
-				return fmt.Errorf("failed to create destination file: %w", err)
-			}
-			defer out.Close()
-
-			if _, err := io.Copy(out, rc); err != nil {
-				return fmt.Errorf("failed to extract file: %w", err)
-			}
+		name, ok := archiveTarget(f.Name, fileNames)
+		if !ok || extracted[name] {
+			continue
+		}
 
-			return nil
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open file in zip: %w", err)
 		}
+		err = extractFile(rc, filepath.Join(destDir, name))
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		extracted[name] = true
 	}
 
-	return fmt.Errorf("deno binary not found in zip archive")
+	return missingArchiveFiles(fileNames, extracted, "zip")
 }
 
-// extractTarGz extracts the deno binary from a tar.gz file.
-func (d *DenoDownloader) extractTarGz(tarGzPath, destPath string) error {
+// extractTarGz extracts the named files from a tar.gz file.
+func (d *DenoDownloader) extractTarGz(tarGzPath, destDir string, fileNames []string) error {
 	f, err := os.Open(tarGzPath)
 	if err != nil {
 		return fmt.Errorf("failed to open tar.gz: %w", err)
@@ -402,7 +591,8 @@ func (d *DenoDownloader) extractTarGz(tarGzPath, destPath string) error {
 
 	tr := tar.NewReader(gzr)
 
-	// Find the deno binary in the tar
+	// Find the wanted files in the tar
+	extracted := make(map[string]bool, len(fileNames))
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
@@ -412,26 +602,25 @@ func (d *DenoDownloader) extractTarGz(tarGzPath, destPath string) error {
 			return fmt.Errorf("failed to read tar: %w", err)
 		}
 
-		if header.Name == denoBinaryName() || header.Name == "deno" {
-			out, err := os.Create(destPath)
-			if err != nil {
-				return fmt.Errorf("failed to create destination file: %w", err)
-			}
-			defer out.Close()
-
-			if _, err := io.Copy(out, tr); err != nil {
-				return fmt.Errorf("failed to extract file: %w", err)
-			}
+		name, ok := archiveTarget(header.Name, fileNames)
+		if !ok || extracted[name] {
+			continue
+		}
 
-			return nil
+		if err := extractFile(tr, filepath.Join(destDir, name)); err != nil {
+			return err
 		}
+		extracted[name] = true
 	}
 
-	return fmt.Errorf("deno binary not found in tar.gz archive")
+	return missingArchiveFiles(fileNames, extracted, "tar.gz")
 }
 
 // cleanupOldVersions removes old Deno versions, keeping only the newest 3.
 func (d *DenoDownloader) cleanupOldVersions(ctx context.Context, cacheDir string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	entries, err := os.ReadDir(cacheDir)
 	if err != nil {
 		return fmt.Errorf("failed to read cache directory: %w", err)
@@ -474,6 +663,15 @@ func (d *DenoDownloader) cleanupOldVersions(ctx context.Context, cacheDir string
 
 	// Remove versions beyond the first 3
 	for i := maxVersionsToKeep; i < len(versions); i++ {
+		// Never remove a version that another goroutine is currently resolving
+		if lock, ok := d.versionLocks[filepath.Base(versions[i].path)]; ok {
+			if !lock.TryLock() {
+				tflog.Debug(ctx, fmt.Sprintf("Skipping removal of in use Deno version: %s", versions[i].version.String()))
+				continue
+			}
+			defer lock.Unlock()
+		}
+
 		tflog.Info(ctx, fmt.Sprintf("Removing old Deno version: %s", versions[i].version.String()))
 		if err := os.RemoveAll(versions[i].path); err != nil {
 			tflog.Warn(ctx, fmt.Sprintf("Failed to remove %s: %s", versions[i].path, err.Error()))
@@ -0,0 +1,82 @@
+package deno
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// checksumSuffix is appended to the name of a release asset to get the name of its checksum sidecar file.
+const checksumSuffix = ".sha256sum"
+
+// sha256Pattern matches a hex encoded SHA256 hash on its own, rather than as part of a longer hex string.
+var sha256Pattern = regexp.MustCompile(`(?i)(?:^|[^0-9a-f])([0-9a-f]{64})(?:[^0-9a-f]|$)`)
+
+// expectedChecksum returns the SHA256 checksum of a release asset.
+//
+// The digest provided by the GitHub API is preferred, but older releases and mirrors do not populate it, so the
+// <asset>.sha256sum sidecar file published alongside the archive is downloaded instead. The sidecar is found in the
+// release's assets, falling back to the archive's URL with the suffix appended.
+func (d *DenoDownloader) expectedChecksum(ctx context.Context, release *githubRelease, asset githubAsset) (checksum, source string, err error) {
+	// Extract SHA256 hash from digest (format: "sha256:hash")
+	if after, ok := strings.CutPrefix(asset.Digest, "sha256:"); ok && after != "" {
+		return strings.ToLower(after), "GitHub API", nil
+	}
+
+	sidecarURL := asset.BrowserDownloadURL + checksumSuffix
+	for _, a := range release.Assets {
+		if a.Name == asset.Name+checksumSuffix {
+			sidecarURL = a.BrowserDownloadURL
+			break
+		}
+	}
+
+	checksum, err = withRetries(ctx, d, fmt.Sprintf("download %s", sidecarURL), func() (string, error) {
+		return d.fetchChecksumFile(ctx, sidecarURL)
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("checksum not provided by GitHub API for asset %s in release %s, and the %s file could not be used: %w", asset.Name, release.TagName, checksumSuffix, err)
+	}
+	return checksum, sidecarURL, nil
+}
+
+// fetchChecksumFile makes a single attempt at downloading a checksum sidecar file and parsing the hash it holds.
+func (d *DenoDownloader) fetchChecksumFile(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &httpStatusError{resp.StatusCode, fmt.Sprintf("download failed with status %d", resp.StatusCode)}
+	}
+
+	// The file holds a single line, so anything much bigger is not a checksum file
+	content, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read checksum file: %w", err)
+	}
+	return parseChecksumFile(string(content))
+}
+
+// parseChecksumFile returns the SHA256 hash found in the contents of a checksum sidecar file, in lower case.
+//
+// Releases built on Linux and macOS publish the output of sha256sum, eg: "<hash>  deno-x86_64-apple-darwin.zip",
+// while those built on Windows publish a table written by PowerShell's Get-FileHash holding the hash in upper case.
+func parseChecksumFile(content string) (string, error) {
+	match := sha256Pattern.FindStringSubmatch(content)
+	if match == nil {
+		return "", fmt.Errorf("no SHA256 hash found in checksum file")
+	}
+	return strings.ToLower(match[1]), nil
+}
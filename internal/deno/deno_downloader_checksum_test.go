@@ -0,0 +1,91 @@
+package deno
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+const testChecksum = "9f2b8c1d0e3a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9011223344"
+
+func TestParseChecksumFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "sha256sum", content: testChecksum + "  deno-x86_64-unknown-linux-gnu.zip\n"},
+		{name: "bare hash", content: testChecksum},
+		{
+			name: "Get-FileHash",
+			content: "\r\nAlgorithm       Hash                                                                   Path\r\n" +
+				"---------       ----                                                                   ----\r\n" +
+				"SHA256          " + "9F2B8C1D0E3A4B5C6D7E8F90A1B2C3D4E5F60718293A4B5C6D7E8F9011223344" + "       D:\\a\\deno\\deno\\target\\release\\deno-x86_64-pc-windows-msvc.zip\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checksum, err := parseChecksumFile(tt.content)
+			assert.NoError(t, err)
+			assert.Equal(t, testChecksum, checksum)
+		})
+	}
+
+	_, err := parseChecksumFile("sha256: " + testChecksum + "ff")
+	assert.EqualError(t, err, "no SHA256 hash found in checksum file")
+}
+
+func TestExpectedChecksum_PrefersAPIDigest(t *testing.T) {
+	server := newFakeReleaseServer(t, fakeReleaseOptions{checksum: true})
+	asset := githubAsset{Name: "deno.zip", BrowserDownloadURL: server.URL + "/deno.zip", Digest: "sha256:abc123"}
+	release := &githubRelease{TagName: "v2.1.4", Assets: []githubAsset{asset}}
+
+	checksum, source, err := NewDenoDownloader().expectedChecksum(context.Background(), release, asset)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", checksum)
+	assert.Equal(t, "GitHub API", source)
+	assert.Equal(t, int64(0), server.requests.Load())
+}
+
+func TestExpectedChecksum_FallsBackToSidecarFile(t *testing.T) {
+	server := newFakeReleaseServer(t, fakeReleaseOptions{checksum: true})
+	tests := []struct {
+		name   string
+		assets []githubAsset
+	}{
+		{
+			name:   "listed asset",
+			assets: []githubAsset{{Name: "deno.zip.sha256sum", BrowserDownloadURL: server.URL + "/deno.zip.sha256sum"}},
+		},
+		{
+			// Mirrors may only list the archives themselves
+			name: "unlisted asset",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset := githubAsset{Name: "deno.zip", BrowserDownloadURL: server.URL + "/deno.zip"}
+			release := &githubRelease{TagName: "v1.0.0", Assets: append([]githubAsset{asset}, tt.assets...)}
+
+			checksum, source, err := NewDenoDownloader().expectedChecksum(context.Background(), release, asset)
+			assert.NoError(t, err)
+			assert.Equal(t, testChecksum, checksum)
+			assert.Equal(t, server.URL+"/deno.zip.sha256sum", source)
+		})
+	}
+	assert.Equal(t, int64(2), server.requests.Load())
+}
+
+func TestExpectedChecksum_SidecarFileMissing(t *testing.T) {
+	server := newFakeReleaseServer(t, fakeReleaseOptions{failures: 10, failStatus: http.StatusNotFound})
+	downloader := NewDenoDownloader()
+	downloader.retryDelay = time.Millisecond
+	asset := githubAsset{Name: "deno.zip", BrowserDownloadURL: server.URL + "/deno.zip"}
+	release := &githubRelease{TagName: "v1.0.0", Assets: []githubAsset{asset}}
+
+	_, _, err := downloader.expectedChecksum(context.Background(), release, asset)
+	assert.EqualError(t, err, "checksum not provided by GitHub API for asset deno.zip in release v1.0.0, and the .sha256sum file could not be used: download failed with status 404")
+	assert.Equal(t, int64(1), server.requests.Load())
+}
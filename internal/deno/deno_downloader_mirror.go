@@ -0,0 +1,83 @@
+package deno
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	// githubDownloadBase is where the assets of GitHub releases are downloaded from.
+	githubDownloadBase = "https://github.com"
+
+	// downloadBaseURLEnvVar sets the mirror Deno is downloaded from, when no download base URL has been set.
+	downloadBaseURLEnvVar = "DENOBRIDGE_DOWNLOAD_BASE_URL"
+)
+
+// SetDownloadBaseURL sets the base URL of an internal mirror that Deno is downloaded from instead of GitHub, for
+// environments that can not reach github.com. The mirror must follow the same path layout as GitHub, serving both
+// the release info of the GitHub API, eg: <base>/repos/denoland/deno/releases/tags/v2.1.4, and the release assets,
+// eg: <base>/denoland/deno/releases/download/v2.1.4/deno-x86_64-unknown-linux-gnu.zip.
+//
+// An empty base URL restores the default, which is the DENOBRIDGE_DOWNLOAD_BASE_URL environment variable when set,
+// otherwise GitHub itself.
+func (d *DenoDownloader) SetDownloadBaseURL(baseURL string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if baseURL != d.downloadBaseURL {
+		// A mirror may not hold the same releases, so what was resolved from elsewhere no longer applies
+		clear(d.resolvedVersions)
+	}
+	d.downloadBaseURL = baseURL
+}
+
+// getDownloadBaseURL returns the base URL of the mirror Deno is downloaded from, empty when downloading from GitHub.
+func (d *DenoDownloader) getDownloadBaseURL() string {
+	d.mu.Lock()
+	baseURL := d.downloadBaseURL
+	d.mu.Unlock()
+	if baseURL == "" {
+		baseURL = strings.TrimSuffix(os.Getenv(downloadBaseURLEnvVar), "/")
+	}
+	return baseURL
+}
+
+// ValidateDownloadBaseURL checks that a download base URL is an absolute http or https URL.
+func ValidateDownloadBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("expected an http or https URL such as https://mirror.example.com/github, got %q: %w", baseURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("expected an http or https URL such as https://mirror.example.com/github, got %q", baseURL)
+	}
+	return nil
+}
+
+// mirrorAssetURLs rewrites the download URLs of a release's assets to the mirror, when one is set.
+// Assets the mirror already serves from elsewhere are left as they are.
+func (d *DenoDownloader) mirrorAssetURLs(release *githubRelease) {
+	baseURL := d.getDownloadBaseURL()
+	if baseURL == "" {
+		return
+	}
+	for i, asset := range release.Assets {
+		if rest, ok := strings.CutPrefix(asset.BrowserDownloadURL, githubDownloadBase+"/"); ok {
+			release.Assets[i].BrowserDownloadURL = baseURL + "/" + rest
+		}
+	}
+}
+
+// authorize adds the GITHUB_TOKEN, when set, to a request made to the GitHub API to raise its rate limit.
+// The token is never sent to a mirror.
+func (d *DenoDownloader) authorize(req *http.Request) {
+	if d.apiBase == "" && d.getDownloadBaseURL() != "" {
+		return
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+}
@@ -0,0 +1,54 @@
+package deno
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestDownloadBaseURL_RewritesReleaseURLs(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	t.Setenv(downloadBaseURLEnvVar, "")
+	server := newFakeReleaseServer(t, fakeReleaseOptions{mirrorRelease: "v2.1.4"})
+	downloader := NewDenoDownloader()
+	downloader.SetDownloadBaseURL(server.URL + "/")
+
+	release, err := downloader.getReleaseInfo(context.Background(), "v2.1.4")
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/denoland/deno/releases/download/v2.1.4/deno.zip", release.Assets[0].BrowserDownloadURL)
+	assert.Equal(t, "https://cdn.example.com/other.zip", release.Assets[1].BrowserDownloadURL)
+}
+
+func TestDownloadBaseURL_EnvVar(t *testing.T) {
+	server := newFakeReleaseServer(t, fakeReleaseOptions{mirrorRelease: "v2.1.4"})
+	t.Setenv(downloadBaseURLEnvVar, server.URL)
+	downloader := NewDenoDownloader()
+	assert.Equal(t, server.URL, downloader.githubAPI())
+
+	release, err := downloader.getReleaseInfo(context.Background(), "v2.1.4")
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/denoland/deno/releases/download/v2.1.4/deno.zip", release.Assets[0].BrowserDownloadURL)
+
+	// The provider attribute takes precedence over the environment variable
+	downloader.SetDownloadBaseURL("https://mirror.example.com")
+	assert.Equal(t, "https://mirror.example.com", downloader.githubAPI())
+}
+
+func TestDownloadBaseURL_Default(t *testing.T) {
+	t.Setenv(downloadBaseURLEnvVar, "")
+	downloader := NewDenoDownloader()
+	assert.Equal(t, githubAPIBase, downloader.githubAPI())
+
+	release := &githubRelease{Assets: []githubAsset{{BrowserDownloadURL: "https://github.com/denoland/deno/releases/download/v2.1.4/deno.zip"}}}
+	downloader.mirrorAssetURLs(release)
+	assert.Equal(t, "https://github.com/denoland/deno/releases/download/v2.1.4/deno.zip", release.Assets[0].BrowserDownloadURL)
+}
+
+func TestValidateDownloadBaseURL(t *testing.T) {
+	assert.NoError(t, ValidateDownloadBaseURL("https://mirror.example.com/github"))
+	assert.NoError(t, ValidateDownloadBaseURL("http://10.0.0.1:8080"))
+	for _, baseURL := range []string{"mirror.example.com", "ftp://mirror.example.com", "https://", "/github"} {
+		assert.Error(t, ValidateDownloadBaseURL(baseURL), baseURL)
+	}
+}
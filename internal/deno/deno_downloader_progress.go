@@ -0,0 +1,72 @@
+package deno
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	// progressStepPercent is how far a download of known size progresses between each progress message.
+	progressStepPercent = 10
+
+	// progressStepBytes is how many bytes of a download of unknown size are read between each progress message.
+	progressStepBytes = 10 << 20
+)
+
+// progressReader reports how much of a download has been read as it is copied, so that a slow download
+// does not look like a hung provider.
+//
+// When the size of the download is known a message is logged every 10%, otherwise every 10MiB.
+type progressReader struct {
+	r     io.Reader
+	name  string
+	total int64
+	read  int64
+	// next is the number of bytes read at which the next message is logged
+	next int64
+	logf func(msg string)
+}
+
+// newProgressReader wraps r, which reads total bytes of the named download. total is -1 when the size is unknown.
+func newProgressReader(r io.Reader, name string, total int64, logf func(msg string)) *progressReader {
+	p := &progressReader{r: r, name: name, total: total, logf: logf}
+	p.next = p.step()
+	return p
+}
+
+// step returns how many bytes are read between each message.
+func (p *progressReader) step() int64 {
+	if p.total > 0 {
+		return max(p.total*progressStepPercent/100, 1)
+	}
+	return progressStepBytes
+}
+
+// Read implements io.Reader, logging progress as each step is passed.
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if p.read >= p.next {
+		if p.total > 0 {
+			p.logf(fmt.Sprintf("Downloading %s: %d%% (%s of %s)", p.name, min(p.read*100/p.total, 100), formatBytes(p.read), formatBytes(p.total)))
+		} else {
+			p.logf(fmt.Sprintf("Downloading %s: %s", p.name, formatBytes(p.read)))
+		}
+		for p.next <= p.read {
+			p.next += p.step()
+		}
+	}
+	return n, err
+}
+
+// formatBytes formats a number of bytes for a progress message, eg: "12.5MiB".
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
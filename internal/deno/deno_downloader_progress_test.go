@@ -0,0 +1,39 @@
+package deno
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestProgressReader_KnownSize(t *testing.T) {
+	var messages []string
+	content := bytes.Repeat([]byte("x"), 1000)
+	r := newProgressReader(iotest.OneByteReader(bytes.NewReader(content)), "deno.zip", int64(len(content)), func(msg string) {
+		messages = append(messages, msg)
+	})
+
+	copied, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, content, copied)
+	assert.Equal(t, 10, len(messages))
+	assert.Equal(t, "Downloading deno.zip: 10% (100B of 1000B)", messages[0])
+	assert.Equal(t, "Downloading deno.zip: 100% (1000B of 1000B)", messages[9])
+}
+
+func TestProgressReader_UnknownSize(t *testing.T) {
+	var messages []string
+	content := bytes.Repeat([]byte("x"), progressStepBytes*2+1)
+	r := newProgressReader(bytes.NewReader(content), "deno.zip", -1, func(msg string) {
+		messages = append(messages, msg)
+	})
+
+	_, err := io.Copy(io.Discard, r)
+	assert.NoError(t, err)
+	assert.True(t, len(messages) >= 1, "expected progress to be reported without a Content-Length")
+	assert.Contains(t, messages[len(messages)-1], "MiB")
+	assert.NotContains(t, messages[0], "%")
+}
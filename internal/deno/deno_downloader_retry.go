@@ -0,0 +1,85 @@
+package deno
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// downloadAttempts is how many times a request to GitHub is made before giving up on a transient failure.
+	downloadAttempts = 3
+
+	// defaultRetryDelay is how long to wait before the first retry, it doubles with each attempt.
+	defaultRetryDelay = time.Second
+)
+
+// httpStatusError reports a response from GitHub with an unexpected status code.
+type httpStatusError struct {
+	StatusCode int
+	message    string
+}
+
+// Error implements the error interface.
+func (e *httpStatusError) Error() string {
+	return e.message
+}
+
+// withRetries calls fn up to downloadAttempts times, waiting with exponential backoff and jitter between attempts.
+// Only transient failures are retried, see isRetryable. Once every attempt has failed, the last error is returned
+// with the number of attempts made.
+func withRetries[T any](ctx context.Context, d *DenoDownloader, what string, fn func() (T, error)) (T, error) {
+	delay := d.retryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil || !isRetryable(ctx, err) {
+			return result, err
+		}
+		if attempt == downloadAttempts {
+			return result, fmt.Errorf("%w (gave up after %d attempts)", err, attempt)
+		}
+
+		// Full jitter on top of the backoff keeps concurrent providers from retrying in lock step
+		wait := delay + rand.N(delay)
+		tflog.Warn(ctx, fmt.Sprintf("Failed to %s, retrying in %s (attempt %d of %d): %s", what, wait.Round(time.Millisecond), attempt, downloadAttempts, err.Error()))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return result, err
+		}
+		delay *= 2
+	}
+}
+
+// isRetryable reports whether err is a transient failure worth retrying: a 5xx response, a connection that was
+// reset or closed early, or a timeout. Client errors such as a 404, and cancellation of ctx, are not retried.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
@@ -0,0 +1,70 @@
+package deno
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestDownloadFile_RetriesServerErrors(t *testing.T) {
+	server := newFakeReleaseServer(t, fakeReleaseOptions{failures: 2, failStatus: http.StatusBadGateway, body: "deno"})
+	downloader := NewDenoDownloader()
+	downloader.retryDelay = time.Millisecond
+
+	destPath := filepath.Join(t.TempDir(), "deno.zip")
+	assert.NoError(t, downloader.downloadFile(context.Background(), server.URL, destPath))
+	assert.Equal(t, int64(3), server.requests.Load())
+	content, err := os.ReadFile(destPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "deno", string(content))
+}
+
+func TestDownloadFile_GivesUpAfterAttempts(t *testing.T) {
+	server := newFakeReleaseServer(t, fakeReleaseOptions{failures: 10, failStatus: http.StatusServiceUnavailable})
+	downloader := NewDenoDownloader()
+	downloader.retryDelay = time.Millisecond
+
+	err := downloader.downloadFile(context.Background(), server.URL, filepath.Join(t.TempDir(), "deno.zip"))
+	assert.EqualError(t, err, "download failed with status 503 (gave up after 3 attempts)")
+	assert.Equal(t, int64(downloadAttempts), server.requests.Load())
+}
+
+func TestGetReleaseInfo_DoesNotRetryNotFound(t *testing.T) {
+	server := newFakeReleaseServer(t, fakeReleaseOptions{failures: 10, failStatus: http.StatusNotFound})
+	downloader := NewDenoDownloader()
+	downloader.apiBase = server.URL
+	downloader.retryDelay = time.Millisecond
+
+	_, err := downloader.getReleaseInfo(context.Background(), "v9.9.9")
+	assert.EqualError(t, err, "GitHub API returned status 404: ")
+	assert.Equal(t, int64(1), server.requests.Load())
+}
+
+func TestGetLatestVersion_RetriesServerErrors(t *testing.T) {
+	server := newFakeReleaseServer(t, fakeReleaseOptions{failures: 1, failStatus: http.StatusInternalServerError, body: `{"tag_name": "v2.1.4"}`})
+	downloader := NewDenoDownloader()
+	downloader.apiBase = server.URL
+	downloader.retryDelay = time.Millisecond
+
+	version, err := downloader.getLatestVersion(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.1.4", version)
+	assert.Equal(t, int64(2), server.requests.Load())
+}
+
+func TestWithRetries_StopsWhenCancelled(t *testing.T) {
+	server := newFakeReleaseServer(t, fakeReleaseOptions{failures: 10, failStatus: http.StatusBadGateway})
+	downloader := NewDenoDownloader()
+	downloader.retryDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := downloader.downloadFile(ctx, server.URL, filepath.Join(t.TempDir(), "deno.zip"))
+	assert.EqualError(t, err, "download failed with status 502")
+	assert.Equal(t, int64(1), server.requests.Load())
+}
@@ -1,14 +1,117 @@
 package deno
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/alecthomas/assert/v2"
 	"github.com/bitfield/script"
 )
 
+// fakeReleaseOptions configures what a fake release server serves, the zero value answers every request with 404.
+type fakeReleaseOptions struct {
+	// failures is how many requests are answered with failStatus before any are served.
+	failures   int64
+	failStatus int
+
+	// delay is how long every request takes to be answered.
+	delay time.Duration
+
+	// token is the GITHUB_TOKEN every request must be authorized with, when set.
+	token string
+
+	// pages is how many pages of releases the releases list has, each with a release and a prerelease.
+	pages int
+
+	// checksum serves the checksum sidecar of deno.zip at /deno.zip.sha256sum.
+	checksum bool
+
+	// mirrorRelease serves the release info of this tag as GitHub would, with asset URLs pointing at github.com,
+	// failing the test when a GITHUB_TOKEN is sent as a mirror must never be given it.
+	mirrorRelease string
+
+	// body is served for any other request, when set.
+	body string
+}
+
+// fakeReleaseServer stands in for the GitHub releases API and the downloads of release assets.
+type fakeReleaseServer struct {
+	*httptest.Server
+
+	// requests counts every request made to the server, including those that failed.
+	requests atomic.Int64
+
+	// maxInFlight is the most requests the server was answering at once.
+	maxInFlight atomic.Int64
+	inFlight    atomic.Int64
+}
+
+// newFakeReleaseServer starts a fake release server that serves what options describe, closed once the test is done.
+func newFakeReleaseServer(t *testing.T, options fakeReleaseOptions) *fakeReleaseServer {
+	s := &fakeReleaseServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+		for {
+			seen := s.maxInFlight.Load()
+			if current <= seen || s.maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(options.delay)
+
+		if s.requests.Add(1) <= options.failures {
+			w.WriteHeader(options.failStatus)
+			return
+		}
+		if options.token != "" && r.Header.Get("Authorization") != "Bearer "+options.token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case options.pages > 0 && r.URL.Path == "/repos/denoland/deno/releases":
+			pageNumber, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/repos/denoland/deno/releases?per_page=100&page=%d>; rel="last"`, r.Host, options.pages))
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"tag_name": fmt.Sprintf("v2.%d.0", pageNumber)},
+				{"tag_name": fmt.Sprintf("v2.%d.0-rc.1", pageNumber), "prerelease": true},
+			})
+		case options.checksum && r.URL.Path == "/deno.zip.sha256sum":
+			_, _ = w.Write([]byte(testChecksum + "  deno.zip\n"))
+		case options.mirrorRelease != "" && r.URL.Path == "/repos/denoland/deno/releases/tags/"+options.mirrorRelease:
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("Expected no GITHUB_TOKEN to be sent to the mirror, got %s", r.Header.Get("Authorization"))
+			}
+			_, _ = fmt.Fprintf(w, `{"tag_name": %[1]q, "assets": [
+				{"name": "deno.zip", "browser_download_url": "https://github.com/denoland/deno/releases/download/%[1]s/deno.zip"},
+				{"name": "other.zip", "browser_download_url": "https://cdn.example.com/other.zip"}
+			]}`, options.mirrorRelease)
+		case options.body != "":
+			_, _ = w.Write([]byte(options.body))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
 func TestGetDenoBinary(t *testing.T) {
 	downloader := NewDenoDownloader()
 
@@ -20,3 +123,199 @@ func TestGetDenoBinary(t *testing.T) {
 
 	assert.Contains(t, denoHelpText, "A modern JavaScript and TypeScript runtime")
 }
+
+func TestGetDenoBinary_ConcurrentCachedVersions(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	downloader := NewDenoDownloader()
+	cacheDir, err := downloader.getCacheDir()
+	assert.NoError(t, err)
+
+	// Seed the cache so no network access is required
+	versions := []string{"v2.0.0", "v2.1.0", "v2.2.0"}
+	for _, version := range versions {
+		assert.NoError(t, os.MkdirAll(filepath.Join(cacheDir, version), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(cacheDir, version, denoBinaryName()), []byte("fake"), 0755))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(versions)*10)
+	for i := 0; i < 10; i++ {
+		for _, version := range versions {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				binPath, err := downloader.GetDenoBinary(context.Background(), version)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if binPath != filepath.Join(cacheDir, version, denoBinaryName()) {
+					errs <- fmt.Errorf("unexpected binary path %s for %s", binPath, version)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, len(versions), len(downloader.versionLocks))
+}
+
+// writeTestZip creates a zip archive containing the given files.
+func writeTestZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "test.zip")
+	f, err := os.Create(archivePath)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return archivePath
+}
+
+// writeTestTarGz creates a tar.gz archive containing the given files.
+func writeTestTarGz(t *testing.T, files map[string]string) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "test.tar.gz")
+	f, err := os.Create(archivePath)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	gzw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gzw)
+	for name, content := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gzw.Close())
+	return archivePath
+}
+
+func TestExtractArchive_MultipleFiles(t *testing.T) {
+	files := map[string]string{"deno": "deno binary", "deployctl": "deployctl binary", "README.md": "ignored"}
+	for kind, archivePath := range map[string]string{
+		"zip":    writeTestZip(t, files),
+		"tar.gz": writeTestTarGz(t, files),
+	} {
+		t.Run(kind, func(t *testing.T) {
+			destDir := t.TempDir()
+			assert.NoError(t, NewDenoDownloader().extractArchive(archivePath, destDir, []string{"deno", "deployctl"}))
+
+			for _, name := range []string{"deno", "deployctl"} {
+				content, err := os.ReadFile(filepath.Join(destDir, name))
+				assert.NoError(t, err)
+				assert.Equal(t, files[name], string(content))
+			}
+
+			_, err := os.Stat(filepath.Join(destDir, "README.md"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestExtractArchive_FileNotFound(t *testing.T) {
+	files := map[string]string{"deno": "deno binary"}
+	for kind, archivePath := range map[string]string{
+		"zip":    writeTestZip(t, files),
+		"tar.gz": writeTestTarGz(t, files),
+	} {
+		t.Run(kind, func(t *testing.T) {
+			err := NewDenoDownloader().extractArchive(archivePath, t.TempDir(), []string{"deno", "deployctl", "denort"})
+			assert.EqualError(t, err, "deployctl, denort not found in "+kind+" archive")
+		})
+	}
+}
+
+func TestExtractArchive_ExeSuffixOptional(t *testing.T) {
+	archivePath := writeTestZip(t, map[string]string{"deno": "deno binary"})
+	destDir := t.TempDir()
+	assert.NoError(t, NewDenoDownloader().extractArchive(archivePath, destDir, []string{"deno.exe"}))
+
+	content, err := os.ReadFile(filepath.Join(destDir, "deno.exe"))
+	assert.NoError(t, err)
+	assert.Equal(t, "deno binary", string(content))
+}
+
+func TestGetDenoBinaryWithFallbacks(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	downloader := NewDenoDownloader()
+	assetName, err := downloader.getPlatformAsset()
+	if err != nil {
+		t.Skip(err.Error())
+	}
+
+	archive, err := os.ReadFile(writeTestZip(t, map[string]string{denoBinaryName(): "fallback deno"}))
+	assert.NoError(t, err)
+	digest := sha256.Sum256(archive)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/denoland/deno/releases/tags/v2.1.3":
+			_ = json.NewEncoder(w).Encode(githubRelease{
+				TagName: "v2.1.3",
+				Assets: []githubAsset{{
+					Name:               assetName,
+					BrowserDownloadURL: "http://" + r.Host + "/download/" + assetName,
+					Digest:             "sha256:" + hex.EncodeToString(digest[:]),
+				}},
+			})
+		case "/download/" + assetName:
+			_, _ = w.Write(archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	downloader.apiBase = server.URL
+
+	binPath, err := downloader.GetDenoBinaryWithFallbacks(context.Background(), "v9.9.9", []string{"v9.9.8", "v2.1.3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.1.3", filepath.Base(filepath.Dir(binPath)))
+	content, err := os.ReadFile(binPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "fallback deno", string(content))
+
+	_, err = downloader.GetDenoBinaryWithFallbacks(context.Background(), "v9.9.9", []string{"v9.9.8"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "deno v9.9.9: failed to download Deno: GitHub API returned status 404")
+	assert.Contains(t, err.Error(), "deno v9.9.8: failed to download Deno: GitHub API returned status 404")
+}
+
+func TestGetCacheDir(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+	t.Setenv(cacheDirEnvVar, "")
+
+	downloader := NewDenoDownloader()
+	cacheDir, err := downloader.getCacheDir()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(tempDir, "terraform-provider-denobridge"), cacheDir)
+
+	envDir := filepath.Join(t.TempDir(), "env")
+	t.Setenv(cacheDirEnvVar, envDir)
+	cacheDir, err = downloader.getCacheDir()
+	assert.NoError(t, err)
+	assert.Equal(t, envDir, cacheDir)
+
+	setDir := filepath.Join(t.TempDir(), "set")
+	downloader.SetCacheDir(setDir)
+	cacheDir, err = downloader.getCacheDir()
+	assert.NoError(t, err)
+	assert.Equal(t, setDir, cacheDir)
+	_, err = os.Stat(setDir)
+	assert.NoError(t, err)
+}
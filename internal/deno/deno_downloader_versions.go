@@ -0,0 +1,262 @@
+package deno
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// releasesPerPage is the largest page size the GitHub API allows when listing releases.
+const releasesPerPage = 100
+
+// lastPageRegex extracts the number of the last page from a GitHub API Link header.
+var lastPageRegex = regexp.MustCompile(`[?&]page=(\d+)[^>]*>;\s*rel="last"`)
+
+// versionConstraintChars are the characters found in a version constraint, but never in an exact version tag.
+const versionConstraintChars = "<>=~^*|, xX"
+
+// releasesPage is a single page of releases listed by the GitHub API.
+type releasesPage []struct {
+	TagName    string `json:"tag_name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// ListVersions returns the version of every stable Deno release, newest first, eg: "v2.1.4".
+//
+// The first page of releases reports how many pages there are, the rest are then fetched
+// concurrently, at most VersionListConcurrency at a time. GITHUB_TOKEN is used when set, to
+// raise the rate limit. Once the rate limit is hit no further pages are requested.
+func (d *DenoDownloader) ListVersions(ctx context.Context) ([]string, error) {
+	versions, err := d.listReleaseVersions(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, 0, len(versions))
+	for _, v := range versions {
+		tags = append(tags, v.Original())
+	}
+	return tags, nil
+}
+
+// IsVersionConstraint reports whether version is a semver constraint, eg: ">=2.1.0, <3.0.0" or "~2.1", rather
+// than "latest" or an exact version tag such as "v2.1.4".
+func IsVersionConstraint(version string) bool {
+	return strings.ContainsAny(version, versionConstraintChars)
+}
+
+// ValidateVersion returns an error when version is a constraint that can not be parsed.
+// Exact versions are not checked, as whether a tag exists is only known once it is downloaded.
+func ValidateVersion(version string) error {
+	if !IsVersionConstraint(version) {
+		return nil
+	}
+	if _, err := semver.NewConstraint(version); err != nil {
+		return fmt.Errorf("invalid Deno version constraint %q: %w", version, err)
+	}
+	return nil
+}
+
+// resolveVersion returns the release tag to download for version, resolving "latest" or a version constraint.
+//
+// The first resolution is pinned for the life of the downloader, which is shared by everything in a provider
+// instance, so that every operation in a session runs the same Deno even when a newer release is published
+// part way through it. Concrete versions are returned unchanged.
+func (d *DenoDownloader) resolveVersion(ctx context.Context, version string) (string, error) {
+	if version != "latest" && !IsVersionConstraint(version) {
+		return version, nil
+	}
+
+	// Concurrent resolutions of the same version wait for the first, rather than each asking GitHub
+	lock := d.versionLock("resolve " + version)
+	lock.Lock()
+	defer lock.Unlock()
+
+	d.mu.Lock()
+	pinned, ok := d.resolvedVersions[version]
+	d.mu.Unlock()
+	if ok {
+		tflog.Debug(ctx, fmt.Sprintf("Using Deno %s, resolved earlier in this session for %s", pinned, version))
+		return pinned, nil
+	}
+
+	var resolved string
+	var err error
+	if version == "latest" {
+		tflog.Info(ctx, "Resolving latest Deno version")
+		resolved, err = d.getLatestVersion(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve latest version: %w", err)
+		}
+		tflog.Info(ctx, fmt.Sprintf("Resolved latest version to %s", resolved))
+	} else {
+		tflog.Info(ctx, fmt.Sprintf("Resolving Deno version constraint %s", version))
+		resolved, err = d.resolveVersionConstraint(ctx, version)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve version constraint: %w", err)
+		}
+		tflog.Info(ctx, fmt.Sprintf("Resolved version constraint %s to %s", version, resolved))
+	}
+
+	d.mu.Lock()
+	d.resolvedVersions[version] = resolved
+	d.mu.Unlock()
+	return resolved, nil
+}
+
+// resolveVersionConstraint returns the tag of the newest Deno release that satisfies the constraint.
+// Pre-releases are only eligible when the constraint itself names one, eg: ">=2.1.0-rc.1".
+func (d *DenoDownloader) resolveVersionConstraint(ctx context.Context, constraint string) (string, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return "", fmt.Errorf("invalid Deno version constraint %q: %w", constraint, err)
+	}
+
+	versions, err := d.listReleaseVersions(ctx, true)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range versions {
+		if c.Check(v) {
+			return v.Original(), nil
+		}
+	}
+	return "", fmt.Errorf("no Deno release satisfies the version constraint %q", constraint)
+}
+
+// listReleaseVersions returns the version of every Deno release, newest first. Drafts are never included,
+// pre-releases only when includePrereleases is true.
+func (d *DenoDownloader) listReleaseVersions(ctx context.Context, includePrereleases bool) ([]*semver.Version, error) {
+	first, header, err := d.getReleasesPage(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	pages := []releasesPage{first}
+
+	lastPage := 1
+	if match := lastPageRegex.FindStringSubmatch(header.Get("Link")); match != nil {
+		lastPage, _ = strconv.Atoi(match[1])
+	}
+	if lastPage > 1 {
+		rest, err := d.getReleasesPages(ctx, lastPage)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, rest...)
+	}
+
+	var versions []*semver.Version
+	for _, page := range pages {
+		for _, release := range page {
+			if release.Draft || (release.Prerelease && !includePrereleases) {
+				continue
+			}
+			if v, err := semver.NewVersion(release.TagName); err == nil {
+				versions = append(versions, v)
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+	return versions, nil
+}
+
+// getReleasesPages fetches pages 2 to lastPage of releases with a bounded pool of workers.
+// The first error cancels the pages that have not been fetched yet.
+func (d *DenoDownloader) getReleasesPages(ctx context.Context, lastPage int) ([]releasesPage, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := d.VersionListConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultVersionListConcurrency
+	}
+
+	pageNumbers := make(chan int)
+	pages := make([]releasesPage, lastPage-1)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for range min(concurrency, lastPage-1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pageNumber := range pageNumbers {
+				page, _, err := d.getReleasesPage(ctx, pageNumber)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				pages[pageNumber-2] = page
+			}
+		}()
+	}
+
+feed:
+	for pageNumber := 2; pageNumber <= lastPage; pageNumber++ {
+		select {
+		case pageNumbers <- pageNumber:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(pageNumbers)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// getReleasesPage fetches a single page of releases, returning the response headers alongside it.
+func (d *DenoDownloader) getReleasesPage(ctx context.Context, pageNumber int) (releasesPage, http.Header, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases?per_page=%d&page=%d", d.githubAPI(), denoRepo, releasesPerPage, pageNumber)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Add GitHub token if available
+	d.authorize(req)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			return nil, nil, fmt.Errorf("GitHub API rate limit exceeded while listing releases, set GITHUB_TOKEN to raise it: %s", string(body))
+		}
+		return nil, nil, fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var page releasesPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return page, resp.Header, nil
+}
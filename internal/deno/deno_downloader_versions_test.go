@@ -0,0 +1,136 @@
+package deno
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestListVersions_ConcurrentPages(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	server := newFakeReleaseServer(t, fakeReleaseOptions{pages: 10, token: "test-token", delay: 20 * time.Millisecond})
+
+	downloader := NewDenoDownloader()
+	downloader.apiBase = server.URL
+	downloader.VersionListConcurrency = 3
+
+	versions, err := downloader.ListVersions(context.Background())
+	assert.NoError(t, err)
+
+	expected := []string{"v2.10.0", "v2.9.0", "v2.8.0", "v2.7.0", "v2.6.0", "v2.5.0", "v2.4.0", "v2.3.0", "v2.2.0", "v2.1.0"}
+	assert.Equal(t, expected, versions)
+	assert.True(t, server.maxInFlight.Load() <= 3, "expected at most 3 pages to be fetched at once, got %d", server.maxInFlight.Load())
+	assert.True(t, server.maxInFlight.Load() > 1, "expected pages to be fetched concurrently")
+}
+
+func TestListVersions_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "3" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s/repos/denoland/deno/releases?per_page=100&page=5>; rel="last"`, r.Host))
+		_ = json.NewEncoder(w).Encode([]map[string]any{{"tag_name": "v2.0.0"}})
+	}))
+	t.Cleanup(server.Close)
+
+	downloader := NewDenoDownloader()
+	downloader.apiBase = server.URL
+
+	_, err := downloader.ListVersions(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limit exceeded")
+}
+
+func TestResolveVersionConstraint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"tag_name": "v3.0.0"},
+			{"tag_name": "v2.2.0-rc.1", "prerelease": true},
+			{"tag_name": "v2.1.5"},
+			{"tag_name": "v2.1.6", "draft": true},
+			{"tag_name": "v2.1.4"},
+			{"tag_name": "v2.0.0"},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	downloader := NewDenoDownloader()
+	downloader.apiBase = server.URL
+
+	tests := []struct {
+		constraint string
+		expected   string
+	}{
+		{">=2.1.0, <3.0.0", "v2.1.5"},
+		{"~2.1.4", "v2.1.5"},
+		{"2.0.x", "v2.0.0"},
+		{">=2.1.0-rc.1, <3.0.0", "v2.2.0-rc.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			version, err := downloader.resolveVersionConstraint(context.Background(), tt.constraint)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, version)
+		})
+	}
+
+	_, err := downloader.resolveVersionConstraint(context.Background(), ">=4.0.0")
+	assert.EqualError(t, err, `no Deno release satisfies the version constraint ">=4.0.0"`)
+}
+
+func TestIsVersionConstraint(t *testing.T) {
+	for _, version := range []string{"latest", "v2.1.4", "2.1.4", "v2.0.0-rc.1"} {
+		assert.False(t, IsVersionConstraint(version), version)
+		assert.NoError(t, ValidateVersion(version))
+	}
+	for _, version := range []string{">=2.1.0, <3.0.0", "~2.1", "^2", "2.x"} {
+		assert.True(t, IsVersionConstraint(version), version)
+		assert.NoError(t, ValidateVersion(version))
+	}
+	assert.Error(t, ValidateVersion(">=two"))
+}
+
+func TestResolveVersion_PinnedForSession(t *testing.T) {
+	var latest atomic.Value
+	latest.Store("v2.1.4")
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"tag_name": latest.Load()})
+	}))
+	t.Cleanup(server.Close)
+
+	downloader := NewDenoDownloader()
+	downloader.apiBase = server.URL
+
+	version, err := downloader.resolveVersion(t.Context(), "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.1.4", version)
+
+	// A release published part way through the session is not picked up
+	latest.Store("v2.1.5")
+	version, err = downloader.resolveVersion(t.Context(), "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.1.4", version)
+	assert.Equal(t, int64(1), requests.Load())
+
+	// Concrete versions are never resolved
+	version, err = downloader.resolveVersion(t.Context(), "v2.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.0.0", version)
+
+	// Pointing at a mirror resolves again, from the mirror
+	downloader.SetDownloadBaseURL(server.URL)
+	version, err = downloader.resolveVersion(t.Context(), "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.1.5", version)
+}
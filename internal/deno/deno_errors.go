@@ -0,0 +1,288 @@
+package deno
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MissingPermission describes a permission that the Deno runtime refused to grant to a script.
+//
+// With --no-prompt Deno never asks interactively, instead it throws an error like:
+//
+//	NotCapable: Requires net access to "example.com:443", run again with the --allow-net flag
+type MissingPermission struct {
+	// Name is the permission kind, e.g. "net", "read" or "env".
+	Name string
+
+	// Value is the resource the script attempted to access, e.g. "example.com:443".
+	// It is empty when Deno did not report a specific resource.
+	Value string
+}
+
+// AllowEntry returns the entry that should be added to the permissions allow list to grant this permission.
+func (p MissingPermission) AllowEntry() string {
+	if p.Value == "" {
+		return p.Name
+	}
+	return fmt.Sprintf("%s=%s", p.Name, p.Value)
+}
+
+// missingPermissionRegex matches the permission errors emitted by the Deno runtime.
+var missingPermissionRegex = regexp.MustCompile(`Requires (\w+) access(?: to "([^"]*)")?`)
+
+// parseMissingPermissions extracts all permission errors from some Deno output.
+func parseMissingPermissions(text string) []MissingPermission {
+	var perms []MissingPermission
+	for _, match := range missingPermissionRegex.FindAllStringSubmatch(text, -1) {
+		perm := MissingPermission{Name: match[1], Value: match[2]}
+		if !slices.Contains(perms, perm) {
+			perms = append(perms, perm)
+		}
+	}
+	return perms
+}
+
+// suggestAllowList merges the missing permissions into an existing allow list, producing an allow list
+// that grants everything observed so far. Values for the same permission are combined into one entry,
+// eg: "net=a.com:443,b.com:443", which is the form Deno expects. Bare entries grant every value.
+func suggestAllowList(allow []string, missing []MissingPermission) []string {
+	type entry struct {
+		bare   bool
+		values []string
+	}
+	var names []string
+	entries := map[string]*entry{}
+	add := func(name, value string) {
+		e, ok := entries[name]
+		if !ok {
+			e = &entry{}
+			entries[name] = e
+			names = append(names, name)
+		}
+		if value == "" {
+			e.bare = true
+		} else if !slices.Contains(e.values, value) {
+			e.values = append(e.values, value)
+		}
+	}
+
+	for _, a := range allow {
+		name, values, found := strings.Cut(a, "=")
+		if !found {
+			add(name, "")
+			continue
+		}
+		for _, value := range strings.Split(values, ",") {
+			add(name, value)
+		}
+	}
+	for _, perm := range missing {
+		add(perm.Name, perm.Value)
+	}
+
+	suggested := make([]string, 0, len(names))
+	for _, name := range names {
+		e := entries[name]
+		if e.bare {
+			suggested = append(suggested, name)
+		} else {
+			suggested = append(suggested, name+"="+strings.Join(e.values, ","))
+		}
+	}
+	return suggested
+}
+
+// outOfMemoryMarkers are fragments of the fatal errors V8 prints when a script exhausts its heap.
+var outOfMemoryMarkers = []string{
+	"Fatal JavaScript out of memory",
+	"Reached heap limit",
+	"JavaScript heap out of memory",
+}
+
+// isOutOfMemory returns true if some Deno output reports that the V8 heap was exhausted.
+func isOutOfMemory(text string) bool {
+	for _, marker := range outOfMemoryMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScriptError describes an error thrown by a Deno script that was not handled by the script itself.
+//
+// Both the Deno runtime and the bridge library report these on stderr as the error followed by its stack trace:
+//
+//	error: Uncaught (in promise) Error: boom
+//	    at create (file:///work/script.ts:12:11)
+//	    at file:///work/script.ts:30:1
+type ScriptError struct {
+	// Message is the first line of the error as printed, e.g. "Error: boom".
+	Message string
+
+	// Frame is the top frame of the stack trace, e.g. "create (file:///work/script.ts:12:11)".
+	// It is empty when no stack trace was printed.
+	Frame string
+}
+
+// scriptErrorRegex matches the first line of an error reported by the Deno runtime ("error: Uncaught ...")
+// or by the bridge library when a method throws ("uncaught error ...").
+var scriptErrorRegex = regexp.MustCompile(`^(?:error: Uncaught (?:\(in promise\) )?|uncaught error )(.+)$`)
+
+// stackFrameRegex matches a single frame of a V8 stack trace.
+var stackFrameRegex = regexp.MustCompile(`^\s+at (.+)$`)
+
+// scriptErrorParser extracts script errors from Deno output one line at a time,
+// keeping the message and top frame rather than the whole stack trace.
+type scriptErrorParser struct {
+	// last is the most recently reported error, nil until one is seen.
+	last *ScriptError
+
+	// inTrace is true while the lines following an error may still belong to it.
+	inTrace bool
+}
+
+// parseLine feeds the next line of output to the parser.
+func (p *scriptErrorParser) parseLine(line string) {
+	if match := scriptErrorRegex.FindStringSubmatch(line); match != nil {
+		p.last = &ScriptError{Message: match[1]}
+		p.inTrace = true
+		return
+	}
+	if !p.inTrace {
+		return
+	}
+	if match := stackFrameRegex.FindStringSubmatch(line); match != nil {
+		if p.last.Frame == "" {
+			p.last.Frame = match[1]
+		}
+		return
+	}
+	// Anything else, including the remaining lines of a multi-line message, is not kept
+	if p.last.Frame != "" {
+		p.inTrace = false
+	}
+}
+
+// ExitError describes how the Deno process exited, turning the bare status reported by the os package into
+// an explanation of the likely cause.
+type ExitError struct {
+	// Code is the exit code of the process, or -1 when it was ended by a signal.
+	Code int
+
+	// Signal is the name of the signal that ended the process, e.g. "SIGKILL".
+	// It is empty when the process exited by itself.
+	Signal string
+
+	// err is the error returned by waiting on the process.
+	err error
+}
+
+// newExitError wraps the error returned by waiting on the Deno process, nil is returned unchanged
+// as is any error that does not carry an exit status.
+func newExitError(err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	return &ExitError{Code: exitErr.ExitCode(), Signal: exitSignal(exitErr.ProcessState), err: err}
+}
+
+// Error implements the error interface.
+func (e *ExitError) Error() string {
+	switch {
+	case e.Killed():
+		return fmt.Sprintf("the process was killed (%s), most often by the operating system when it runs out of memory", e.describeStatus())
+	case e.Signal != "":
+		return fmt.Sprintf("the process was ended by signal %s", e.Signal)
+	case e.Code == 1:
+		return "the process exited with code 1 (uncaught error)"
+	default:
+		return fmt.Sprintf("the process exited with code %d", e.Code)
+	}
+}
+
+// Unwrap returns the error returned by waiting on the process.
+func (e *ExitError) Unwrap() error {
+	return e.err
+}
+
+// Killed reports whether the process was forcefully killed, either by SIGKILL or with the
+// exit code 137 (128 + SIGKILL) that is reported when something in between was killed.
+func (e *ExitError) Killed() bool {
+	return e.Signal == "SIGKILL" || e.Code == 137
+}
+
+// describeStatus returns the signal or exit code the process ended with.
+func (e *ExitError) describeStatus() string {
+	if e.Signal != "" {
+		return e.Signal
+	}
+	return fmt.Sprintf("exit code %d", e.Code)
+}
+
+// ErrProcessExited is matched by errors.Is for every *ProcessExitedError.
+var ErrProcessExited = errors.New("the deno process exited")
+
+// ProcessExitedError is returned by Call when the Deno process exited without responding, either while the call
+// was waiting or before it was even made, eg: when the script crashed between two calls.
+type ProcessExitedError struct {
+	// Method is the remote method that was called.
+	Method string
+
+	// Code is the exit code of the process, 0 when it exited cleanly or -1 when it was ended by a signal.
+	Code int
+
+	// Stderr holds the last lines the process wrote to stderr, with any secrets redacted.
+	Stderr []string
+
+	// BeforeCall is true when the process had already exited by the time the call was made.
+	BeforeCall bool
+
+	// exit is the *ExitError describing how the process exited, nil when it exited cleanly.
+	exit error
+}
+
+// newProcessExitedError describes the exit of the process of c, which must have exited, for a call of method.
+func newProcessExitedError(c *DenoClient, method string, beforeCall bool) *ProcessExitedError {
+	err := &ProcessExitedError{Method: method, Stderr: c.StderrTail(), BeforeCall: beforeCall, exit: c.exitErr}
+	var exitErr *ExitError
+	if errors.As(c.exitErr, &exitErr) {
+		err.Code = exitErr.Code
+	} else if c.exitErr != nil {
+		err.Code = -1
+	}
+	return err
+}
+
+// Error implements the error interface.
+func (e *ProcessExitedError) Error() string {
+	var stderr string
+	if len(e.Stderr) > 0 {
+		stderr = "\n\nThe last lines written to stderr were:\n" + strings.Join(e.Stderr, "\n")
+	}
+	switch {
+	case e.BeforeCall && e.exit == nil:
+		return fmt.Sprintf("the Deno script had already exited before %s() was called, check that the script does not call Deno.exit()%s", e.Method, stderr)
+	case e.BeforeCall:
+		return fmt.Sprintf("the Deno script had already exited before %s() was called: %s%s", e.Method, e.exit, stderr)
+	case e.exit == nil:
+		return fmt.Sprintf("the Deno script's %s() returned but sent no response before the process exited, check that the bridge library is handling the call and the script does not call Deno.exit()", e.Method)
+	default:
+		return fmt.Sprintf("the Deno script exited before responding to %s(): %s%s", e.Method, e.exit, stderr)
+	}
+}
+
+// Unwrap returns the *ExitError describing how the process exited, nil when it exited cleanly.
+func (e *ProcessExitedError) Unwrap() error {
+	return e.exit
+}
+
+// Is reports whether target is ErrProcessExited.
+func (e *ProcessExitedError) Is(target error) bool {
+	return target == ErrProcessExited
+}
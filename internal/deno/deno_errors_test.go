@@ -0,0 +1,220 @@
+package deno
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseMissingPermissions_Net(t *testing.T) {
+	perms := parseMissingPermissions(`uncaught error NotCapable: Requires net access to "example.com:443", run again with the --allow-net flag`)
+
+	if len(perms) != 1 {
+		t.Fatalf("Expected 1 permission, got %d", len(perms))
+	}
+	if perms[0].Name != "net" {
+		t.Errorf("Expected name 'net', got '%s'", perms[0].Name)
+	}
+	if perms[0].Value != "example.com:443" {
+		t.Errorf("Expected value 'example.com:443', got '%s'", perms[0].Value)
+	}
+	if perms[0].AllowEntry() != "net=example.com:443" {
+		t.Errorf("Expected allow entry 'net=example.com:443', got '%s'", perms[0].AllowEntry())
+	}
+}
+
+func TestParseMissingPermissions_WithoutValue(t *testing.T) {
+	perms := parseMissingPermissions(`PermissionDenied: Requires sys access, run again with the --allow-sys flag`)
+
+	if len(perms) != 1 {
+		t.Fatalf("Expected 1 permission, got %d", len(perms))
+	}
+	if perms[0].AllowEntry() != "sys" {
+		t.Errorf("Expected allow entry 'sys', got '%s'", perms[0].AllowEntry())
+	}
+}
+
+func TestParseMissingPermissions_Deduplicates(t *testing.T) {
+	perms := parseMissingPermissions(
+		`Requires read access to "/tmp/a", run again with the --allow-read flag
+Requires read access to "/tmp/a", run again with the --allow-read flag
+Requires env access to "HOME", run again with the --allow-env flag`,
+	)
+
+	if len(perms) != 2 {
+		t.Fatalf("Expected 2 permissions, got %d", len(perms))
+	}
+	if perms[0].AllowEntry() != "read=/tmp/a" {
+		t.Errorf("Expected allow entry 'read=/tmp/a', got '%s'", perms[0].AllowEntry())
+	}
+	if perms[1].AllowEntry() != "env=HOME" {
+		t.Errorf("Expected allow entry 'env=HOME', got '%s'", perms[1].AllowEntry())
+	}
+}
+
+func TestParseMissingPermissions_NoMatch(t *testing.T) {
+	if perms := parseMissingPermissions("Shutting down gracefully..."); len(perms) != 0 {
+		t.Errorf("Expected no permissions, got %v", perms)
+	}
+}
+
+func TestDenoClient_MissingPermissions(t *testing.T) {
+	c := NewDenoClient("deno", "script.ts", "", nil, nil, nil)
+	c.recordStderrLine(`uncaught error NotCapable: Requires write access to "/etc/hosts", run again with the --allow-write flag`)
+
+	perms := c.MissingPermissions(errors.New(`Requires net access to "example.com:443"`))
+	if len(perms) != 2 {
+		t.Fatalf("Expected 2 permissions, got %d", len(perms))
+	}
+	if perms[0].AllowEntry() != "net=example.com:443" {
+		t.Errorf("Expected allow entry 'net=example.com:443', got '%s'", perms[0].AllowEntry())
+	}
+	if perms[1].AllowEntry() != "write=/etc/hosts" {
+		t.Errorf("Expected allow entry 'write=/etc/hosts', got '%s'", perms[1].AllowEntry())
+	}
+}
+
+func TestDenoClient_MissingPermissions_WaitsForStderrToEnd(t *testing.T) {
+	c := NewDenoClient("deno", "script.ts", "", nil, nil, nil)
+	stderr, stderrWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.pipeStderr(t.Context(), stderr)
+
+	// The process wrote the permission error and exited, but it may not have been read yet
+	if _, err := stderrWriter.WriteString("uncaught error NotCapable: Requires env access to \"HOME\", run again with the --allow-env flag\n"); err != nil {
+		t.Fatal(err)
+	}
+	_ = stderrWriter.Close()
+
+	perms := c.MissingPermissions(errors.New("deno child proc exited"))
+	if len(perms) != 1 || perms[0].AllowEntry() != "env=HOME" {
+		t.Errorf("Expected the env=HOME permission from stderr, got %v", perms)
+	}
+}
+
+func TestDenoClient_MissingPermissions_RunningProcess(t *testing.T) {
+	c := NewDenoClient("deno", "script.ts", "", nil, nil, nil)
+	stderr, stderrWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = stderrWriter.Close() })
+	c.pipeStderr(t.Context(), stderr)
+
+	// A process that is still running never closes its stderr, so only the short wait is paid
+	start := time.Now()
+	if perms := c.MissingPermissions(errors.New("boom")); len(perms) != 0 {
+		t.Errorf("Expected no permissions, got %v", perms)
+	}
+	if elapsed := time.Since(start); elapsed > stderrTailWait {
+		t.Errorf("Expected MissingPermissions to return within %s, took %s", stderrTailWait, elapsed)
+	}
+}
+
+func TestIsOutOfMemory(t *testing.T) {
+	if !isOutOfMemory("<--- Last few GCs ---> ... Fatal JavaScript out of memory: Reached heap limit") {
+		t.Error("Expected the V8 heap limit error to be detected")
+	}
+	if isOutOfMemory("uncaught error Error: boom") {
+		t.Error("Expected an ordinary error not to be detected as out of memory")
+	}
+}
+
+func TestDenoClient_OutOfMemory(t *testing.T) {
+	c := NewDenoClient("deno", "script.ts", "", nil, nil, nil)
+	if c.OutOfMemory() {
+		t.Fatal("Expected a new client not to be out of memory")
+	}
+
+	c.recordStderrLine("#\n# Fatal JavaScript out of memory: Reached heap limit\n#")
+	if !c.OutOfMemory() {
+		t.Error("Expected the client to be out of memory after V8 reported it")
+	}
+}
+
+func TestSuggestAllowList_MergesValues(t *testing.T) {
+	suggested := suggestAllowList(
+		[]string{"net=api.example.com:443", "env"},
+		[]MissingPermission{
+			{Name: "net", Value: "cdn.example.com:443"},
+			{Name: "net", Value: "api.example.com:443"},
+			{Name: "env", Value: "HOME"},
+			{Name: "read", Value: "/tmp"},
+		},
+	)
+
+	expected := []string{"net=api.example.com:443,cdn.example.com:443", "env", "read=/tmp"}
+	if !slices.Equal(suggested, expected) {
+		t.Errorf("Expected %v, got %v", expected, suggested)
+	}
+}
+
+func TestDenoClient_SuggestAllowList_RequiresLearnMode(t *testing.T) {
+	missing := []MissingPermission{{Name: "net", Value: "example.com:443"}}
+
+	c := NewDenoClient("deno", "script.ts", "", &Permissions{Allow: []string{"env"}}, nil, nil)
+	if suggested := c.SuggestAllowList(missing); suggested != nil {
+		t.Errorf("Expected no suggestion without learn mode, got %v", suggested)
+	}
+
+	c = NewDenoClient("deno", "script.ts", "", &Permissions{Allow: []string{"env"}, Learn: true}, nil, nil)
+	expected := []string{"env", "net=example.com:443"}
+	if suggested := c.SuggestAllowList(missing); !slices.Equal(suggested, expected) {
+		t.Errorf("Expected %v, got %v", expected, suggested)
+	}
+}
+
+func TestScriptErrorParser_Uncaught(t *testing.T) {
+	var p scriptErrorParser
+	for _, line := range []string{
+		"This is a JSON-RPC 2.0 server for the denobridge terraform provider.",
+		"error: Uncaught (in promise) Error: boom",
+		"    at create (file:///work/script.ts:12:11)",
+		"    at file:///work/script.ts:30:1",
+	} {
+		p.parseLine(line)
+	}
+
+	expected := ScriptError{Message: "Error: boom", Frame: "create (file:///work/script.ts:12:11)"}
+	if p.last == nil || *p.last != expected {
+		t.Errorf("Expected %+v, got %+v", expected, p.last)
+	}
+}
+
+func TestScriptErrorParser_BridgeLibrary(t *testing.T) {
+	var p scriptErrorParser
+	for _, line := range []string{
+		"uncaught error TypeError: Cannot read properties of undefined (reading 'id')",
+		"    at read (file:///work/script.ts:20:18)",
+		"    at async wrapMethod (https://jsr.io/@brad-jones/terraform-provider-denobridge/lib/providers/base.ts:140:14)",
+		"Shutting down gracefully...",
+		"    at unrelated (file:///work/other.ts:1:1)",
+	} {
+		p.parseLine(line)
+	}
+
+	expected := ScriptError{Message: "TypeError: Cannot read properties of undefined (reading 'id')", Frame: "read (file:///work/script.ts:20:18)"}
+	if p.last == nil || *p.last != expected {
+		t.Errorf("Expected %+v, got %+v", expected, p.last)
+	}
+}
+
+func TestDenoClient_ScriptError(t *testing.T) {
+	c := NewDenoClient("deno", "script.ts", "", nil, nil, nil)
+	if c.ScriptError() != nil {
+		t.Fatal("Expected a new client not to have a script error")
+	}
+
+	c.recordStderrLine("error: Uncaught Error: first")
+	c.recordStderrLine("error: Uncaught Error: second")
+	c.recordStderrLine("    at file:///work/script.ts:3:7")
+
+	scriptError := c.ScriptError()
+	if scriptError == nil || scriptError.Message != "Error: second" || scriptError.Frame != "file:///work/script.ts:3:7" {
+		t.Errorf("Expected the most recent script error to be returned, got %+v", scriptError)
+	}
+}
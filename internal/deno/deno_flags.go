@@ -0,0 +1,39 @@
+package deno
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// controlledFlags are the deno run flags that the provider sets itself, from its own attributes and the
+// permissions of each block, and so may not be given as extra flags.
+var controlledFlags = []string{
+	"--config", "--no-config", "--quiet", "--no-prompt", "--allow-all", "--permission-set",
+	"--env-file", "--cert", "--lock", "--frozen", "--preload", "--v8-flags", "--watch", "--watch-hmr",
+}
+
+// controlledShortFlags are the single letter forms of the controlled flags, including those of the permissions.
+const controlledShortFlags = "cqARWNESP"
+
+// ValidateDenoFlag returns an error when flag can not be passed to deno run as an extra flag, eg: with deno_flags.
+//
+// Flags must start with a dash, as anything else would be taken to be the script, and may not be one of the flags
+// the provider controls, such as the config file or any permission, which are configured by their own attributes.
+func ValidateDenoFlag(flag string) error {
+	name, _, _ := strings.Cut(flag, "=")
+	switch {
+	case name == "-" || name == "--" || !strings.HasPrefix(name, "-"):
+		return fmt.Errorf("expected a flag starting with a dash, eg: --node-modules-dir, got %q", flag)
+	case name == "--allow-scripts":
+		// Runs the install scripts of npm packages, rather than granting the script a permission
+		return nil
+	case strings.HasPrefix(name, "--allow-") || strings.HasPrefix(name, "--deny-"):
+		return fmt.Errorf("%s can not be set by deno_flags, use the permissions attribute instead", name)
+	case slices.Contains(controlledFlags, name):
+		return fmt.Errorf("%s can not be set by deno_flags, it is controlled by the provider", name)
+	case !strings.HasPrefix(name, "--") && strings.ContainsAny(name[1:], controlledShortFlags):
+		return fmt.Errorf("%s can not be set by deno_flags, it sets a flag controlled by the provider or a permission", name)
+	}
+	return nil
+}
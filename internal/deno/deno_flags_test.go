@@ -0,0 +1,30 @@
+package deno
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestValidateDenoFlag(t *testing.T) {
+	for _, flag := range []string{"--node-modules-dir", "--node-modules-dir=auto", "--allow-scripts=npm:sharp", "--unstable-kv", "--inspect=127.0.0.1:9229", "-r"} {
+		assert.NoError(t, ValidateDenoFlag(flag), flag)
+	}
+
+	tests := map[string]string{
+		"node-modules-dir":   "expected a flag starting with a dash",
+		"--":                 "expected a flag starting with a dash",
+		"--allow-net":        "use the permissions attribute instead",
+		"--deny-env=HOME":    "use the permissions attribute instead",
+		"--config=deno.json": "it is controlled by the provider",
+		"--allow-all":        "use the permissions attribute instead",
+		"--lock=deno.lock":   "it is controlled by the provider",
+		"-A":                 "it sets a flag controlled by the provider or a permission",
+		"-c=deno.json":       "it sets a flag controlled by the provider or a permission",
+	}
+	for flag, expected := range tests {
+		err := ValidateDenoFlag(flag)
+		assert.Error(t, err, flag)
+		assert.Contains(t, err.Error(), expected, flag)
+	}
+}
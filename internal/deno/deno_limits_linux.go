@@ -0,0 +1,15 @@
+//go:build linux
+
+package deno
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// limitCPU sets RLIMIT_CPU on the running process, the kernel kills it once the limit is reached.
+func limitCPU(process *os.Process, seconds int64) error {
+	limit := &unix.Rlimit{Cur: uint64(seconds), Max: uint64(seconds)}
+	return unix.Prlimit(process.Pid, unix.RLIMIT_CPU, limit, nil)
+}
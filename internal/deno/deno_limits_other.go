@@ -0,0 +1,13 @@
+//go:build !linux
+
+package deno
+
+import (
+	"errors"
+	"os"
+)
+
+// limitCPU is not supported on this platform.
+func limitCPU(_ *os.Process, _ int64) error {
+	return errors.New("cpu limits are only supported on linux")
+}
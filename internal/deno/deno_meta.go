@@ -0,0 +1,23 @@
+package deno
+
+import "runtime"
+
+// Meta describes the environment the provider is running in.
+// It is sent to the script with the health request so that it is available to every method.
+type Meta struct {
+	// OS is the operating system the provider is running on, as reported by runtime.GOOS
+	OS string `json:"os"`
+	// Arch is the architecture the provider is running on, as reported by runtime.GOARCH
+	Arch string `json:"arch"`
+	// TargetPlatform is the user supplied platform that artifacts are being built for, eg: "linux/arm64"
+	TargetPlatform string `json:"targetPlatform,omitempty"`
+}
+
+// newMeta returns the Meta sent to scripts run with the given options.
+func newMeta(options *ClientOptions) *Meta {
+	meta := &Meta{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	if options != nil {
+		meta.TargetPlatform = options.TargetPlatform
+	}
+	return meta
+}
@@ -0,0 +1,25 @@
+package deno
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestNewMeta(t *testing.T) {
+	meta := newMeta(&ClientOptions{TargetPlatform: "linux/arm64"})
+
+	if meta.OS != runtime.GOOS || meta.Arch != runtime.GOARCH {
+		t.Errorf("Expected %s/%s, got %s/%s", runtime.GOOS, runtime.GOARCH, meta.OS, meta.Arch)
+	}
+	if meta.TargetPlatform != "linux/arm64" {
+		t.Errorf("Expected target platform 'linux/arm64', got '%s'", meta.TargetPlatform)
+	}
+}
+
+func TestNewMeta_NilOptions(t *testing.T) {
+	meta := newMeta(nil)
+
+	if meta.TargetPlatform != "" {
+		t.Errorf("Expected no target platform, got '%s'", meta.TargetPlatform)
+	}
+}
@@ -0,0 +1,166 @@
+package deno
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sharedMetrics is the single collector returned by SharedMetrics.
+var sharedMetrics = &Metrics{}
+
+// SharedMetrics returns the metrics collector shared by every DenoClient and DenoDownloader in
+// this process, or nil when metrics are disabled.
+//
+// Metrics are only collected in test mode and when DENO_TOFU_BRIDGE_METRICS is "true".
+// They exist for performance work on the provider itself, eg: benchmarks asserting
+// that fewer processes are spawned, and are never collected in normal use.
+func SharedMetrics() *Metrics {
+	if !isTestContext() || os.Getenv("DENO_TOFU_BRIDGE_METRICS") != "true" {
+		return nil
+	}
+	return sharedMetrics
+}
+
+// Metrics aggregates per-method RPC durations, process spawn counts and download times.
+//
+// All methods are safe for concurrent use and a nil *Metrics records nothing.
+type Metrics struct {
+	spawns        atomic.Int64
+	downloads     atomic.Int64
+	downloadNanos atomic.Int64
+
+	// calls maps a method name to its *callMetrics.
+	calls sync.Map
+}
+
+// callMetrics aggregates the durations of the calls made to a single method.
+type callMetrics struct {
+	count      atomic.Int64
+	totalNanos atomic.Int64
+	maxNanos   atomic.Int64
+}
+
+// MetricsSnapshot is a point in time copy of the collected metrics.
+type MetricsSnapshot struct {
+	Spawns       int64
+	Downloads    int64
+	DownloadTime time.Duration
+	Calls        map[string]CallMetrics
+}
+
+// CallMetrics summarizes the calls made to a single method.
+type CallMetrics struct {
+	Count int64
+	Total time.Duration
+	Max   time.Duration
+}
+
+// recordSpawn counts a Deno process being started.
+func (m *Metrics) recordSpawn() {
+	if m == nil {
+		return
+	}
+	m.spawns.Add(1)
+}
+
+// recordDownload records a download that began at start.
+func (m *Metrics) recordDownload(start time.Time) {
+	if m == nil {
+		return
+	}
+	m.downloads.Add(1)
+	m.downloadNanos.Add(int64(time.Since(start)))
+}
+
+// recordCall records a call to method that began at start.
+func (m *Metrics) recordCall(method string, start time.Time) {
+	if m == nil {
+		return
+	}
+	elapsed := int64(time.Since(start))
+	v, _ := m.calls.LoadOrStore(method, &callMetrics{})
+	cm := v.(*callMetrics)
+	cm.count.Add(1)
+	cm.totalNanos.Add(elapsed)
+	for {
+		current := cm.maxNanos.Load()
+		if elapsed <= current || cm.maxNanos.CompareAndSwap(current, elapsed) {
+			break
+		}
+	}
+}
+
+// Snapshot returns a copy of the metrics collected so far.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	snapshot := MetricsSnapshot{Calls: map[string]CallMetrics{}}
+	if m == nil {
+		return snapshot
+	}
+	snapshot.Spawns = m.spawns.Load()
+	snapshot.Downloads = m.downloads.Load()
+	snapshot.DownloadTime = time.Duration(m.downloadNanos.Load())
+	m.calls.Range(func(key, value any) bool {
+		cm := value.(*callMetrics)
+		snapshot.Calls[key.(string)] = CallMetrics{
+			Count: cm.count.Load(),
+			Total: time.Duration(cm.totalNanos.Load()),
+			Max:   time.Duration(cm.maxNanos.Load()),
+		}
+		return true
+	})
+	return snapshot
+}
+
+// Reset discards the metrics collected so far, eg: between benchmarks.
+func (m *Metrics) Reset() {
+	if m == nil {
+		return
+	}
+	m.spawns.Store(0)
+	m.downloads.Store(0)
+	m.downloadNanos.Store(0)
+	m.calls.Clear()
+}
+
+// Dump writes the metrics collected so far to w in the Prometheus text exposition format.
+func (m *Metrics) Dump(w io.Writer) error {
+	snapshot := m.Snapshot()
+	methods := make([]string, 0, len(snapshot.Calls))
+	for method := range snapshot.Calls {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	lines := []string{
+		"# TYPE denobridge_process_spawns_total counter",
+		fmt.Sprintf("denobridge_process_spawns_total %d", snapshot.Spawns),
+		"# TYPE denobridge_downloads_total counter",
+		fmt.Sprintf("denobridge_downloads_total %d", snapshot.Downloads),
+		"# TYPE denobridge_download_seconds_total counter",
+		fmt.Sprintf("denobridge_download_seconds_total %g", snapshot.DownloadTime.Seconds()),
+		"# TYPE denobridge_rpc_calls_total counter",
+	}
+	for _, method := range methods {
+		lines = append(lines, fmt.Sprintf("denobridge_rpc_calls_total{method=%q} %d", method, snapshot.Calls[method].Count))
+	}
+	lines = append(lines, "# TYPE denobridge_rpc_duration_seconds_total counter")
+	for _, method := range methods {
+		lines = append(lines, fmt.Sprintf("denobridge_rpc_duration_seconds_total{method=%q} %g", method, snapshot.Calls[method].Total.Seconds()))
+	}
+	lines = append(lines, "# TYPE denobridge_rpc_duration_seconds_max gauge")
+	for _, method := range methods {
+		lines = append(lines, fmt.Sprintf("denobridge_rpc_duration_seconds_max{method=%q} %g", method, snapshot.Calls[method].Max.Seconds()))
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to write metrics: %w", err)
+		}
+	}
+	return nil
+}
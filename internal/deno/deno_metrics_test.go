@@ -0,0 +1,53 @@
+package deno
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestSharedMetrics_Disabled(t *testing.T) {
+	t.Setenv("DENO_TOFU_BRIDGE_TEST_MODE", "true")
+	t.Setenv("DENO_TOFU_BRIDGE_METRICS", "")
+	assert.Zero(t, SharedMetrics())
+
+	// A nil collector records nothing
+	var m *Metrics
+	m.recordSpawn()
+	m.recordCall("create", time.Now())
+	assert.Equal(t, 0, len(m.Snapshot().Calls))
+}
+
+func TestMetrics_RecordsCalls(t *testing.T) {
+	t.Setenv("DENO_TOFU_BRIDGE_TEST_MODE", "true")
+	t.Setenv("DENO_TOFU_BRIDGE_METRICS", "true")
+	SharedMetrics().Reset()
+	t.Cleanup(SharedMetrics().Reset)
+
+	c := connectFakeScript(t, map[string]any{
+		"read": func() map[string]any {
+			return map[string]any{}
+		},
+	})
+	assert.NoError(t, c.Call(t.Context(), "read", nil, nil))
+	assert.NoError(t, c.Call(t.Context(), "read", nil, nil))
+
+	snapshot := SharedMetrics().Snapshot()
+	assert.Equal(t, 2, snapshot.Calls["read"].Count)
+	assert.True(t, snapshot.Calls["read"].Max <= snapshot.Calls["read"].Total)
+}
+
+func TestMetrics_Dump(t *testing.T) {
+	m := &Metrics{}
+	m.recordSpawn()
+	m.recordDownload(time.Now().Add(-time.Second))
+	m.recordCall("health", time.Now())
+
+	var b strings.Builder
+	assert.NoError(t, m.Dump(&b))
+	assert.Contains(t, b.String(), "denobridge_process_spawns_total 1\n")
+	assert.Contains(t, b.String(), "denobridge_downloads_total 1\n")
+	assert.Contains(t, b.String(), `denobridge_rpc_calls_total{method="health"} 1`)
+}
@@ -1,6 +1,10 @@
 package deno
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
@@ -14,6 +18,8 @@ type Permissions struct {
 	Allow []string
 	// Deny is a list of specific permissions to explicitly deny
 	Deny []string
+	// Learn suggests a least-privilege allow list when the script is refused a permission
+	Learn bool
 }
 
 // MapToDenoPermissionsTF converts Go-native Permissions to Terraform Framework types.
@@ -28,11 +34,16 @@ func (permissions *Permissions) MapToDenoPermissionsTF() *PermissionsTF {
 			All:   types.BoolValue(false),
 			Allow: types.ListNull(types.StringType),
 			Deny:  types.ListNull(types.StringType),
+			Learn: types.BoolNull(),
 		}
 	}
 
 	output := &PermissionsTF{
-		All: types.BoolValue(permissions.All),
+		All:   types.BoolValue(permissions.All),
+		Learn: types.BoolNull(),
+	}
+	if permissions.Learn {
+		output.Learn = types.BoolValue(true)
 	}
 
 	// Convert Allow []string to types.List
@@ -69,6 +80,8 @@ type PermissionsTF struct {
 	Allow types.List `tfsdk:"allow"`
 	// Deny is a list of specific permissions to explicitly deny
 	Deny types.List `tfsdk:"deny"`
+	// Learn suggests a least-privilege allow list when the script is refused a permission
+	Learn types.Bool `tfsdk:"learn"`
 }
 
 // MapToDenoPermissions converts Terraform Framework types to Go-native Permissions.
@@ -89,7 +102,8 @@ func (permissions *PermissionsTF) MapToDenoPermissions() *Permissions {
 	}
 
 	output := &Permissions{
-		All: permissions.All.ValueBool(),
+		All:   permissions.All.ValueBool(),
+		Learn: permissions.Learn.ValueBool(),
 	}
 
 	if !permissions.Allow.IsNull() {
@@ -114,3 +128,217 @@ func (permissions *PermissionsTF) MapToDenoPermissions() *Permissions {
 
 	return output
 }
+
+// Grants reports whether the named permission (e.g., "env") is granted, either fully
+// or scoped to particular values such as "env=HOME", and has not been denied outright.
+func (permissions *Permissions) Grants(name string) bool {
+	if permissions == nil || slices.Contains(permissions.Deny, name) {
+		return false
+	}
+	if permissions.All {
+		return true
+	}
+	for _, allow := range permissions.Allow {
+		if allow == name || strings.HasPrefix(allow, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// Args returns the Deno CLI flags that apply these permissions.
+//
+// Deny flags are always included, even alongside --allow-all, as Deno gives them precedence.
+// This allows broad access to be granted while carving out specific resources, eg:
+// --allow-all --deny-write=/etc.
+func (permissions *Permissions) Args() []string {
+	if permissions == nil {
+		return nil
+	}
+	var args []string
+	if permissions.All {
+		args = append(args, "--allow-all")
+	} else {
+		for _, perm := range permissions.Allow {
+			args = append(args, fmt.Sprintf("--allow-%s", perm))
+		}
+	}
+	for _, perm := range permissions.Deny {
+		args = append(args, fmt.Sprintf("--deny-%s", perm))
+	}
+	return args
+}
+
+// PermissionEntryError describes an invalid entry in an allow or deny list, see ValidatePermissionEntries.
+type PermissionEntryError struct {
+	// Index is the position of the invalid entry in the list
+	Index int
+	// Entry is the invalid entry, eg: "read=/etc"
+	Entry string
+	// Reason explains why the entry is invalid
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *PermissionEntryError) Error() string {
+	return fmt.Sprintf("invalid permission %q: %s", e.Entry, e.Reason)
+}
+
+// ValidatePermissionEntries checks the entries of an allow or deny list, returning a *PermissionEntryError
+// for the first that is invalid.
+//
+// Entries are either a bare permission name, eg: "read", or a permission scoped to particular values, eg:
+// "read=/etc,/tmp" or "net=api.github.com:443", which become --allow-read=/etc,/tmp and so on. A permission
+// may not be given both bare and scoped in the same list, as the flags would conflict and it would be unclear
+// whether the whole permission or only the scoped values were meant.
+func ValidatePermissionEntries(entries []string) error {
+	bare := map[string]int{}
+	scoped := map[string]int{}
+	for i, entry := range entries {
+		name, values := parsePermissionEntry(entry)
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return &PermissionEntryError{Index: i, Entry: entry, Reason: "expected a permission name such as read, optionally scoped with =, eg: read=/etc"}
+		}
+		if values == nil {
+			if j, ok := scoped[name]; ok {
+				return &PermissionEntryError{Index: i, Entry: entry, Reason: fmt.Sprintf("%s is already scoped by %q, remove one of them", name, entries[j])}
+			}
+			bare[name] = i
+			continue
+		}
+		if slices.Contains(values, "") {
+			return &PermissionEntryError{Index: i, Entry: entry, Reason: fmt.Sprintf("expected a comma separated list of values after %s=", name)}
+		}
+		if j, ok := bare[name]; ok {
+			return &PermissionEntryError{Index: i, Entry: entry, Reason: fmt.Sprintf("%s is already given unscoped by %q, remove one of them", name, entries[j])}
+		}
+		scoped[name] = i
+	}
+	return nil
+}
+
+// withImportHosts returns a copy of these permissions that also allow importing modules from the given hosts,
+// merged into any import entry already in the allow list. Permissions that already allow importing from
+// anywhere, or an empty list of hosts, are returned unchanged.
+func (permissions *Permissions) withImportHosts(hosts []string) *Permissions {
+	if len(hosts) == 0 || (permissions != nil && permissions.All) {
+		return permissions
+	}
+	merged := &Permissions{}
+	if permissions != nil {
+		*merged = *permissions
+	}
+	merged.Allow = make([]string, 0, len(merged.Allow)+1)
+
+	importHosts := slices.Clone(hosts)
+	if permissions != nil {
+		for _, allow := range permissions.Allow {
+			name, values := parsePermissionEntry(allow)
+			if name != "import" {
+				merged.Allow = append(merged.Allow, allow)
+				continue
+			}
+			if values == nil {
+				return permissions
+			}
+			for _, value := range values {
+				if !slices.Contains(importHosts, value) {
+					importHosts = append(importHosts, value)
+				}
+			}
+		}
+	}
+	merged.Allow = append(merged.Allow, "import="+strings.Join(importHosts, ","))
+	return merged
+}
+
+// CompareDeclared compares the permissions a script declared it needs with these configured permissions.
+//
+// Declared entries use the same format as the allow list, eg: "env" or "net=example.com,api.example.com".
+//
+// Insufficient lists the declared permissions that are not granted, eg: "net=api.example.com" when only
+// "net=example.com" is allowed. Broad lists the configured grants that go beyond what was declared, eg:
+// "all", a permission that was not declared at all, or a bare "net" when only particular hosts were declared.
+func (permissions *Permissions) CompareDeclared(declared []string) (insufficient, broad []string) {
+	if permissions == nil {
+		permissions = &Permissions{}
+	}
+
+	granted := func(name, value string) bool {
+		for _, deny := range permissions.Deny {
+			denyName, denyValues := parsePermissionEntry(deny)
+			if denyName == name && (denyValues == nil || (value != "" && slices.Contains(denyValues, value))) {
+				return false
+			}
+		}
+		if permissions.All {
+			return true
+		}
+		for _, allow := range permissions.Allow {
+			allowName, allowValues := parsePermissionEntry(allow)
+			if allowName == name && (allowValues == nil || (value != "" && slices.Contains(allowValues, value))) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Gather what was declared, a nil slice of values means the whole permission was declared
+	var declaredNames []string
+	declaredValues := map[string][]string{}
+	for _, entry := range declared {
+		name, values := parsePermissionEntry(entry)
+		if !slices.Contains(declaredNames, name) {
+			declaredNames = append(declaredNames, name)
+			declaredValues[name] = values
+		} else if declaredValues[name] != nil {
+			declaredValues[name] = append(declaredValues[name], values...)
+		}
+		if values == nil {
+			declaredValues[name] = nil
+			if !granted(name, "") {
+				insufficient = append(insufficient, name)
+			}
+			continue
+		}
+		for _, value := range values {
+			if !granted(name, value) {
+				insufficient = append(insufficient, name+"="+value)
+			}
+		}
+	}
+
+	if permissions.All {
+		return insufficient, append(broad, "all")
+	}
+	for _, allow := range permissions.Allow {
+		name, values := parsePermissionEntry(allow)
+		if !slices.Contains(declaredNames, name) {
+			broad = append(broad, allow)
+			continue
+		}
+		if declaredValues[name] == nil {
+			continue
+		}
+		if values == nil {
+			broad = append(broad, name)
+			continue
+		}
+		for _, value := range values {
+			if !slices.Contains(declaredValues[name], value) {
+				broad = append(broad, name+"="+value)
+			}
+		}
+	}
+	return insufficient, broad
+}
+
+// parsePermissionEntry splits an allow or deny list entry into the permission name and the values
+// it is scoped to, eg: "net=example.com,api.example.com". Values is nil for an entry that is not scoped.
+func parsePermissionEntry(entry string) (string, []string) {
+	name, values, found := strings.Cut(entry, "=")
+	if !found {
+		return name, nil
+	}
+	return name, strings.Split(values, ",")
+}
@@ -1,6 +1,8 @@
 package deno
 
 import (
+	"errors"
+	"slices"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
@@ -144,3 +146,157 @@ func TestDenoPermissions_MapToDenoPermissions_NullLists(t *testing.T) {
 		t.Errorf("Expected empty or nil Deny list for null value, got %d items", len(result.Deny))
 	}
 }
+
+// TestDenoPermissions_Grants tests detecting whether a permission is granted.
+func TestDenoPermissions_Grants(t *testing.T) {
+	cases := []struct {
+		name  string
+		perms *Permissions
+		want  bool
+	}{
+		{"nil", nil, false},
+		{"all", &Permissions{All: true}, true},
+		{"all but denied", &Permissions{All: true, Deny: []string{"env"}}, false},
+		{"allowed", &Permissions{Allow: []string{"read", "env"}}, true},
+		{"scoped", &Permissions{Allow: []string{"env=HOME,PATH"}}, true},
+		{"similar name", &Permissions{Allow: []string{"environment"}}, false},
+		{"not allowed", &Permissions{Allow: []string{"read"}}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.perms.Grants("env"); got != tc.want {
+			t.Errorf("%s: expected Grants(\"env\") to be %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+// TestDenoPermissions_Args tests building the Deno CLI permission flags.
+func TestDenoPermissions_Args(t *testing.T) {
+	cases := []struct {
+		name  string
+		perms *Permissions
+		want  []string
+	}{
+		{"nil", nil, nil},
+		{"allow and deny", &Permissions{Allow: []string{"read", "net=example.com"}, Deny: []string{"write"}}, []string{"--allow-read", "--allow-net=example.com", "--deny-write"}},
+		{"all", &Permissions{All: true}, []string{"--allow-all"}},
+		{"all with deny", &Permissions{All: true, Deny: []string{"write=/etc", "run"}}, []string{"--allow-all", "--deny-write=/etc", "--deny-run"}},
+	}
+	for _, tc := range cases {
+		if got := tc.perms.Args(); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+// TestValidatePermissionEntries tests rejecting malformed and conflicting allow and deny entries.
+func TestValidatePermissionEntries(t *testing.T) {
+	cases := []struct {
+		name      string
+		entries   []string
+		wantIndex int
+	}{
+		{"bare", []string{"read", "env"}, -1},
+		{"scoped", []string{"read=/etc,/tmp", "net=api.github.com:443"}, -1},
+		{"bare and scoped", []string{"read", "net=api.github.com:443", "read=/etc"}, 2},
+		{"scoped then bare", []string{"read=/etc", "read"}, 1},
+		{"empty value", []string{"read=/etc,"}, 0},
+		{"no value", []string{"env", "write="}, 1},
+		{"no name", []string{"=/etc"}, 0},
+	}
+	for _, tc := range cases {
+		err := ValidatePermissionEntries(tc.entries)
+		var entryErr *PermissionEntryError
+		switch {
+		case tc.wantIndex < 0 && err != nil:
+			t.Errorf("%s: expected no error, got %v", tc.name, err)
+		case tc.wantIndex >= 0 && !errors.As(err, &entryErr):
+			t.Errorf("%s: expected a PermissionEntryError, got %v", tc.name, err)
+		case tc.wantIndex >= 0 && entryErr.Index != tc.wantIndex:
+			t.Errorf("%s: expected entry %d to be invalid, got %d: %v", tc.name, tc.wantIndex, entryErr.Index, err)
+		}
+	}
+}
+
+// TestDenoPermissions_WithImportHosts tests merging the trusted import hosts into the --allow-import flag.
+func TestDenoPermissions_WithImportHosts(t *testing.T) {
+	options := &ClientOptions{TrustedImportHosts: []string{"deno.land", "esm.sh:443"}}
+	cases := []struct {
+		name    string
+		perms   *Permissions
+		options *ClientOptions
+		want    []string
+	}{
+		{"no trusted hosts", &Permissions{Allow: []string{"env"}}, &ClientOptions{}, []string{"--allow-env"}},
+		{"nil permissions", nil, options, []string{"--allow-import=jsr.io,deno.land,esm.sh:443"}},
+		{"appended", &Permissions{Allow: []string{"env"}, Deny: []string{"run"}}, options, []string{"--allow-env", "--allow-import=jsr.io,deno.land,esm.sh:443", "--deny-run"}},
+		{"merged", &Permissions{Allow: []string{"import=example.com,deno.land", "env"}}, options, []string{"--allow-env", "--allow-import=jsr.io,deno.land,esm.sh:443,example.com"}},
+		{"registry mirror", &Permissions{}, &ClientOptions{TrustedImportHosts: []string{"deno.land"}, JSRRegistryURL: "https://jsr.corp.example.com/"}, []string{"--allow-import=jsr.corp.example.com,deno.land"}},
+		{"import unrestricted", &Permissions{Allow: []string{"import"}}, options, []string{"--allow-import"}},
+		{"all", &Permissions{All: true}, options, []string{"--allow-all"}},
+	}
+	for _, tc := range cases {
+		if got := tc.perms.withImportHosts(tc.options.importHosts()).Args(); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+// TestDenoPermissions_CompareDeclared tests comparing declared permissions with the configured ones.
+func TestDenoPermissions_CompareDeclared(t *testing.T) {
+	tests := []struct {
+		name                 string
+		permissions          *Permissions
+		declared             []string
+		expectedInsufficient []string
+		expectedBroad        []string
+	}{
+		{
+			name:        "exact match",
+			permissions: &Permissions{Allow: []string{"env", "net=example.com"}},
+			declared:    []string{"env", "net=example.com"},
+		},
+		{
+			name:                 "missing permissions",
+			permissions:          &Permissions{Allow: []string{"net=example.com"}},
+			declared:             []string{"env", "net=example.com,api.example.com"},
+			expectedInsufficient: []string{"env", "net=api.example.com"},
+		},
+		{
+			name:                 "denied permission",
+			permissions:          &Permissions{All: true, Deny: []string{"write"}},
+			declared:             []string{"write=/tmp"},
+			expectedInsufficient: []string{"write=/tmp"},
+			expectedBroad:        []string{"all"},
+		},
+		{
+			name:          "broader than declared",
+			permissions:   &Permissions{Allow: []string{"net", "read=/tmp,/etc", "run"}},
+			declared:      []string{"net=example.com", "read=/tmp"},
+			expectedBroad: []string{"net", "read=/etc", "run"},
+		},
+		{
+			name:          "declared nothing",
+			permissions:   &Permissions{Allow: []string{"env"}},
+			declared:      []string{},
+			expectedBroad: []string{"env"},
+		},
+		{
+			name:                 "nil permissions",
+			permissions:          nil,
+			declared:             []string{"env"},
+			expectedInsufficient: []string{"env"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			insufficient, broad := tt.permissions.CompareDeclared(tt.declared)
+			if !slices.Equal(insufficient, tt.expectedInsufficient) {
+				t.Errorf("Expected insufficient %v, got %v", tt.expectedInsufficient, insufficient)
+			}
+			if !slices.Equal(broad, tt.expectedBroad) {
+				t.Errorf("Expected broad %v, got %v", tt.expectedBroad, broad)
+			}
+		})
+	}
+}
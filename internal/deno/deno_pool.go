@@ -0,0 +1,131 @@
+package deno
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+)
+
+// ClientPool keeps started resource clients idle between operations so that later operations on the
+// same script can reuse a warm Deno process, instead of each paying the cost of starting Deno and
+// loading the script's imports again.
+//
+// A client is only ever used by one operation at a time, it is removed from the pool by Take and
+// only returned by Put once that operation is done. All methods are safe for concurrent use and a
+// nil *ClientPool keeps nothing, so every operation starts its own process.
+type ClientPool struct {
+	// maxIdle is how many idle clients may be kept across all scripts.
+	maxIdle int
+
+	// mu guards everything below.
+	mu     sync.Mutex
+	idle   map[string][]*DenoClientResource
+	count  int
+	closed bool
+}
+
+// NewClientPool creates a pool that keeps at most maxIdle idle clients, or nil when maxIdle is not positive.
+func NewClientPool(maxIdle int) *ClientPool {
+	if maxIdle <= 0 {
+		return nil
+	}
+	return &ClientPool{maxIdle: maxIdle, idle: map[string][]*DenoClientResource{}}
+}
+
+// PoolKey identifies the clients that may be reused for one another, those running the same
+// script with the same binary, config file, permissions and options.
+func PoolKey(denoBinaryPath, scriptPath, configPath string, permissions *Permissions, options *ClientOptions) string {
+	encoded, _ := json.Marshal([]any{denoBinaryPath, scriptPath, configPath, permissions.Args(), options})
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// Take removes an idle client with the given key from the pool and returns it, or nil when there is none.
+// Clients whose process exited while they were idle are discarded.
+func (p *ClientPool) Take(key string) *DenoClientResource {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for clients := p.idle[key]; len(clients) > 0; clients = p.idle[key] {
+		c := clients[len(clients)-1]
+		p.idle[key] = clients[:len(clients)-1]
+		p.count--
+		if len(p.idle[key]) == 0 {
+			delete(p.idle, key)
+		}
+		if !c.Client.hasExited() {
+			return c
+		}
+		_ = c.Client.Stop()
+	}
+	return nil
+}
+
+// Put returns a client to the pool once an operation is done with it. It reports false, leaving
+// the caller to stop the client, when the pool is full or closed, the process has exited or its
+// options do not allow it to be reused.
+//
+// Anything collected from the process's stderr during the operation is discarded, so that it is not
+// reported against the next operation.
+func (p *ClientPool) Put(key string, c *DenoClientResource) bool {
+	if p == nil || !c.Client.options.Reusable() || c.Client.hasExited() {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.count >= p.maxIdle {
+		return false
+	}
+	c.Client.resetStderrDetails()
+	p.idle[key] = append(p.idle[key], c)
+	p.count++
+	return true
+}
+
+// Close stops every idle client, concurrently so that shutdown is not held up by each stop grace
+// period in turn. Clients returned to the pool afterwards are not kept. The first error is returned.
+func (p *ClientPool) Close() error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	p.closed = true
+	idle := p.idle
+	p.idle = map[string][]*DenoClientResource{}
+	p.count = 0
+	p.mu.Unlock()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for _, clients := range idle {
+		for _, c := range clients {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := c.Client.Stop(); err != nil {
+					errOnce.Do(func() { firstErr = err })
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// Idle returns how many idle clients the pool holds.
+func (p *ClientPool) Idle() int {
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.count
+}
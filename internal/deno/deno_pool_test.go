@@ -0,0 +1,103 @@
+//go:build !windows
+
+package deno
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+// startFakeResourceClient starts a long running shell script in place of a real Deno process.
+func startFakeResourceClient(t *testing.T) *DenoClientResource {
+	t.Helper()
+	c := startFakeProcess(t, &ClientOptions{StopGrace: 100 * time.Millisecond}, `while :; do sleep 0.05; done`)
+	t.Cleanup(func() { _ = c.Stop() })
+	return &DenoClientResource{Client: c}
+}
+
+func TestClientPool_ReusesIdleClients(t *testing.T) {
+	pool := NewClientPool(2)
+	c := startFakeResourceClient(t)
+
+	assert.Zero(t, pool.Take("a"))
+	assert.True(t, pool.Put("a", c))
+	assert.Equal(t, 1, pool.Idle())
+
+	// Clients are only reused for the same key, and only by one operation at a time
+	assert.Zero(t, pool.Take("b"))
+	assert.True(t, pool.Take("a") == c, "expected the idle client to be reused")
+	assert.Zero(t, pool.Take("a"))
+	assert.Equal(t, 0, pool.Idle())
+}
+
+func TestClientPool_ResetsStderrDetails(t *testing.T) {
+	pool := NewClientPool(1)
+	c := startFakeResourceClient(t)
+	c.Client.recordStderrLine("error: Uncaught (in promise) Error: boom")
+	assert.NotZero(t, c.Client.ScriptError())
+
+	assert.True(t, pool.Put("a", c))
+	assert.Zero(t, pool.Take("a").Client.ScriptError())
+}
+
+func TestClientPool_RefusesWhenFull(t *testing.T) {
+	pool := NewClientPool(1)
+	assert.True(t, pool.Put("a", startFakeResourceClient(t)))
+	assert.False(t, pool.Put("b", startFakeResourceClient(t)))
+	assert.Equal(t, 1, pool.Idle())
+}
+
+func TestClientPool_RefusesCPULimitedClients(t *testing.T) {
+	pool := NewClientPool(2)
+	c := startFakeResourceClient(t)
+	c.Client.options = &ClientOptions{StopGrace: 100 * time.Millisecond, MaxCPUSeconds: 60}
+
+	// The CPU time of every operation would count towards the limit of a pooled process
+	assert.False(t, pool.Put("a", c))
+	assert.Equal(t, 0, pool.Idle())
+}
+
+func TestClientPool_DiscardsExitedClients(t *testing.T) {
+	pool := NewClientPool(2)
+	c := startFakeResourceClient(t)
+	assert.True(t, pool.Put("a", c))
+
+	assert.NoError(t, c.Client.process.Process.Kill())
+	<-c.Client.exited()
+	assert.Zero(t, pool.Take("a"))
+	assert.Equal(t, 0, pool.Idle())
+
+	// An exited client is not accepted back either
+	assert.False(t, pool.Put("a", c))
+}
+
+func TestClientPool_Close(t *testing.T) {
+	pool := NewClientPool(2)
+	c := startFakeResourceClient(t)
+	assert.True(t, pool.Put("a", c))
+
+	assert.NoError(t, pool.Close())
+	assert.True(t, c.Client.hasExited())
+	assert.Equal(t, 0, pool.Idle())
+	assert.False(t, pool.Put("a", startFakeResourceClient(t)))
+}
+
+func TestClientPool_Disabled(t *testing.T) {
+	pool := NewClientPool(0)
+	assert.Zero(t, pool)
+	assert.False(t, pool.Put("a", startFakeResourceClient(t)))
+	assert.Zero(t, pool.Take("a"))
+	assert.NoError(t, pool.Close())
+}
+
+func TestPoolKey(t *testing.T) {
+	read := &Permissions{Allow: []string{"read"}}
+	key := PoolKey("deno", "script.ts", "", read, nil)
+	assert.Equal(t, key, PoolKey("deno", "script.ts", "", &Permissions{Allow: []string{"read"}}, nil))
+	assert.NotEqual(t, key, PoolKey("deno", "other.ts", "", read, nil))
+	assert.NotEqual(t, key, PoolKey("deno", "script.ts", "deno.json", read, nil))
+	assert.NotEqual(t, key, PoolKey("deno", "script.ts", "", &Permissions{Allow: []string{"net"}}, nil))
+	assert.NotEqual(t, key, PoolKey("deno", "script.ts", "", read, &ClientOptions{EnvFile: ".env"}))
+}
@@ -0,0 +1,109 @@
+package deno
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// redactedValue replaces any log output matched by a redact pattern.
+const redactedValue = "***"
+
+// logRedactor scrubs sensitive values from log output before it is written.
+type logRedactor struct {
+	patterns []*regexp.Regexp
+
+	// secretsMu guards secrets, which may be added while output is being redacted.
+	secretsMu sync.RWMutex
+	secrets   []string
+}
+
+// newLogRedactor compiles the given regular expressions into a logRedactor.
+//
+// A pattern without capture groups has its whole match redacted. A pattern with capture
+// groups only has the groups redacted, which allows matching on a key while keeping it
+// visible, eg: `"token":"([^"]+)"`.
+func newLogRedactor(patterns []string) (*logRedactor, error) {
+	r := &logRedactor{}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log redact pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// addSecrets adds literal values to redact wherever they appear, both as is and JSON encoded, eg: in the
+// params of a JSON-RPC message. Empty values are ignored.
+func (r *logRedactor) addSecrets(values ...string) {
+	r.secretsMu.Lock()
+	defer r.secretsMu.Unlock()
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		r.secrets = append(r.secrets, value)
+		if quoted := strconv.Quote(value); quoted[1:len(quoted)-1] != value {
+			r.secrets = append(r.secrets, quoted[1:len(quoted)-1])
+		}
+	}
+}
+
+// Redact returns s with every secret and every match of the configured patterns replaced by "***".
+func (r *logRedactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+	r.secretsMu.RLock()
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+	}
+	r.secretsMu.RUnlock()
+	for _, re := range r.patterns {
+		if re.NumSubexp() == 0 {
+			s = re.ReplaceAllLiteralString(s, redactedValue)
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+			for i := 2; i < len(loc); i += 2 {
+				// Skip groups that did not participate in the match or are nested in a previous group
+				if loc[i] < 0 || loc[i] < last {
+					continue
+				}
+				b.WriteString(s[last:loc[i]])
+				b.WriteString(redactedValue)
+				last = loc[i+1]
+			}
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+	}
+	return s
+}
+
+// rpcLogger logs the JSON-RPC messages exchanged with the Deno child process at trace level.
+// It satisfies jsonrpc2.Logger so that it can be given to jsonrpc2.LogMessages.
+type rpcLogger struct {
+	ctx      context.Context
+	redactor *logRedactor
+}
+
+// Printf formats and redacts a JSON-RPC log message before writing it.
+func (l *rpcLogger) Printf(format string, v ...any) {
+	msg := l.redactor.Redact(strings.TrimSuffix(fmt.Sprintf(format, v...), "\n"))
+	if isTestContext() {
+		log.Printf("[TRACE] %s", msg)
+	} else {
+		tflog.Trace(l.ctx, msg)
+	}
+}
@@ -0,0 +1,136 @@
+package deno
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/jsocket"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func TestLogRedactor_WholeMatch(t *testing.T) {
+	r, err := newLogRedactor([]string{`ghp_[A-Za-z0-9]+`})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	got := r.Redact("cloning with ghp_abc123 and ghp_def456")
+	if got != "cloning with *** and ***" {
+		t.Errorf("Expected both tokens to be redacted, got '%s'", got)
+	}
+}
+
+func TestLogRedactor_CaptureGroups(t *testing.T) {
+	r, err := newLogRedactor([]string{`"(?:token|password)":"([^"]+)"`})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	got := r.Redact(`{"user":"bob","token":"s3cret","password":"hunter2"}`)
+	if got != `{"user":"bob","token":"***","password":"***"}` {
+		t.Errorf("Expected only the captured values to be redacted, got '%s'", got)
+	}
+}
+
+func TestLogRedactor_Nil(t *testing.T) {
+	var r *logRedactor
+	if got := r.Redact("nothing to hide"); got != "nothing to hide" {
+		t.Errorf("Expected a nil redactor to leave the input unchanged, got '%s'", got)
+	}
+}
+
+func TestLogRedactor_InvalidPattern(t *testing.T) {
+	if _, err := newLogRedactor([]string{`(`}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestPipeToDebugLog_Redacts(t *testing.T) {
+	t.Setenv("DENO_TOFU_BRIDGE_TEST_MODE", "true")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	r, err := newLogRedactor([]string{`Bearer \S+`})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var seen []string
+	pipeToDebugLog(context.Background(), strings.NewReader("Authorization: Bearer abc.def\n"), "[deno stderr] ", r, func(line string) {
+		seen = append(seen, line)
+	})
+
+	if strings.Contains(buf.String(), "abc.def") {
+		t.Errorf("Expected the token to be redacted from the log, got '%s'", buf.String())
+	}
+	if !strings.Contains(buf.String(), "[deno stderr] Authorization: ***") {
+		t.Errorf("Expected the redacted line to be logged, got '%s'", buf.String())
+	}
+	if len(seen) != 1 || seen[0] != "Authorization: Bearer abc.def" {
+		t.Errorf("Expected onLine to receive the original line, got %v", seen)
+	}
+}
+
+func TestLogRedactor_Secrets(t *testing.T) {
+	r, err := newLogRedactor(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	r.addSecrets("hunter2", `pa"ss`, "")
+
+	got := r.Redact(`{"password":"hunter2","other":"pa\"ss"} hunter2`)
+	if got != `{"password":"***","other":"***"} ***` {
+		t.Errorf("Expected the secrets to be redacted, including when JSON encoded, got '%s'", got)
+	}
+}
+
+func TestDenoClient_AddSecrets_RedactsTraceLogs(t *testing.T) {
+	t.Setenv("DENO_TOFU_BRIDGE_TEST_MODE", "true")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	server := jsocket.New(t.Context(), serverReader, serverWriter, func(ctx context.Context, c *jsonrpc2.Conn) map[string]any {
+		return map[string]any{
+			"create": func(params CreateRequest) (map[string]any, error) {
+				return nil, &jsonrpc2.Error{Code: 1, Message: "bad password: hunter2"}
+			},
+		}
+	})
+	t.Cleanup(func() { _ = server.Close() })
+
+	// The client is wired up the same way start does, with every JSON-RPC message logged at trace level
+	c := NewDenoClient("deno", "script.ts", "", nil, nil, nil)
+	c.redactor, _ = newLogRedactor(nil)
+	c.Socket = jsocket.New(t.Context(), clientReader, clientWriter, func(ctx context.Context, c *jsonrpc2.Conn) map[string]any {
+		return map[string]any{}
+	}, jsonrpc2.LogMessages(&rpcLogger{ctx: t.Context(), redactor: c.redactor}))
+	t.Cleanup(func() { _ = c.Socket.Close() })
+
+	c.AddSecrets("hunter2")
+	err := c.Call(t.Context(), "create", &CreateRequest{
+		Props:          map[string]any{"user": "bob"},
+		WriteOnlyProps: map[string]any{"password": "hunter2"},
+	}, nil)
+	if err == nil {
+		t.Fatal("Expected the call to fail")
+	}
+
+	if !strings.Contains(buf.String(), "[TRACE]") || !strings.Contains(buf.String(), "bob") {
+		t.Fatalf("Expected the request to be logged at trace level, got '%s'", buf.String())
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("Expected the write-only prop to be redacted from the trace log, got '%s'", buf.String())
+	}
+	if got := c.Redact(err.Error()); strings.Contains(got, "hunter2") {
+		t.Errorf("Expected the write-only prop to be redacted from the error, got '%s'", got)
+	}
+}
@@ -0,0 +1,69 @@
+package deno
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ResourceID is the identifier of a resource as returned by a script.
+//
+// Scripts usually return a string, but resources with composite natural keys may return a structured id,
+// eg: {"region": "us-east-1", "name": "web"}. Terraform ids are strings, so a structured id is stored as its
+// compact JSON encoding, with object keys sorted, and is decoded again whenever it is sent back to the script.
+type ResourceID string
+
+// UnmarshalJSON accepts either a string or a structured id, a null id is empty.
+func (id *ResourceID) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*id = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = ResourceID(s)
+		return nil
+	}
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return fmt.Errorf("expected the resource id to be a string, an object or an array, got: %s", data)
+	}
+
+	// Round trip through a generic value to sort object keys, numbers are kept exactly as written
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	*id = ResourceID(encoded)
+	return nil
+}
+
+// MarshalJSON sends a structured id to the script decoded, and any other id as a string.
+func (id ResourceID) MarshalJSON() ([]byte, error) {
+	if id.IsStructured() {
+		return []byte(id), nil
+	}
+	return json.Marshal(string(id))
+}
+
+// IsStructured reports whether the id is the JSON encoding of an object or array, rather than a plain string.
+func (id ResourceID) IsStructured() bool {
+	return len(id) > 0 && (id[0] == '{' || id[0] == '[') && json.Valid([]byte(id))
+}
+
+// Decode unmarshals a structured id into v, eg: a struct holding the fields of a composite key.
+func (id ResourceID) Decode(v any) error {
+	if !id.IsStructured() {
+		return fmt.Errorf("the resource id %q is not structured", string(id))
+	}
+	return json.Unmarshal([]byte(id), v)
+}
@@ -0,0 +1,53 @@
+package deno
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestResourceID_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected ResourceID
+	}{
+		{"string", `"web"`, "web"},
+		{"null", `null`, ""},
+		{"object", `{"region": "us-east-1", "name": "web"}`, `{"name":"web","region":"us-east-1"}`},
+		{"array", `["us-east-1", 12345678901234567890]`, `["us-east-1",12345678901234567890]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id ResourceID
+			assert.NoError(t, json.Unmarshal([]byte(tt.json), &id))
+			assert.Equal(t, tt.expected, id)
+		})
+	}
+
+	var id ResourceID
+	assert.Error(t, json.Unmarshal([]byte(`42`), &id))
+}
+
+func TestResourceID_MarshalJSON(t *testing.T) {
+	encoded, err := json.Marshal(CreateReadRequest{ID: `{"name":"web","region":"us-east-1"}`})
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `"id":{"name":"web","region":"us-east-1"}`)
+
+	encoded, err = json.Marshal(CreateReadRequest{ID: "{not json"})
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `"id":"{not json"`)
+}
+
+func TestResourceID_Decode(t *testing.T) {
+	var key struct {
+		Region string `json:"region"`
+		Name   string `json:"name"`
+	}
+	assert.NoError(t, ResourceID(`{"name":"web","region":"us-east-1"}`).Decode(&key))
+	assert.Equal(t, "us-east-1", key.Region)
+	assert.Equal(t, "web", key.Name)
+
+	assert.Error(t, ResourceID("web").Decode(&key))
+}
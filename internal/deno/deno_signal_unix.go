@@ -0,0 +1,34 @@
+//go:build !windows
+
+package deno
+
+import (
+	"os"
+	"syscall"
+)
+
+// terminateProcess asks the process to exit gracefully by sending it SIGTERM.
+func terminateProcess(process *os.Process) error {
+	return process.Signal(syscall.SIGTERM)
+}
+
+// signalNames are the names of the signals commonly seen to end a Deno process.
+var signalNames = map[syscall.Signal]string{
+	syscall.SIGABRT: "SIGABRT",
+	syscall.SIGINT:  "SIGINT",
+	syscall.SIGKILL: "SIGKILL",
+	syscall.SIGSEGV: "SIGSEGV",
+	syscall.SIGTERM: "SIGTERM",
+}
+
+// exitSignal returns the name of the signal that ended the process, or "" when it exited by itself.
+func exitSignal(state *os.ProcessState) string {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+	if name, ok := signalNames[status.Signal()]; ok {
+		return name
+	}
+	return status.Signal().String()
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package deno
+
+import "os"
+
+// terminateProcess asks the process to exit.
+//
+// Windows has no equivalent of SIGTERM for console processes without a shared console,
+// so the process is killed immediately.
+func terminateProcess(process *os.Process) error {
+	return process.Kill()
+}
+
+// exitSignal returns the name of the signal that ended the process.
+//
+// Windows processes are not ended by signals, so this is always "".
+func exitSignal(_ *os.ProcessState) string {
+	return ""
+}
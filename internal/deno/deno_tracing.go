@@ -0,0 +1,96 @@
+package deno
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the instrumentation scope of every span emitted by the provider.
+const tracerName = "github.com/brad-jones/terraform-provider-denobridge"
+
+// Span attributes shared by the spans of the deno and provider packages.
+const (
+	// AttrScriptPath is the path of the script a span operates on.
+	AttrScriptPath = attribute.Key("denobridge.script.path")
+	// AttrOperation is the operation a span covers, eg: "create" or "read".
+	AttrOperation = attribute.Key("denobridge.operation")
+	// AttrDenoVersion is the version of Deno the script runs under, when the bridge library reports it.
+	AttrDenoVersion = attribute.Key("denobridge.deno.version")
+)
+
+// Tracer returns the tracer used for the spans emitted by the provider.
+//
+// Spans go to the tracer provider installed by StartTracing, until then, and when no OTLP endpoint is
+// configured, they are recorded by nothing and cost next to nothing.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// StartTracing exports the spans emitted by the provider over OTLP/HTTP, when an endpoint is configured by the
+// standard OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variables. Everything
+// else about the exporter, eg: headers, timeouts and TLS, is configured by the other standard OTEL_EXPORTER_OTLP_*
+// variables. Setting OTEL_SDK_DISABLED to "true" disables tracing even when an endpoint is configured.
+//
+// The returned func flushes any spans not yet exported and must be called before the process exits.
+// When tracing is not enabled it does nothing.
+func StartTracing(ctx context.Context, version string) (func(ctx context.Context) error, error) {
+	noop := func(ctx context.Context) error { return nil }
+	if !tracingEnabled() {
+		return noop, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return noop, fmt.Errorf("failed to create the OTLP trace exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName("terraform-provider-denobridge"),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return noop, fmt.Errorf("failed to describe the provider to the OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// tracingEnabled reports whether the environment configures an OTLP endpoint to export spans to.
+func tracingEnabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// EndSpan ends span, first marking it as failed with err when err is not nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startSpan starts a span around work done by the client on its script.
+func (c *DenoClient) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, AttrScriptPath.String(c.scriptPath))
+	if c.denoVersion != "" {
+		attrs = append(attrs, AttrDenoVersion.String(c.denoVersion))
+	}
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
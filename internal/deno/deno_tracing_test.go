@@ -0,0 +1,82 @@
+package deno
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans installs a tracer provider that records every span ended during the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestStartTracing_DisabledWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	previous := otel.GetTracerProvider()
+
+	stop, err := StartTracing(t.Context(), "dev")
+	assert.NoError(t, err)
+	assert.NoError(t, stop(t.Context()))
+	assert.Equal(t, previous, otel.GetTracerProvider())
+}
+
+func TestTracingEnabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://localhost:4318/v1/traces")
+	assert.True(t, tracingEnabled())
+
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	assert.False(t, tracingEnabled())
+}
+
+func TestDenoClient_Call_EmitsSpan(t *testing.T) {
+	recorder := recordSpans(t)
+	c := connectFakeScript(t, map[string]any{
+		"read": func() map[string]any {
+			return map[string]any{}
+		},
+	})
+	c.denoVersion = "2.5.0"
+
+	assert.NoError(t, c.Call(t.Context(), "read", nil, nil))
+	assert.Error(t, c.Call(t.Context(), "missing", nil, nil))
+
+	spans := recorder.Ended()
+	assert.Equal(t, 2, len(spans))
+	assert.Equal(t, "deno.call read", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	attrs := map[string]string{}
+	for _, attr := range spans[0].Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	assert.Equal(t, "script.ts", attrs[string(AttrScriptPath)])
+	assert.Equal(t, "2.5.0", attrs[string(AttrDenoVersion)])
+	assert.Equal(t, "read", attrs["rpc.method"])
+
+	assert.Equal(t, "deno.call missing", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
+
+func TestEndSpan_RecordsError(t *testing.T) {
+	recorder := recordSpans(t)
+	_, span := Tracer().Start(t.Context(), "work")
+	EndSpan(span, errors.New("boom"))
+
+	spans := recorder.Ended()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "boom", spans[0].Status().Description)
+	assert.Equal(t, 1, len(spans[0].Events()))
+}
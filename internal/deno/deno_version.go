@@ -0,0 +1,79 @@
+package deno
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// MinimumDenoVersion is the oldest Deno runtime the denobridge lib is known to work with.
+const MinimumDenoVersion = "2.0.0"
+
+// ProtocolVersion is the version of the JSON-RPC contract between the provider and the denobridge lib.
+// It is bumped whenever either side changes in a way the other can not handle, and is exchanged in the
+// health handshake so that incompatible combinations fail loudly instead of misbehaving.
+const ProtocolVersion = 1
+
+// codeProtocolVersionMismatch is the JSON-RPC error code the denobridge lib responds to health with
+// when it does not speak the provider's protocol version.
+const codeProtocolVersionMismatch = -32001
+
+// ErrProtocolVersionMismatch is returned by Start when a script's denobridge lib speaks a different
+// protocol version to the provider.
+var ErrProtocolVersionMismatch = errors.New("denobridge protocol version mismatch")
+
+// checkProtocolVersion returns an error if the protocol version reported by a script differs from ProtocolVersion.
+// Versions of the denobridge lib that predate protocol versioning report 0 and are let through.
+func checkProtocolVersion(version int) error {
+	if version == 0 || version == ProtocolVersion {
+		return nil
+	}
+	return fmt.Errorf(
+		"%w: the provider speaks version %d but the script's denobridge lib speaks version %d, upgrade whichever is older so that they match",
+		ErrProtocolVersionMismatch, ProtocolVersion, version,
+	)
+}
+
+// checkDenoVersion returns an error if the Deno runtime version reported by a script
+// is older than MinimumDenoVersion, so users get a clear message instead of an
+// obscure runtime error from a feature the lib relies on.
+//
+// Versions that can not be parsed are let through, the check is only a courtesy.
+func checkDenoVersion(version string) error {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil
+	}
+	if v.LessThan(semver.MustParse(MinimumDenoVersion)) {
+		return fmt.Errorf(
+			"deno %s is older than the minimum supported version %s, set deno_version to a newer release or upgrade the deno binary",
+			v, MinimumDenoVersion,
+		)
+	}
+	return nil
+}
+
+// BinaryVersion returns the version of the Deno binary at denoBinaryPath, eg: v2.1.4, by running it with --version.
+// It is used when the version can not be told from where the binary was downloaded to, eg: a Deno found on the PATH.
+func BinaryVersion(ctx context.Context, denoBinaryPath string) (string, error) {
+	output, err := exec.CommandContext(ctx, denoBinaryPath, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s --version: %w", denoBinaryPath, err)
+	}
+
+	// The first line names the runtime and its version, eg: deno 2.1.4 (stable, release, x86_64-unknown-linux-gnu)
+	firstLine, _, _ := strings.Cut(string(output), "\n")
+	fields := strings.Fields(firstLine)
+	if len(fields) < 2 || fields[0] != "deno" {
+		return "", fmt.Errorf("unexpected output from %s --version: %q", denoBinaryPath, firstLine)
+	}
+	v, err := semver.NewVersion(fields[1])
+	if err != nil {
+		return "", fmt.Errorf("unexpected output from %s --version: %q", denoBinaryPath, firstLine)
+	}
+	return "v" + v.String(), nil
+}
@@ -0,0 +1,85 @@
+package deno
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func TestCheckDenoVersion(t *testing.T) {
+	assert.NoError(t, checkDenoVersion("2.0.0"))
+	assert.NoError(t, checkDenoVersion("2.5.6"))
+	assert.NoError(t, checkDenoVersion("v2.1.4"))
+
+	assert.EqualError(t, checkDenoVersion("1.46.3"),
+		"deno 1.46.3 is older than the minimum supported version 2.0.0, set deno_version to a newer release or upgrade the deno binary",
+	)
+	assert.NoError(t, checkDenoVersion("not-a-version"))
+}
+
+func TestCheckProtocolVersion(t *testing.T) {
+	assert.NoError(t, checkProtocolVersion(ProtocolVersion))
+	assert.NoError(t, checkProtocolVersion(0))
+	assert.IsError(t, checkProtocolVersion(ProtocolVersion+1), ErrProtocolVersionMismatch)
+}
+
+func TestDenoClient_Handshake_LibReportsMismatch(t *testing.T) {
+	c := connectFakeScript(t, map[string]any{
+		"health": func() map[string]any {
+			return map[string]any{"ok": true, "denoVersion": "2.5.6", "protocolVersion": ProtocolVersion + 1}
+		},
+	})
+	err := c.handshake(t.Context())
+	assert.IsError(t, err, ErrProtocolVersionMismatch)
+}
+
+func TestDenoClient_Handshake_LibRejectsProvider(t *testing.T) {
+	c := connectFakeScript(t, map[string]any{
+		"health": func() (map[string]any, error) {
+			return nil, &jsonrpc2.Error{Code: codeProtocolVersionMismatch, Message: "the denobridge lib speaks protocol version 2"}
+		},
+	})
+	err := c.handshake(t.Context())
+	assert.IsError(t, err, ErrProtocolVersionMismatch)
+	assert.Contains(t, err.Error(), "the denobridge lib speaks protocol version 2")
+}
+
+func TestDenoClient_Handshake_Compatible(t *testing.T) {
+	c := connectFakeScript(t, map[string]any{
+		"health": func(params map[string]any) map[string]any {
+			return map[string]any{"ok": params["protocolVersion"] == float64(ProtocolVersion), "protocolVersion": ProtocolVersion}
+		},
+	})
+	assert.NoError(t, c.handshake(t.Context()))
+}
+
+func TestDenoClient_Handshake_DeclaredPermissions(t *testing.T) {
+	c := connectFakeScript(t, map[string]any{
+		"health": func() map[string]any {
+			return map[string]any{"ok": true, "protocolVersion": ProtocolVersion, "permissions": []string{"env", "net=example.com"}}
+		},
+	})
+	assert.NoError(t, c.handshake(t.Context()))
+	assert.Equal(t, []string{"env", "net=example.com"}, c.DeclaredPermissions())
+
+	undeclared := connectFakeScript(t, map[string]any{
+		"health": func() map[string]any {
+			return map[string]any{"ok": true, "protocolVersion": ProtocolVersion}
+		},
+	})
+	assert.NoError(t, undeclared.handshake(t.Context()))
+	assert.Zero(t, undeclared.DeclaredPermissions())
+}
+
+func TestBinaryVersion(t *testing.T) {
+	fakeDeno := writeFakeDeno(t, `echo "deno 2.1.4 (stable, release, x86_64-unknown-linux-gnu)"; echo "v8 13.0.245.12-rusty"`)
+	version, err := BinaryVersion(t.Context(), fakeDeno)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.1.4", version)
+
+	notDeno := writeFakeDeno(t, `echo "node v22.0.0"`)
+	_, err = BinaryVersion(t.Context(), notDeno)
+	assert.EqualError(t, err, fmt.Sprintf("unexpected output from %s --version: %q", notDeno, "node v22.0.0"))
+}
@@ -19,10 +19,10 @@ import (
 //   - dynVal: The Terraform Dynamic value to convert
 //
 // Returns a Go value of the appropriate type:
-//   - nil for null values
+//   - nil for null and unknown values, use ContainsUnknown to tell them apart
 //   - string for String values
 //   - bool for Bool values
-//   - float64 for Number values
+//   - int64 or *big.Int for integral Number values, float64 for all others
 //   - []any for List and Tuple values
 //   - map[string]any for Map and Object values
 //   - string representation for unknown types
@@ -30,6 +30,9 @@ func FromDynamic(dynVal types.Dynamic) any {
 	if dynVal.IsNull() || dynVal.IsUnderlyingValueNull() {
 		return nil
 	}
+	if dynVal.IsUnknown() || dynVal.IsUnderlyingValueUnknown() {
+		return nil
+	}
 
 	underlyingValue := dynVal.UnderlyingValue()
 
@@ -41,8 +44,7 @@ func FromDynamic(dynVal types.Dynamic) any {
 	case types.Number:
 		bigFloat := v.ValueBigFloat()
 		if bigFloat != nil {
-			f64, _ := bigFloat.Float64()
-			return f64
+			return fromBigFloat(bigFloat)
 		}
 		return nil
 	case types.List:
@@ -85,16 +87,16 @@ func FromDynamic(dynVal types.Dynamic) any {
 //   - in: The Terraform attr.Value to convert
 //
 // Returns a Go value of the appropriate type:
-//   - nil for null values
+//   - nil for null and unknown values
 //   - Recursively converts Dynamic values via FromDynamic
 //   - string for String values
 //   - bool for Bool values
-//   - float64 for Number values
+//   - int64 or *big.Int for integral Number values, float64 for all others
 //   - []any for List and Tuple values (with recursive element conversion)
 //   - map[string]any for Map and Object values (with recursive element conversion)
 //   - string representation for unknown types
 func FromValue(in attr.Value) any {
-	if in.IsNull() {
+	if in.IsNull() || in.IsUnknown() {
 		return nil
 	}
 
@@ -108,8 +110,7 @@ func FromValue(in attr.Value) any {
 	case types.Number:
 		bigFloat := v.ValueBigFloat()
 		if bigFloat != nil {
-			f64, _ := bigFloat.Float64()
-			return f64
+			return fromBigFloat(bigFloat)
 		}
 		return nil
 	case types.List:
@@ -145,6 +146,71 @@ func FromValue(in attr.Value) any {
 	}
 }
 
+// fromBigFloat converts a Terraform number to a native Go number. Integers are kept exact, as an int64 when they
+// fit or a *big.Int otherwise, so that large IDs and epoch timestamps do not lose precision by becoming a float64.
+func fromBigFloat(f *big.Float) any {
+	if f.IsInt() {
+		if i, accuracy := f.Int64(); accuracy == big.Exact {
+			return i
+		}
+		i, _ := f.Int(nil)
+		return i
+	}
+	f64, _ := f.Float64()
+	return f64
+}
+
+// ContainsUnknown reports whether a Terraform value is unknown or contains an unknown value at any depth.
+// During plan, values that reference the computed outputs of other resources are unknown until apply.
+func ContainsUnknown(in attr.Value) bool {
+	if in == nil || in.IsNull() {
+		return false
+	}
+	if in.IsUnknown() {
+		return true
+	}
+
+	switch v := in.(type) {
+	case types.Dynamic:
+		if v.IsUnderlyingValueUnknown() {
+			return true
+		}
+		if v.IsUnderlyingValueNull() {
+			return false
+		}
+		return ContainsUnknown(v.UnderlyingValue())
+	case types.List:
+		return anyUnknown(v.Elements())
+	case types.Set:
+		return anyUnknown(v.Elements())
+	case types.Tuple:
+		return anyUnknown(v.Elements())
+	case types.Map:
+		for _, elem := range v.Elements() {
+			if ContainsUnknown(elem) {
+				return true
+			}
+		}
+	case types.Object:
+		for _, attr := range v.Attributes() {
+			if ContainsUnknown(attr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// anyUnknown reports whether any of the given values contain an unknown value.
+func anyUnknown(values []attr.Value) bool {
+	for _, value := range values {
+		if ContainsUnknown(value) {
+			return true
+		}
+	}
+	return false
+}
+
 // ToDynamic converts a native Go value to a Terraform Dynamic type.
 // It handles nil values, pointer dereferencing, primitives, and complex types.
 //
@@ -152,30 +218,26 @@ func FromValue(in attr.Value) any {
 //   - value: The Go value to convert (supports any, but specific types are handled specially)
 //
 // Returns a types.Dynamic value:
-//   - types.DynamicNull() for nil values
-//   - Automatically dereferences pointers before conversion
+//   - types.DynamicNull() for nil values, including nil pointers
+//   - Automatically dereferences pointers before conversion, at every level of nesting
 //   - Converts string, bool, numeric types to appropriate Terraform types
-//   - Converts []any to types.List with Dynamic elements
-//   - Converts map[string]any to types.Object with Dynamic values
+//   - Converts []any, and any other slice or array, to types.List with Dynamic elements
+//   - Converts map[string]any, and any other map with string keys, to types.Object with Dynamic values
 //   - Falls back to string representation for unknown types
 //
-// Supported numeric types: float64, float32, int, int64, int32.
+// Supported numeric types: float64, float32, int, int64, int32, uint64, *big.Int and *big.Float.
+// Integers are converted exactly, rather than through a float64.
 func ToDynamic(value any) types.Dynamic {
+	value = derefAny(value)
 	if value == nil {
 		return types.DynamicNull()
 	}
 
-	// Dereference pointers
-	rv := reflect.ValueOf(value)
-	for rv.Kind() == reflect.Pointer {
-		if rv.IsNil() {
-			return types.DynamicNull()
-		}
-		rv = rv.Elem()
-	}
-	value = rv.Interface()
-
 	switch v := value.(type) {
+	case *big.Int:
+		return types.DynamicValue(types.NumberValue(new(big.Float).SetInt(v)))
+	case *big.Float:
+		return types.DynamicValue(types.NumberValue(v))
 	case string:
 		return types.DynamicValue(types.StringValue(v))
 	case bool:
@@ -187,13 +249,16 @@ func ToDynamic(value any) types.Dynamic {
 		numVal := types.NumberValue(big.NewFloat(float64(v)))
 		return types.DynamicValue(numVal)
 	case int:
-		numVal := types.NumberValue(big.NewFloat(float64(v)))
+		numVal := types.NumberValue(new(big.Float).SetInt64(int64(v)))
 		return types.DynamicValue(numVal)
 	case int64:
-		numVal := types.NumberValue(big.NewFloat(float64(v)))
+		numVal := types.NumberValue(new(big.Float).SetInt64(v))
 		return types.DynamicValue(numVal)
 	case int32:
-		numVal := types.NumberValue(big.NewFloat(float64(v)))
+		numVal := types.NumberValue(new(big.Float).SetInt64(int64(v)))
+		return types.DynamicValue(numVal)
+	case uint64:
+		numVal := types.NumberValue(new(big.Float).SetUint64(v))
 		return types.DynamicValue(numVal)
 	case []any:
 		elements := make([]attr.Value, len(v))
@@ -214,7 +279,45 @@ func ToDynamic(value any) types.Dynamic {
 		objVal, _ := types.ObjectValue(attrTypes, elements)
 		return types.DynamicValue(objVal)
 	default:
+		// Typed collections, eg: []string or map[string]*int, are converted element by element
+		rv := reflect.ValueOf(v)
+		switch {
+		case rv.Kind() == reflect.Slice, rv.Kind() == reflect.Array:
+			elements := make([]any, rv.Len())
+			for i := range elements {
+				elements[i] = rv.Index(i).Interface()
+			}
+			return ToDynamic(elements)
+		case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+			elements := make(map[string]any, rv.Len())
+			for iter := rv.MapRange(); iter.Next(); {
+				elements[iter.Key().String()] = iter.Value().Interface()
+			}
+			return ToDynamic(elements)
+		}
+
 		// Fallback: convert to string
 		return types.DynamicValue(types.StringValue(fmt.Sprintf("%+v", v)))
 	}
 }
+
+// derefAny unwraps a value that may be behind any number of pointers or interfaces, eg: an optional value as
+// decoded from a JSON-RPC response, returning nil when any of them is nil. Arbitrary precision numbers are
+// returned as they are, rather than the struct they point to.
+func derefAny(value any) any {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		switch rv.Interface().(type) {
+		case *big.Int, *big.Float:
+			return rv.Interface()
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
+}
@@ -1,6 +1,7 @@
 package dynamic
 
 import (
+	"fmt"
 	"math/big"
 	"reflect"
 	"testing"
@@ -19,6 +20,49 @@ func TestFromDynamic_Null(t *testing.T) {
 	}
 }
 
+// TestFromDynamic_Unknown tests conversion of unknown dynamic values.
+func TestFromDynamic_Unknown(t *testing.T) {
+	if result := FromDynamic(types.DynamicUnknown()); result != nil {
+		t.Errorf("Expected nil for unknown dynamic value, got %v", result)
+	}
+	if result := FromDynamic(types.DynamicValue(types.StringUnknown())); result != nil {
+		t.Errorf("Expected nil for unknown underlying value, got %v", result)
+	}
+}
+
+// TestContainsUnknown tests detection of unknown values nested at any depth.
+func TestContainsUnknown(t *testing.T) {
+	nested, _ := types.ObjectValue(
+		map[string]attr.Type{"name": types.StringType, "tags": types.ListType{ElemType: types.StringType}},
+		map[string]attr.Value{
+			"name": types.StringValue("web"),
+			"tags": types.ListValueMust(types.StringType, []attr.Value{types.StringValue("a"), types.StringUnknown()}),
+		},
+	)
+	known, _ := types.ObjectValue(
+		map[string]attr.Type{"name": types.StringType},
+		map[string]attr.Value{"name": types.StringValue("web")},
+	)
+
+	tests := []struct {
+		name     string
+		value    attr.Value
+		expected bool
+	}{
+		{"null", types.DynamicNull(), false},
+		{"unknown", types.DynamicUnknown(), true},
+		{"known", types.DynamicValue(known), false},
+		{"nested unknown", types.DynamicValue(nested), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContainsUnknown(tt.value); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
 // TestFromDynamic_String tests conversion of string dynamic value.
 func TestFromDynamic_String(t *testing.T) {
 	stringVal := types.StringValue("test")
@@ -55,6 +99,37 @@ func TestFromDynamic_Number(t *testing.T) {
 	}
 }
 
+// TestFromDynamic_Integers tests that integral numbers are converted exactly, including beyond 2^53.
+func TestFromDynamic_Integers(t *testing.T) {
+	beyondInt64, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	tests := []struct {
+		name  string
+		value *big.Float
+		want  any
+	}{
+		{name: "small", value: big.NewFloat(42), want: int64(42)},
+		{name: "negative", value: big.NewFloat(-17), want: int64(-17)},
+		{name: "beyond 2^53", value: new(big.Float).SetInt64(9007199254740993), want: int64(9007199254740993)},
+		{name: "negative beyond 2^53", value: new(big.Float).SetInt64(-9007199254740993), want: int64(-9007199254740993)},
+		{name: "beyond int64", value: new(big.Float).SetInt(beyondInt64), want: beyondInt64},
+		{name: "fractional", value: big.NewFloat(-2.5), want: -2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FromDynamic(types.DynamicValue(types.NumberValue(tt.value)))
+			if want, ok := tt.want.(*big.Int); ok {
+				if got, ok := result.(*big.Int); !ok || got.Cmp(want) != 0 {
+					t.Errorf("Expected %v, got %v (%T)", want, result, result)
+				}
+				return
+			}
+			if result != tt.want {
+				t.Errorf("Expected %v (%T), got %v (%T)", tt.want, tt.want, result, result)
+			}
+		})
+	}
+}
+
 // TestFromDynamic_List tests conversion of list dynamic value.
 func TestFromDynamic_List(t *testing.T) {
 	listVal, _ := types.ListValue(types.StringType, []attr.Value{
@@ -121,7 +196,7 @@ func TestFromDynamic_Object(t *testing.T) {
 	if objResult["name"] != "John" {
 		t.Errorf("Expected 'John' for name, got %v", objResult["name"])
 	}
-	if age, ok := objResult["age"].(float64); !ok || age != 30 {
+	if age, ok := objResult["age"].(int64); !ok || age != 30 {
 		t.Errorf("Expected 30 for age, got %v", objResult["age"])
 	}
 }
@@ -178,6 +253,26 @@ func TestToDynamic_Float(t *testing.T) {
 	}
 }
 
+// TestToDynamic_Integers tests that integers round trip exactly, rather than through a float64.
+func TestToDynamic_Integers(t *testing.T) {
+	beyondInt64, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	for _, value := range []any{int64(9007199254740993), int64(-9007199254740993), uint64(18446744073709551615), beyondInt64} {
+		result := ToDynamic(value)
+		numVal, ok := result.UnderlyingValue().(types.Number)
+		if !ok {
+			t.Fatalf("Expected types.Number, got %T", result.UnderlyingValue())
+		}
+		if got := numVal.ValueBigFloat().Text('f', 0); got != fmt.Sprint(value) {
+			t.Errorf("Expected %v, got %s", value, got)
+		}
+
+		roundTripped := FromDynamic(result)
+		if fmt.Sprint(roundTripped) != fmt.Sprint(value) {
+			t.Errorf("Expected %v to round trip, got %v", value, roundTripped)
+		}
+	}
+}
+
 // TestToDynamic_Map tests conversion of map to dynamic value.
 func TestToDynamic_Map(t *testing.T) {
 	input := map[string]any{
@@ -211,3 +306,49 @@ func TestToDynamic_Slice(t *testing.T) {
 		t.Errorf("Expected types.List, got %T", underlying)
 	}
 }
+
+// TestToDynamic_NestedNils tests that nil values, including typed nil pointers, are null at any depth.
+func TestToDynamic_NestedNils(t *testing.T) {
+	name := "a"
+	var missing *string
+	var missingAny *any
+	input := map[string]any{
+		"foo":    nil,
+		"bar":    &name,
+		"baz":    missing,
+		"qux":    missingAny,
+		"list":   []any{nil, missing, &name},
+		"nested": map[string]any{"inner": missing},
+	}
+
+	expected := map[string]any{
+		"foo":    nil,
+		"bar":    "a",
+		"baz":    nil,
+		"qux":    nil,
+		"list":   []any{nil, nil, "a"},
+		"nested": map[string]any{"inner": nil},
+	}
+	if result := FromDynamic(ToDynamic(input)); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+// TestToDynamic_TypedCollections tests that typed slices and maps are converted element by element.
+func TestToDynamic_TypedCollections(t *testing.T) {
+	port := 443
+	input := map[string]any{
+		"names": []string{"a", "b"},
+		"ports": map[string]*int{"https": &port, "http": nil},
+		"pairs": [2]bool{true, false},
+	}
+
+	expected := map[string]any{
+		"names": []any{"a", "b"},
+		"ports": map[string]any{"https": int64(443), "http": nil},
+		"pairs": []any{true, false},
+	}
+	if result := FromDynamic(ToDynamic(input)); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
@@ -0,0 +1,43 @@
+package dynamic
+
+// MergeState overlays next onto prior, keeping any keys of prior that next does not return.
+//
+// Objects are merged recursively, any other value in next (including null) replaces the value in prior.
+//
+// Examples:
+//   - prior {"a": 1, "b": 2} with next {"b": 3} → {"a": 1, "b": 3}
+//   - prior {"conn": {"host": "db", "port": 5432}} with next {"conn": {"port": 6543}}
+//     → {"conn": {"host": "db", "port": 6543}}
+//
+// Either value may be a pointer as decoded from a JSON-RPC response. A null next returns prior unchanged.
+func MergeState(prior, next any) any {
+	prior = derefAny(prior)
+	next = derefAny(next)
+	if next == nil {
+		return prior
+	}
+
+	priorObj, ok := prior.(map[string]any)
+	if !ok {
+		return next
+	}
+	nextObj, ok := next.(map[string]any)
+	if !ok {
+		return next
+	}
+
+	merged := make(map[string]any, len(priorObj)+len(nextObj))
+	for key, value := range priorObj {
+		merged[key] = value
+	}
+	for key, value := range nextObj {
+		if priorValue, found := merged[key]; found {
+			if _, isObj := derefAny(value).(map[string]any); isObj {
+				merged[key] = MergeState(priorValue, value)
+				continue
+			}
+		}
+		merged[key] = value
+	}
+	return merged
+}
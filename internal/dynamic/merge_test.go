@@ -0,0 +1,49 @@
+package dynamic
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMergeState_KeepsOmittedKeys tests that keys the script did not return are kept.
+func TestMergeState_KeepsOmittedKeys(t *testing.T) {
+	merged := MergeState(
+		map[string]any{"a": 1.0, "b": 2.0},
+		map[string]any{"b": 3.0, "c": 4.0},
+	)
+
+	if expected := map[string]any{"a": 1.0, "b": 3.0, "c": 4.0}; !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected %v, got %v", expected, merged)
+	}
+}
+
+// TestMergeState_Nested tests that nested objects are merged rather than replaced.
+func TestMergeState_Nested(t *testing.T) {
+	var next any = map[string]any{"conn": map[string]any{"port": 6543.0}, "tags": []any{"x"}}
+	merged := MergeState(
+		map[string]any{"conn": map[string]any{"host": "db", "port": 5432.0}, "tags": []any{"a", "b"}},
+		&next,
+	)
+
+	expected := map[string]any{"conn": map[string]any{"host": "db", "port": 6543.0}, "tags": []any{"x"}}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected %v, got %v", expected, merged)
+	}
+}
+
+// TestMergeState_Null tests merging with missing values on either side.
+func TestMergeState_Null(t *testing.T) {
+	prior := map[string]any{"a": 1.0}
+	if merged := MergeState(prior, nil); !reflect.DeepEqual(merged, prior) {
+		t.Errorf("Expected a null next to keep the prior state, got %v", merged)
+	}
+
+	next := map[string]any{"b": 2.0}
+	if merged := MergeState(nil, next); !reflect.DeepEqual(merged, next) {
+		t.Errorf("Expected a null prior to use the next state, got %v", merged)
+	}
+
+	if merged := MergeState(prior, map[string]any{"a": nil}); !reflect.DeepEqual(merged, map[string]any{"a": nil}) {
+		t.Errorf("Expected an explicit null to replace the prior value, got %v", merged)
+	}
+}
@@ -0,0 +1,71 @@
+package dynamic
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NonFiniteError reports a number that is NaN or infinite, which JSON can not represent.
+// Terraform numbers are arbitrary precision, so a fractional one too large for a float64 is converted to an infinity.
+type NonFiniteError struct {
+	// PropPath is the path to the number, numeric segments are list indexes
+	PropPath []string
+	// Value is the offending number
+	Value float64
+}
+
+// Error implements the error interface.
+func (e *NonFiniteError) Error() string {
+	return fmt.Sprintf("the number at %s is %v, which can not be represented in JSON", strings.Join(e.PropPath, "."), e.Value)
+}
+
+// ReplaceNonFinite walks value, which is as returned by FromDynamic, looking for numbers that are NaN or infinite.
+// When toNull is true each one is replaced with nil, otherwise a *NonFiniteError is returned for the first one found.
+// Object keys are walked in sorted order so the same number is always reported first.
+//
+// Maps and slices are modified in place.
+func ReplaceNonFinite(value any, toNull bool) (any, error) {
+	return replaceNonFinite(value, nil, toNull)
+}
+
+// replaceNonFinite does the work of ReplaceNonFinite, propPath is the path to value.
+func replaceNonFinite(value any, propPath []string, toNull bool) (any, error) {
+	switch v := value.(type) {
+	case float64:
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			return v, nil
+		}
+		if toNull {
+			return nil, nil
+		}
+		return nil, &NonFiniteError{PropPath: slices.Clone(propPath), Value: v}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			replaced, err := replaceNonFinite(v[key], append(propPath, key), toNull)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = replaced
+		}
+		return v, nil
+	case []any:
+		for i, item := range v {
+			replaced, err := replaceNonFinite(item, append(propPath, strconv.Itoa(i)), toNull)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = replaced
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
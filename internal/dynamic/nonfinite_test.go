@@ -0,0 +1,95 @@
+package dynamic
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// TestReplaceNonFinite_Error tests that the first NaN or infinite number is reported with its path.
+func TestReplaceNonFinite_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		wantPath []string
+	}{
+		{name: "top level", value: math.NaN(), wantPath: nil},
+		{name: "object", value: map[string]any{"size": math.Inf(1)}, wantPath: []string{"size"}},
+		{name: "list", value: map[string]any{"weights": []any{1.0, math.Inf(-1)}}, wantPath: []string{"weights", "1"}},
+		{name: "sorted keys", value: map[string]any{"b": math.NaN(), "a": map[string]any{"c": math.Inf(1)}}, wantPath: []string{"a", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReplaceNonFinite(tt.value, false)
+			var nonFinite *NonFiniteError
+			if !errors.As(err, &nonFinite) {
+				t.Fatalf("Expected a NonFiniteError, got %v", err)
+			}
+			if !reflect.DeepEqual(nonFinite.PropPath, tt.wantPath) {
+				t.Errorf("Expected path %v, got %v", tt.wantPath, nonFinite.PropPath)
+			}
+		})
+	}
+}
+
+// TestReplaceNonFinite_ToNull tests that NaN and infinite numbers are replaced with nil when asked.
+func TestReplaceNonFinite_ToNull(t *testing.T) {
+	value := map[string]any{
+		"count":   2.0,
+		"ratio":   math.NaN(),
+		"limits":  []any{math.Inf(1), 3.0, math.Inf(-1)},
+		"name":    "test",
+		"enabled": true,
+	}
+
+	result, err := ReplaceNonFinite(value, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]any{
+		"count":   2.0,
+		"ratio":   nil,
+		"limits":  []any{nil, 3.0, nil},
+		"name":    "test",
+		"enabled": true,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+// TestReplaceNonFinite_Finite tests that values without NaN or infinite numbers are returned unchanged.
+func TestReplaceNonFinite_Finite(t *testing.T) {
+	value := map[string]any{"count": 2.0, "tags": []any{"a", nil}}
+	result, err := ReplaceNonFinite(value, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, map[string]any{"count": 2.0, "tags": []any{"a", nil}}) {
+		t.Errorf("Expected the value to be unchanged, got %v", result)
+	}
+}
+
+// TestReplaceNonFinite_FromDynamic tests that a fractional Terraform number too large for a float64 is caught.
+func TestReplaceNonFinite_FromDynamic(t *testing.T) {
+	huge, _, err := big.ParseFloat("1"+strings.Repeat("0", 400)+".5", 10, 2048, big.ToNearestEven)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dynVal := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{"size": types.NumberType},
+		map[string]attr.Value{"size": types.NumberValue(huge)},
+	))
+
+	_, err = ReplaceNonFinite(FromDynamic(dynVal), false)
+	if err == nil || err.Error() != "the number at size is +Inf, which can not be represented in JSON" {
+		t.Errorf("Expected an error naming the size prop, got %v", err)
+	}
+}
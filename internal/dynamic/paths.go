@@ -0,0 +1,65 @@
+package dynamic
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ResolvePaths makes the relative file paths found at each of the given keys within state absolute,
+// by joining them to baseDir. Nested keys are separated by dots, eg: "output.path".
+//
+// Examples:
+//   - state {"file": "./out.txt"} with ["file"] and baseDir "/work"
+//     → state {"file": "/work/out.txt"}
+//   - state {"artifacts": {"files": ["a.zip", "/tmp/b.zip"]}} with ["artifacts.files"] and baseDir "/work"
+//     → state {"artifacts": {"files": ["/work/a.zip", "/tmp/b.zip"]}}
+//
+// A key may hold a single path or a list of paths, anything else is left as is, as are keys that are not
+// found in state. The state is modified in place, it may be a pointer as decoded from a JSON-RPC response.
+//
+// Returns the resulting state.
+func ResolvePaths(state any, keys []string, baseDir string) any {
+	state = derefAny(state)
+
+	for _, key := range keys {
+		propPath := strings.Split(key, ".")
+
+		// Locate the parent object of the value in state
+		parent, ok := state.(map[string]any)
+		for _, k := range propPath[:len(propPath)-1] {
+			if !ok {
+				break
+			}
+			parent, ok = parent[k].(map[string]any)
+		}
+		if !ok {
+			continue
+		}
+		last := propPath[len(propPath)-1]
+		value, found := parent[last]
+		if !found {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			parent[last] = resolvePath(v, baseDir)
+		case []any:
+			for i, item := range v {
+				if s, ok := item.(string); ok {
+					v[i] = resolvePath(s, baseDir)
+				}
+			}
+		}
+	}
+
+	return state
+}
+
+// resolvePath joins a relative path to baseDir, absolute and empty paths are returned unchanged.
+func resolvePath(p, baseDir string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(baseDir, p)
+}
@@ -0,0 +1,40 @@
+package dynamic
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestResolvePaths tests making relative paths in state absolute.
+func TestResolvePaths(t *testing.T) {
+	baseDir := filepath.Join(t.TempDir(), "work")
+	absPath := filepath.Join(t.TempDir(), "b.zip")
+	var state any = map[string]any{
+		"file":      "./out.txt",
+		"absolute":  absPath,
+		"untouched": "relative.txt",
+		"artifacts": map[string]any{"files": []any{"a.zip", absPath, 1.0}},
+		"count":     2.0,
+	}
+
+	resolved := ResolvePaths(&state, []string{"file", "absolute", "artifacts.files", "count", "missing.key"}, baseDir)
+
+	expected := map[string]any{
+		"file":      filepath.Join(baseDir, "out.txt"),
+		"absolute":  absPath,
+		"untouched": "relative.txt",
+		"artifacts": map[string]any{"files": []any{filepath.Join(baseDir, "a.zip"), absPath, 1.0}},
+		"count":     2.0,
+	}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("Expected state %v, got %v", expected, resolved)
+	}
+}
+
+// TestResolvePaths_Nil tests resolving paths in a missing state.
+func TestResolvePaths_Nil(t *testing.T) {
+	if resolved := ResolvePaths(nil, []string{"file"}, "/work"); resolved != nil {
+		t.Errorf("Expected nil, got %v", resolved)
+	}
+}
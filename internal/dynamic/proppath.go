@@ -47,3 +47,35 @@ func PropPathToPath(propPath *[]string) path.Path {
 
 	return p
 }
+
+// ValueAt returns the value found by following propPath into value, which is as returned by FromDynamic.
+// Numeric segments index into lists, all other segments are object keys.
+//
+// Examples:
+//   - {"network": {"cidr": "10.0.0.0/16"}} with ["network", "cidr"] → "10.0.0.0/16", true
+//   - {"subnets": ["a", "b"]} with ["subnets", "1"] → "b", true
+//   - {"subnets": ["a", "b"]} with ["subnets", "2"] → nil, false
+//
+// Returns false when the path does not exist.
+func ValueAt(value any, propPath []string) (any, bool) {
+	value = derefAny(value)
+	for _, segment := range propPath {
+		switch v := value.(type) {
+		case map[string]any:
+			item, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = item
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			value = v[idx]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
@@ -1,6 +1,7 @@
 package dynamic
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -152,3 +153,37 @@ func TestPropPathToPath_RealWorldScenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestValueAt(t *testing.T) {
+	value := map[string]any{
+		"network": map[string]any{"cidr": "10.0.0.0/16"},
+		"subnets": []any{"a", "b"},
+	}
+
+	tests := []struct {
+		name     string
+		propPath []string
+		expected any
+		found    bool
+	}{
+		{"root", nil, value, true},
+		{"nested key", []string{"network", "cidr"}, "10.0.0.0/16", true},
+		{"list index", []string{"subnets", "1"}, "b", true},
+		{"missing key", []string{"network", "name"}, nil, false},
+		{"index out of range", []string{"subnets", "2"}, nil, false},
+		{"key into list", []string{"subnets", "name"}, nil, false},
+		{"key into string", []string{"network", "cidr", "x"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, found := ValueAt(value, tt.propPath)
+			if found != tt.found {
+				t.Fatalf("Expected found %v, got %v", tt.found, found)
+			}
+			if found && fmt.Sprint(result) != fmt.Sprint(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
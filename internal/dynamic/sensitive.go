@@ -0,0 +1,66 @@
+package dynamic
+
+// MoveSensitivePaths moves the values found at each path within state into the same path
+// within sensitiveState, creating any intermediate objects along the way. This allows a
+// script to mark individual nested values as sensitive instead of the whole state.
+//
+// Examples:
+//   - state {"host": "db", "password": "x"} with [["password"]]
+//     → state {"host": "db"}, sensitiveState {"password": "x"}
+//   - state {"conn": {"url": "x", "port": 5432}} with [["conn", "url"]]
+//     → state {"conn": {"port": 5432}}, sensitiveState {"conn": {"url": "x"}}
+//
+// Only object keys can be traversed. Paths that do not resolve to a value in state are ignored.
+// The state is modified in place, both values may be pointers as decoded from a JSON-RPC response.
+//
+// Returns the resulting state and sensitive state.
+func MoveSensitivePaths(state, sensitiveState any, paths [][]string) (any, any) {
+	state = derefAny(state)
+	sensitiveState = derefAny(sensitiveState)
+
+	for _, propPath := range paths {
+		if len(propPath) == 0 {
+			continue
+		}
+
+		// Locate the parent object of the value in state
+		parent, ok := state.(map[string]any)
+		for _, key := range propPath[:len(propPath)-1] {
+			if !ok {
+				break
+			}
+			parent, ok = parent[key].(map[string]any)
+		}
+		if !ok {
+			continue
+		}
+		key := propPath[len(propPath)-1]
+		value, found := parent[key]
+		if !found {
+			continue
+		}
+
+		// Create the same parent object within the sensitive state
+		if sensitiveState == nil {
+			sensitiveState = map[string]any{}
+		}
+		target, ok := sensitiveState.(map[string]any)
+		for _, k := range propPath[:len(propPath)-1] {
+			if !ok {
+				break
+			}
+			if _, exists := target[k]; !exists {
+				target[k] = map[string]any{}
+			}
+			target, ok = target[k].(map[string]any)
+		}
+		if !ok {
+			continue
+		}
+
+		target[key] = value
+		delete(parent, key)
+	}
+
+	return state, sensitiveState
+}
@@ -0,0 +1,56 @@
+package dynamic
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMoveSensitivePaths_TopLevel tests moving a top level value into the sensitive state.
+func TestMoveSensitivePaths_TopLevel(t *testing.T) {
+	state, sensitive := MoveSensitivePaths(
+		map[string]any{"host": "db", "password": "hunter2"},
+		nil,
+		[][]string{{"password"}},
+	)
+
+	if expected := map[string]any{"host": "db"}; !reflect.DeepEqual(state, expected) {
+		t.Errorf("Expected state %v, got %v", expected, state)
+	}
+	if expected := map[string]any{"password": "hunter2"}; !reflect.DeepEqual(sensitive, expected) {
+		t.Errorf("Expected sensitive state %v, got %v", expected, sensitive)
+	}
+}
+
+// TestMoveSensitivePaths_Nested tests moving a nested value into an existing sensitive state.
+func TestMoveSensitivePaths_Nested(t *testing.T) {
+	var existing any = map[string]any{"token": "abc"}
+	state, sensitive := MoveSensitivePaths(
+		map[string]any{"conn": map[string]any{"url": "postgres://x", "port": 5432.0}},
+		&existing,
+		[][]string{{"conn", "url"}},
+	)
+
+	if expected := map[string]any{"conn": map[string]any{"port": 5432.0}}; !reflect.DeepEqual(state, expected) {
+		t.Errorf("Expected state %v, got %v", expected, state)
+	}
+	expected := map[string]any{"token": "abc", "conn": map[string]any{"url": "postgres://x"}}
+	if !reflect.DeepEqual(sensitive, expected) {
+		t.Errorf("Expected sensitive state %v, got %v", expected, sensitive)
+	}
+}
+
+// TestMoveSensitivePaths_Unresolvable tests that paths which do not resolve to a value are ignored.
+func TestMoveSensitivePaths_Unresolvable(t *testing.T) {
+	state, sensitive := MoveSensitivePaths(
+		map[string]any{"list": []any{"a"}, "name": "x"},
+		nil,
+		[][]string{{"missing"}, {"list", "0"}, {"name", "nested"}, {}},
+	)
+
+	if expected := map[string]any{"list": []any{"a"}, "name": "x"}; !reflect.DeepEqual(state, expected) {
+		t.Errorf("Expected state %v, got %v", expected, state)
+	}
+	if sensitive != nil {
+		t.Errorf("Expected nil sensitive state, got %v", sensitive)
+	}
+}
@@ -0,0 +1,335 @@
+package dynamic
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// ToTypedDynamic converts a native Go value to a Terraform Dynamic type, like ToDynamic, except that the value found
+// at each of typedPaths is converted to a collection with a single element type. A list becomes a Terraform list and
+// an object becomes a Terraform map, rather than a list of dynamic values and an object, so that HCL knows the type
+// of every element, eg: when iterating over them with for_each.
+//
+// Examples:
+//   - {"items": [{"name": "a", "size": 1}, {"name": "b"}]} with [["items"]]
+//     → items is a list(object({name = string, size = number})), with a null size for b
+//   - {"ports": {"http": 80, "https": 443}} with [["ports"]]
+//     → ports is a map(number)
+//
+// Within a typed collection objects stay objects, lists become lists and every element must have the same type.
+// Objects with different keys are given the keys of all of them, missing values are null. A value whose type can not
+// be told, because it is only ever null, is typed as a string.
+//
+// Returns an error naming the first element that does not match the type of the elements before it, or a typed
+// path that holds something other than a list or an object. Typed paths that are not found are ignored.
+func ToTypedDynamic(value any, typedPaths [][]string) (types.Dynamic, error) {
+	if len(typedPaths) == 0 {
+		return ToDynamic(value), nil
+	}
+	return toTypedDynamic(derefAny(value), nil, typedPaths)
+}
+
+// toTypedDynamic does the work of ToTypedDynamic, propPath is the path to value.
+func toTypedDynamic(value any, propPath []string, typedPaths [][]string) (types.Dynamic, error) {
+	if slices.ContainsFunc(typedPaths, func(p []string) bool { return slices.Equal(p, propPath) }) {
+		return toTypedCollection(value, propPath)
+	}
+
+	switch v := value.(type) {
+	case []any:
+		elements := make([]attr.Value, len(v))
+		for i, elem := range v {
+			dynValue, err := toTypedDynamic(derefAny(elem), append(slices.Clone(propPath), strconv.Itoa(i)), typedPaths)
+			if err != nil {
+				return types.DynamicNull(), err
+			}
+			elements[i] = dynValue
+		}
+		listVal, _ := types.ListValue(types.DynamicType, elements)
+		return types.DynamicValue(listVal), nil
+	case map[string]any:
+		elements := make(map[string]attr.Value, len(v))
+		attrTypes := make(map[string]attr.Type, len(v))
+		for k, elem := range v {
+			dynValue, err := toTypedDynamic(derefAny(elem), append(slices.Clone(propPath), k), typedPaths)
+			if err != nil {
+				return types.DynamicNull(), err
+			}
+			elements[k] = dynValue
+			attrTypes[k] = types.DynamicType
+		}
+		objVal, _ := types.ObjectValue(attrTypes, elements)
+		return types.DynamicValue(objVal), nil
+	default:
+		return ToDynamic(value), nil
+	}
+}
+
+// toTypedCollection converts the list or object found at a typed path to a Terraform list or map.
+func toTypedCollection(value any, propPath []string) (types.Dynamic, error) {
+	var collectionType attr.Type
+	switch v := value.(type) {
+	case nil:
+		return types.DynamicNull(), nil
+	case []any:
+		elemType, err := inferElementType(v, listIndexes(len(v)), propPath)
+		if err != nil {
+			return types.DynamicNull(), err
+		}
+		collectionType = types.ListType{ElemType: concreteType(elemType)}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		elems := make([]any, len(keys))
+		for i, key := range keys {
+			elems[i] = v[key]
+		}
+		elemType, err := inferElementType(elems, keys, propPath)
+		if err != nil {
+			return types.DynamicNull(), err
+		}
+		collectionType = types.MapType{ElemType: concreteType(elemType)}
+	default:
+		return types.DynamicNull(), fmt.Errorf("the value at %s is typed, so must be a list or an object, got a %s", strings.Join(propPath, "."), describeTyped(inferTypeOf(value)))
+	}
+
+	typed, err := toTypedValue(value, collectionType)
+	if err != nil {
+		return types.DynamicNull(), fmt.Errorf("failed to convert the typed value at %s: %w", strings.Join(propPath, "."), err)
+	}
+	return types.DynamicValue(typed), nil
+}
+
+// inferElementType returns the single type of every element, or an error naming the first element that differs.
+// Each element is found at the path segment of the same index, its list index or map key.
+// The result is nil when every element is null, see concreteType.
+func inferElementType(elems []any, segments []string, propPath []string) (attr.Type, error) {
+	var elemType attr.Type
+	for i, elem := range elems {
+		elemPath := append(slices.Clone(propPath), segments[i])
+		t, err := inferType(derefAny(elem), elemPath)
+		if err != nil {
+			return nil, err
+		}
+		unified, ok := unifyTypes(elemType, t)
+		if !ok {
+			if describeTyped(t) != describeTyped(elemType) {
+				return nil, fmt.Errorf("the elements of %s must all have the same type, %s is a %s but the elements before it are a %s", strings.Join(propPath, "."), strings.Join(elemPath, "."), describeTyped(t), describeTyped(elemType))
+			}
+			return nil, fmt.Errorf("the elements of %s must all have the same type, %s holds values of a different type to the elements before it", strings.Join(propPath, "."), strings.Join(elemPath, "."))
+		}
+		elemType = unified
+	}
+	return elemType, nil
+}
+
+// listIndexes returns the path segments of the elements of a list of length n.
+func listIndexes(n int) []string {
+	segments := make([]string, n)
+	for i := range segments {
+		segments[i] = strconv.Itoa(i)
+	}
+	return segments
+}
+
+// inferType returns the Terraform type of a value within a typed collection, nil when the value is null.
+func inferType(value any, propPath []string) (attr.Type, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		elemType, err := inferElementType(v, listIndexes(len(v)), propPath)
+		if err != nil {
+			return nil, err
+		}
+		return types.ListType{ElemType: elemType}, nil
+	case map[string]any:
+		attrTypes := make(map[string]attr.Type, len(v))
+		for key, item := range v {
+			t, err := inferType(derefAny(item), append(slices.Clone(propPath), key))
+			if err != nil {
+				return nil, err
+			}
+			attrTypes[key] = t
+		}
+		return types.ObjectType{AttrTypes: attrTypes}, nil
+	default:
+		return inferTypeOf(value), nil
+	}
+}
+
+// inferTypeOf returns the Terraform type of a scalar value.
+func inferTypeOf(value any) attr.Type {
+	switch ToDynamic(value).UnderlyingValue().(type) {
+	case types.Bool:
+		return types.BoolType
+	case types.Number:
+		return types.NumberType
+	default:
+		return types.StringType
+	}
+}
+
+// unifyTypes returns the type that both a and b can be converted to, where nil is the type of a null value.
+// Objects are given the attributes of both, which are unified in turn.
+func unifyTypes(a, b attr.Type) (attr.Type, bool) {
+	if a == nil {
+		return b, true
+	}
+	if b == nil {
+		return a, true
+	}
+	switch at := a.(type) {
+	case basetypes.ListType:
+		bt, ok := b.(basetypes.ListType)
+		if !ok {
+			return nil, false
+		}
+		elemType, ok := unifyTypes(at.ElemType, bt.ElemType)
+		return types.ListType{ElemType: elemType}, ok
+	case basetypes.ObjectType:
+		bt, ok := b.(basetypes.ObjectType)
+		if !ok {
+			return nil, false
+		}
+		attrTypes := make(map[string]attr.Type, len(at.AttrTypes))
+		for key, t := range at.AttrTypes {
+			attrTypes[key] = t
+		}
+		for key, t := range bt.AttrTypes {
+			unified, ok := unifyTypes(attrTypes[key], t)
+			if !ok {
+				return nil, false
+			}
+			attrTypes[key] = unified
+		}
+		return types.ObjectType{AttrTypes: attrTypes}, true
+	default:
+		return a, a.Equal(b)
+	}
+}
+
+// concreteType replaces the nil types left by values that are only ever null with a string.
+func concreteType(t attr.Type) attr.Type {
+	switch t := t.(type) {
+	case nil:
+		return types.StringType
+	case basetypes.ListType:
+		return types.ListType{ElemType: concreteType(t.ElemType)}
+	case basetypes.MapType:
+		return types.MapType{ElemType: concreteType(t.ElemType)}
+	case basetypes.ObjectType:
+		attrTypes := make(map[string]attr.Type, len(t.AttrTypes))
+		for key, attrType := range t.AttrTypes {
+			attrTypes[key] = concreteType(attrType)
+		}
+		return types.ObjectType{AttrTypes: attrTypes}
+	default:
+		return t
+	}
+}
+
+// toTypedValue converts value to a Terraform value of type t, which was inferred from it.
+func toTypedValue(value any, t attr.Type) (attr.Value, error) {
+	value = derefAny(value)
+	if value == nil {
+		return nullOf(t), nil
+	}
+
+	switch t := t.(type) {
+	case basetypes.ListType:
+		items, _ := value.([]any)
+		elements := make([]attr.Value, len(items))
+		for i, item := range items {
+			elem, err := toTypedValue(item, t.ElemType)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = elem
+		}
+		listVal, diags := types.ListValue(t.ElemType, elements)
+		return listVal, diagsToError(diags)
+	case basetypes.MapType:
+		items, _ := value.(map[string]any)
+		elements := make(map[string]attr.Value, len(items))
+		for key, item := range items {
+			elem, err := toTypedValue(item, t.ElemType)
+			if err != nil {
+				return nil, err
+			}
+			elements[key] = elem
+		}
+		mapVal, diags := types.MapValue(t.ElemType, elements)
+		return mapVal, diagsToError(diags)
+	case basetypes.ObjectType:
+		fields, _ := value.(map[string]any)
+		attrs := make(map[string]attr.Value, len(t.AttrTypes))
+		for key, attrType := range t.AttrTypes {
+			attrValue, err := toTypedValue(fields[key], attrType)
+			if err != nil {
+				return nil, err
+			}
+			attrs[key] = attrValue
+		}
+		objVal, diags := types.ObjectValue(t.AttrTypes, attrs)
+		return objVal, diagsToError(diags)
+	default:
+		return ToDynamic(value).UnderlyingValue(), nil
+	}
+}
+
+// nullOf returns the null value of type t.
+func nullOf(t attr.Type) attr.Value {
+	switch t := t.(type) {
+	case basetypes.BoolType:
+		return types.BoolNull()
+	case basetypes.NumberType:
+		return types.NumberNull()
+	case basetypes.ListType:
+		return types.ListNull(t.ElemType)
+	case basetypes.MapType:
+		return types.MapNull(t.ElemType)
+	case basetypes.ObjectType:
+		return types.ObjectNull(t.AttrTypes)
+	default:
+		return types.StringNull()
+	}
+}
+
+// describeTyped names the kind of a type inferred within a typed collection in an error message.
+func describeTyped(t attr.Type) string {
+	switch t.(type) {
+	case nil:
+		return "null"
+	case basetypes.BoolType:
+		return "boolean"
+	case basetypes.NumberType:
+		return "number"
+	case basetypes.ListType:
+		return "list"
+	case basetypes.MapType:
+		return "map"
+	case basetypes.ObjectType:
+		return "object"
+	default:
+		return "string"
+	}
+}
+
+// diagsToError returns the first error in diags, as returned when building a Terraform value.
+func diagsToError(diags diag.Diagnostics) error {
+	for _, d := range diags.Errors() {
+		return fmt.Errorf("%s: %s", d.Summary(), d.Detail())
+	}
+	return nil
+}
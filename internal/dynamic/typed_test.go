@@ -0,0 +1,133 @@
+package dynamic
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// TestToTypedDynamic_ListOfObjects tests that a typed list of objects round trips with a single element type.
+func TestToTypedDynamic_ListOfObjects(t *testing.T) {
+	state := map[string]any{
+		"count": int64(2),
+		"items": []any{
+			map[string]any{"name": "a", "size": int64(1), "tags": []any{"x"}},
+			map[string]any{"name": "b", "tags": []any{}},
+		},
+	}
+
+	dynVal, err := ToTypedDynamic(state, [][]string{{"items"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	obj, ok := dynVal.UnderlyingValue().(types.Object)
+	if !ok {
+		t.Fatalf("Expected the state to stay an object, got %T", dynVal.UnderlyingValue())
+	}
+	items, ok := obj.Attributes()["items"].(types.Dynamic).UnderlyingValue().(types.List)
+	if !ok {
+		t.Fatalf("Expected items to be a list, got %T", obj.Attributes()["items"])
+	}
+	expectedType := types.ObjectType{AttrTypes: map[string]attr.Type{
+		"name": types.StringType,
+		"size": types.NumberType,
+		"tags": types.ListType{ElemType: types.StringType},
+	}}
+	if !items.ElementType(t.Context()).Equal(expectedType) {
+		t.Errorf("Expected elements of type %s, got %s", expectedType, items.ElementType(t.Context()))
+	}
+
+	// The missing size is null, and the state reads back as it was returned
+	expected := map[string]any{
+		"count": int64(2),
+		"items": []any{
+			map[string]any{"name": "a", "size": int64(1), "tags": []any{"x"}},
+			map[string]any{"name": "b", "size": nil, "tags": []any{}},
+		},
+	}
+	if result := FromDynamic(dynVal); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+// TestToTypedDynamic_Map tests that a typed object becomes a map with a single element type.
+func TestToTypedDynamic_Map(t *testing.T) {
+	state := map[string]any{"ports": map[string]any{"http": int64(80), "https": int64(443)}}
+
+	dynVal, err := ToTypedDynamic(state, [][]string{{"ports"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ports := dynVal.UnderlyingValue().(types.Object).Attributes()["ports"].(types.Dynamic).UnderlyingValue()
+	expected := types.MapValueMust(types.NumberType, map[string]attr.Value{
+		"http":  types.NumberValue(big.NewFloat(80)),
+		"https": types.NumberValue(big.NewFloat(443)),
+	})
+	if !ports.Equal(expected) {
+		t.Errorf("Expected %s, got %s", expected, ports)
+	}
+}
+
+// TestToTypedDynamic_Errors tests that values which can not be given a single element type are reported.
+func TestToTypedDynamic_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    map[string]any
+		expected string
+	}{
+		{
+			name:     "mixed kinds",
+			state:    map[string]any{"items": []any{"a", int64(1)}},
+			expected: "the elements of items must all have the same type, items.1 is a number but the elements before it are a string",
+		},
+		{
+			name:     "mixed fields",
+			state:    map[string]any{"items": []any{map[string]any{"size": int64(1)}, map[string]any{"size": "big"}}},
+			expected: "the elements of items must all have the same type, items.1 holds values of a different type to the elements before it",
+		},
+		{
+			name:     "scalar",
+			state:    map[string]any{"items": "a"},
+			expected: "the value at items is typed, so must be a list or an object, got a string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ToTypedDynamic(tt.state, [][]string{{"items"}})
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+// TestToTypedDynamic_NoTypedPaths tests that values are converted exactly as ToDynamic does without typed paths.
+func TestToTypedDynamic_NoTypedPaths(t *testing.T) {
+	state := map[string]any{"items": []any{"a", int64(1)}, "missing": nil}
+	dynVal, err := ToTypedDynamic(state, [][]string{{"other"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !dynVal.Equal(ToDynamic(state)) {
+		t.Errorf("Expected %s, got %s", ToDynamic(state), dynVal)
+	}
+}
+
+// TestToTypedDynamic_NilPointers tests that typed nil pointers within a typed collection are null values.
+func TestToTypedDynamic_NilPointers(t *testing.T) {
+	var missing *string
+	state := map[string]any{"items": []any{map[string]any{"name": "a"}, map[string]any{"name": missing}}}
+
+	dynVal, err := ToTypedDynamic(state, [][]string{{"items"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]any{"items": []any{map[string]any{"name": "a"}, map[string]any{"name": nil}}}
+	if result := FromDynamic(dynVal); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
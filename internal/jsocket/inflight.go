@@ -0,0 +1,97 @@
+package jsocket
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// inflight tracks the messages received from the peer that are still being handled, for JSocket.Flush.
+//
+// Every message is handled in its own goroutine so that a slow server method does not hold up the responses to
+// the calls made to the peer. Notifications are however handled one at a time, in the order they were received,
+// as a peer that sends several expects them to be seen in that order, eg: progress updates.
+type inflight struct {
+	mu sync.Mutex
+
+	// pending is how many messages are still being handled, idle is closed whenever it is zero.
+	pending int
+	idle    chan struct{}
+
+	// turnCond is signalled whenever a notification has been handled, letting the one with the next ticket go.
+	turnCond   *sync.Cond
+	nextTicket uint64
+	turn       uint64
+}
+
+// handler wraps h so that every message it handles is tracked, it is called in the order messages are received.
+func (f *inflight) handler(h jsonrpc2.Handler) jsonrpc2.Handler {
+	return inflightHandler{f, h}
+}
+
+type inflightHandler struct {
+	f *inflight
+	h jsonrpc2.Handler
+}
+
+// Handle is called by the connection's read loop, so messages are counted, and notifications given their
+// tickets, in the order they were received before being handled in the background.
+func (h inflightHandler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	f := h.f
+	f.mu.Lock()
+	if f.turnCond == nil {
+		f.turnCond = sync.NewCond(&f.mu)
+	}
+	if f.pending == 0 {
+		f.idle = make(chan struct{})
+	}
+	f.pending++
+	ticket := f.nextTicket
+	if req.Notif {
+		f.nextTicket++
+	}
+	f.mu.Unlock()
+
+	go func() {
+		if req.Notif {
+			f.mu.Lock()
+			for f.turn != ticket {
+				f.turnCond.Wait()
+			}
+			f.mu.Unlock()
+		}
+
+		h.h.Handle(ctx, conn, req)
+
+		f.mu.Lock()
+		if req.Notif {
+			f.turn++
+			f.turnCond.Broadcast()
+		}
+		f.pending--
+		if f.pending == 0 {
+			close(f.idle)
+		}
+		f.mu.Unlock()
+	}()
+}
+
+// idleCh returns a channel that is closed once no messages are being handled.
+func (f *inflight) idleCh() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.pending == 0 {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+	return f.idle
+}
+
+// count returns how many messages are still being handled.
+func (f *inflight) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pending
+}
@@ -25,10 +25,33 @@
 //	var result struct{ Message string }
 //	err := socket.Call(ctx, "greet", struct{ Name string }{"Alice"}, &result)
 //
+// Pipeline many RPC calls in a single round trip, each call receiving its own result or error:
+//
+//	calls := []jsocket.PipelinedCall{
+//		{Method: "greet", Params: struct{ Name string }{"Alice"}, Result: &alice},
+//		{Method: "greet", Params: struct{ Name string }{"Bob"}, Result: &bob},
+//	}
+//	if err := socket.PipelineCalls(ctx, calls); err != nil {
+//		// The calls could not be sent
+//	}
+//	for _, call := range calls {
+//		if call.Error != nil {
+//			// This call failed, the others may still have succeeded
+//		}
+//	}
+//
 // Send fire-and-forget notifications:
 //
 //	err := socket.Notify(ctx, "log", struct{ Level, Message string }{"info", "Started"})
 //
+// Limit how long calls wait for a response, telling the peer when one is no longer wanted:
+//
+//	socket.CallTimeout = 30 * time.Second
+//	socket.CancelMethod = "cancel"
+//	if err := socket.Call(ctx, "greet", params, &result); errors.Is(err, jsocket.ErrCallTimeout) {
+//		// The peer is slow, rather than having failed
+//	}
+//
 // # Typed Server Methods
 //
 // For better type safety and organization, use TypedServerMethods to automatically
@@ -132,9 +155,11 @@ package jsocket
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"time"
 	"unicode"
 
 	"github.com/sourcegraph/jsonrpc2"
@@ -147,6 +172,40 @@ import (
 // and supports both synchronous calls and fire-and-forget notifications.
 type JSocket struct {
 	conn *jsonrpc2.Conn
+
+	// CallTimeout limits how long Call waits for a response, after which it fails with a *CallTimeoutError.
+	// Zero waits until the context is done. Set it before making any calls.
+	CallTimeout time.Duration
+
+	// CancelMethod is the notification sent to the peer when a call times out, with the params
+	// {"method": "<the method called>"}, so that it can stop work whose result is no longer wanted.
+	// Empty sends no notification. Set it before making any calls.
+	CancelMethod string
+
+	// inflight tracks the requests and notifications received from the peer that are still being handled.
+	inflight inflight
+}
+
+// ErrCallTimeout is matched by errors.Is for every *CallTimeoutError.
+var ErrCallTimeout = errors.New("json-rpc call timed out")
+
+// CallTimeoutError is returned by Call when the peer did not respond within the JSocket's CallTimeout.
+type CallTimeoutError struct {
+	// Method is the remote method that was called.
+	Method string
+
+	// Timeout is how long the call waited for a response.
+	Timeout time.Duration
+}
+
+// Error implements the error interface.
+func (e *CallTimeoutError) Error() string {
+	return fmt.Sprintf("%s() did not respond within %s", e.Method, e.Timeout)
+}
+
+// Is reports whether target is ErrCallTimeout.
+func (e *CallTimeoutError) Is(target error) bool {
+	return target == ErrCallTimeout
 }
 
 // New creates a new JSocket instance that wraps a JSON-RPC 2.0 bidirectional connection.
@@ -173,7 +232,8 @@ func New(ctx context.Context, reader io.ReadCloser, writer io.Writer, serverMeth
 		Writer:     writer,
 	})
 
-	handler := jsonrpc2.AsyncHandler(
+	j := &JSocket{}
+	handler := j.inflight.handler(
 		jsonrpc2.HandlerWithError(func(ctx context.Context, c *jsonrpc2.Conn, r *jsonrpc2.Request) (any, error) {
 			// Build the methods map
 			methods := serverMethods(ctx, c)
@@ -229,7 +289,7 @@ func New(ctx context.Context, reader io.ReadCloser, writer io.Writer, serverMeth
 						if !ok {
 							return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInternalError, Message: "Method returned invalid error type"}
 						}
-						return nil, fmt.Errorf("method failed: %w", err)
+						return nil, methodError(err)
 					}
 					return nil, nil
 				}
@@ -244,7 +304,7 @@ func New(ctx context.Context, reader io.ReadCloser, writer io.Writer, serverMeth
 					if !ok {
 						return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInternalError, Message: "Method returned invalid error type"}
 					}
-					return nil, fmt.Errorf("method failed: %w", err)
+					return nil, methodError(err)
 				}
 				return response, nil
 			default:
@@ -253,16 +313,159 @@ func New(ctx context.Context, reader io.ReadCloser, writer io.Writer, serverMeth
 		}),
 	)
 
-	return &JSocket{jsonrpc2.NewConn(ctx, stream, handler, opts...)}
+	j.conn = jsonrpc2.NewConn(ctx, stream, handler, opts...)
+	return j
+}
+
+// methodError converts an error returned by a server method into the error sent to the remote peer.
+// A *jsonrpc2.Error is sent as is so that the peer receives its code, anything else is wrapped.
+func methodError(err error) error {
+	var rpcErr *jsonrpc2.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	return fmt.Errorf("method failed: %w", err)
 }
 
 // Call sends a JSON-RPC request to the remote peer and waits for a response.
 // The method parameter specifies the remote method to invoke, params contains the
 // input parameters, and result will be populated with the response data.
-// The call blocks until a response is received or the context is cancelled.
+// The call blocks until a response is received, the context is cancelled or the
+// CallTimeout elapses, in which case a *CallTimeoutError is returned and the peer
+// is sent the CancelMethod notification.
 // Returns an error if the call fails or the remote method returns an error.
 func (j *JSocket) Call(ctx context.Context, method string, params, result any, opts ...jsonrpc2.CallOption) error {
-	return j.conn.Call(ctx, method, params, result, opts...)
+	if j.CallTimeout <= 0 {
+		return j.conn.Call(ctx, method, params, result, opts...)
+	}
+
+	timeoutErr := &CallTimeoutError{Method: method, Timeout: j.CallTimeout}
+	callCtx, cancel := context.WithTimeoutCause(ctx, j.CallTimeout, timeoutErr)
+	defer cancel()
+	err := j.conn.Call(callCtx, method, params, result, opts...)
+	if err == nil || ctx.Err() != nil || context.Cause(callCtx) != timeoutErr {
+		return err
+	}
+
+	// The call context is done so it can't be used to send the notification
+	if j.CancelMethod != "" {
+		if notifyErr := j.conn.Notify(context.Background(), j.CancelMethod, map[string]string{"method": method}); notifyErr != nil {
+			return errors.Join(timeoutErr, fmt.Errorf("failed to notify the peer of the timeout: %w", notifyErr))
+		}
+	}
+	return timeoutErr
+}
+
+// PipelinedCall is a single call made by PipelineCalls.
+type PipelinedCall struct {
+	// Method is the remote method to invoke.
+	Method string
+
+	// Params are the input parameters of the call.
+	Params any
+
+	// Result is populated with the response data, it may be nil when the response is not needed.
+	Result any
+
+	// Error is set by PipelineCalls when this call failed, eg: the remote method returned an error.
+	Error error
+}
+
+// PipelineCalls pipelines the given calls, sending every request to the remote peer before waiting for any of the
+// responses, so that they cost a single round trip rather than one per call. Responses are scattered back to each
+// call's Result, and a call that failed is given its own Error.
+//
+// This is not a JSON-RPC batch, the requests are written as separate messages, so the peer handles them as it
+// would any other calls, and may respond to them in any order.
+//
+// The CallTimeout limits how long the calls wait for their responses, together. Each call still waiting once it
+// elapses fails with a *CallTimeoutError, and the peer is sent the CancelMethod notification for it.
+//
+// Returns an error when a request could not be sent, in which case no responses are waited for, or when the context
+// is done before every response was received. The Error of every call is set in either case.
+func (j *JSocket) PipelineCalls(ctx context.Context, calls []PipelinedCall) error {
+	callCtx, cancel := ctx, context.CancelFunc(func() {})
+	if j.CallTimeout > 0 {
+		callCtx, cancel = context.WithTimeoutCause(ctx, j.CallTimeout, ErrCallTimeout)
+	}
+	defer cancel()
+
+	waiters := make([]jsonrpc2.Waiter, len(calls))
+	for i := range calls {
+		waiter, err := j.conn.DispatchCall(callCtx, calls[i].Method, calls[i].Params)
+		if err != nil {
+			err = fmt.Errorf("failed to send the call to %s(): %w", calls[i].Method, err)
+			// The calls already sent can't be withdrawn, but their responses are not waited for
+			for k := range calls {
+				calls[k].Error = err
+			}
+			return err
+		}
+		waiters[i] = waiter
+	}
+
+	var timedOut []string
+	for i := range calls {
+		err := waiters[i].Wait(callCtx, calls[i].Result)
+		if err != nil && ctx.Err() == nil && context.Cause(callCtx) == ErrCallTimeout {
+			err = &CallTimeoutError{Method: calls[i].Method, Timeout: j.CallTimeout}
+			timedOut = append(timedOut, calls[i].Method)
+		}
+		calls[i].Error = err
+	}
+
+	// The call context is done so it can't be used to send the notifications
+	var notifyErrs []error
+	if j.CancelMethod != "" {
+		for _, method := range timedOut {
+			if err := j.conn.Notify(context.Background(), j.CancelMethod, map[string]string{"method": method}); err != nil {
+				notifyErrs = append(notifyErrs, fmt.Errorf("failed to notify the peer of the timeout of %s(): %w", method, err))
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return errors.Join(notifyErrs...)
+}
+
+// Flush waits until every request and notification received from the peer so far has been handled, eg: so that
+// the notifications a peer sent before responding to a call are not lost when the caller moves on once it returns.
+// Messages are received in the order the peer sent them, so everything sent before a response has at least
+// started being handled by the time the call returns.
+//
+// Returns a *FlushTimeoutError when they are still being handled once timeout elapses, zero waits until the
+// context is done.
+func (j *JSocket) Flush(ctx context.Context, timeout time.Duration) error {
+	idle := j.inflight.idleCh()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-idle:
+		return nil
+	case <-expired:
+		return &FlushTimeoutError{Pending: j.inflight.count(), Timeout: timeout}
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// FlushTimeoutError is returned by Flush when messages from the peer were still being handled once its timeout elapsed.
+type FlushTimeoutError struct {
+	// Pending is how many messages were still being handled.
+	Pending int
+
+	// Timeout is how long Flush waited.
+	Timeout time.Duration
+}
+
+// Error implements the error interface.
+func (e *FlushTimeoutError) Error() string {
+	return fmt.Sprintf("%d message(s) from the peer were still being handled after %s", e.Pending, e.Timeout)
 }
 
 // Notify sends a JSON-RPC notification to the remote peer without expecting a response.
@@ -0,0 +1,167 @@
+package jsocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// connectPeers connects a client to a server over a pair of in-memory pipes, each peer with its own methods.
+func connectPeers(t *testing.T, clientMethods, serverMethods func(ctx context.Context, c *jsonrpc2.Conn) map[string]any) *JSocket {
+	t.Helper()
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	server := New(t.Context(), serverReader, serverWriter, serverMethods)
+	t.Cleanup(func() { _ = server.Close() })
+
+	client := New(t.Context(), clientReader, clientWriter, clientMethods)
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// methods returns serverMethods for a peer whose methods do not need the connection.
+func methods(m map[string]any) func(ctx context.Context, c *jsonrpc2.Conn) map[string]any {
+	return func(context.Context, *jsonrpc2.Conn) map[string]any { return m }
+}
+
+func TestJSocket_PipelineCalls(t *testing.T) {
+	client := connectPeers(t, methods(nil), methods(map[string]any{
+		"double": func(params map[string]int) map[string]int {
+			// Later calls respond first, results must still reach the call that made them
+			time.Sleep(time.Duration(10-params["n"]) * 5 * time.Millisecond)
+			return map[string]int{"n": params["n"] * 2}
+		},
+		"fail": func() error {
+			return errors.New("boom")
+		},
+	}))
+
+	results := make([]map[string]int, 4)
+	calls := []PipelinedCall{
+		{Method: "double", Params: map[string]int{"n": 1}, Result: &results[0]},
+		{Method: "double", Params: map[string]int{"n": 2}, Result: &results[1]},
+		{Method: "fail", Result: &results[2]},
+		{Method: "double", Params: map[string]int{"n": 3}, Result: &results[3]},
+	}
+	assert.NoError(t, client.PipelineCalls(t.Context(), calls))
+
+	assert.NoError(t, calls[0].Error)
+	assert.Equal(t, 2, results[0]["n"])
+	assert.NoError(t, calls[1].Error)
+	assert.Equal(t, 4, results[1]["n"])
+	assert.Error(t, calls[2].Error)
+	assert.Contains(t, calls[2].Error.Error(), "boom")
+	assert.NoError(t, calls[3].Error)
+	assert.Equal(t, 6, results[3]["n"])
+}
+
+func TestJSocket_PipelineCalls_Timeout(t *testing.T) {
+	cancelled := make(chan string, 2)
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	client := connectPeers(t, methods(nil), methods(map[string]any{
+		"fast": func() map[string]any {
+			return map[string]any{"ok": true}
+		},
+		"slow": func() map[string]any {
+			<-release
+			return map[string]any{}
+		},
+		"cancel": func(params map[string]string) {
+			cancelled <- params["method"]
+		},
+	}))
+	client.CallTimeout = 100 * time.Millisecond
+	client.CancelMethod = "cancel"
+
+	var fast map[string]any
+	calls := []PipelinedCall{
+		{Method: "fast", Result: &fast},
+		{Method: "slow"},
+	}
+	assert.NoError(t, client.PipelineCalls(t.Context(), calls))
+	assert.NoError(t, calls[0].Error)
+	assert.Equal(t, true, fast["ok"])
+	assert.IsError(t, calls[1].Error, ErrCallTimeout)
+	assert.EqualError(t, calls[1].Error, "slow() did not respond within 100ms")
+
+	select {
+	case method := <-cancelled:
+		assert.Equal(t, "slow", method)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the script to be notified of the timeout")
+	}
+}
+
+func TestJSocket_Flush_DeliversNotificationsInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	client := connectPeers(t,
+		methods(map[string]any{
+			"progress": func(params map[string]string) {
+				// Slow enough that the response arrives while notifications are still being handled
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				received = append(received, params["message"])
+			},
+		}),
+		func(ctx context.Context, conn *jsonrpc2.Conn) map[string]any {
+			return map[string]any{
+				"run": func() (map[string]any, error) {
+					for i := range 50 {
+						if err := conn.Notify(ctx, "progress", map[string]string{"message": fmt.Sprintf("step %d", i)}); err != nil {
+							return nil, err
+						}
+					}
+					return map[string]any{"done": true}, nil
+				},
+			}
+		},
+	)
+
+	var result map[string]any
+	assert.NoError(t, client.Call(t.Context(), "run", nil, &result))
+	assert.NoError(t, client.Flush(t.Context(), 5*time.Second))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 50, len(received))
+	for i, message := range received {
+		assert.Equal(t, fmt.Sprintf("step %d", i), message)
+	}
+}
+
+func TestJSocket_Flush_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	client := connectPeers(t,
+		methods(map[string]any{
+			"progress": func() {
+				<-release
+			},
+		}),
+		func(ctx context.Context, conn *jsonrpc2.Conn) map[string]any {
+			return map[string]any{
+				"run": func() (map[string]any, error) {
+					return map[string]any{}, conn.Notify(ctx, "progress", nil)
+				},
+			}
+		},
+	)
+
+	assert.NoError(t, client.Call(t.Context(), "run", nil, nil))
+	err := client.Flush(t.Context(), 50*time.Millisecond)
+	var flushErr *FlushTimeoutError
+	assert.True(t, errors.As(err, &flushErr))
+	assert.Equal(t, 1, flushErr.Pending)
+	assert.EqualError(t, err, "1 message(s) from the peer were still being handled after 50ms")
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
 	"github.com/hashicorp/terraform-plugin-framework/action"
 	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -29,10 +30,14 @@ type denoBridgeAction struct {
 
 // denoBridgeActionModel maps the action schema data.
 type denoBridgeActionModel struct {
-	Path        types.String        `tfsdk:"path"`
-	Props       types.Dynamic       `tfsdk:"props"`
-	ConfigFile  types.String        `tfsdk:"config_file"`
-	Permissions *deno.PermissionsTF `tfsdk:"permissions"`
+	Path              types.String        `tfsdk:"path"`
+	Props             types.Dynamic       `tfsdk:"props"`
+	ConfigFile        types.String        `tfsdk:"config_file"`
+	NoConfigDiscovery types.Bool          `tfsdk:"no_config_discovery"`
+	EnvFile           types.String        `tfsdk:"env_file"`
+	Env               types.Map           `tfsdk:"env"`
+	DenoFlags         types.List          `tfsdk:"deno_flags"`
+	Permissions       *deno.PermissionsTF `tfsdk:"permissions"`
 }
 
 func (a *denoBridgeAction) Metadata(ctx context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
@@ -44,7 +49,7 @@ func (a *denoBridgeAction) Schema(_ context.Context, _ action.SchemaRequest, res
 		Description: "Bridges the terraform-plugin-framework Action to a Deno script.",
 		Attributes: map[string]schema.Attribute{
 			"path": schema.StringAttribute{
-				Description: "Path to the Deno script to execute.",
+				Description: "Path to the Deno script to execute, or env:NAME to read the path from the environment variable NAME when the script is run.",
 				Required:    true,
 			},
 			"props": schema.DynamicAttribute{
@@ -55,6 +60,25 @@ func (a *denoBridgeAction) Schema(_ context.Context, _ action.SchemaRequest, res
 				Description: "File path to a deno config file to use with the deno script. Useful for import maps, etc...",
 				Optional:    true,
 			},
+			"no_config_discovery": schema.BoolAttribute{
+				Description: "Disable locating the closest deno config file relative to the script when config_file is not set. The script is then run with --no-config.",
+				Optional:    true,
+			},
+			"env_file": schema.StringAttribute{
+				Description: "Path to a .env file whose variables are loaded into the script's environment via --env-file. The script needs the env permission to read them.",
+				Optional:    true,
+			},
+			"env": schema.MapAttribute{
+				Description: "Environment variables set in the script's environment, on top of any set by the provider's env. The script needs the env permission to read them, which may be scoped to just these variables, eg: env=AWS_REGION.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"deno_flags": schema.ListAttribute{
+				Description: "Extra flags passed to deno run after the permissions, eg: --node-modules-dir=auto or --allow-scripts for scripts that import npm packages. Flags the provider controls, such as the config file and permissions, are rejected as they are set by their own attributes.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators:  []validator.List{denoFlagsValidator{}},
+			},
 			"permissions": schema.SingleNestedAttribute{
 				Description: "Deno runtime permissions for the script.",
 				Optional:    true,
@@ -64,14 +88,20 @@ func (a *denoBridgeAction) Schema(_ context.Context, _ action.SchemaRequest, res
 						Optional:    true,
 					},
 					"allow": schema.ListAttribute{
-						Description: "List of permissions to allow (e.g., 'read', 'write', 'net').",
+						Description: "List of permissions to allow, optionally scoped to particular values (e.g., 'read', 'net', 'read=/etc,/tmp', 'net=api.github.com:443').",
 						ElementType: types.StringType,
 						Optional:    true,
+						Validators:  []validator.List{permissionEntriesValidator{}},
 					},
 					"deny": schema.ListAttribute{
-						Description: "List of permissions to deny.",
+						Description: "List of permissions to deny, taking precedence over all and allow (e.g., 'write=/etc').",
 						ElementType: types.StringType,
 						Optional:    true,
+						Validators:  []validator.List{permissionEntriesValidator{}},
+					},
+					"learn": schema.BoolAttribute{
+						Description: "Learn mode. When the script is refused a permission, suggest a least-privilege allow list covering every permission observed so far.",
+						Optional:    true,
 					},
 				},
 			},
@@ -105,16 +135,29 @@ func (a *denoBridgeAction) Invoke(ctx context.Context, req action.InvokeRequest,
 		return
 	}
 
+	// Warn if the script can not read the variables loaded from its env file
+	addEnvFileDiagnostics(&resp.Diagnostics, data.EnvFile, data.Permissions)
+	addEnvDiagnostics(&resp.Diagnostics, data.Env, data.Permissions)
+
+	// Evaluate any props template functions
+	props, err := a.providerConfig.propsFor(data.Props)
+	if err != nil {
+		addPropsError(&resp.Diagnostics, err)
+		return
+	}
+
 	// Start the Deno server
 	c := deno.NewDenoClientAction(
 		a.providerConfig.DenoBinaryPath,
 		data.Path.ValueString(),
 		data.ConfigFile.ValueString(),
 		data.Permissions.MapToDenoPermissions(),
+		a.providerConfig.clientOptionsFor(data.NoConfigDiscovery, data.EnvFile, data.Env, data.DenoFlags),
 		resp,
 	)
-	if err := c.Client.Start(ctx); err != nil {
+	if err := startDeno(ctx, c.Client); err != nil {
 		resp.Diagnostics.AddError("Failed to start Deno", err.Error())
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 	defer func() {
@@ -124,9 +167,10 @@ func (a *denoBridgeAction) Invoke(ctx context.Context, req action.InvokeRequest,
 	}()
 
 	// Call the invoke JSON-RPC method
-	response, err := c.Invoke(ctx, &deno.InvokeRequest{Props: dynamic.FromDynamic(data.Props)})
+	response, err := c.Invoke(ctx, &deno.InvokeRequest{Props: props})
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to invoke action", err.Error())
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 
@@ -155,8 +199,8 @@ func (a *denoBridgeAction) Invoke(ctx context.Context, req action.InvokeRequest,
 		}
 	}
 
-	// Double check that the operation actually completed
-	if !response.Done {
+	// Double check that the operation actually completed, only an explicit done: false fails unless strict_done is set
+	if !response.IsDone(a.providerConfig.StrictDone) {
 		resp.Diagnostics.AddError(
 			"Failed to complete action",
 			"Deno script did not report the operation as done",
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// privateState reads and writes keys of a resource's private state.
+type privateState interface {
+	privateStateGetter
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// addAdvisoryWarnings shows the advisories returned by a script as warnings and records a hash of them in
+// private state.
+//
+// Scripts return their advisories on every call, so when onlyChanged is set, as it is while refreshing,
+// advisories identical to those last shown are skipped. This keeps them visible after every apply without
+// repeating them on every plan.
+func addAdvisoryWarnings(ctx context.Context, diags *diag.Diagnostics, private privateState, advisories []deno.Advisory, onlyChanged bool) {
+	hash := hashJSON(advisories)
+	if onlyChanged {
+		oldHashBytes, d := private.GetKey(ctx, "advisories_hash")
+		diags.Append(d...)
+		if d.HasError() {
+			return
+		}
+		var hashWrapper struct {
+			Hash string `json:"hash"`
+		}
+		if oldHashBytes != nil {
+			if err := json.Unmarshal(oldHashBytes, &hashWrapper); err != nil {
+				diags.AddError(
+					"Failed to read advisories hash",
+					fmt.Sprintf("Could not parse hash from private state: %s", err.Error()),
+				)
+				return
+			}
+		}
+		if hashWrapper.Hash == hash {
+			return
+		}
+	}
+
+	for _, advisory := range advisories {
+		diags.AddWarning(advisory.Summary, advisory.Detail)
+	}
+	diags.Append(private.SetKey(ctx, "advisories_hash", fmt.Appendf(nil, `{"hash":"%s"}`, hash))...)
+}
+
+// addNextStepsWarning shows the manual steps a script reports the user must take to finish a create or update,
+// eg: "Point your DNS at 203.0.113.10". They are numbered in a single warning so that none are missed, and unlike
+// advisories are only ever returned by an apply so are never repeated while refreshing.
+func addNextStepsWarning(diags *diag.Diagnostics, nextSteps []string) {
+	if len(nextSteps) == 0 {
+		return
+	}
+	var detail strings.Builder
+	detail.WriteString("The change was applied, but the following must be done by hand to finish it:\n")
+	for i, step := range nextSteps {
+		fmt.Fprintf(&detail, "\n  %d. %s", i+1, step)
+	}
+	diags.AddWarning("Next steps required", detail.String())
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func (p fakePrivateState) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	p[key] = value
+	return nil
+}
+
+func TestAddAdvisoryWarnings(t *testing.T) {
+	private := fakePrivateState{}
+	advisories := []deno.Advisory{{Summary: "API deprecated", Detail: "Migrate to v2 by Q3"}}
+
+	// Always shown after an apply
+	var applied diag.Diagnostics
+	addAdvisoryWarnings(t.Context(), &applied, private, advisories, false)
+	if applied.WarningsCount() != 1 {
+		t.Fatalf("Expected the advisory to be shown, got %v", applied)
+	}
+
+	// Not repeated while refreshing
+	var refreshed diag.Diagnostics
+	addAdvisoryWarnings(t.Context(), &refreshed, private, advisories, true)
+	if refreshed.WarningsCount() != 0 {
+		t.Errorf("Expected the unchanged advisory to be skipped, got %v", refreshed)
+	}
+
+	// Shown again once it changes
+	var changed diag.Diagnostics
+	addAdvisoryWarnings(t.Context(), &changed, private, []deno.Advisory{{Summary: "API removed"}}, true)
+	if changed.WarningsCount() != 1 {
+		t.Errorf("Expected the changed advisory to be shown, got %v", changed)
+	}
+}
+
+func TestAddNextStepsWarning(t *testing.T) {
+	var diags diag.Diagnostics
+	addNextStepsWarning(&diags, nil)
+	if len(diags) != 0 {
+		t.Fatalf("Expected no warning without next steps, got %v", diags)
+	}
+
+	addNextStepsWarning(&diags, []string{"Point your DNS at 203.0.113.10", "Verify the domain in the console"})
+	if diags.WarningsCount() != 1 || diags.ErrorsCount() != 0 {
+		t.Fatalf("Expected a single warning, got %v", diags)
+	}
+	expected := "The change was applied, but the following must be done by hand to finish it:\n\n" +
+		"  1. Point your DNS at 203.0.113.10\n  2. Verify the domain in the console"
+	if diags[0].Detail() != expected {
+		t.Errorf("Expected the steps to be numbered, got %q", diags[0].Detail())
+	}
+}
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// auditLogger appends a JSON line to the audit_log file for every operation a script performs.
+type auditLogger struct {
+	path string
+	mu   sync.Mutex
+}
+
+// auditEntry is a single line of the audit log.
+//
+// It deliberately records only metadata about the operation, never props, state or error messages,
+// any of which may hold sensitive values.
+type auditEntry struct {
+	Time        time.Time        `json:"time"`
+	Resource    string           `json:"resource"`
+	Operation   string           `json:"operation"`
+	Script      string           `json:"script"`
+	Permissions auditPermissions `json:"permissions"`
+	DurationMs  int64            `json:"durationMs"`
+	Success     bool             `json:"success"`
+}
+
+// auditPermissions records the permissions an operation was run with.
+type auditPermissions struct {
+	All   bool     `json:"all"`
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// newAuditLogger returns an audit logger appending to the file at path, or nil when path is empty.
+func newAuditLogger(path string) *auditLogger {
+	if path == "" {
+		return nil
+	}
+	return &auditLogger{path: path}
+}
+
+// write appends entry to the audit log.
+func (l *auditLogger) write(entry auditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit log entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// audit records an operation that began at start, it is intended to be deferred at the start of the operation:
+//
+//	defer r.providerConfig.audit(ctx, "denobridge_resource", "create", path, permissions, time.Now(), &resp.Diagnostics)
+//
+// The operation is recorded as a failure when diags holds an error once it returns. Failing to write the audit
+// log only logs a warning, it does not fail the operation. Does nothing when no audit_log is configured.
+func (c *ProviderConfig) audit(ctx context.Context, resource, operation, script string, permissions *deno.PermissionsTF, start time.Time, diags *diag.Diagnostics) {
+	if c.AuditLog == nil {
+		return
+	}
+	perms := permissions.MapToDenoPermissions()
+	err := c.AuditLog.write(auditEntry{
+		Time:        start.UTC(),
+		Resource:    resource,
+		Operation:   operation,
+		Script:      script,
+		Permissions: auditPermissions{All: perms.All, Allow: perms.Allow, Deny: perms.Deny},
+		DurationMs:  time.Since(start).Milliseconds(),
+		Success:     !diags.HasError(),
+	})
+	if err != nil {
+		tflog.Warn(ctx, err.Error())
+	}
+}
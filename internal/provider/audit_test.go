@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestAudit(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	config := &ProviderConfig{AuditLog: newAuditLogger(logPath)}
+	permissions := &deno.PermissionsTF{
+		All:   types.BoolValue(false),
+		Allow: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("net=example.com")}),
+		Deny:  types.ListNull(types.StringType),
+	}
+
+	var succeeded diag.Diagnostics
+	config.audit(context.Background(), "denobridge_resource", "create", "./bucket.ts", permissions, time.Now(), &succeeded)
+	var failed diag.Diagnostics
+	failed.AddError("Failed to delete resource", "secret-value")
+	config.audit(context.Background(), "denobridge_resource", "delete", "./bucket.ts", nil, time.Now(), &failed)
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %s", err)
+	}
+	defer f.Close()
+	var entries []auditEntry
+	var raw []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		raw = append(raw, scanner.Text())
+		var entry auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Expected every line to be JSON, got '%s': %s", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Operation != "create" || e.Script != "./bucket.ts" || e.Resource != "denobridge_resource" || !e.Success {
+		t.Errorf("Unexpected create entry: %+v", e)
+	}
+	if e := entries[0]; len(e.Permissions.Allow) != 1 || e.Permissions.Allow[0] != "net=example.com" {
+		t.Errorf("Expected the create entry to record its permissions, got %+v", e.Permissions)
+	}
+	if e := entries[1]; e.Operation != "delete" || e.Success {
+		t.Errorf("Expected a failed delete entry, got %+v", e)
+	}
+	for _, line := range raw {
+		if strings.Contains(line, "secret-value") {
+			t.Errorf("Expected error details to be kept out of the audit log, got '%s'", line)
+		}
+	}
+}
+
+func TestAudit_Disabled(t *testing.T) {
+	var diags diag.Diagnostics
+	(&ProviderConfig{}).audit(context.Background(), "denobridge_resource", "create", "./bucket.ts", nil, time.Now(), &diags)
+	if newAuditLogger("") != nil {
+		t.Error("Expected no audit logger without an audit_log")
+	}
+}
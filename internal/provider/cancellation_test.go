@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+)
+
+// TestCancellationSignal_PerRequest tests that cancelling one request does not abort the requests that follow it
+// in the same Deno process, as happens when a process is pooled or run as a daemon.
+func TestCancellationSignal_PerRequest(t *testing.T) {
+	denoBinaryPath, err := exec.LookPath("deno")
+	if err != nil {
+		t.Skip("deno is not on the PATH")
+	}
+	client := deno.NewDenoClientResource(denoBinaryPath, "./cancellation_test.ts", "", nil, nil)
+	if err := client.Client.Start(t.Context()); err != nil {
+		t.Fatalf("Failed to start the script: %v", err)
+	}
+	defer func() { _ = client.Client.Stop() }()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := client.Create(ctx, &deno.CreateRequest{Props: map[string]any{"name": "hung"}}); err == nil {
+		t.Fatal("Expected create to be cancelled")
+	}
+
+	// The cancel notification is sent alongside the read, so wait until the script has seen it
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := client.Read(t.Context(), &deno.CreateReadRequest{ID: "hung", Props: map[string]any{"name": "hung"}})
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		state := (*resp.State).(map[string]any)
+		if state["aborted"] != false {
+			t.Fatalf("Expected the read after a cancelled create not to be aborted, got state %v", state)
+		}
+		if state["cancelled"] == float64(1) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the create to be cancelled, got state %v", state)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
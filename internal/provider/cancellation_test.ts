@@ -0,0 +1,31 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { cancellationSignal, ResourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  name: string;
+}
+
+interface State {
+  aborted: boolean;
+  cancelled: number;
+}
+
+let cancelled = 0;
+
+// Create hangs until it is cancelled, read reports whether its own request looks cancelled.
+new ResourceProvider<Props, State>({
+  async create({ name }) {
+    const signal = cancellationSignal();
+    signal.addEventListener("abort", () => cancelled++);
+    await new Promise((_, reject) => signal.addEventListener("abort", () => reject(signal.reason)));
+    return { id: name, state: { aborted: true, cancelled } };
+  },
+  async read(id, props) {
+    return { props, state: { aborted: cancellationSignal().aborted, cancelled } };
+  },
+  async update(id, nextProps, currentProps, currentState) {
+    return currentState;
+  },
+  async delete(id, props) {},
+});
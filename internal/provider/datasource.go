@@ -2,12 +2,16 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
 	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -29,12 +33,19 @@ type denoBridgeDataSource struct {
 
 // denoBridgeDataSourceModel maps the data source schema data.
 type denoBridgeDataSourceModel struct {
-	Path            types.String        `tfsdk:"path"`
-	Props           types.Dynamic       `tfsdk:"props"`
-	Result          types.Dynamic       `tfsdk:"result"`
-	SensitiveResult types.Dynamic       `tfsdk:"sensitive_result"`
-	ConfigFile      types.String        `tfsdk:"config_file"`
-	Permissions     *deno.PermissionsTF `tfsdk:"permissions"`
+	Path              types.String        `tfsdk:"path"`
+	Props             types.Dynamic       `tfsdk:"props"`
+	Result            types.Dynamic       `tfsdk:"result"`
+	SensitiveResult   types.Dynamic       `tfsdk:"sensitive_result"`
+	SensitiveOutputs  types.List          `tfsdk:"sensitive_outputs"`
+	SensitiveValues   types.Map           `tfsdk:"sensitive_values"`
+	ConfigFile        types.String        `tfsdk:"config_file"`
+	NoConfigDiscovery types.Bool          `tfsdk:"no_config_discovery"`
+	EnvFile           types.String        `tfsdk:"env_file"`
+	Env               types.Map           `tfsdk:"env"`
+	DenoFlags         types.List          `tfsdk:"deno_flags"`
+	Permissions       *deno.PermissionsTF `tfsdk:"permissions"`
+	Cache             types.Bool          `tfsdk:"cache"`
 }
 
 // Metadata returns the data source type name.
@@ -48,7 +59,7 @@ func (d *denoBridgeDataSource) Schema(_ context.Context, _ datasource.SchemaRequ
 		Description: "Bridges the terraform-plugin-framework Datasource to a Deno script.",
 		Attributes: map[string]schema.Attribute{
 			"path": schema.StringAttribute{
-				Description: "Path to the Deno script to execute.",
+				Description: "Path to the Deno script to execute, or env:NAME to read the path from the environment variable NAME when the script is run.",
 				Required:    true,
 			},
 			"props": schema.DynamicAttribute{
@@ -64,10 +75,45 @@ func (d *denoBridgeDataSource) Schema(_ context.Context, _ datasource.SchemaRequ
 				Computed:    true,
 				Sensitive:   true,
 			},
+			"sensitive_outputs": schema.ListAttribute{
+				Description: "Names of the top level keys of sensitive_result to expose individually in sensitive_values, eg: ['api_key', 'db_password']. Every name must be returned by the script.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"sensitive_values": schema.MapAttribute{
+				Description: "The sensitive outputs named in sensitive_outputs, each a sensitive value of its own. Values that are not strings are JSON encoded.",
+				ElementType: types.StringType,
+				Computed:    true,
+				Sensitive:   true,
+			},
 			"config_file": schema.StringAttribute{
 				Description: "File path to a deno config file to use with the deno script. Useful for import maps, etc...",
 				Optional:    true,
 			},
+			"no_config_discovery": schema.BoolAttribute{
+				Description: "Disable locating the closest deno config file relative to the script when config_file is not set. The script is then run with --no-config.",
+				Optional:    true,
+			},
+			"env_file": schema.StringAttribute{
+				Description: "Path to a .env file whose variables are loaded into the script's environment via --env-file. The script needs the env permission to read them.",
+				Optional:    true,
+			},
+			"env": schema.MapAttribute{
+				Description: "Environment variables set in the script's environment, on top of any set by the provider's env. The script needs the env permission to read them, which may be scoped to just these variables, eg: env=AWS_REGION.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"deno_flags": schema.ListAttribute{
+				Description: "Extra flags passed to deno run after the permissions, eg: --node-modules-dir=auto or --allow-scripts for scripts that import npm packages. Flags the provider controls, such as the config file and permissions, are rejected as they are set by their own attributes.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators:  []validator.List{denoFlagsValidator{}},
+			},
+			"cache": schema.BoolAttribute{
+				Description: "Reuse the result of an identical read, one with the same script, config, env, permissions and props, made earlier by this provider instance rather than running the script again. Defaults to true, set it to false for scripts that may return a different result each time they are read.",
+				Optional:    true,
+			},
 			"permissions": schema.SingleNestedAttribute{
 				Description: "Deno runtime permissions for the script.",
 				Optional:    true,
@@ -77,14 +123,20 @@ func (d *denoBridgeDataSource) Schema(_ context.Context, _ datasource.SchemaRequ
 						Optional:    true,
 					},
 					"allow": schema.ListAttribute{
-						Description: "List of permissions to allow (e.g., 'read', 'write', 'net').",
+						Description: "List of permissions to allow, optionally scoped to particular values (e.g., 'read', 'net', 'read=/etc,/tmp', 'net=api.github.com:443').",
 						ElementType: types.StringType,
 						Optional:    true,
+						Validators:  []validator.List{permissionEntriesValidator{}},
 					},
 					"deny": schema.ListAttribute{
-						Description: "List of permissions to deny.",
+						Description: "List of permissions to deny, taking precedence over all and allow (e.g., 'write=/etc').",
 						ElementType: types.StringType,
 						Optional:    true,
+						Validators:  []validator.List{permissionEntriesValidator{}},
+					},
+					"learn": schema.BoolAttribute{
+						Description: "Learn mode. When the script is refused a permission, suggest a least-privilege allow list covering every permission observed so far.",
+						Optional:    true,
 					},
 				},
 			},
@@ -121,30 +173,94 @@ func (d *denoBridgeDataSource) Read(ctx context.Context, req datasource.ReadRequ
 		return
 	}
 
+	// Warn if the script can not read the variables loaded from its env file
+	addEnvFileDiagnostics(&resp.Diagnostics, state.EnvFile, state.Permissions)
+	addEnvDiagnostics(&resp.Diagnostics, state.Env, state.Permissions)
+
+	// Evaluate any props template functions
+	props, err := d.providerConfig.propsFor(state.Props)
+	if err != nil {
+		addPropsError(&resp.Diagnostics, err)
+		return
+	}
+
+	// Reuse the result of an identical read, so the script is run once however many times it is referenced
+	cacheKey := datasourceCacheKey(&state)
+	response, cached := (*deno.ReadResponse)(nil), false
+	if state.Cache.IsNull() || state.Cache.ValueBool() {
+		unlock, err := d.providerConfig.datasourceCache.lock(ctx, cacheKey)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to read data", fmt.Sprintf("Gave up waiting for an identical read to finish: %s", err))
+			return
+		}
+		defer unlock()
+		response, cached = d.providerConfig.datasourceCache.get(cacheKey)
+	}
+	if !cached {
+		response = d.readFromScript(ctx, &state, props, cacheKey, &resp.Diagnostics)
+		if response == nil {
+			return
+		}
+	}
+
+	// Expose the named sensitive outputs individually
+	state.SensitiveValues = types.MapNull(types.StringType)
+	if !state.SensitiveOutputs.IsNull() {
+		var names []string
+		resp.Diagnostics.Append(state.SensitiveOutputs.ElementsAs(ctx, &names, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		values, err := sensitiveOutputValues(response.SensitiveResult, names)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("sensitive_outputs"), "Failed to read sensitive outputs", err.Error())
+			return
+		}
+		sensitiveValues, diags := types.MapValueFrom(ctx, types.StringType, values)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.SensitiveValues = sensitiveValues
+	}
+
+	// Set state
+	state.Result = dynamic.ToDynamic(response.Result)
+	state.SensitiveResult = dynamic.ToDynamic(response.SensitiveResult)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// readFromScript starts the data source's Deno script and calls its read method, caching the response under
+// cacheKey. It returns nil when the read failed, in which case the reason has been added to diags.
+func (d *denoBridgeDataSource) readFromScript(ctx context.Context, state *denoBridgeDataSourceModel, props any, cacheKey string, diags *diag.Diagnostics) *deno.ReadResponse {
 	// Start the Deno server
 	c := deno.NewDenoClientDatasource(
 		d.providerConfig.DenoBinaryPath,
 		state.Path.ValueString(),
 		state.ConfigFile.ValueString(),
 		state.Permissions.MapToDenoPermissions(),
+		d.providerConfig.clientOptionsFor(state.NoConfigDiscovery, state.EnvFile, state.Env, state.DenoFlags),
 	)
-	if err := c.Client.Start(ctx); err != nil {
-		resp.Diagnostics.AddError("Failed to start Deno", err.Error())
-		return
+	if err := startDeno(ctx, c.Client); err != nil {
+		diags.AddError("Failed to start Deno", err.Error())
+		addDenoErrorDiagnostics(diags, c.Client, err)
+		return nil
 	}
 	defer func() {
 		if err := c.Client.Stop(); err != nil {
-			resp.Diagnostics.AddWarning("Failed to stop Deno", err.Error())
+			diags.AddWarning("Failed to stop Deno", err.Error())
 		}
 	}()
 
 	// Call the read JSON-RPC method
-	response, err := c.Read(ctx, &deno.ReadRequest{Props: dynamic.FromDynamic(state.Props)})
+	response, err := c.Read(ctx, &deno.ReadRequest{Props: props})
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Failed to read data",
 			fmt.Sprintf("Could not read data from Deno script: %s", err.Error()),
 		)
+		addDenoErrorDiagnostics(diags, c.Client, err)
+		return nil
 	}
 
 	// Handle diagnostics - allows the script to add warnings or errors
@@ -155,25 +271,59 @@ func (d *denoBridgeDataSource) Read(ctx context.Context, req datasource.ReadRequ
 			case "error":
 				fatal = true
 				if diag.PropPath != nil {
-					resp.Diagnostics.AddAttributeError(dynamic.PropPathToPath(diag.PropPath), diag.Summary, diag.Detail)
+					diags.AddAttributeError(dynamic.PropPathToPath(diag.PropPath), diag.Summary, diag.Detail)
 				} else {
-					resp.Diagnostics.AddError(diag.Summary, diag.Detail)
+					diags.AddError(diag.Summary, diag.Detail)
 				}
 			case "warning":
 				if diag.PropPath != nil {
-					resp.Diagnostics.AddAttributeWarning(dynamic.PropPathToPath(diag.PropPath), diag.Summary, diag.Detail)
+					diags.AddAttributeWarning(dynamic.PropPathToPath(diag.PropPath), diag.Summary, diag.Detail)
 				} else {
-					resp.Diagnostics.AddWarning(diag.Summary, diag.Detail)
+					diags.AddWarning(diag.Summary, diag.Detail)
 				}
 			}
 		}
 		if fatal {
-			return
+			return nil
 		}
 	}
 
-	// Set state
-	state.Result = dynamic.ToDynamic(response.Result)
-	state.SensitiveResult = dynamic.ToDynamic(response.SensitiveResult)
-	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	// The script may ask to reuse the result of a previous identical read
+	if response.Skip {
+		cached, ok := d.providerConfig.datasourceCache.get(cacheKey)
+		if !ok {
+			diags.AddError(
+				"Failed to read data",
+				"The Deno script skipped the read but there is no previous result to reuse.",
+			)
+			return nil
+		}
+		response = cached
+	} else {
+		d.providerConfig.datasourceCache.set(cacheKey, response)
+	}
+	return response
+}
+
+// sensitiveOutputValues picks the named top level keys out of a scripts sensitive result.
+// Values that are not strings are JSON encoded, as each becomes an element of a map of strings.
+func sensitiveOutputValues(sensitiveResult any, names []string) (map[string]string, error) {
+	result, _ := sensitiveResult.(map[string]any)
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		value, ok := result[name]
+		if !ok {
+			return nil, fmt.Errorf("the Deno script did not return the sensitive output %q in its sensitiveResult", name)
+		}
+		if str, ok := value.(string); ok {
+			values[name] = str
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode the sensitive output %q: %w", name, err)
+		}
+		values[name] = string(encoded)
+	}
+	return values, nil
 }
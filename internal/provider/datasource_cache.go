@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
+)
+
+// datasourceCache remembers the results of datasource reads for the lifetime of the provider.
+// It is safe for concurrent use.
+//
+// Terraform reads data sources concurrently, so identical reads hold the lock of their key while they run
+// the script in order that the reads waiting on them find the result in the cache rather than each running it too.
+type datasourceCache struct {
+	keyedLock
+
+	mu      sync.Mutex
+	entries map[string]*deno.ReadResponse
+}
+
+// newDatasourceCache creates an empty datasource cache.
+func newDatasourceCache() *datasourceCache {
+	return &datasourceCache{
+		entries: make(map[string]*deno.ReadResponse),
+	}
+}
+
+// get returns the cached response for the given key, if any.
+func (c *datasourceCache) get(key string) (*deno.ReadResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	response, ok := c.entries[key]
+	return response, ok
+}
+
+// set stores the response for the given key, replacing any previous response.
+func (c *datasourceCache) set(key string, response *deno.ReadResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = response
+}
+
+// datasourceCacheKey identifies a datasource read by everything that can influence its result.
+func datasourceCacheKey(model *denoBridgeDataSourceModel) string {
+	data, _ := json.Marshal(struct {
+		Path              string            `json:"path"`
+		ConfigFile        string            `json:"configFile"`
+		NoConfigDiscovery bool              `json:"noConfigDiscovery"`
+		EnvFile           string            `json:"envFile"`
+		Env               map[string]string `json:"env"`
+		DenoFlags         []string          `json:"denoFlags"`
+		Permissions       *deno.Permissions `json:"permissions"`
+		Props             any               `json:"props"`
+	}{
+		Path:              model.Path.ValueString(),
+		ConfigFile:        model.ConfigFile.ValueString(),
+		NoConfigDiscovery: model.NoConfigDiscovery.ValueBool(),
+		EnvFile:           model.EnvFile.ValueString(),
+		Env:               envVars(model.Env),
+		DenoFlags:         denoFlags(model.DenoFlags),
+		Permissions:       model.Permissions.MapToDenoPermissions(),
+		Props:             dynamic.FromDynamic(model.Props),
+	})
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
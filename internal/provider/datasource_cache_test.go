@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+func TestDatasourceCache_GetSet(t *testing.T) {
+	cache := newDatasourceCache()
+
+	if _, ok := cache.get("missing"); ok {
+		t.Error("Expected no cached response for unknown key")
+	}
+
+	response := &deno.ReadResponse{Result: "value"}
+	cache.set("key", response)
+
+	cached, ok := cache.get("key")
+	if !ok {
+		t.Fatal("Expected cached response")
+	}
+	if cached != response {
+		t.Errorf("Expected cached response to be %v, got %v", response, cached)
+	}
+}
+
+func TestDatasourceCacheKey_DependsOnProps(t *testing.T) {
+	model := func(value string) *denoBridgeDataSourceModel {
+		return &denoBridgeDataSourceModel{
+			Path:  types.StringValue("./datasource_test.ts"),
+			Props: types.DynamicValue(basetypes.NewStringValue(value)),
+		}
+	}
+
+	if datasourceCacheKey(model("a")) != datasourceCacheKey(model("a")) {
+		t.Error("Expected identical reads to share a cache key")
+	}
+	if datasourceCacheKey(model("a")) == datasourceCacheKey(model("b")) {
+		t.Error("Expected reads with different props to have different cache keys")
+	}
+}
@@ -0,0 +1,15 @@
+import { DatasourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  value: string;
+}
+
+interface Result {
+  readId: string;
+}
+
+new DatasourceProvider<Props, Result>({
+  read() {
+    return { readId: crypto.randomUUID() };
+  },
+});
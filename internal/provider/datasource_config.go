@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &configDataSource{}
+	_ datasource.DataSourceWithConfigure = &configDataSource{}
+)
+
+// NewConfigDataSource is a helper function to simplify the provider implementation.
+func NewConfigDataSource() datasource.DataSource {
+	return &configDataSource{}
+}
+
+// configDataSource exposes the resolved provider configuration, it never runs a script.
+type configDataSource struct {
+	providerConfig *ProviderConfig
+}
+
+// configDataSourceModel maps the data source schema data.
+type configDataSourceModel struct {
+	DenoBinaryPath     types.String `tfsdk:"deno_binary_path"`
+	DenoVersion        types.String `tfsdk:"deno_version"`
+	DenoCacheDir       types.String `tfsdk:"deno_cache_dir"`
+	DefaultPermissions types.List   `tfsdk:"default_permissions"`
+}
+
+// Metadata returns the data source type name.
+func (d *configDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_config"
+}
+
+// Schema defines the schema for the data source.
+func (d *configDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Exposes the resolved configuration of the denobridge provider. Useful for confirming which Deno binary is in use when troubleshooting.",
+		Attributes: map[string]schema.Attribute{
+			"deno_binary_path": schema.StringAttribute{
+				Description: "Path to the Deno binary used to run scripts.",
+				Computed:    true,
+			},
+			"deno_version": schema.StringAttribute{
+				Description: "The version of the Deno binary used to run scripts, eg: v2.1.4. When the binary was not downloaded by the provider, eg: a custom deno_binary_path or a Deno found on the PATH, this is reported by running the binary with --version, and is null if that fails.",
+				Computed:    true,
+			},
+			"deno_cache_dir": schema.StringAttribute{
+				Description: "Directory that downloaded Deno binaries are cached in.",
+				Computed:    true,
+			},
+			"default_permissions": schema.ListAttribute{
+				Description: "The Deno permission flags a script is run with when its block sets no permissions, eg: --allow-import scoped to the trusted_import_hosts. Empty when such scripts are given no permissions.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *configDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*ProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.providerConfig = providerConfig
+}
+
+// Read sets the state from the resolved provider configuration.
+func (d *configDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.providerConfig == nil {
+		resp.Diagnostics.AddError("Provider not configured", "The denobridge provider must be configured before its configuration can be read.")
+		return
+	}
+
+	state := configDataSourceModel{
+		DenoBinaryPath: types.StringValue(d.providerConfig.DenoBinaryPath),
+		DenoVersion:    types.StringNull(),
+		DenoCacheDir:   types.StringNull(),
+	}
+	if d.providerConfig.DenoVersion != "" {
+		state.DenoVersion = types.StringValue(d.providerConfig.DenoVersion)
+	} else if version, err := deno.BinaryVersion(ctx, d.providerConfig.DenoBinaryPath); err != nil {
+		resp.Diagnostics.AddWarning("Could not determine the Deno version", err.Error())
+	} else {
+		state.DenoVersion = types.StringValue(version)
+	}
+	if d.providerConfig.Downloader != nil {
+		cacheDir, err := d.providerConfig.Downloader.CacheDir()
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to resolve the Deno cache directory", err.Error())
+			return
+		}
+		state.DenoCacheDir = types.StringValue(cacheDir)
+	}
+
+	defaultPermissions, diags := types.ListValueFrom(ctx, types.StringType, append([]string{}, d.providerConfig.ClientOptions.DefaultPermissionArgs()...))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.DefaultPermissions = defaultPermissions
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestConfigDataSource(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "denobridge_config" "test" {}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.denobridge_config.test",
+						tfjsonpath.New("deno_binary_path"),
+						knownvalue.NotNull(),
+					),
+					statecheck.ExpectKnownValue(
+						"data.denobridge_config.test",
+						tfjsonpath.New("deno_version"),
+						knownvalue.StringRegexp(regexp.MustCompile(`^v\d+\.\d+\.\d+`)),
+					),
+					statecheck.ExpectKnownValue(
+						"data.denobridge_config.test",
+						tfjsonpath.New("deno_cache_dir"),
+						knownvalue.NotNull(),
+					),
+					statecheck.ExpectKnownValue(
+						"data.denobridge_config.test",
+						tfjsonpath.New("default_permissions"),
+						knownvalue.ListSizeExact(0),
+					),
+				},
+			},
+		},
+	})
+}
+
+// TestConfigDataSource_DenoFromPath tests that the version and default permissions are exposed
+// when Deno was found on the PATH instead of being downloaded, so its version is not known up front.
+func TestConfigDataSource_DenoFromPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake Deno binary is a shell script")
+	}
+	fakeDeno := filepath.Join(t.TempDir(), "deno")
+	if err := os.WriteFile(fakeDeno, []byte("#!/bin/sh\necho 'deno 2.1.4 (stable, release, x86_64-unknown-linux-gnu)'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		options *deno.ClientOptions
+		want    []string
+	}{
+		{"no options", nil, []string{}},
+		{"trusted import hosts", &deno.ClientOptions{TrustedImportHosts: []string{"deno.land"}}, []string{"--allow-import=jsr.io,deno.land"}},
+	}
+	for _, tc := range cases {
+		d := &configDataSource{providerConfig: &ProviderConfig{DenoBinaryPath: fakeDeno, ClientOptions: tc.options}}
+		schemaResp := &datasource.SchemaResponse{}
+		d.Schema(t.Context(), datasource.SchemaRequest{}, schemaResp)
+		resp := &datasource.ReadResponse{State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(t.Context()), nil),
+		}}
+		d.Read(t.Context(), datasource.ReadRequest{}, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%s: unexpected diagnostics: %v", tc.name, resp.Diagnostics)
+		}
+
+		var state configDataSourceModel
+		if diags := resp.State.Get(t.Context(), &state); diags.HasError() {
+			t.Fatalf("%s: unexpected diagnostics: %v", tc.name, diags)
+		}
+		if got := state.DenoVersion.ValueString(); got != "v2.1.4" {
+			t.Errorf("%s: expected deno_version v2.1.4, got %q", tc.name, got)
+		}
+		if !state.DenoCacheDir.IsNull() {
+			t.Errorf("%s: expected a null deno_cache_dir, got %s", tc.name, state.DenoCacheDir)
+		}
+		var got []string
+		if diags := state.DefaultPermissions.ElementsAs(t.Context(), &got, false); diags.HasError() {
+			t.Fatalf("%s: unexpected diagnostics: %v", tc.name, diags)
+		}
+		if state.DefaultPermissions.IsNull() || !slices.Equal(got, tc.want) {
+			t.Errorf("%s: expected default_permissions %v, got %s", tc.name, tc.want, state.DefaultPermissions)
+		}
+	}
+}
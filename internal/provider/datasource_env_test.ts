@@ -0,0 +1,15 @@
+import { DatasourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  name: string;
+}
+
+interface Result {
+  value: string | null;
+}
+
+new DatasourceProvider<Props, Result>({
+  read({ name }) {
+    return { value: Deno.env.get(name) ?? null };
+  },
+});
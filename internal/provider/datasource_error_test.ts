@@ -0,0 +1,11 @@
+import { DatasourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  value: string;
+}
+
+new DatasourceProvider<Props, Record<string, never>>({
+  read({ value }) {
+    throw new Error(`failed to read ${value}`);
+  },
+});
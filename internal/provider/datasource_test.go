@@ -1,8 +1,10 @@
 package provider
 
 import (
+	"regexp"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-testing/compare"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
@@ -57,6 +59,29 @@ func TestDataSource(t *testing.T) {
 	})
 }
 
+func TestDataSourceReadError(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "denobridge_datasource" "test" {
+						path = "./datasource_error_test.ts"
+						props = {
+							value = "Hello World"
+						}
+					}
+				`,
+				ExpectError: regexp.MustCompile(`Failed to read data`),
+			},
+		},
+	})
+}
+
 func TestDatasourceWithZod(t *testing.T) {
 	t.Setenv("TF_ACC", "1")
 	t.Setenv("TF_LOG", "DEBUG")
@@ -104,3 +129,115 @@ func TestDatasourceWithZod(t *testing.T) {
 		},
 	})
 }
+
+func TestSensitiveOutputValues(t *testing.T) {
+	values, err := sensitiveOutputValues(map[string]any{
+		"api_key":  "abc123",
+		"port":     5432.0,
+		"ignored":  "not named",
+		"settings": map[string]any{"tls": true},
+	}, []string{"api_key", "port", "settings"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := map[string]string{"api_key": "abc123", "port": "5432", "settings": `{"tls":true}`}
+	if len(values) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, values)
+	}
+	for name, value := range expected {
+		if values[name] != value {
+			t.Errorf("Expected %s to be '%s', got '%s'", name, value, values[name])
+		}
+	}
+
+	if _, err := sensitiveOutputValues(map[string]any{"api_key": "abc123"}, []string{"password"}); err == nil {
+		t.Error("Expected an error for a sensitive output the script did not return")
+	}
+	if _, err := sensitiveOutputValues(nil, []string{"password"}); err == nil {
+		t.Error("Expected an error when the script returned no sensitive result")
+	}
+}
+
+func TestDataSourceEnv(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "denobridge_datasource" "test" {
+						path = "./datasource_env_test.ts"
+						props = {
+							name = "DENOBRIDGE_TEST_REGION"
+						}
+						env = {
+							DENOBRIDGE_TEST_REGION = "us-east-1"
+						}
+						permissions = {
+							allow = ["env=DENOBRIDGE_TEST_REGION"]
+						}
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.denobridge_datasource.test",
+						tfjsonpath.New("result").AtMapKey("value"),
+						knownvalue.StringExact("us-east-1"),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestDataSourceCache(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "denobridge_datasource" "first" {
+						path  = "./datasource_cache_test.ts"
+						props = { value = "shared" }
+					}
+					data "denobridge_datasource" "second" {
+						path  = "./datasource_cache_test.ts"
+						props = { value = "shared" }
+					}
+					data "denobridge_datasource" "uncached_first" {
+						path  = "./datasource_cache_test.ts"
+						props = { value = "shared" }
+						cache = false
+					}
+					data "denobridge_datasource" "uncached_second" {
+						path  = "./datasource_cache_test.ts"
+						props = { value = "shared" }
+						cache = false
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					// Identical reads share the result of running the script once
+					statecheck.CompareValuePairs(
+						"data.denobridge_datasource.first", tfjsonpath.New("result").AtMapKey("readId"),
+						"data.denobridge_datasource.second", tfjsonpath.New("result").AtMapKey("readId"),
+						compare.ValuesSame(),
+					),
+					// While reads that opt out of the cache run the script every time
+					statecheck.CompareValuePairs(
+						"data.denobridge_datasource.uncached_first", tfjsonpath.New("result").AtMapKey("readId"),
+						"data.denobridge_datasource.uncached_second", tfjsonpath.New("result").AtMapKey("readId"),
+						compare.ValuesDiffer(),
+					),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// parseDefaultProps returns the provider's default_props, as merged into the props of every resource.
+// It returns nil when no default props are set, or they are not yet known.
+func parseDefaultProps(value types.Dynamic) (map[string]any, error) {
+	switch v := dynamic.FromDynamic(value).(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("expected an object such as { environment = \"prod\" }, got %s", describeValueKind(v))
+	}
+}
+
+// withDefaultProps returns a resource's props, as returned by dynamic.FromDynamic, with the provider's default_props
+// merged beneath them. See mergeDefaultProps.
+func (c *ProviderConfig) withDefaultProps(props any) any {
+	if c == nil || len(c.DefaultProps) == 0 {
+		return props
+	}
+	return mergeDefaultProps(c.DefaultProps, props)
+}
+
+// mergeDefaultProps deep merges defaults beneath props, neither of which are modified.
+//
+// Objects are merged key by key, recursively, and any other value set by props takes precedence over the default,
+// including lists, which are not merged, and null. A resource without props is given the defaults, props that are
+// not an object are left as they are.
+func mergeDefaultProps(defaults map[string]any, props any) any {
+	if props == nil {
+		return cloneProps(defaults)
+	}
+	fields, ok := props.(map[string]any)
+	if !ok {
+		return props
+	}
+
+	merged := make(map[string]any, len(defaults)+len(fields))
+	for key, value := range defaults {
+		merged[key] = cloneProps(value)
+	}
+	for key, value := range fields {
+		defaultFields, isDefaultObject := defaults[key].(map[string]any)
+		if _, isObject := value.(map[string]any); isDefaultObject && isObject {
+			merged[key] = mergeDefaultProps(defaultFields, value)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// withoutDefaultProps returns props returned by a resource's script, eg: refreshed by read, without the default_props
+// that were merged into them, so that they are not stored in state and planned as a change to the resource's config.
+// own are the resource's own props, as returned by dynamic.FromDynamic. See stripDefaultProps.
+func (c *ProviderConfig) withoutDefaultProps(props, own any) any {
+	if c == nil || len(c.DefaultProps) == 0 {
+		return props
+	}
+	return stripDefaultProps(c.DefaultProps, props, own)
+}
+
+// stripDefaultProps removes the props that still hold their default value and that the resource does not set
+// itself, recursing into objects, without modifying props. A prop whose value differs from its default is kept,
+// so that drift from the default is planned as a change, which sends the default to the script again.
+func stripDefaultProps(defaults map[string]any, props, own any) any {
+	fields, ok := props.(map[string]any)
+	if !ok {
+		return props
+	}
+	ownFields, _ := own.(map[string]any)
+
+	stripped := make(map[string]any, len(fields))
+	for key, value := range fields {
+		defaultValue, isDefault := defaults[key]
+		ownValue, isOwn := ownFields[key]
+		switch {
+		case !isDefault:
+			stripped[key] = value
+		case !isOwn && samePropValue(value, defaultValue):
+			// Left out, it is the default
+		default:
+			defaultFields, isDefaultObject := defaultValue.(map[string]any)
+			if _, isObject := value.(map[string]any); isDefaultObject && isObject {
+				value = stripDefaultProps(defaultFields, value, ownValue)
+				if len(value.(map[string]any)) == 0 && !isOwn {
+					continue
+				}
+			}
+			stripped[key] = value
+		}
+	}
+	return stripped
+}
+
+// samePropValue reports whether two values hold the same JSON, whether they were decoded from JSON or
+// returned by dynamic.FromDynamic, which represent numbers differently.
+func samePropValue(a, b any) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aJSON, bJSON)
+}
+
+// cloneProps deep copies a value as returned by dynamic.FromDynamic, so that the copy may be modified in place,
+// eg: by dynamic.ReplaceNonFinite, without modifying the original.
+func cloneProps(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		clone := make(map[string]any, len(v))
+		for key, item := range v {
+			clone[key] = cloneProps(item)
+		}
+		return clone
+	case []any:
+		clone := make([]any, len(v))
+		for i, item := range v {
+			clone[i] = cloneProps(item)
+		}
+		return clone
+	default:
+		return v
+	}
+}
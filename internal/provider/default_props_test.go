@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
+)
+
+func TestMergeDefaultProps(t *testing.T) {
+	defaults := map[string]any{
+		"environment": "prod",
+		"team":        "platform",
+		"tags":        map[string]any{"cost-center": "1234", "owner": "ops"},
+		"zones":       []any{"a", "b"},
+	}
+	tests := []struct {
+		name     string
+		props    any
+		expected any
+	}{
+		{
+			name:  "resource props take precedence",
+			props: map[string]any{"name": "web", "team": "frontend"},
+			expected: map[string]any{
+				"name": "web", "environment": "prod", "team": "frontend",
+				"tags":  map[string]any{"cost-center": "1234", "owner": "ops"},
+				"zones": []any{"a", "b"},
+			},
+		},
+		{
+			name:  "nested objects are merged",
+			props: map[string]any{"tags": map[string]any{"owner": "web", "tier": "1"}},
+			expected: map[string]any{
+				"environment": "prod", "team": "platform",
+				"tags":  map[string]any{"cost-center": "1234", "owner": "web", "tier": "1"},
+				"zones": []any{"a", "b"},
+			},
+		},
+		{
+			name:  "lists and nulls replace the default",
+			props: map[string]any{"zones": []any{"c"}, "tags": nil},
+			expected: map[string]any{
+				"environment": "prod", "team": "platform",
+				"tags":  nil,
+				"zones": []any{"c"},
+			},
+		},
+		{
+			name:  "no props",
+			props: nil,
+			expected: map[string]any{
+				"environment": "prod", "team": "platform",
+				"tags":  map[string]any{"cost-center": "1234", "owner": "ops"},
+				"zones": []any{"a", "b"},
+			},
+		},
+		{
+			name:     "props that are not an object",
+			props:    []any{"x"},
+			expected: []any{"x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if merged := mergeDefaultProps(defaults, tt.props); !reflect.DeepEqual(merged, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, merged)
+			}
+		})
+	}
+
+	// The defaults are copied, so modifying the merged props in place leaves them as they were
+	merged := mergeDefaultProps(defaults, map[string]any{}).(map[string]any)
+	merged["tags"].(map[string]any)["owner"] = "changed"
+	if defaults["tags"].(map[string]any)["owner"] != "ops" {
+		t.Errorf("Expected the defaults to be left unmodified, got %v", defaults)
+	}
+}
+
+func TestStripDefaultProps(t *testing.T) {
+	defaults := map[string]any{
+		"environment": "prod",
+		"replicas":    int64(2),
+		"tags":        map[string]any{"cost-center": "1234"},
+	}
+	own := map[string]any{"name": "web", "tags": map[string]any{"owner": "web"}}
+
+	// As decoded from a script's JSON response, which holds numbers as float64
+	props := map[string]any{
+		"name":        "web",
+		"environment": "prod",
+		"replicas":    float64(2),
+		"tags":        map[string]any{"cost-center": "1234", "owner": "web"},
+	}
+	expected := map[string]any{"name": "web", "tags": map[string]any{"owner": "web"}}
+	if stripped := stripDefaultProps(defaults, props, own); !reflect.DeepEqual(stripped, expected) {
+		t.Errorf("Expected %v, got %v", expected, stripped)
+	}
+
+	// Drift from a default is kept, so that it is planned as a change
+	props["environment"] = "dev"
+	expected["environment"] = "dev"
+	if stripped := stripDefaultProps(defaults, props, own); !reflect.DeepEqual(stripped, expected) {
+		t.Errorf("Expected %v, got %v", expected, stripped)
+	}
+
+	// A default the resource sets itself is its own
+	own["replicas"] = int64(2)
+	expected["replicas"] = float64(2)
+	if stripped := stripDefaultProps(defaults, props, own); !reflect.DeepEqual(stripped, expected) {
+		t.Errorf("Expected %v, got %v", expected, stripped)
+	}
+}
+
+func TestParseDefaultProps(t *testing.T) {
+	defaults, err := parseDefaultProps(dynamic.ToDynamic(map[string]any{"environment": "prod"}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(defaults, map[string]any{"environment": "prod"}) {
+		t.Errorf("Unexpected default props: %v", defaults)
+	}
+
+	if defaults, err := parseDefaultProps(dynamic.ToDynamic(nil)); err != nil || defaults != nil {
+		t.Errorf("Expected no default props, got %v, %v", defaults, err)
+	}
+
+	if _, err := parseDefaultProps(dynamic.ToDynamic([]any{"prod"})); err == nil || err.Error() != `expected an object such as { environment = "prod" }, got a list` {
+		t.Errorf("Expected an error for a list, got %v", err)
+	}
+}
+
+func TestResourcePropsFor_WriteOnlyPropsUnaffected(t *testing.T) {
+	c := &ProviderConfig{DefaultProps: map[string]any{"environment": "prod", "password": "default"}}
+	writeOnlyProps := map[string]any{"password": "secret"}
+	hash := hashJSON(writeOnlyProps)
+
+	props, err := c.resourcePropsFor(dynamic.ToDynamic(map[string]any{"name": "web"}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := map[string]any{"name": "web", "environment": "prod", "password": "default"}
+	if !reflect.DeepEqual(props, expected) {
+		t.Errorf("Expected %v, got %v", expected, props)
+	}
+
+	// Defaults only ever reach props, the write-only props and so their hash are left as they were
+	if !reflect.DeepEqual(writeOnlyProps, map[string]any{"password": "secret"}) || hashJSON(writeOnlyProps) != hash {
+		t.Errorf("Expected the write-only props to be unaffected, got %v", writeOnlyProps)
+	}
+}
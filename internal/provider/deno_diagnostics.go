@@ -0,0 +1,269 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
+	"github.com/brad-jones/terraform-provider-denobridge/internal/jsocket"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// addDenoErrorDiagnostics explains why an operation against a Deno script failed, when the cause can be
+// determined from the Deno runtime's output.
+//
+// A targeted error diagnostic is added for each permission the Deno runtime refused to grant to the script,
+// telling the user exactly what to add to the permissions allow list. In learn mode a warning also suggests
+// a complete least-privilege allow list. An error is also added when the script
+// ran out of heap memory, as the raw crash output does not make the cause obvious.
+// When the script threw an error it did not handle, the error message and the top
+// frame of its stack trace are reported rather than the whole trace, and an error is
+// added when the process was forcefully killed, or did not respond within deno_call_timeout.
+// Otherwise a process that exited without responding is explained, rather than leaving a bare RPC error.
+//
+// Call this after an operation against the Deno script has failed, passing the error that was returned.
+func addDenoErrorDiagnostics(diags *diag.Diagnostics, client *deno.DenoClient, err error) {
+	missing := client.MissingPermissions(err)
+	for _, perm := range missing {
+		detail := fmt.Sprintf("The Deno script requires %s access", perm.Name)
+		if perm.Value != "" {
+			detail += fmt.Sprintf(" to %q", perm.Value)
+		}
+		detail += fmt.Sprintf(
+			", which has not been granted.\n\nAdd %q to the permissions allow list to grant it, for example:\n\n"+
+				"  permissions = {\n    allow = [%q]\n  }",
+			perm.AllowEntry(), perm.AllowEntry(),
+		)
+		diags.AddAttributeError(path.Root("permissions").AtName("allow"), "Missing Deno permission", detail)
+	}
+
+	if suggested := client.SuggestAllowList(missing); suggested != nil {
+		entries := make([]string, 0, len(suggested))
+		for _, entry := range suggested {
+			entries = append(entries, fmt.Sprintf("%q", entry))
+		}
+		diags.AddAttributeWarning(
+			path.Root("permissions").AtName("allow"),
+			"Suggested Deno permissions",
+			fmt.Sprintf(
+				"Learn mode observed the script being refused permissions. An allow list granting everything observed so far is:\n\n"+
+					"  permissions = {\n    allow = [%s]\n  }\n\n"+
+					"Apply it and run again until no further permissions are reported, then remove learn = true.",
+				strings.Join(entries, ", "),
+			),
+		)
+	}
+
+	outOfMemory := client.OutOfMemory()
+	if outOfMemory {
+		diags.AddError(
+			"Deno script ran out of memory",
+			"The Deno script exhausted its V8 heap and was terminated. If the script legitimately needs more memory "+
+				"raise deno_max_heap_mb in the provider configuration, otherwise check the script for unbounded memory use.",
+		)
+	}
+
+	// The process may be killed for running out of memory without V8 reporting it, eg: by the OOM killer
+	var exitErr *deno.ExitError
+	if !outOfMemory && errors.As(err, &exitErr) && exitErr.Killed() {
+		diags.AddError(
+			"Deno script was killed",
+			"The Deno process was forcefully killed, most often by the operating system because it ran out of memory. "+
+				"Check the script for unbounded memory use, or the memory available to the machine running Terraform.",
+		)
+	}
+
+	var timeoutErr *jsocket.CallTimeoutError
+	if errors.As(err, &timeoutErr) {
+		diags.AddError(
+			"Deno script timed out",
+			fmt.Sprintf(
+				"The Deno script did not respond to %s() within %s and was sent a cancel notification. "+
+					"Raise deno_call_timeout in the provider configuration if the script needs longer.",
+				timeoutErr.Method, timeoutErr.Timeout,
+			),
+		)
+	}
+
+	// Permission and memory errors are also thrown as uncaught errors, but are already explained above
+	scriptError := client.ScriptError()
+	if scriptError != nil && len(missing) == 0 && !outOfMemory {
+		detail := scriptError.Message
+		if scriptError.Frame != "" {
+			detail += "\n    at " + scriptError.Frame
+		}
+		diags.AddError(
+			"Deno script threw an uncaught error",
+			detail+"\n\nThe full stack trace is written to the provider's debug log, see TF_LOG_PROVIDER=DEBUG.",
+		)
+	}
+
+	// Any other exit is explained here, the cause is left to the script's own output
+	var exitedErr *deno.ProcessExitedError
+	if errors.As(err, &exitedErr) && scriptError == nil && len(missing) == 0 && !outOfMemory && (exitErr == nil || !exitErr.Killed()) {
+		diags.AddError("Deno script exited unexpectedly", processExitedDetail(exitedErr))
+	}
+}
+
+// processExitedDetail explains what to look at when the Deno process exited without responding to a call.
+func processExitedDetail(err *deno.ProcessExitedError) string {
+	var detail strings.Builder
+	if err.BeforeCall {
+		fmt.Fprintf(&detail, "The Deno process had already exited when %s() was called, so it was never run. "+
+			"The script may have crashed after its previous call, eg: from an unhandled promise rejection in a timer.", err.Method)
+	} else {
+		fmt.Fprintf(&detail, "The Deno process exited before responding to %s().", err.Method)
+	}
+	if exit := err.Unwrap(); exit != nil {
+		status := exit.Error()
+		fmt.Fprintf(&detail, " %s%s, check the script's output for the cause.", strings.ToUpper(status[:1]), status[1:])
+	} else {
+		detail.WriteString(" It exited cleanly, check that the script does not call Deno.exit() and that it keeps " +
+			"running until the provider asks it to shutdown.")
+	}
+	if len(err.Stderr) > 0 {
+		detail.WriteString("\n\nThe last lines written to stderr were:\n" + strings.Join(err.Stderr, "\n"))
+	}
+	detail.WriteString("\n\nThe script's full output is written to the provider's debug log, see TF_LOG_PROVIDER=DEBUG.")
+	return detail.String()
+}
+
+// addEnvFileDiagnostics warns when an env file is configured but the script has not been
+// granted the env permission, in which case it can not read the variables that were loaded.
+func addEnvFileDiagnostics(diags *diag.Diagnostics, envFile types.String, permissions *deno.PermissionsTF) {
+	if envFile.ValueString() == "" || permissions.MapToDenoPermissions().Grants("env") {
+		return
+	}
+	diags.AddAttributeWarning(
+		path.Root("env_file"),
+		"Deno env permission not granted",
+		fmt.Sprintf(
+			"The variables in %s are loaded into the script's environment, but the script can not read them "+
+				"without the env permission. Add \"env\" to the permissions allow list to grant it.",
+			envFile.ValueString(),
+		),
+	)
+}
+
+// addEnvDiagnostics warns when env sets variables but the script has not been granted the env
+// permission, in which case it can not read them.
+func addEnvDiagnostics(diags *diag.Diagnostics, env types.Map, permissions *deno.PermissionsTF) {
+	if len(envVars(env)) == 0 || permissions.MapToDenoPermissions().Grants("env") {
+		return
+	}
+	diags.AddAttributeWarning(
+		path.Root("env"),
+		"Deno env permission not granted",
+		"The variables in env are set in the script's environment, but the script can not read them "+
+			"without the env permission. Add \"env\" to the permissions allow list to grant it, or scope it "+
+			"to the variables the script needs, eg: \"env=AWS_REGION\".",
+	)
+}
+
+// addDeclaredPermissionDiagnostics warns when the configured permissions do not match the permissions
+// the script declared it needs, as either the script will be refused access part way through or it has
+// been granted more than its author intended.
+//
+// Scripts that do not declare their permissions are not checked. Call this once the client has started.
+func addDeclaredPermissionDiagnostics(diags *diag.Diagnostics, client *deno.DenoClient, permissions *deno.PermissionsTF) {
+	declared := client.DeclaredPermissions()
+	if declared == nil {
+		return
+	}
+
+	insufficient, broad := permissions.MapToDenoPermissions().CompareDeclared(declared)
+	if len(insufficient) > 0 {
+		diags.AddAttributeWarning(
+			path.Root("permissions").AtName("allow"),
+			"Deno permissions insufficient",
+			fmt.Sprintf(
+				"The Deno script declares that it needs permissions that have not been granted:\n\n- %s\n\n"+
+					"Add them to the permissions allow list, otherwise the script may be refused access part way through.",
+				strings.Join(insufficient, "\n- "),
+			),
+		)
+	}
+	if len(broad) > 0 {
+		needs := "no permissions at all."
+		if len(declared) > 0 {
+			needs = "only:\n\n- " + strings.Join(declared, "\n- ")
+		}
+		diags.AddAttributeWarning(
+			path.Root("permissions"),
+			"Deno permissions broader than declared",
+			fmt.Sprintf(
+				"The Deno script has been granted permissions beyond those it declares that it needs:\n\n- %s\n\n"+
+					"Consider removing them so that the script runs with least privilege, it declares that it needs %s",
+				strings.Join(broad, "\n- "), needs,
+			),
+		)
+	}
+}
+
+// redactDiagnostics scrubs the secrets known to client from the summary and detail of every diagnostic
+// in diags, eg: a write-only prop value that a script echoed back in an error message.
+func redactDiagnostics(diags *diag.Diagnostics, client *deno.DenoClient) {
+	for i, d := range *diags {
+		summary, detail := client.Redact(d.Summary()), client.Redact(d.Detail())
+		if summary == d.Summary() && detail == d.Detail() {
+			continue
+		}
+		withPath, hasPath := d.(diag.DiagnosticWithPath)
+		switch {
+		case hasPath && d.Severity() == diag.SeverityError:
+			(*diags)[i] = diag.NewAttributeErrorDiagnostic(withPath.Path(), summary, detail)
+		case hasPath:
+			(*diags)[i] = diag.NewAttributeWarningDiagnostic(withPath.Path(), summary, detail)
+		case d.Severity() == diag.SeverityError:
+			(*diags)[i] = diag.NewErrorDiagnostic(summary, detail)
+		default:
+			(*diags)[i] = diag.NewWarningDiagnostic(summary, detail)
+		}
+	}
+}
+
+// writeOnlySecrets returns every string found in the given write-only props, so that they can be scrubbed
+// from logs and diagnostics. Other values, eg: numbers, are too likely to match unrelated output.
+func writeOnlySecrets(props any) []string {
+	var secrets []string
+	switch v := props.(type) {
+	case string:
+		secrets = append(secrets, v)
+	case map[string]any:
+		for _, item := range v {
+			secrets = append(secrets, writeOnlySecrets(item)...)
+		}
+	case []any:
+		for _, item := range v {
+			secrets = append(secrets, writeOnlySecrets(item)...)
+		}
+	}
+	return secrets
+}
+
+// redactedPropSecrets returns every string found at, or nested within, each of the redact_props paths of the given
+// props, so that they are scrubbed from logs and diagnostics in the same way as write-only props. Nested keys are
+// separated by dots, numeric segments are list indexes and paths that are not found are ignored.
+func redactedPropSecrets(ctx context.Context, redactProps types.List, props ...types.Dynamic) []string {
+	if redactProps.IsNull() || redactProps.IsUnknown() {
+		return nil
+	}
+	var keys []string
+	_ = redactProps.ElementsAs(ctx, &keys, false)
+
+	var secrets []string
+	for _, p := range props {
+		value := dynamic.FromDynamic(p)
+		for _, key := range keys {
+			if found, ok := dynamic.ValueAt(value, strings.Split(key, ".")); ok {
+				secrets = append(secrets, writeOnlySecrets(found)...)
+			}
+		}
+	}
+	return secrets
+}
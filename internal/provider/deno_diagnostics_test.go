@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestRedactDiagnostics(t *testing.T) {
+	client := deno.NewDenoClient("deno", "script.ts", "", nil, nil, nil)
+	client.AddSecrets(writeOnlySecrets(map[string]any{
+		"password": "hunter2",
+		"keys":     []any{"key-one", 42.0},
+	})...)
+
+	var diags diag.Diagnostics
+	diags.AddError("Failed to create resource", "bad password: hunter2")
+	diags.AddAttributeWarning(path.Root("props"), "Rotate key-one", "it is old")
+	diags.AddWarning("Unrelated", "nothing to hide")
+	redactDiagnostics(&diags, client)
+
+	if got := diags[0].Detail(); got != "bad password: ***" {
+		t.Errorf("Expected the error detail to be redacted, got %q", got)
+	}
+	if got := diags[1].Summary(); got != "Rotate ***" {
+		t.Errorf("Expected the warning summary to be redacted, got %q", got)
+	}
+	if withPath, ok := diags[1].(diag.DiagnosticWithPath); !ok || !withPath.Path().Equal(path.Root("props")) {
+		t.Errorf("Expected the warning to keep its path, got %v", diags[1])
+	}
+	if diags[1].Severity() != diag.SeverityWarning || diags.ErrorsCount() != 1 {
+		t.Errorf("Expected the severities to be kept, got %v", diags)
+	}
+	if got := diags[2].Detail(); got != "nothing to hide" {
+		t.Errorf("Expected other diagnostics to be unchanged, got %q", got)
+	}
+}
+
+func TestWriteOnlySecrets(t *testing.T) {
+	secrets := writeOnlySecrets(map[string]any{
+		"password": "hunter2",
+		"nested":   map[string]any{"tokens": []any{"a", "b"}},
+		"port":     5432.0,
+		"enabled":  true,
+	})
+	slices.Sort(secrets)
+	if !slices.Equal(secrets, []string{"a", "b", "hunter2"}) {
+		t.Errorf("Expected only the strings to be collected, got %v", secrets)
+	}
+	if secrets := writeOnlySecrets(nil); secrets != nil {
+		t.Errorf("Expected no secrets without write-only props, got %v", secrets)
+	}
+}
+
+func TestRedactedPropSecrets(t *testing.T) {
+	props := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{
+			"name":     types.StringType,
+			"password": types.StringType,
+			"hosts":    types.TupleType{ElemTypes: []attr.Type{types.StringType, types.StringType}},
+		},
+		map[string]attr.Value{
+			"name":     types.StringValue("db"),
+			"password": types.StringValue("hunter2"),
+			"hosts":    types.TupleValueMust([]attr.Type{types.StringType, types.StringType}, []attr.Value{types.StringValue("a.internal"), types.StringValue("b.internal")}),
+		},
+	))
+	redactProps := types.ListValueMust(types.StringType, []attr.Value{
+		types.StringValue("password"),
+		types.StringValue("hosts.1"),
+		types.StringValue("missing.path"),
+	})
+
+	secrets := redactedPropSecrets(context.Background(), redactProps, props)
+	slices.Sort(secrets)
+	if !slices.Equal(secrets, []string{"b.internal", "hunter2"}) {
+		t.Errorf("Expected the strings at the redacted paths, got %v", secrets)
+	}
+
+	// The values are masked in everything the client logs, eg: the JSON-RPC messages logged at trace level
+	client := deno.NewDenoClient("deno", "script.ts", "", nil, nil, nil)
+	client.AddSecrets(secrets...)
+	message := `--> request #1: create: {"props":{"hosts":["a.internal","b.internal"],"name":"db","password":"hunter2"}}`
+	expected := `--> request #1: create: {"props":{"hosts":["a.internal","***"],"name":"db","password":"***"}}`
+	if got := client.Redact(message); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if secrets := redactedPropSecrets(context.Background(), types.ListNull(types.StringType), props); secrets != nil {
+		t.Errorf("Expected no secrets without redact_props, got %v", secrets)
+	}
+}
+
+func TestAddDenoErrorDiagnostics_Killed(t *testing.T) {
+	client := deno.NewDenoClient("deno", "script.ts", "", nil, nil, nil)
+
+	var diags diag.Diagnostics
+	addDenoErrorDiagnostics(&diags, client, fmt.Errorf("the Deno script exited before responding to create(): %w", &deno.ExitError{Code: 137}))
+	if len(diags) != 1 || diags[0].Summary() != "Deno script was killed" {
+		t.Errorf("Expected the kill to be explained, got %v", diags)
+	}
+
+	diags = nil
+	addDenoErrorDiagnostics(&diags, client, &deno.ExitError{Code: 1})
+	if len(diags) != 0 {
+		t.Errorf("Expected no diagnostics for a script that exited by itself, got %v", diags)
+	}
+}
+
+func TestAddDenoErrorDiagnostics_ProcessExited(t *testing.T) {
+	client := deno.NewDenoClient("deno", "script.ts", "", nil, nil, nil)
+
+	var diags diag.Diagnostics
+	exited := &deno.ProcessExitedError{Method: "read", BeforeCall: true, Stderr: []string{"shutting down"}}
+	addDenoErrorDiagnostics(&diags, client, fmt.Errorf("failed to call read method over JSON-RPC: %w", exited))
+	if len(diags) != 1 || diags[0].Summary() != "Deno script exited unexpectedly" {
+		t.Fatalf("Expected the exit to be explained, got %v", diags)
+	}
+	for _, expected := range []string{"had already exited when read() was called", "does not call Deno.exit()", "shutting down"} {
+		if !strings.Contains(diags[0].Detail(), expected) {
+			t.Errorf("Expected the detail to contain %q, got %q", expected, diags[0].Detail())
+		}
+	}
+}
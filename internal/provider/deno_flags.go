@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// denoFlagsValidator rejects deno_flags entries that conflict with the flags the provider controls, see deno.ValidateDenoFlag.
+type denoFlagsValidator struct{}
+
+// Description describes the validation in plain text formatting.
+func (v denoFlagsValidator) Description(_ context.Context) string {
+	return "entries must be deno run flags starting with a dash, other than the config, permission and other flags controlled by the provider"
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v denoFlagsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateList validates the entries of the list, entries that are not known yet are skipped.
+func (v denoFlagsValidator) ValidateList(_ context.Context, req validator.ListRequest, resp *validator.ListResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	for i, elem := range req.ConfigValue.Elements() {
+		flag, ok := elem.(types.String)
+		if !ok || flag.IsNull() || flag.IsUnknown() {
+			continue
+		}
+		if err := deno.ValidateDenoFlag(flag.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(req.Path.AtListIndex(i), "Invalid deno flag", err.Error())
+		}
+	}
+}
+
+// denoFlags returns the known entries of a deno_flags attribute, nil when it is null or unknown.
+func denoFlags(flags types.List) []string {
+	if flags.IsNull() || flags.IsUnknown() {
+		return nil
+	}
+	var values []string
+	for _, elem := range flags.Elements() {
+		if flag, ok := elem.(types.String); ok && !flag.IsNull() && !flag.IsUnknown() {
+			values = append(values, flag.ValueString())
+		}
+	}
+	return values
+}
@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// startRetryBackoff is how long to wait before the first retry of a failed start, doubling after each attempt.
+var startRetryBackoff = 250 * time.Millisecond
+
+// denoStarter is the part of deno.DenoClient used by startDeno.
+type denoStarter interface {
+	Start(ctx context.Context) error
+	StartRetries() int64
+}
+
+// startDeno starts the Deno child process, retrying with backoff up to the configured
+// number of times, as start up failures are often transient under heavy parallel load.
+//
+// The error of the last attempt is returned when every attempt fails.
+func startDeno(ctx context.Context, client denoStarter) error {
+	retries := client.StartRetries()
+	backoff := startRetryBackoff
+	for attempt := int64(1); ; attempt++ {
+		err := client.Start(ctx)
+		if err == nil || attempt > retries || ctx.Err() != nil {
+			return err
+		}
+
+		tflog.Warn(ctx, fmt.Sprintf("Failed to start Deno, retrying in %s (retry %d of %d): %s", backoff, attempt, retries, err.Error()))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyStarter fails to start a given number of times before succeeding.
+type flakyStarter struct {
+	failures int
+	retries  int64
+	attempts int
+}
+
+func (s *flakyStarter) Start(_ context.Context) error {
+	s.attempts++
+	if s.attempts <= s.failures {
+		return errors.New("failed to create stdout pipe: too many open files")
+	}
+	return nil
+}
+
+func (s *flakyStarter) StartRetries() int64 {
+	return s.retries
+}
+
+func TestStartDeno_RetriesTransientFailure(t *testing.T) {
+	startRetryBackoff = time.Millisecond
+	t.Cleanup(func() { startRetryBackoff = 250 * time.Millisecond })
+
+	s := &flakyStarter{failures: 1, retries: 2}
+	if err := startDeno(t.Context(), s); err != nil {
+		t.Fatalf("Expected the retry to succeed, got: %s", err)
+	}
+	if s.attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", s.attempts)
+	}
+}
+
+func TestStartDeno_GivesUpAfterRetries(t *testing.T) {
+	startRetryBackoff = time.Millisecond
+	t.Cleanup(func() { startRetryBackoff = 250 * time.Millisecond })
+
+	s := &flakyStarter{failures: 5, retries: 2}
+	if err := startDeno(t.Context(), s); err == nil {
+		t.Fatal("Expected an error once the retries were exhausted")
+	}
+	if s.attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", s.attempts)
+	}
+}
+
+func TestStartDeno_NoRetries(t *testing.T) {
+	s := &flakyStarter{failures: 1}
+	if err := startDeno(t.Context(), s); err == nil {
+		t.Fatal("Expected the failure to be returned without retrying")
+	}
+	if s.attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", s.attempts)
+	}
+}
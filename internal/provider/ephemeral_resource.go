@@ -10,6 +10,7 @@ import (
 	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -33,12 +34,16 @@ type denoBridgeEphemeralResource struct {
 
 // denoBridgeEphemeralResourceModel maps the resource schema data.
 type denoBridgeEphemeralResourceModel struct {
-	Path            types.String        `tfsdk:"path"`
-	Props           types.Dynamic       `tfsdk:"props"`
-	Result          types.Dynamic       `tfsdk:"result"`
-	SensitiveResult types.Dynamic       `tfsdk:"sensitive_result"`
-	ConfigFile      types.String        `tfsdk:"config_file"`
-	Permissions     *deno.PermissionsTF `tfsdk:"permissions"`
+	Path              types.String        `tfsdk:"path"`
+	Props             types.Dynamic       `tfsdk:"props"`
+	Result            types.Dynamic       `tfsdk:"result"`
+	SensitiveResult   types.Dynamic       `tfsdk:"sensitive_result"`
+	ConfigFile        types.String        `tfsdk:"config_file"`
+	NoConfigDiscovery types.Bool          `tfsdk:"no_config_discovery"`
+	EnvFile           types.String        `tfsdk:"env_file"`
+	Env               types.Map           `tfsdk:"env"`
+	DenoFlags         types.List          `tfsdk:"deno_flags"`
+	Permissions       *deno.PermissionsTF `tfsdk:"permissions"`
 }
 
 func (r *denoBridgeEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
@@ -50,7 +55,7 @@ func (r *denoBridgeEphemeralResource) Schema(_ context.Context, _ ephemeral.Sche
 		Description: "Bridges the terraform-plugin-framework Ephemeral Resource to a Deno script.",
 		Attributes: map[string]schema.Attribute{
 			"path": schema.StringAttribute{
-				Description: "Path to the Deno script to execute.",
+				Description: "Path to the Deno script to execute, or env:NAME to read the path from the environment variable NAME when the script is run.",
 				Required:    true,
 			},
 			"props": schema.DynamicAttribute{
@@ -70,6 +75,26 @@ func (r *denoBridgeEphemeralResource) Schema(_ context.Context, _ ephemeral.Sche
 				Description: "File path to a deno config file to use with the deno script. Useful for import maps, etc...",
 				Optional:    true,
 			},
+			"no_config_discovery": schema.BoolAttribute{
+				Description: "Disable locating the closest deno config file relative to the script when config_file is not set. The script is then run with --no-config.",
+				Optional:    true,
+			},
+			"env_file": schema.StringAttribute{
+				Description: "Path to a .env file whose variables are loaded into the script's environment via --env-file. The script needs the env permission to read them.",
+				Optional:    true,
+			},
+			"env": schema.MapAttribute{
+				Description: "Environment variables set in the script's environment, on top of any set by the provider's env. The script needs the env permission to read them, which may be scoped to just these variables, eg: env=AWS_REGION.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"deno_flags": schema.ListAttribute{
+				Description: "Extra flags passed to deno run after the permissions, eg: --node-modules-dir=auto or --allow-scripts for scripts that import npm packages. Flags the provider controls, such as the config file and permissions, are rejected as they are set by their own attributes.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators:  []validator.List{denoFlagsValidator{}},
+			},
 			"permissions": schema.SingleNestedAttribute{
 				Description: "Deno runtime permissions for the script.",
 				Optional:    true,
@@ -79,14 +104,20 @@ func (r *denoBridgeEphemeralResource) Schema(_ context.Context, _ ephemeral.Sche
 						Optional:    true,
 					},
 					"allow": schema.ListAttribute{
-						Description: "List of permissions to allow (e.g., 'read', 'write', 'net').",
+						Description: "List of permissions to allow, optionally scoped to particular values (e.g., 'read', 'net', 'read=/etc,/tmp', 'net=api.github.com:443').",
 						ElementType: types.StringType,
 						Optional:    true,
+						Validators:  []validator.List{permissionEntriesValidator{}},
 					},
 					"deny": schema.ListAttribute{
-						Description: "List of permissions to deny.",
+						Description: "List of permissions to deny, taking precedence over all and allow (e.g., 'write=/etc').",
 						ElementType: types.StringType,
 						Optional:    true,
+						Validators:  []validator.List{permissionEntriesValidator{}},
+					},
+					"learn": schema.BoolAttribute{
+						Description: "Learn mode. When the script is refused a permission, suggest a least-privilege allow list covering every permission observed so far.",
+						Optional:    true,
 					},
 				},
 			},
@@ -121,15 +152,28 @@ func (r *denoBridgeEphemeralResource) Open(ctx context.Context, req ephemeral.Op
 		return
 	}
 
+	// Warn if the script can not read the variables loaded from its env file
+	addEnvFileDiagnostics(&resp.Diagnostics, data.EnvFile, data.Permissions)
+	addEnvDiagnostics(&resp.Diagnostics, data.Env, data.Permissions)
+
+	// Evaluate any props template functions
+	props, err := r.providerConfig.propsFor(data.Props)
+	if err != nil {
+		addPropsError(&resp.Diagnostics, err)
+		return
+	}
+
 	// Start the Deno server
 	c := deno.NewDenoClientEphemeralResource(
 		r.providerConfig.DenoBinaryPath,
 		data.Path.ValueString(),
 		data.ConfigFile.ValueString(),
 		data.Permissions.MapToDenoPermissions(),
+		r.providerConfig.clientOptionsFor(data.NoConfigDiscovery, data.EnvFile, data.Env, data.DenoFlags),
 	)
-	if err := c.Client.Start(ctx); err != nil {
+	if err := startDeno(ctx, c.Client); err != nil {
 		resp.Diagnostics.AddError("Failed to start Deno", err.Error())
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 	defer func() {
@@ -139,12 +183,14 @@ func (r *denoBridgeEphemeralResource) Open(ctx context.Context, req ephemeral.Op
 	}()
 
 	// Call the open endpoint
-	response, err := c.Open(ctx, &deno.OpenRequest{Props: dynamic.FromDynamic(data.Props)})
+	response, err := c.Open(ctx, &deno.OpenRequest{Props: props})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to open data",
 			fmt.Sprintf("Could not open data from Deno script: %s", err.Error()),
 		)
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
+		return
 	}
 
 	// Handle diagnostics - allows the script to add warnings or errors
@@ -196,6 +242,7 @@ func (r *denoBridgeEphemeralResource) Open(ctx context.Context, req ephemeral.Op
 		"DenoScriptPath":  data.Path.ValueString(),
 		"DenoConfigPath":  data.ConfigFile.ValueString(),
 		"DenoPermissions": data.Permissions.MapToDenoPermissions(),
+		"DenoOptions":     r.providerConfig.clientOptionsFor(data.NoConfigDiscovery, data.EnvFile, data.Env, data.DenoFlags),
 	})
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -224,6 +271,7 @@ func (r *denoBridgeEphemeralResource) Renew(ctx context.Context, req ephemeral.R
 		DenoScriptPath  string
 		DenoConfigPath  string
 		DenoPermissions *deno.Permissions
+		DenoOptions     *deno.ClientOptions
 	}
 	err := json.Unmarshal(privateConfigBytes, &privateConfig)
 	if err != nil {
@@ -258,9 +306,11 @@ func (r *denoBridgeEphemeralResource) Renew(ctx context.Context, req ephemeral.R
 		privateConfig.DenoScriptPath,
 		privateConfig.DenoConfigPath,
 		privateConfig.DenoPermissions,
+		privateConfig.DenoOptions,
 	)
-	if err := c.Client.Start(ctx); err != nil {
+	if err := startDeno(ctx, c.Client); err != nil {
 		resp.Diagnostics.AddError("Failed to start Deno", err.Error())
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 	defer func() {
@@ -276,6 +326,7 @@ func (r *denoBridgeEphemeralResource) Renew(ctx context.Context, req ephemeral.R
 			"Failed to renew",
 			fmt.Sprintf("Could not renew data from Deno script: %s", err.Error()),
 		)
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 
@@ -335,6 +386,7 @@ func (r *denoBridgeEphemeralResource) Close(ctx context.Context, req ephemeral.C
 		DenoScriptPath  string
 		DenoConfigPath  string
 		DenoPermissions *deno.Permissions
+		DenoOptions     *deno.ClientOptions
 	}
 	err := json.Unmarshal(privateConfigBytes, &privateConfig)
 	if err != nil {
@@ -369,9 +421,11 @@ func (r *denoBridgeEphemeralResource) Close(ctx context.Context, req ephemeral.C
 		privateConfig.DenoScriptPath,
 		privateConfig.DenoConfigPath,
 		privateConfig.DenoPermissions,
+		privateConfig.DenoOptions,
 	)
-	if err := c.Client.Start(ctx); err != nil {
+	if err := startDeno(ctx, c.Client); err != nil {
 		resp.Diagnostics.AddError("Failed to start Deno", err.Error())
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 	defer func() {
@@ -387,6 +441,7 @@ func (r *denoBridgeEphemeralResource) Close(ctx context.Context, req ephemeral.C
 			"Failed to close",
 			fmt.Sprintf("Could not close data from Deno script: %s", err.Error()),
 		)
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 
@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &validatePropsFunction{}
+
+// NewValidatePropsFunction is a helper function to simplify the provider implementation.
+func NewValidatePropsFunction(p *DenoBridgeProvider) func() function.Function {
+	return func() function.Function {
+		return &validatePropsFunction{provider: p}
+	}
+}
+
+// validatePropsFunction is the validate_props function implementation.
+type validatePropsFunction struct {
+	provider *DenoBridgeProvider
+}
+
+// Metadata returns the function name.
+func (f *validatePropsFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_props"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *validatePropsFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Validates props against the expectations of a Deno script.",
+		MarkdownDescription: "Runs the `validate` method of a Deno script against the given props and returns a list of " +
+			"validation errors. An empty list means the props are valid. Scripts built with the Zod providers " +
+			"validate against their props schema automatically. Useful for gating configuration with `precondition` blocks.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "path",
+				MarkdownDescription: "Path to the Deno script to validate the props with.",
+			},
+			function.DynamicParameter{
+				Name:                "props",
+				MarkdownDescription: "The props to validate.",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+// Run executes the function.
+func (f *validatePropsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var scriptPath string
+	var props types.Dynamic
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &scriptPath, &props))
+	if resp.Error != nil {
+		return
+	}
+
+	denoBinaryPath, err := f.denoBinaryPath(ctx)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Could not locate a Deno binary: %s", err.Error()))
+		return
+	}
+
+	// Start the Deno server
+	var options *deno.ClientOptions
+	if f.provider != nil && f.provider.config != nil {
+		options = f.provider.config.ClientOptions
+	}
+	c := deno.NewDenoClientFunction(denoBinaryPath, scriptPath, "", nil, options)
+	if err := startDeno(ctx, c.Client); err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Failed to start Deno: %s", err.Error()))
+		return
+	}
+	defer func() {
+		_ = c.Client.Stop()
+	}()
+
+	// Call the validate endpoint
+	response, err := c.Validate(ctx, &deno.ValidateRequest{Props: dynamic.FromDynamic(props)})
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Could not validate props via Deno script: %s", err.Error()))
+		return
+	}
+	if response == nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("The Deno script %s does not implement the validate method", scriptPath))
+		return
+	}
+
+	// Only errors make props invalid, warnings are dropped
+	errors := []string{}
+	if response.Diagnostics != nil {
+		for _, diag := range *response.Diagnostics {
+			if diag.Severity != "error" {
+				continue
+			}
+			msg := diag.Summary
+			if diag.Detail != "" {
+				msg += ": " + diag.Detail
+			}
+			if diag.PropPath != nil && len(*diag.PropPath) > 0 {
+				msg = strings.Join(*diag.PropPath, ".") + ": " + msg
+			}
+			errors = append(errors, msg)
+		}
+	}
+
+	result, diags := types.ListValueFrom(ctx, types.StringType, errors)
+	resp.Error = function.ConcatFuncErrors(resp.Error, function.FuncErrorFromDiags(ctx, diags))
+	if resp.Error != nil {
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
+
+// denoBinaryPath returns the Deno binary to run the script with.
+//
+// Terraform may call functions on a provider instance that has not been configured,
+// in which case a deno binary on the PATH is preferred before falling back to the
+// latest version from the download cache.
+func (f *validatePropsFunction) denoBinaryPath(ctx context.Context) (string, error) {
+	if f.provider != nil && f.provider.config != nil {
+		return f.provider.config.DenoBinaryPath, nil
+	}
+	if path, err := exec.LookPath("deno"); err == nil {
+		return path, nil
+	}
+	if f.provider != nil {
+		return f.provider.downloader().GetDenoBinary(ctx, "latest")
+	}
+	return deno.NewDenoDownloader().GetDenoBinary(ctx, "latest")
+}
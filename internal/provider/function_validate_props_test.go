@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestValidatePropsFunction(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					output "valid" {
+						value = provider::denobridge::validate_props("./resource_zod_test.ts", {
+							path    = "./test.txt"
+							content = "Hello World"
+						})
+					}
+
+					output "invalid" {
+						value = provider::denobridge::validate_props("./resource_zod_test.ts", {
+							path = "./test.txt"
+						})
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("valid", knownvalue.ListSizeExact(0)),
+					statecheck.ExpectKnownOutputValue("invalid", knownvalue.ListSizeExact(1)),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"context"
+	"sync"
+)
+
+// keyedLock is a set of locks identified by key, created as they are first taken.
+// The zero value is ready to use and it is safe for concurrent use.
+type keyedLock struct {
+	mu sync.Mutex
+
+	// held holds a channel for each key that is locked, closed once it is unlocked.
+	held map[string]chan struct{}
+}
+
+// lock waits until no one else holds the lock for key, then takes it until the returned func is called.
+// It gives up with the cause of ctx if ctx is done first.
+func (l *keyedLock) lock(ctx context.Context, key string) (func(), error) {
+	for {
+		l.mu.Lock()
+		held, ok := l.held[key]
+		if !ok {
+			if l.held == nil {
+				l.held = make(map[string]chan struct{})
+			}
+			release := make(chan struct{})
+			l.held[key] = release
+			l.mu.Unlock()
+			return func() {
+				l.mu.Lock()
+				delete(l.held, key)
+				l.mu.Unlock()
+				close(release)
+			}, nil
+		}
+		l.mu.Unlock()
+
+		select {
+		case <-held:
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+	}
+}
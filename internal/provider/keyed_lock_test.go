@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeyedLock(t *testing.T) {
+	var locks keyedLock
+
+	unlock, err := locks.lock(t.Context(), "key")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Other keys are not held up by the lock
+	unlockOther, err := locks.lock(t.Context(), "other")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	unlockOther()
+
+	// The same key waits until the lock is released, or gives up with the context
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := locks.lock(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the lock to be held, got %v", err)
+	}
+
+	locked := make(chan struct{})
+	go func() {
+		unlock, err := locks.lock(t.Context(), "key")
+		if err == nil {
+			unlock()
+		}
+		close(locked)
+	}()
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Error("Expected the lock to be taken once released")
+	}
+}
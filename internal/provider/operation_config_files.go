@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// operationConfigFilesTF maps the operation_config_files schema data, each config
+// file replaces the resource's config_file while that operation runs.
+type operationConfigFilesTF struct {
+	Create types.String `tfsdk:"create"`
+	Read   types.String `tfsdk:"read"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
+}
+
+// configFileFor returns the deno config file to run the given operation ("create", "read", "update" or "delete") with.
+//
+// This is the operation's own config file when operation_config_files sets one, otherwise the resource's config_file.
+// An empty string means no config file was set, in which case the closest config file to the script is discovered.
+func (m *denoBridgeResourceModel) configFileFor(ctx context.Context, operation string) string {
+	configFile := m.ConfigFile
+	if m.OperationConfigFiles != nil {
+		var override types.String
+		switch operation {
+		case "create":
+			override = m.OperationConfigFiles.Create
+		case "read":
+			override = m.OperationConfigFiles.Read
+		case "update":
+			override = m.OperationConfigFiles.Update
+		case "delete":
+			override = m.OperationConfigFiles.Delete
+		}
+		if override.ValueString() != "" {
+			tflog.Debug(ctx, fmt.Sprintf("Running the Deno script's %s with operation_config_files.%s: %s", operation, operation, override.ValueString()))
+			configFile = override
+		}
+	}
+	return configFile.ValueString()
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// operationPermissionsTF maps the operation_permissions schema data, each set of
+// permissions replaces the resource's permissions while that operation runs.
+type operationPermissionsTF struct {
+	Create *deno.PermissionsTF `tfsdk:"create"`
+	Read   *deno.PermissionsTF `tfsdk:"read"`
+	Update *deno.PermissionsTF `tfsdk:"update"`
+	Delete *deno.PermissionsTF `tfsdk:"delete"`
+}
+
+// permissionsFor returns the permissions to run the given operation ("create", "read", "update" or "delete") with.
+//
+// These are the operation's own permissions when operation_permissions sets them, otherwise the resource's permissions.
+// Which set was used, and the flags it maps to, is logged at info level so that the phase a script was given
+// elevated permissions in can be audited.
+func (m *denoBridgeResourceModel) permissionsFor(ctx context.Context, operation string) *deno.PermissionsTF {
+	permissions, source := m.Permissions, "permissions"
+	if m.OperationPermissions != nil {
+		var override *deno.PermissionsTF
+		switch operation {
+		case "create":
+			override = m.OperationPermissions.Create
+		case "read":
+			override = m.OperationPermissions.Read
+		case "update":
+			override = m.OperationPermissions.Update
+		case "delete":
+			override = m.OperationPermissions.Delete
+		}
+		if override != nil {
+			permissions, source = override, "operation_permissions."+operation
+		}
+	}
+
+	flags := strings.Join(permissions.MapToDenoPermissions().Args(), " ")
+	if flags == "" {
+		flags = "none"
+	}
+	tflog.Info(ctx, fmt.Sprintf("Running the Deno script's %s with its %s: %s", operation, source, flags))
+	return permissions
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// operationTimeoutsTF maps the timeouts schema data, each a Go duration limiting how long that operation may run.
+type operationTimeoutsTF struct {
+	Create types.String `tfsdk:"create"`
+	Read   types.String `tfsdk:"read"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
+}
+
+// errOperationTimeout is the cause of a context cancelled by withOperationTimeout, telling it apart from Terraform's own deadlines.
+var errOperationTimeout = errors.New("operation timed out")
+
+// withOperationTimeout limits how long the given operation ("create", "read", "update" or "delete") may run for,
+// when timeouts sets a limit for it. The returned timeout is zero when the operation is unlimited.
+//
+// Call addTimeoutDiagnostic once the operation is done, to explain a failure caused by the timeout.
+func (m *denoBridgeResourceModel) withOperationTimeout(ctx context.Context, operation string, diags *diag.Diagnostics) (context.Context, context.CancelFunc, time.Duration) {
+	if m.Timeouts == nil {
+		return ctx, func() {}, 0
+	}
+	var value types.String
+	switch operation {
+	case "create":
+		value = m.Timeouts.Create
+	case "read":
+		value = m.Timeouts.Read
+	case "update":
+		value = m.Timeouts.Update
+	case "delete":
+		value = m.Timeouts.Delete
+	}
+	if value.ValueString() == "" {
+		return ctx, func() {}, 0
+	}
+
+	timeout, err := time.ParseDuration(value.ValueString())
+	if err != nil || timeout <= 0 {
+		diags.AddAttributeError(
+			path.Root("timeouts").AtName(operation),
+			"Invalid timeout",
+			fmt.Sprintf("Expected a positive duration such as '10m', got: %s", value.ValueString()),
+		)
+		return ctx, func() {}, 0
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, errOperationTimeout)
+	return ctx, cancel, timeout
+}
+
+// addTimeoutDiagnostic explains that the operation failed because it ran for longer than its timeout, in which
+// case the Deno process has been stopped rather than left running.
+func addTimeoutDiagnostic(ctx context.Context, diags *diag.Diagnostics, operation string, timeout time.Duration) {
+	if timeout <= 0 || !errors.Is(context.Cause(ctx), errOperationTimeout) {
+		return
+	}
+	diags.AddAttributeError(
+		path.Root("timeouts").AtName(operation),
+		"Operation timed out",
+		fmt.Sprintf("%s timed out after %s and the Deno script was stopped. Increase timeouts.%s if the script needs longer.", operation, timeout, operation),
+	)
+}
+
+// durationValidator checks that a string attribute holds a positive Go duration, eg: "10m".
+type durationValidator struct{}
+
+// Description describes the validation in plain text.
+func (v durationValidator) Description(_ context.Context) string {
+	return "value must be a positive Go duration, eg: 10m or 1h30m"
+}
+
+// MarkdownDescription describes the validation in Markdown.
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString reports a value that is not a positive duration.
+func (v durationValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if timeout, err := time.ParseDuration(req.ConfigValue.ValueString()); err != nil || timeout <= 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid timeout",
+			fmt.Sprintf("Expected a positive duration such as '10m', got: %s", req.ConfigValue.ValueString()),
+		)
+	}
+}
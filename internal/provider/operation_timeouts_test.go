@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestWithOperationTimeout(t *testing.T) {
+	model := &denoBridgeResourceModel{Timeouts: &operationTimeoutsTF{
+		Create: types.StringValue("50ms"),
+		Read:   types.StringNull(),
+		Update: types.StringValue("soon"),
+	}}
+
+	// An operation without a timeout is unlimited
+	var diags diag.Diagnostics
+	ctx, cancel, timeout := model.withOperationTimeout(context.Background(), "read", &diags)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok || timeout != 0 || diags.HasError() {
+		t.Errorf("Expected read to be unlimited, got timeout %s and %v", timeout, diags)
+	}
+
+	// An invalid timeout is reported against its attribute
+	_, cancel, _ = model.withOperationTimeout(context.Background(), "update", &diags)
+	defer cancel()
+	if diags.ErrorsCount() != 1 {
+		t.Fatalf("Expected an error for an invalid timeout, got %v", diags)
+	}
+	if withPath, ok := diags[0].(diag.DiagnosticWithPath); !ok || !withPath.Path().Equal(path.Root("timeouts").AtName("update")) {
+		t.Errorf("Expected the error to point at timeouts.update, got %v", diags[0])
+	}
+
+	// Once the timeout elapses the failure is explained
+	diags = nil
+	ctx, cancel, timeout = model.withOperationTimeout(context.Background(), "create", &diags)
+	defer cancel()
+	if timeout != 50*time.Millisecond {
+		t.Errorf("Expected a 50ms timeout, got %s", timeout)
+	}
+	addTimeoutDiagnostic(ctx, &diags, "create", timeout)
+	if diags.HasError() {
+		t.Fatalf("Expected no error before the timeout elapsed, got %v", diags)
+	}
+	<-ctx.Done()
+	addTimeoutDiagnostic(ctx, &diags, "create", timeout)
+	if diags.ErrorsCount() != 1 || diags[0].Detail() != "create timed out after 50ms and the Deno script was stopped. Increase timeouts.create if the script needs longer." {
+		t.Errorf("Expected a timed out error, got %v", diags)
+	}
+}
+
+func TestAddTimeoutDiagnostic_OtherDeadline(t *testing.T) {
+	// A deadline set by Terraform rather than timeouts is not reported as the operation timing out
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	var diags diag.Diagnostics
+	addTimeoutDiagnostic(ctx, &diags, "delete", time.Minute)
+	if diags.HasError() {
+		t.Errorf("Expected no diagnostic, got %v", diags)
+	}
+}
+
+func TestDurationValidator(t *testing.T) {
+	tests := []struct {
+		value   types.String
+		wantErr bool
+	}{
+		{value: types.StringValue("10m"), wantErr: false},
+		{value: types.StringValue("1h30m"), wantErr: false},
+		{value: types.StringNull(), wantErr: false},
+		{value: types.StringUnknown(), wantErr: false},
+		{value: types.StringValue("10"), wantErr: true},
+		{value: types.StringValue("-5s"), wantErr: true},
+		{value: types.StringValue("0s"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value.String(), func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("timeouts").AtName("create"), ConfigValue: tt.value}
+			resp := &validator.StringResponse{}
+			durationValidator{}.ValidateString(context.Background(), req, resp)
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Errorf("Expected error %t, got %v", tt.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
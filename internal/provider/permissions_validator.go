@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// permissionEntriesValidator rejects invalid entries in a permissions allow or deny list,
+// eg: a permission given both bare and scoped, see deno.ValidatePermissionEntries.
+type permissionEntriesValidator struct{}
+
+// Description describes the validation in plain text formatting.
+func (v permissionEntriesValidator) Description(_ context.Context) string {
+	return "entries must be a permission name, optionally scoped to values, eg: read or read=/etc,/tmp, and a permission may not be both bare and scoped"
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v permissionEntriesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateList validates the entries of the list, entries that are not known yet are skipped.
+func (v permissionEntriesValidator) ValidateList(_ context.Context, req validator.ListRequest, resp *validator.ListResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var entries []string
+	var indexes []int
+	for i, elem := range req.ConfigValue.Elements() {
+		if entry, ok := elem.(types.String); ok && !entry.IsNull() && !entry.IsUnknown() {
+			entries = append(entries, entry.ValueString())
+			indexes = append(indexes, i)
+		}
+	}
+
+	var entryErr *deno.PermissionEntryError
+	if err := deno.ValidatePermissionEntries(entries); errors.As(err, &entryErr) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path.AtListIndex(indexes[entryErr.Index]),
+			"Invalid Deno permission",
+			entryErr.Error(),
+		)
+	}
+}
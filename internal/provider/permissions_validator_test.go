@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestPermissionEntriesValidator(t *testing.T) {
+	allow := path.Root("permissions").AtName("allow")
+	cases := []struct {
+		name      string
+		value     types.List
+		wantIndex int
+	}{
+		{"null", types.ListNull(types.StringType), -1},
+		{"scoped", types.ListValueMust(types.StringType, []attr.Value{types.StringValue("read=/etc,/tmp"), types.StringValue("net")}), -1},
+		{"conflicting", types.ListValueMust(types.StringType, []attr.Value{types.StringValue("read"), types.StringValue("read=/etc")}), 1},
+		{"conflicting after unknown", types.ListValueMust(types.StringType, []attr.Value{types.StringUnknown(), types.StringValue("read"), types.StringValue("read=/etc")}), 2},
+	}
+	for _, tc := range cases {
+		resp := &validator.ListResponse{}
+		permissionEntriesValidator{}.ValidateList(t.Context(), validator.ListRequest{Path: allow, ConfigValue: tc.value}, resp)
+		if tc.wantIndex < 0 {
+			if resp.Diagnostics.HasError() {
+				t.Errorf("%s: expected no error, got %v", tc.name, resp.Diagnostics)
+			}
+			continue
+		}
+		if resp.Diagnostics.ErrorsCount() != 1 {
+			t.Errorf("%s: expected an error, got %v", tc.name, resp.Diagnostics)
+			continue
+		}
+		if d, ok := resp.Diagnostics[0].(interface{ Path() path.Path }); !ok || !d.Path().Equal(allow.AtListIndex(tc.wantIndex)) {
+			t.Errorf("%s: expected the error on entry %d, got %v", tc.name, tc.wantIndex, resp.Diagnostics[0])
+		}
+	}
+}
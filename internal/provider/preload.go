@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// resolvePreloadModule checks a preload_module, returning it as Deno's --preload flag expects it.
+//
+// Local paths, including file:// URLs, are made absolute and must be a file. Remote modules must be an http or
+// https URL, or a jsr: or npm: specifier. When trustedHosts is set, scripts may only import from those hosts, so
+// an http or https module must be served by one of them or the preload would fail in every Deno process.
+func resolvePreloadModule(module string, trustedHosts []string) (string, error) {
+	if strings.HasPrefix(module, "jsr:") || strings.HasPrefix(module, "npm:") {
+		return module, nil
+	}
+
+	localPath := module
+	if strings.Contains(module, "://") {
+		parsed, err := url.Parse(module)
+		if err != nil {
+			return "", err
+		}
+		switch parsed.Scheme {
+		case "http", "https":
+			if parsed.Host == "" {
+				return "", fmt.Errorf("%s has no host", module)
+			}
+			if len(trustedHosts) > 0 && !slices.Contains(trustedHosts, parsed.Host) && !slices.Contains(trustedHosts, parsed.Hostname()) {
+				return "", fmt.Errorf("%s is not one of the trusted_import_hosts, add it so that scripts may import the module", parsed.Host)
+			}
+			return module, nil
+		case "file":
+			localPath = parsed.Path
+		default:
+			return "", fmt.Errorf("unsupported scheme %q, expected a local path, an http or https URL, or a jsr: or npm: specifier", parsed.Scheme)
+		}
+	}
+
+	absPath, err := filepath.Abs(localPath)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", absPath)
+	}
+	return absPath, nil
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestResolvePreloadModule tests that preload modules are resolved, and rejected when they could never be imported.
+func TestResolvePreloadModule(t *testing.T) {
+	dir := t.TempDir()
+	setup := filepath.Join(dir, "setup.ts")
+	if err := os.WriteFile(setup, []byte("console.error('setup');\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		module       string
+		trustedHosts []string
+		expected     string
+		expectedErr  string
+	}{
+		{name: "local path", module: setup, expected: setup},
+		{name: "file url", module: "file://" + setup, expected: setup},
+		{name: "remote", module: "https://deno.land/x/setup.ts", expected: "https://deno.land/x/setup.ts"},
+		{name: "trusted remote", module: "https://deno.land/x/setup.ts", trustedHosts: []string{"deno.land"}, expected: "https://deno.land/x/setup.ts"},
+		{name: "jsr", module: "jsr:@acme/setup", trustedHosts: []string{"deno.land"}, expected: "jsr:@acme/setup"},
+		{name: "untrusted remote", module: "https://esm.sh/setup.ts", trustedHosts: []string{"deno.land"}, expectedErr: "esm.sh is not one of the trusted_import_hosts"},
+		{name: "missing", module: filepath.Join(dir, "missing.ts"), expectedErr: "no such file"},
+		{name: "directory", module: dir, expectedErr: "is a directory"},
+		{name: "scheme", module: "ftp://example.com/setup.ts", expectedErr: `unsupported scheme "ftp"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := resolvePreloadModule(tt.module, tt.trustedHosts)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("Expected an error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
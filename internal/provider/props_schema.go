@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// propsShape is a parsed props_schema, the shape that a resource's props are expected to have.
+//
+// The schema is written in the same notation as the JSON-RPC contract, see deno.RPCContract. Scalars are "string",
+// "number", "boolean" or "any", a list is a single element list holding the shape of its elements and a map is an
+// object with a single "[key]" field. Any other object maps each field to its shape, a field name ending in "?" is
+// optional and fields the schema does not declare are not allowed.
+type propsShape struct {
+	// kind is one of the scalars, "list", "map" or "object"
+	kind string
+	// elem is the shape of the elements of a list or map
+	elem *propsShape
+	// fields are the shapes of the fields of an object
+	fields map[string]*propsShape
+	// optional holds the fields of an object that may be left out
+	optional map[string]bool
+}
+
+// propsShapeError describes a prop that does not match its shape.
+type propsShapeError struct {
+	// propPath is the path to the prop, numeric segments are list indexes
+	propPath []string
+	message  string
+}
+
+// parsePropsShape parses a props_schema, as returned by dynamic.FromDynamic.
+func parsePropsShape(spec any) (*propsShape, error) {
+	return parsePropsShapeAt(spec, nil)
+}
+
+// parsePropsShapeAt parses the part of a props_schema found at specPath.
+func parsePropsShapeAt(spec any, specPath []string) (*propsShape, error) {
+	switch v := spec.(type) {
+	case string:
+		switch v {
+		case "string", "number", "boolean", "any":
+			return &propsShape{kind: v}, nil
+		}
+		return nil, fmt.Errorf("%sunknown type %q, expected one of string, number, boolean or any", describeSpecPath(specPath), v)
+	case []any:
+		if len(v) != 1 {
+			return nil, fmt.Errorf("%sa list must hold exactly one element, the shape of its elements, got %d", describeSpecPath(specPath), len(v))
+		}
+		elem, err := parsePropsShapeAt(v[0], append(specPath, "0"))
+		if err != nil {
+			return nil, err
+		}
+		return &propsShape{kind: "list", elem: elem}, nil
+	case map[string]any:
+		if elemSpec, ok := v["[key]"]; ok && len(v) == 1 {
+			elem, err := parsePropsShapeAt(elemSpec, append(specPath, "[key]"))
+			if err != nil {
+				return nil, err
+			}
+			return &propsShape{kind: "map", elem: elem}, nil
+		}
+		shape := &propsShape{kind: "object", fields: map[string]*propsShape{}, optional: map[string]bool{}}
+		for key, fieldSpec := range v {
+			name, optional := strings.CutSuffix(key, "?")
+			field, err := parsePropsShapeAt(fieldSpec, append(specPath, key))
+			if err != nil {
+				return nil, err
+			}
+			shape.fields[name] = field
+			shape.optional[name] = optional
+		}
+		return shape, nil
+	default:
+		return nil, fmt.Errorf("%sexpected a type name, a list or an object, got %v", describeSpecPath(specPath), spec)
+	}
+}
+
+// describeSpecPath prefixes a props_schema parse error with where it was found.
+func describeSpecPath(specPath []string) string {
+	if len(specPath) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("at %s: ", strings.Join(specPath, "."))
+}
+
+// validate returns every part of value, as returned by dynamic.FromDynamic, that does not match the shape.
+//
+// Null values are not checked, as unknown values are also converted to nil and are only known once applied.
+// Errors are ordered by prop path so they are reported consistently.
+func (s *propsShape) validate(value any, propPath []string) []propsShapeError {
+	if value == nil || s.kind == "any" {
+		return nil
+	}
+	mismatch := func() []propsShapeError {
+		return []propsShapeError{{
+			propPath: slices.Clone(propPath),
+			message:  fmt.Sprintf("Expected %s, got %s.", describeShapeKind(s.kind), describeValueKind(value)),
+		}}
+	}
+
+	switch s.kind {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return mismatch()
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch()
+		}
+	case "number":
+		switch value.(type) {
+		case int64, float64, *big.Int:
+		default:
+			return mismatch()
+		}
+	case "list":
+		items, ok := value.([]any)
+		if !ok {
+			return mismatch()
+		}
+		var errs []propsShapeError
+		for i, item := range items {
+			errs = append(errs, s.elem.validate(item, append(propPath, strconv.Itoa(i)))...)
+		}
+		return errs
+	case "map", "object":
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return mismatch()
+		}
+		var errs []propsShapeError
+		for _, key := range sortedKeys(fields) {
+			fieldPath := append(propPath, key)
+			if s.kind == "map" {
+				errs = append(errs, s.elem.validate(fields[key], fieldPath)...)
+				continue
+			}
+			field, declared := s.fields[key]
+			if !declared {
+				errs = append(errs, propsShapeError{propPath: slices.Clone(fieldPath), message: "Unexpected prop, it is not declared by the props_schema."})
+				continue
+			}
+			errs = append(errs, field.validate(fields[key], fieldPath)...)
+		}
+		if s.kind == "object" {
+			for _, key := range sortedKeys(s.fields) {
+				if _, ok := fields[key]; !ok && !s.optional[key] {
+					errs = append(errs, propsShapeError{propPath: append(slices.Clone(propPath), key), message: "Missing required prop."})
+				}
+			}
+		}
+		return errs
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// describeShapeKind names the kind of a shape in an error message.
+func describeShapeKind(kind string) string {
+	if kind == "object" {
+		return "an object"
+	}
+	return "a " + kind
+}
+
+// describeValueKind names the kind of a value, as returned by dynamic.FromDynamic, in an error message.
+func describeValueKind(value any) string {
+	switch value.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case int64, float64, *big.Int:
+		return "a number"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "an object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
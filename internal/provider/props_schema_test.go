@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePropsShape_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    any
+		wantErr string
+	}{
+		{name: "unknown type", spec: map[string]any{"size": "int"}, wantErr: `at size: unknown type "int"`},
+		{name: "list of two", spec: map[string]any{"tags": []any{"string", "number"}}, wantErr: "at tags: a list must hold exactly one element"},
+		{name: "nested", spec: map[string]any{"network": map[string]any{"ports": []any{"port"}}}, wantErr: `at network.ports.0: unknown type "port"`},
+		{name: "not a type", spec: true, wantErr: "expected a type name, a list or an object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePropsShape(tt.spec)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPropsShape_Validate(t *testing.T) {
+	shape, err := parsePropsShape(map[string]any{
+		"name":         "string",
+		"size":         "number",
+		"enabled?":     "boolean",
+		"tags?":        []any{"string"},
+		"labels?":      map[string]any{"[key]": "string"},
+		"network?":     map[string]any{"cidr": "string"},
+		"passthrough?": "any",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		props map[string]any
+		want  []propsShapeError
+	}{
+		{
+			name: "valid",
+			props: map[string]any{
+				"name":        "web",
+				"size":        int64(3),
+				"enabled":     true,
+				"tags":        []any{"a", "b"},
+				"labels":      map[string]any{"team": "platform"},
+				"network":     map[string]any{"cidr": "10.0.0.0/16"},
+				"passthrough": []any{1.5, "anything"},
+			},
+		},
+		{
+			name:  "unknown values are not checked",
+			props: map[string]any{"name": nil, "size": int64(1), "tags": []any{nil}},
+		},
+		{
+			name:  "wrong types",
+			props: map[string]any{"name": "web", "size": "three", "tags": []any{"a", 2.5}, "labels": map[string]any{"team": true}},
+			want: []propsShapeError{
+				{propPath: []string{"labels", "team"}, message: "Expected a string, got a boolean."},
+				{propPath: []string{"size"}, message: "Expected a number, got a string."},
+				{propPath: []string{"tags", "1"}, message: "Expected a string, got a number."},
+			},
+		},
+		{
+			name:  "missing and unexpected props",
+			props: map[string]any{"name": "web", "sise": int64(3), "network": map[string]any{}},
+			want: []propsShapeError{
+				{propPath: []string{"network", "cidr"}, message: "Missing required prop."},
+				{propPath: []string{"sise"}, message: "Unexpected prop, it is not declared by the props_schema."},
+				{propPath: []string{"size"}, message: "Missing required prop."},
+			},
+		},
+		{
+			name:  "not an object",
+			props: map[string]any{"name": "web", "size": int64(3), "network": "10.0.0.0/16"},
+			want:  []propsShapeError{{propPath: []string{"network"}, message: "Expected an object, got a string."}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shape.validate(tt.props, nil)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -2,12 +2,24 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
 
 	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
 	"github.com/hashicorp/terraform-plugin-framework/action"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -19,13 +31,21 @@ var (
 	_ provider.Provider                       = &DenoBridgeProvider{}
 	_ provider.ProviderWithActions            = &DenoBridgeProvider{}
 	_ provider.ProviderWithEphemeralResources = &DenoBridgeProvider{}
+	_ provider.ProviderWithFunctions          = &DenoBridgeProvider{}
 )
 
+// defaultStartRetries is how many times a Deno process that failed to start is retried when deno_start_retries is not set.
+const defaultStartRetries = 2
+
+// defaultSlowStartWarning is how long a script may take to start before it is warned about when deno_slow_start_warning is not set.
+const defaultSlowStartWarning = 2 * time.Second
+
 // New is a helper function to simplify provider server and testing implementation.
 func New(version string) func() provider.Provider {
 	return func() provider.Provider {
 		return &DenoBridgeProvider{
-			version: version,
+			version:    version,
+			downloader: sync.OnceValue(deno.NewDenoDownloader),
 		}
 	}
 }
@@ -33,17 +53,203 @@ func New(version string) func() provider.Provider {
 // DenoBridgeProvider is the provider implementation.
 type DenoBridgeProvider struct {
 	version string
+
+	// downloader lazily creates the single DenoDownloader shared by everything in this provider instance.
+	downloader func() *deno.DenoDownloader
+
+	// config is the resolved configuration, it remains nil until Configure has been called.
+	config *ProviderConfig
 }
 
 // denoBridgeProviderModel maps the provider schema data.
 type denoBridgeProviderModel struct {
-	DenoBinaryPath types.String `tfsdk:"deno_binary_path"`
-	DenoVersion    types.String `tfsdk:"deno_version"`
+	DenoBinaryPath           types.String  `tfsdk:"deno_binary_path"`
+	DenoVersion              types.String  `tfsdk:"deno_version"`
+	DenoVersionFallbacks     types.List    `tfsdk:"deno_version_fallbacks"`
+	DenoCacheDir             types.String  `tfsdk:"deno_cache_dir"`
+	DenoDownloadBaseURL      types.String  `tfsdk:"deno_download_base_url"`
+	DenoPathFallback         types.Bool    `tfsdk:"deno_path_fallback"`
+	DenoStopGrace            types.String  `tfsdk:"deno_stop_grace"`
+	DenoResponseGrace        types.String  `tfsdk:"deno_response_grace"`
+	DenoCallTimeout          types.String  `tfsdk:"deno_call_timeout"`
+	DenoProgressFlushTimeout types.String  `tfsdk:"deno_progress_flush_timeout"`
+	DenoStartRetries         types.Int64   `tfsdk:"deno_start_retries"`
+	DenoSlowStartWarning     types.String  `tfsdk:"deno_slow_start_warning"`
+	DenoMaxHeapMB            types.Int64   `tfsdk:"deno_max_heap_mb"`
+	DenoCert                 types.String  `tfsdk:"deno_cert"`
+	PreloadModule            types.String  `tfsdk:"preload_module"`
+	LockFile                 types.String  `tfsdk:"lock_file"`
+	LockFrozen               types.Bool    `tfsdk:"lock_frozen"`
+	DenoMaxCPUSeconds        types.Int64   `tfsdk:"deno_max_cpu_seconds"`
+	LogRedactPatterns        types.List    `tfsdk:"log_redact_patterns"`
+	TargetPlatform           types.String  `tfsdk:"target_platform"`
+	JSRRegistryURL           types.String  `tfsdk:"jsr_registry_url"`
+	ScriptBaseDir            types.String  `tfsdk:"script_base_dir"`
+	PropsTemplates           types.Bool    `tfsdk:"props_templates"`
+	DebugDir                 types.String  `tfsdk:"debug_dir"`
+	AuditLog                 types.String  `tfsdk:"audit_log"`
+	TrustedImportHosts       types.List    `tfsdk:"trusted_import_hosts"`
+	MaxIdleProcesses         types.Int64   `tfsdk:"max_idle_processes"`
+	NonFiniteNumbers         types.String  `tfsdk:"non_finite_numbers"`
+	StrictDone               types.Bool    `tfsdk:"strict_done"`
+	Daemon                   types.Bool    `tfsdk:"daemon"`
+	DefaultProps             types.Dynamic `tfsdk:"default_props"`
+	Env                      types.Map     `tfsdk:"env"`
+	InheritEnv               types.Bool    `tfsdk:"inherit_env"`
 }
 
 // ProviderConfig holds the resolved provider configuration.
 type ProviderConfig struct {
 	DenoBinaryPath string
+
+	// DenoVersion is the resolved version of the downloaded Deno binary.
+	// It is empty when a custom deno_binary_path was configured, or Deno was found on the PATH.
+	DenoVersion string
+
+	// ClientOptions tune how the Deno child processes are run.
+	ClientOptions *deno.ClientOptions
+
+	// Downloader is shared so that all Deno version resolutions go through the same cache locking.
+	Downloader *deno.DenoDownloader
+
+	// PropsTemplates enables evaluating props template functions, eg: ${uuid()}, before props are sent to a script.
+	PropsTemplates bool
+
+	// NonFiniteToNull sends numbers in props that JSON can not represent, eg: fractional ones too large for a float64,
+	// to scripts as null instead of failing with an error naming the offending prop.
+	NonFiniteToNull bool
+
+	// DefaultProps are deep merged beneath the props of every resource before they are sent to its script,
+	// nil when no default_props are configured.
+	DefaultProps map[string]any
+
+	// StrictDone fails a delete or action unless the script reports done: true, rather than only when it reports
+	// done: false.
+	StrictDone bool
+
+	// AuditLog records every operation performed by a resource script, nil when no audit_log is configured.
+	AuditLog *auditLogger
+
+	// datasourceCache holds the results of datasource reads made by this provider instance.
+	datasourceCache *datasourceCache
+
+	// serializeLocks serializes the operations of resources sharing a serialize_key.
+	serializeLocks keyedLock
+
+	// clientPool keeps idle resource clients for reuse, nil when max_idle_processes is not set.
+	clientPool *deno.ClientPool
+
+	// daemons keeps a resource client running for each script for the life of the provider, nil when daemon is not set.
+	daemons *deno.ClientDaemons
+}
+
+// clientPools holds the client pool and daemons of every provider instance configured in this process,
+// so that their idle Deno processes can be stopped by Shutdown.
+var clientPools struct {
+	sync.Mutex
+	pools   []*deno.ClientPool
+	daemons []*deno.ClientDaemons
+}
+
+// Shutdown stops the idle Deno processes kept by every provider instance in this process.
+// It is called once the provider server has stopped serving.
+func Shutdown() error {
+	clientPools.Lock()
+	pools := clientPools.pools
+	daemons := clientPools.daemons
+	clientPools.pools = nil
+	clientPools.daemons = nil
+	clientPools.Unlock()
+
+	var firstErr error
+	for _, pool := range pools {
+		if err := pool.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, d := range daemons {
+		if err := d.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// propsFor returns the props to send to a script, with any props template functions evaluated when enabled.
+//
+// Numbers that JSON can not represent are replaced with null or reported with a *dynamic.NonFiniteError,
+// depending on non_finite_numbers, rather than failing later with an unclear marshaling error.
+func (c *ProviderConfig) propsFor(props types.Dynamic) (any, error) {
+	return c.prepareProps(dynamic.FromDynamic(props))
+}
+
+// resourcePropsFor is propsFor for the props of a resource, which have the provider's default_props merged
+// beneath them first, so that templates in the defaults are evaluated too.
+func (c *ProviderConfig) resourcePropsFor(props types.Dynamic) (any, error) {
+	return c.prepareProps(c.withDefaultProps(dynamic.FromDynamic(props)))
+}
+
+// prepareProps does the work of propsFor, value is as returned by dynamic.FromDynamic and may be modified in place.
+func (c *ProviderConfig) prepareProps(value any) (any, error) {
+	value, err := dynamic.ReplaceNonFinite(value, c.NonFiniteToNull)
+	if err != nil {
+		return nil, err
+	}
+	if !c.PropsTemplates {
+		return value, nil
+	}
+	return evaluatePropsTemplates(value)
+}
+
+// addPropsError adds an error returned by propsFor to diags, against the offending prop when it is known.
+func addPropsError(diags *diag.Diagnostics, err error) {
+	var nonFinite *dynamic.NonFiniteError
+	if errors.As(err, &nonFinite) {
+		propPath := append([]string{"props"}, nonFinite.PropPath...)
+		diags.AddAttributeError(
+			dynamic.PropPathToPath(&propPath),
+			"Invalid number in props",
+			fmt.Sprintf("%s. Use a smaller number, or set non_finite_numbers = \"null\" on the provider to send it as null.", err.Error()),
+		)
+		return
+	}
+	diags.AddError("Failed to evaluate props templates", err.Error())
+}
+
+// clientOptionsFor returns the client options for a single block, applying its overrides
+// on top of the provider wide defaults without modifying them.
+//
+// Environment variables set by the block are merged over those set by the provider.
+func (c *ProviderConfig) clientOptionsFor(noConfigDiscovery types.Bool, envFile types.String, env types.Map, flags types.List) *deno.ClientOptions {
+	options := deno.ClientOptions{}
+	if c.ClientOptions != nil {
+		options = *c.ClientOptions
+	}
+	options.NoConfigDiscovery = noConfigDiscovery.ValueBool()
+	options.EnvFile = envFile.ValueString()
+	options.DenoFlags = denoFlags(flags)
+	if blockEnv := envVars(env); len(blockEnv) > 0 {
+		options.Env = maps.Clone(options.Env)
+		if options.Env == nil {
+			options.Env = map[string]string{}
+		}
+		maps.Copy(options.Env, blockEnv)
+	}
+	return &options
+}
+
+// envVars returns the known variables of an env attribute, nil when it is null or unknown.
+func envVars(env types.Map) map[string]string {
+	if env.IsNull() || env.IsUnknown() {
+		return nil
+	}
+	vars := make(map[string]string, len(env.Elements()))
+	for name, value := range env.Elements() {
+		if value, ok := value.(types.String); ok && !value.IsNull() && !value.IsUnknown() {
+			vars[name] = value.ValueString()
+		}
+	}
+	return vars
 }
 
 // Metadata returns the provider type name.
@@ -62,7 +268,136 @@ func (p *DenoBridgeProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 				Optional:            true,
 			},
 			"deno_version": schema.StringAttribute{
-				MarkdownDescription: "Deno version to auto-download (e.g., 'v2.1.4', 'v2.0.0-rc.1'). Defaults to 'latest' which downloads the latest stable GA release.",
+				MarkdownDescription: "Deno version to auto-download (e.g., 'v2.1.4', 'v2.0.0-rc.1'), or a semver constraint such as '>=2.1.0, <3.0.0' or '~2.1', which downloads the newest release that satisfies it. Pre-releases only satisfy a constraint that names one, e.g., '>=2.1.0-rc.1'. Defaults to 'latest' which downloads the latest stable GA release. 'latest' and constraints are resolved once, when the provider is first configured, so every operation in a run uses the same Deno even when a newer release is published part way through it.",
+				Optional:            true,
+			},
+			"deno_version_fallbacks": schema.ListAttribute{
+				MarkdownDescription: "Deno versions to try in order when `deno_version` can not be downloaded, eg: the release has no binary for this platform (e.g., `[\"v2.1.3\", \"v2.1.0\"]`). The version used is logged at warn level.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"deno_cache_dir": schema.StringAttribute{
+				MarkdownDescription: "The directory that downloaded Deno binaries are cached in, eg: a persistent per-user location on CI systems that wipe the temp dir between steps. Only the newest 3 versions are kept in it. Can also be set with the `DENOBRIDGE_CACHE_DIR` environment variable. Defaults to a directory in the system temp dir.",
+				Optional:            true,
+			},
+			"deno_download_base_url": schema.StringAttribute{
+				MarkdownDescription: "Base URL of an internal mirror to download Deno from instead of GitHub, for environments that can not reach github.com. The mirror must follow the same path layout as GitHub, serving both the release info of the GitHub API, eg: `<base>/repos/denoland/deno/releases/tags/v2.1.4`, and the release assets, eg: `<base>/denoland/deno/releases/download/v2.1.4/deno-x86_64-unknown-linux-gnu.zip`. `GITHUB_TOKEN` is never sent to the mirror. Can also be set with the `DENOBRIDGE_DOWNLOAD_BASE_URL` environment variable. Defaults to GitHub.",
+				Optional:            true,
+			},
+			"deno_path_fallback": schema.BoolAttribute{
+				MarkdownDescription: "When the Deno binary can not be downloaded (e.g., offline or rate limited), fall back to a `deno` binary found on the PATH with a warning instead of failing. Defaults to true.",
+				Optional:            true,
+			},
+			"deno_stop_grace": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for a Deno process to exit after it has been asked to shutdown, as a Go duration (e.g., '10s'). If still running it is sent SIGTERM and given the same grace period again before being killed. Defaults to '5s'.",
+				Optional:            true,
+			},
+			"deno_response_grace": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for a response after a Deno process exits mid call, as a Go duration (e.g., '2s'). If none arrives the call fails with an error explaining that the script never responded. Defaults to '1s'.",
+				Optional:            true,
+			},
+			"deno_call_timeout": schema.StringAttribute{
+				MarkdownDescription: "Limits how long the provider waits for a script to respond to each call, as a Go duration (e.g., '10m'). A call that takes longer fails with an error saying the script timed out, rather than that it failed, and the script is sent a cancel notification. Unlike the `timeouts` of a resource this applies to every call of every script, including data sources. Defaults to no limit.",
+				Optional:            true,
+			},
+			"deno_progress_flush_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long an action waits, once its script has completed, for the progress updates the script sent before completing to be delivered to Terraform, as a Go duration (e.g., '5s'). Any still not delivered are dropped with a warning in the logs. Defaults to '1s'.",
+				Optional:            true,
+			},
+			"deno_start_retries": schema.Int64Attribute{
+				MarkdownDescription: "How many times to retry starting a Deno process that failed to start, with an exponential backoff starting at 250ms. Start up failures are often transient under heavy parallel load. Set to 0 to disable. Defaults to 2.",
+				Optional:            true,
+			},
+			"deno_slow_start_warning": schema.StringAttribute{
+				MarkdownDescription: "How long a script may take from its Deno process being started to answering the first call before a warning is logged, as a Go duration (e.g., '5s'). Code a script runs at the top level when it is imported is run again for every Deno process started, which is one per operation unless `max_idle_processes` or `daemon` is set, so slow top-level code is best moved into the methods that need it. Set to '0s' to disable. Defaults to '2s'.",
+				Optional:            true,
+			},
+			"deno_max_heap_mb": schema.Int64Attribute{
+				MarkdownDescription: "Limits the V8 heap of each Deno process to this many megabytes (`--max-old-space-size`). A script that exceeds it fails with an out of memory error. Defaults to the V8 default.",
+				Optional:            true,
+			},
+			"deno_cert": schema.StringAttribute{
+				MarkdownDescription: "Path to a PEM encoded CA bundle that each Deno process trusts (`--cert`), for scripts that make HTTPS requests to, or import modules from, services signed by a private CA, eg: behind TLS interception. Only affects the scripts, Deno itself is downloaded trusting the system's CAs.",
+				Optional:            true,
+			},
+			"lock_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a lockfile that each Deno process checks the integrity of every remote dependency against (`--lock`), so that scripts can not silently pull different versions of their dependencies between runs. Dependencies not yet in it are added, unless `lock_frozen` is set. Takes precedence over any lockfile found next to a discovered `deno.json`.",
+				Optional:            true,
+			},
+			"lock_frozen": schema.BoolAttribute{
+				MarkdownDescription: "Fail any script whose dependencies are not exactly those recorded in `lock_file`, rather than adding them to it (`--frozen`). Requires `lock_file`. Defaults to false.",
+				Optional:            true,
+			},
+			"preload_module": schema.StringAttribute{
+				MarkdownDescription: "A module that each Deno process imports before the script (`--preload`), eg: to configure logging or initialize an SDK the same way for every script. A local path, resolved against the working directory, an http or https URL, or a `jsr:` or `npm:` specifier. The module runs with the same permissions as the script, so a remote module must be served by one of the `trusted_import_hosts` when they are set. Requires Deno 2.4 or later.",
+				Optional:            true,
+			},
+			"audit_log": schema.StringAttribute{
+				MarkdownDescription: "Path to a file that a JSON line is appended to for every create, read, update and delete performed by a resource script, recording the time, script, operation, permissions, duration and whether it succeeded. Props, state and errors are never recorded as they may hold sensitive values.",
+				Optional:            true,
+			},
+			"debug_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory that every script is copied into when a Deno process is started, alongside a `.cmd` file holding the time and the command line it was run with, whether or not it then succeeds. Useful to see exactly what the provider ran. The copies are never cleaned up.",
+				Optional:            true,
+			},
+			"deno_max_cpu_seconds": schema.Int64Attribute{
+				MarkdownDescription: "Limits the CPU time each Deno process may consume, in seconds. The process is killed once exceeded. The limit covers the whole life of a process, so limited processes are never kept idle for reuse by `max_idle_processes` nor run as a `daemon`, every operation starts its own. Only supported on Linux, ignored with a warning elsewhere.",
+				Optional:            true,
+			},
+			"log_redact_patterns": schema.ListAttribute{
+				MarkdownDescription: "Regular expressions matching values to replace with `***` in the Deno stderr and JSON-RPC messages written to the Terraform logs. A pattern with capture groups only has the groups replaced, eg: `\"token\":\"([^\"]+)\"`. Defense in depth against scripts that accidentally log secrets.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"jsr_registry_url": schema.StringAttribute{
+				MarkdownDescription: "URL of a JSR registry mirror to resolve `jsr:` imports through, including the denobridge library itself, for networks that can not reach jsr.io. Given to Deno as `JSR_URL`, scripts also need net permission for the mirror's host. Checked to be reachable when the provider is configured. Defaults to the `DENO_REGISTRY_URL` environment variable.",
+				Optional:            true,
+			},
+			"non_finite_numbers": schema.StringAttribute{
+				MarkdownDescription: "What to do with numbers in props that JSON can not represent, eg: a fractional number too large for a float64 that becomes infinite once converted. Either `error`, failing with an error naming the offending prop, or `null`, sending it to the script as null. Defaults to `error`.",
+				Optional:            true,
+			},
+			"props_templates": schema.BoolAttribute{
+				MarkdownDescription: "Evaluate the template functions `${uuid()}` and `${timestamp()}` found in string props before they are sent to a script, escaped in HCL as `$${uuid()}`. Evaluated whenever a resource is planned, created or updated, a data source is read, an ephemeral resource is opened or an action is invoked, afresh each time, so a script is given different values when planning a change to when applying it.",
+				Optional:            true,
+			},
+			"script_base_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory that relative script `path`s are resolved against, instead of the directory Terraform is run from. Terraform does not tell providers which module a resource belongs to, so a module that bundles its own scripts can either be given a provider with this set to its `path.module`, or prefix each `path` with `path.module`.",
+				Optional:            true,
+			},
+			"strict_done": schema.BoolAttribute{
+				MarkdownDescription: "Require resource deletes and actions to report `done: true`, failing when a script returns without it. By default only an explicit `done: false` fails, so that a script which returns nothing, or only diagnostics, has succeeded. Defaults to false.",
+				Optional:            true,
+			},
+			"target_platform": schema.StringAttribute{
+				MarkdownDescription: "The platform scripts should generate artifacts for, eg: 'linux/arm64'. Passed to scripts as `meta.targetPlatform`, alongside the `os` and `arch` the provider is running on.",
+				Optional:            true,
+			},
+			"env": schema.MapAttribute{
+				MarkdownDescription: "Environment variables set in the environment of every script, eg: `{ AWS_REGION = \"us-east-1\" }`. A block's own `env` is merged over these. Scripts need the `env` permission to read them, which may be scoped to just the variables they need, eg: `env=AWS_REGION`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"inherit_env": schema.BoolAttribute{
+				MarkdownDescription: "Whether scripts inherit the environment Terraform runs the provider with. When false scripts start from an empty environment holding only the variables set by `env` and those Deno itself needs to locate its cache, create temporary files and reach the network, eg: `HOME`, `DENO_DIR` and `HTTPS_PROXY`. Defaults to true.",
+				Optional:            true,
+			},
+			"max_idle_processes": schema.Int64Attribute{
+				MarkdownDescription: "How many started Deno processes to keep idle for reuse by later resource operations, eg: the read, create and update of each instance of a resource with `for_each`, instead of starting a new process for each. A process is only reused by operations running the same script with the same config file, permissions, env file and env, one operation at a time, and only after an operation succeeded. Idle processes are stopped when the provider shuts down. Scripts must not rely on module level state being fresh for each operation when set. Defaults to 0, which starts a new process for every operation.",
+				Optional:            true,
+			},
+			"daemon": schema.BoolAttribute{
+				MarkdownDescription: "Keep a single long lived Deno process, a daemon, running for each resource script for the life of the provider, shared concurrently by every operation running that script with the same config file, permissions, env file and env, eg: the reads of each instance of a resource with `for_each`. Speeds up iterating on scripts during development, where starting Deno for every operation is slow. A daemon is replaced by a new one after an operation using it fails, or it exits. Scripts must not rely on module level state being fresh for each operation, nor on operations running one at a time, when set. Takes precedence over `max_idle_processes`. Defaults to false.",
+				Optional:            true,
+			},
+			"default_props": schema.DynamicAttribute{
+				MarkdownDescription: "Props merged into the props of every `denobridge_resource` before they are sent to its script, eg: `{ environment = \"prod\", team = \"platform\" }`, so that common values do not have to be repeated by each resource. Objects are deep merged, a resource's own props take precedence over the defaults, and lists are replaced rather than merged. Defaults are never merged into `write_only_props`, nor stored in state, so changing them does not by itself plan an update.",
+				Optional:            true,
+			},
+			"trusted_import_hosts": schema.ListAttribute{
+				MarkdownDescription: "Hosts that scripts may import remote modules from, eg: `deno.land` or `esm.sh:443`. When set, scripts are run with `--allow-import` scoped to these hosts and the host the denobridge library is imported from (jsr.io, or the `jsr_registry_url` mirror), merged with any `import` entry in a block's permissions allow list. Has no effect on scripts granted `all` permissions or an unscoped `import` permission.",
+				ElementType:         types.StringType,
 				Optional:            true,
 			},
 		},
@@ -81,36 +416,389 @@ func (p *DenoBridgeProvider) Configure(ctx context.Context, req provider.Configu
 
 	// Resolve the Deno binary path
 	var denoBinaryPath string
+	var denoVersion string
 
 	if !config.DenoBinaryPath.IsNull() {
 		// Use custom path if provided
 		denoBinaryPath = config.DenoBinaryPath.ValueString()
 	} else {
 		// Auto-download Deno
-		downloader := deno.NewDenoDownloader()
+		downloader := p.downloader()
+		if !config.DenoCacheDir.IsNull() {
+			cacheDir, err := filepath.Abs(config.DenoCacheDir.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("deno_cache_dir"),
+					"Invalid deno_cache_dir",
+					fmt.Sprintf("Failed to resolve the Deno cache dir: %s", err.Error()),
+				)
+				return
+			}
+			downloader.SetCacheDir(cacheDir)
+		}
+		if !config.DenoDownloadBaseURL.IsNull() {
+			if err := deno.ValidateDownloadBaseURL(config.DenoDownloadBaseURL.ValueString()); err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("deno_download_base_url"), "Invalid deno_download_base_url", err.Error())
+				return
+			}
+			downloader.SetDownloadBaseURL(config.DenoDownloadBaseURL.ValueString())
+		}
 
 		version := "latest"
 		if !config.DenoVersion.IsNull() {
 			version = config.DenoVersion.ValueString()
 		}
+		if err := deno.ValidateVersion(version); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("deno_version"), "Invalid deno_version", err.Error())
+			return
+		}
+
+		var fallbacks []string
+		if !config.DenoVersionFallbacks.IsNull() {
+			resp.Diagnostics.Append(config.DenoVersionFallbacks.ElementsAs(ctx, &fallbacks, false)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			for i, fallback := range fallbacks {
+				if fallback == "" {
+					resp.Diagnostics.AddAttributeError(
+						path.Root("deno_version_fallbacks").AtListIndex(i),
+						"Invalid deno_version_fallbacks",
+						"Expected a Deno version such as v2.1.3 or latest, got an empty string",
+					)
+					return
+				}
+				if err := deno.ValidateVersion(fallback); err != nil {
+					resp.Diagnostics.AddAttributeError(
+						path.Root("deno_version_fallbacks").AtListIndex(i),
+						"Invalid deno_version_fallbacks",
+						err.Error(),
+					)
+					return
+				}
+			}
+		}
 
-		path, err := downloader.GetDenoBinary(ctx, version)
+		path, err := downloader.GetDenoBinaryWithFallbacks(ctx, version, fallbacks)
+		if err != nil {
+			// Fall back to a preinstalled Deno, unless disabled
+			fallback := config.DenoPathFallback.IsNull() || config.DenoPathFallback.ValueBool()
+			systemPath, lookErr := exec.LookPath("deno")
+			if !fallback || lookErr != nil {
+				resp.Diagnostics.AddError(
+					"Failed to get Deno binary",
+					fmt.Sprintf("Could not download or locate Deno binary: %s", err.Error()),
+				)
+				return
+			}
+			resp.Diagnostics.AddWarning(
+				"Using Deno from PATH",
+				fmt.Sprintf(
+					"Could not download Deno %s, so %s found on the PATH is being used. Set deno_path_fallback = false to fail instead.\n\n%s",
+					version, systemPath, err.Error(),
+				),
+			)
+			denoBinaryPath = systemPath
+		} else {
+			denoBinaryPath = path
+
+			// The downloader caches each binary in a directory named after its resolved version
+			denoVersion = filepath.Base(filepath.Dir(path))
+		}
+	}
+
+	// Resolve the options used to run the Deno child processes
+	clientOptions := &deno.ClientOptions{}
+	if !config.DenoStopGrace.IsNull() {
+		stopGrace, err := time.ParseDuration(config.DenoStopGrace.ValueString())
+		if err != nil || stopGrace <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("deno_stop_grace"),
+				"Invalid deno_stop_grace",
+				fmt.Sprintf("Expected a positive duration such as '10s', got: %s", config.DenoStopGrace.ValueString()),
+			)
+			return
+		}
+		clientOptions.StopGrace = stopGrace
+	}
+	if !config.DenoResponseGrace.IsNull() {
+		responseGrace, err := time.ParseDuration(config.DenoResponseGrace.ValueString())
+		if err != nil || responseGrace <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("deno_response_grace"),
+				"Invalid deno_response_grace",
+				fmt.Sprintf("Expected a positive duration such as '2s', got: %s", config.DenoResponseGrace.ValueString()),
+			)
+			return
+		}
+		clientOptions.ResponseGrace = responseGrace
+	}
+	if !config.DenoCallTimeout.IsNull() {
+		callTimeout, err := time.ParseDuration(config.DenoCallTimeout.ValueString())
+		if err != nil || callTimeout <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("deno_call_timeout"),
+				"Invalid deno_call_timeout",
+				fmt.Sprintf("Expected a positive duration such as '10m', got: %s", config.DenoCallTimeout.ValueString()),
+			)
+			return
+		}
+		clientOptions.CallTimeout = callTimeout
+	}
+	if !config.DenoProgressFlushTimeout.IsNull() {
+		progressFlushTimeout, err := time.ParseDuration(config.DenoProgressFlushTimeout.ValueString())
+		if err != nil || progressFlushTimeout <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("deno_progress_flush_timeout"),
+				"Invalid deno_progress_flush_timeout",
+				fmt.Sprintf("Expected a positive duration such as '5s', got: %s", config.DenoProgressFlushTimeout.ValueString()),
+			)
+			return
+		}
+		clientOptions.ProgressFlushTimeout = progressFlushTimeout
+	}
+	clientOptions.SlowStartWarning = defaultSlowStartWarning
+	if !config.DenoSlowStartWarning.IsNull() {
+		slowStartWarning, err := time.ParseDuration(config.DenoSlowStartWarning.ValueString())
+		if err != nil || slowStartWarning < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("deno_slow_start_warning"),
+				"Invalid deno_slow_start_warning",
+				fmt.Sprintf("Expected a duration such as '5s', or '0s' to disable the warning, got: %s", config.DenoSlowStartWarning.ValueString()),
+			)
+			return
+		}
+		clientOptions.SlowStartWarning = slowStartWarning
+	}
+	clientOptions.StartRetries = defaultStartRetries
+	if !config.DenoStartRetries.IsNull() {
+		if config.DenoStartRetries.ValueInt64() < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("deno_start_retries"),
+				"Invalid deno_start_retries",
+				fmt.Sprintf("Expected zero or a positive number of retries, got: %d", config.DenoStartRetries.ValueInt64()),
+			)
+			return
+		}
+		clientOptions.StartRetries = config.DenoStartRetries.ValueInt64()
+	}
+	if !config.DenoMaxHeapMB.IsNull() {
+		if config.DenoMaxHeapMB.ValueInt64() <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("deno_max_heap_mb"),
+				"Invalid deno_max_heap_mb",
+				fmt.Sprintf("Expected a positive number of megabytes, got: %d", config.DenoMaxHeapMB.ValueInt64()),
+			)
+			return
+		}
+		clientOptions.MaxHeapMB = config.DenoMaxHeapMB.ValueInt64()
+	}
+	if !config.DenoCert.IsNull() {
+		certFile, err := filepath.Abs(config.DenoCert.ValueString())
+		if err == nil {
+			var info os.FileInfo
+			if info, err = os.Stat(certFile); err == nil && info.IsDir() {
+				err = fmt.Errorf("%s is a directory", certFile)
+			}
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("deno_cert"),
+				"Invalid deno_cert",
+				fmt.Sprintf("Expected the path to a CA bundle file: %s", err.Error()),
+			)
+			return
+		}
+		clientOptions.CertFile = certFile
+	}
+	if !config.DenoMaxCPUSeconds.IsNull() {
+		if config.DenoMaxCPUSeconds.ValueInt64() <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("deno_max_cpu_seconds"),
+				"Invalid deno_max_cpu_seconds",
+				fmt.Sprintf("Expected a positive number of seconds, got: %d", config.DenoMaxCPUSeconds.ValueInt64()),
+			)
+			return
+		}
+		clientOptions.MaxCPUSeconds = config.DenoMaxCPUSeconds.ValueInt64()
+	}
+	if !config.LogRedactPatterns.IsNull() {
+		var patterns []string
+		resp.Diagnostics.Append(config.LogRedactPatterns.ElementsAs(ctx, &patterns, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for i, pattern := range patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("log_redact_patterns").AtListIndex(i),
+					"Invalid log_redact_patterns",
+					fmt.Sprintf("Expected a valid regular expression, got: %s", err.Error()),
+				)
+				return
+			}
+		}
+		clientOptions.LogRedactPatterns = patterns
+	}
+	clientOptions.TargetPlatform = config.TargetPlatform.ValueString()
+	clientOptions.Env = envVars(config.Env)
+	clientOptions.NoInheritEnv = !config.InheritEnv.IsNull() && !config.InheritEnv.ValueBool()
+	jsrRegistryURL := os.Getenv("DENO_REGISTRY_URL")
+	if !config.JSRRegistryURL.IsNull() {
+		jsrRegistryURL = config.JSRRegistryURL.ValueString()
+	}
+	if jsrRegistryURL != "" {
+		if err := checkRegistryReachable(ctx, jsrRegistryURL); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("jsr_registry_url"),
+				"Invalid jsr_registry_url",
+				fmt.Sprintf("The JSR registry mirror %s is not reachable: %s", jsrRegistryURL, err.Error()),
+			)
+			return
+		}
+		clientOptions.JSRRegistryURL = jsrRegistryURL
+	}
+	if !config.DebugDir.IsNull() {
+		debugDir, err := filepath.Abs(config.DebugDir.ValueString())
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Failed to get Deno binary",
-				fmt.Sprintf("Could not download or locate Deno binary: %s", err.Error()),
+			resp.Diagnostics.AddAttributeError(
+				path.Root("debug_dir"),
+				"Invalid debug_dir",
+				fmt.Sprintf("Failed to resolve the debug dir: %s", err.Error()),
 			)
 			return
 		}
+		clientOptions.DebugDir = debugDir
+	}
+	if !config.TrustedImportHosts.IsNull() {
+		var hosts []string
+		resp.Diagnostics.Append(config.TrustedImportHosts.ElementsAs(ctx, &hosts, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for i, host := range hosts {
+			if !isImportHost(host) {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("trusted_import_hosts").AtListIndex(i),
+					"Invalid trusted_import_hosts",
+					fmt.Sprintf("Expected a hostname with an optional port, eg: deno.land or esm.sh:443, got: %q", host),
+				)
+				return
+			}
+		}
+		clientOptions.TrustedImportHosts = hosts
+	}
+	if !config.LockFile.IsNull() {
+		lockFile, err := filepath.Abs(config.LockFile.ValueString())
+		if err == nil {
+			var info os.FileInfo
+			if info, err = os.Stat(lockFile); err == nil && info.IsDir() {
+				err = fmt.Errorf("%s is a directory", lockFile)
+			} else if errors.Is(err, os.ErrNotExist) && !config.LockFrozen.ValueBool() {
+				// Deno creates the lockfile, unless it is frozen
+				err = nil
+			}
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("lock_file"),
+				"Invalid lock_file",
+				fmt.Sprintf("Expected the path to a lockfile: %s", err.Error()),
+			)
+			return
+		}
+		clientOptions.LockFile = lockFile
+		clientOptions.LockFrozen = config.LockFrozen.ValueBool()
+	} else if config.LockFrozen.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("lock_frozen"),
+			"Invalid lock_frozen",
+			"lock_frozen requires a lock_file to freeze.",
+		)
+		return
+	}
+	if !config.PreloadModule.IsNull() {
+		preloadModule, err := resolvePreloadModule(config.PreloadModule.ValueString(), clientOptions.TrustedImportHosts)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("preload_module"),
+				"Invalid preload_module",
+				fmt.Sprintf("Expected a module that scripts may import: %s", err.Error()),
+			)
+			return
+		}
+		clientOptions.PreloadModule = preloadModule
+	}
+	if !config.ScriptBaseDir.IsNull() {
+		scriptBaseDir, err := filepath.Abs(config.ScriptBaseDir.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("script_base_dir"),
+				"Invalid script_base_dir",
+				fmt.Sprintf("Failed to resolve the script base dir: %s", err.Error()),
+			)
+			return
+		}
+		clientOptions.ScriptBaseDir = scriptBaseDir
+	}
+	nonFiniteNumbers := config.NonFiniteNumbers.ValueString()
+	if nonFiniteNumbers != "" && nonFiniteNumbers != "error" && nonFiniteNumbers != "null" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("non_finite_numbers"),
+			"Invalid non_finite_numbers",
+			fmt.Sprintf("Expected either error or null, got: %q", nonFiniteNumbers),
+		)
+		return
+	}
+	if !config.MaxIdleProcesses.IsNull() && config.MaxIdleProcesses.ValueInt64() < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_idle_processes"),
+			"Invalid max_idle_processes",
+			fmt.Sprintf("Expected zero or a positive number of processes, got: %d", config.MaxIdleProcesses.ValueInt64()),
+		)
+		return
+	}
 
-		denoBinaryPath = path
+	defaultProps, err := parseDefaultProps(config.DefaultProps)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("default_props"), "Invalid default_props", err.Error())
+		return
 	}
 
 	// Create provider config
 	providerConfig := &ProviderConfig{
-		DenoBinaryPath: denoBinaryPath,
+		DenoBinaryPath:  denoBinaryPath,
+		DenoVersion:     denoVersion,
+		ClientOptions:   clientOptions,
+		Downloader:      p.downloader(),
+		PropsTemplates:  config.PropsTemplates.ValueBool(),
+		NonFiniteToNull: nonFiniteNumbers == "null",
+		StrictDone:      config.StrictDone.ValueBool(),
+		DefaultProps:    defaultProps,
+		AuditLog:        newAuditLogger(config.AuditLog.ValueString()),
+		datasourceCache: newDatasourceCache(),
+		clientPool:      deno.NewClientPool(int(config.MaxIdleProcesses.ValueInt64())),
+		daemons:         deno.NewClientDaemons(config.Daemon.ValueBool()),
+	}
+
+	// Stop any processes kept idle by a previous configuration of this provider instance
+	if p.config != nil {
+		_ = p.config.clientPool.Close()
+		_ = p.config.daemons.Close()
+	}
+	if providerConfig.clientPool != nil || providerConfig.daemons != nil {
+		clientPools.Lock()
+		if providerConfig.clientPool != nil {
+			clientPools.pools = append(clientPools.pools, providerConfig.clientPool)
+		}
+		if providerConfig.daemons != nil {
+			clientPools.daemons = append(clientPools.daemons, providerConfig.daemons)
+		}
+		clientPools.Unlock()
 	}
 
+	p.config = providerConfig
+
 	// Make available to resources and data sources
 	resp.DataSourceData = providerConfig
 	resp.ResourceData = providerConfig
@@ -129,6 +817,7 @@ func (p *DenoBridgeProvider) Actions(_ context.Context) []func() action.Action {
 func (p *DenoBridgeProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewDenoBridgeDataSource,
+		NewConfigDataSource,
 	}
 }
 
@@ -145,3 +834,10 @@ func (p *DenoBridgeProvider) EphemeralResources(_ context.Context) []func() ephe
 		NewDenoBridgeEphemeralResource,
 	}
 }
+
+// Functions defines the functions implemented in the provider.
+func (p *DenoBridgeProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewValidatePropsFunction(p),
+	}
+}
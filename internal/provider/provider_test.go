@@ -1,9 +1,17 @@
 package provider
 
 import (
+	"math/big"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
 )
@@ -24,3 +32,72 @@ func testAccPreCheck(t *testing.T) {
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+func TestPropsFor_NonFiniteNumbers(t *testing.T) {
+	huge, _, err := big.ParseFloat("1"+strings.Repeat("0", 400)+".5", 10, 2048, big.ToNearestEven)
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{"size": types.NumberType},
+		map[string]attr.Value{"size": types.NumberValue(huge)},
+	))
+
+	// By default the offending prop is reported
+	_, err = (&ProviderConfig{}).propsFor(props)
+	if err == nil {
+		t.Fatal("Expected an error for a number too large for a float64")
+	}
+	var diags diag.Diagnostics
+	addPropsError(&diags, err)
+	if len(diags) != 1 {
+		t.Fatalf("Expected one diagnostic, got %d", len(diags))
+	}
+	withPath, ok := diags[0].(diag.DiagnosticWithPath)
+	if !ok || !withPath.Path().Equal(path.Root("props").AtMapKey("size")) {
+		t.Errorf("Expected the diagnostic to point at props.size, got %v", diags[0])
+	}
+
+	// Or it may be sent as null instead
+	value, err := (&ProviderConfig{NonFiniteToNull: true}).propsFor(props)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(value, map[string]any{"size": nil}) {
+		t.Errorf("Expected size to be null, got %v", value)
+	}
+}
+
+func TestClientOptionsFor_Env(t *testing.T) {
+	config := &ProviderConfig{ClientOptions: &deno.ClientOptions{Env: map[string]string{"AWS_REGION": "us-east-1", "STAGE": "dev"}}}
+	env := types.MapValueMust(types.StringType, map[string]attr.Value{"STAGE": types.StringValue("prod")})
+
+	options := config.clientOptionsFor(types.BoolNull(), types.StringNull(), env, types.ListNull(types.StringType))
+	if !reflect.DeepEqual(options.Env, map[string]string{"AWS_REGION": "us-east-1", "STAGE": "prod"}) {
+		t.Errorf("Expected the block's env to be merged over the provider's, got %v", options.Env)
+	}
+	if config.ClientOptions.Env["STAGE"] != "dev" {
+		t.Errorf("Expected the provider's env to be left unchanged, got %v", config.ClientOptions.Env)
+	}
+
+	options = config.clientOptionsFor(types.BoolNull(), types.StringNull(), types.MapNull(types.StringType), types.ListNull(types.StringType))
+	if !reflect.DeepEqual(options.Env, config.ClientOptions.Env) {
+		t.Errorf("Expected the provider's env when the block sets none, got %v", options.Env)
+	}
+}
+
+func TestClientOptionsFor_DenoFlags(t *testing.T) {
+	config := &ProviderConfig{}
+	flags := types.ListValueMust(types.StringType, []attr.Value{types.StringValue("--node-modules-dir=auto"), types.StringUnknown()})
+
+	options := config.clientOptionsFor(types.BoolNull(), types.StringNull(), types.MapNull(types.StringType), flags)
+	if !reflect.DeepEqual(options.DenoFlags, []string{"--node-modules-dir=auto"}) {
+		t.Errorf("Expected the known flags of the block, got %v", options.DenoFlags)
+	}
+
+	// Pooled processes started with different flags are never shared
+	other := config.clientOptionsFor(types.BoolNull(), types.StringNull(), types.MapNull(types.StringType), types.ListNull(types.StringType))
+	if deno.PoolKey("deno", "script.ts", "", nil, options) == deno.PoolKey("deno", "script.ts", "", nil, other) {
+		t.Error("Expected the flags to be part of the pool key")
+	}
+}
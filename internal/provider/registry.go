@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// registryCheckTimeout limits how long checkRegistryReachable waits for the registry to respond.
+const registryCheckTimeout = 10 * time.Second
+
+// checkRegistryReachable confirms that a registry mirror responds at the given URL.
+//
+// Any response short of a server error is accepted, as mirrors do not necessarily serve
+// anything at their root.
+func checkRegistryReachable(ctx context.Context, registryURL string) error {
+	parsed, err := url.Parse(registryURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("expected an absolute http or https URL")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, registryCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, registryURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	return nil
+}
+
+// importHostRegex matches a hostname with an optional port, the form Deno expects in --allow-import.
+var importHostRegex = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*(:[0-9]{1,5})?$`)
+
+// isImportHost reports whether host is a valid trusted import host, eg: "deno.land" or "esm.sh:443".
+func isImportHost(host string) bool {
+	return importHostRegex.MatchString(host)
+}
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckRegistryReachable(t *testing.T) {
+	ok := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(ok.Close)
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(broken.Close)
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	cases := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{"reachable", ok.URL, false},
+		{"server error", broken.URL, true},
+		{"unreachable", closed.URL, true},
+		{"not a url", "jsr.example.com", true},
+	}
+	for _, tc := range cases {
+		if err := checkRegistryReachable(t.Context(), tc.url); (err != nil) != tc.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tc.name, tc.wantErr, err)
+		}
+	}
+}
+
+func TestIsImportHost(t *testing.T) {
+	for _, host := range []string{"deno.land", "esm.sh:443", "cdn.jsdelivr.net", "localhost", "my-registry.corp.example.com:8443"} {
+		if !isImportHost(host) {
+			t.Errorf("Expected %q to be a valid import host", host)
+		}
+	}
+	for _, host := range []string{"", "https://deno.land", "deno.land/x", "-bad.com", "bad..com", "*.example.com", "deno.land:"} {
+		if isImportHost(host) {
+			t.Errorf("Expected %q not to be a valid import host", host)
+		}
+	}
+}
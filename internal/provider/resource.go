@@ -6,23 +6,39 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
 	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource                = &denoBridgeResource{}
-	_ resource.ResourceWithConfigure   = &denoBridgeResource{}
-	_ resource.ResourceWithModifyPlan  = &denoBridgeResource{}
-	_ resource.ResourceWithImportState = &denoBridgeResource{}
+	_ resource.Resource                   = &denoBridgeResource{}
+	_ resource.ResourceWithConfigure      = &denoBridgeResource{}
+	_ resource.ResourceWithModifyPlan     = &denoBridgeResource{}
+	_ resource.ResourceWithImportState    = &denoBridgeResource{}
+	_ resource.ResourceWithValidateConfig = &denoBridgeResource{}
+)
+
+// The modes state_merge may be set to.
+const (
+	stateMergeReplace = "replace"
+	stateMergeMerge   = "merge"
 )
 
 // NewDenoBridgeResource is a helper function to simplify the provider implementation.
@@ -37,15 +53,31 @@ type denoBridgeResource struct {
 
 // denoBridgeResourceModel maps the resource schema data.
 type denoBridgeResourceModel struct {
-	ID                    types.String        `tfsdk:"id"`
-	Path                  types.String        `tfsdk:"path"`
-	Props                 types.Dynamic       `tfsdk:"props"`
-	State                 types.Dynamic       `tfsdk:"state"`
-	SensitiveState        types.Dynamic       `tfsdk:"sensitive_state"`
-	ConfigFile            types.String        `tfsdk:"config_file"`
-	Permissions           *deno.PermissionsTF `tfsdk:"permissions"`
-	WriteOnlyProps        types.Dynamic       `tfsdk:"write_only_props"`
-	WriteOnlyPropsVersion types.Int64         `tfsdk:"write_only_props_version"`
+	ID                    types.String            `tfsdk:"id"`
+	Identifiers           types.Map               `tfsdk:"identifiers"`
+	Path                  types.String            `tfsdk:"path"`
+	Props                 types.Dynamic           `tfsdk:"props"`
+	State                 types.Dynamic           `tfsdk:"state"`
+	SensitiveState        types.Dynamic           `tfsdk:"sensitive_state"`
+	ConfigFile            types.String            `tfsdk:"config_file"`
+	NoConfigDiscovery     types.Bool              `tfsdk:"no_config_discovery"`
+	EnvFile               types.String            `tfsdk:"env_file"`
+	Env                   types.Map               `tfsdk:"env"`
+	DenoFlags             types.List              `tfsdk:"deno_flags"`
+	Permissions           *deno.PermissionsTF     `tfsdk:"permissions"`
+	OperationPermissions  *operationPermissionsTF `tfsdk:"operation_permissions"`
+	OperationConfigFiles  *operationConfigFilesTF `tfsdk:"operation_config_files"`
+	Timeouts              *operationTimeoutsTF    `tfsdk:"timeouts"`
+	WriteOnlyProps        types.Dynamic           `tfsdk:"write_only_props"`
+	WriteOnlyPropsVersion types.Int64             `tfsdk:"write_only_props_version"`
+	CheckExternalOnPlan   types.Bool              `tfsdk:"check_external_on_plan"`
+	ReplaceTriggers       types.Dynamic           `tfsdk:"replace_triggers"`
+	StateMerge            types.String            `tfsdk:"state_merge"`
+	ConfirmReplace        types.Bool              `tfsdk:"confirm_replace"`
+	SerializeKey          types.String            `tfsdk:"serialize_key"`
+	ResolvePaths          types.List              `tfsdk:"resolve_paths"`
+	PropsSchema           types.Dynamic           `tfsdk:"props_schema"`
+	RedactProps           types.List              `tfsdk:"redact_props"`
 }
 
 // Metadata returns the resource type name.
@@ -65,14 +97,26 @@ func (r *denoBridgeResource) Schema(_ context.Context, _ resource.SchemaRequest,
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"identifiers": schema.MapAttribute{
+				Description: "Additional identifiers of the resource returned by the Deno script, such as an ARN or URN, for other resources to reference.",
+				ElementType: types.StringType,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"path": schema.StringAttribute{
-				Description: "Path to the Deno script to execute.",
+				Description: "Path to the Deno script to execute, or env:NAME to read the path from the environment variable NAME when the script is run.",
 				Required:    true,
 			},
 			"props": schema.DynamicAttribute{
 				Description: "Input properties to pass to the Deno script.",
 				Required:    true,
 			},
+			"props_schema": schema.DynamicAttribute{
+				Description: "The shape props are expected to have, checked when the configuration is validated so that mistakes are caught before the script runs. Types are 'string', 'number', 'boolean' or 'any', a list is a single element list holding the shape of its elements, eg: ['string'], and a map is an object with a single '[key]' field. Any other object lists each prop, a prop whose name ends in '?' is optional and props it does not list are not allowed.",
+				Optional:    true,
+			},
 			"write_only_props": schema.DynamicAttribute{
 				Description: "Input properties to pass to the Deno script that are write-only.",
 				WriteOnly:   true,
@@ -95,27 +139,184 @@ func (r *denoBridgeResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				Description: "File path to a deno config file to use with the deno script. Useful for import maps, etc...",
 				Optional:    true,
 			},
+			"check_external_on_plan": schema.BoolAttribute{
+				Description: "Call the Deno script's modifyPlan method even when the props have not changed. Allows the script to force a replacement based on external signals that Terraform does not see in the props.",
+				Optional:    true,
+			},
+			"replace_triggers": schema.DynamicAttribute{
+				Description: "Arbitrary values that force the resource to be replaced whenever they change, eg: the result of a data source. Like replace_triggered_by but for any value rather than only other resources.",
+				Optional:    true,
+			},
+			"confirm_replace": schema.BoolAttribute{
+				Description: "Acknowledges a replacement that the script has asked to be confirmed. Without it such a replacement fails the plan, protecting critical resources from being destroyed unexpectedly.",
+				Optional:    true,
+			},
+			"resolve_paths": schema.ListAttribute{
+				Description: "Keys of the state and sensitive_state returned by the script that hold file paths, eg: ['output_file', 'artifacts.files']. Relative paths found at these keys are made absolute against the directory the script ran in, so that state does not depend on where Terraform is next run from. Nested keys are separated by dots and a key may hold a single path or a list of paths.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"redact_props": schema.ListAttribute{
+				Description: "Paths of props whose values are kept out of the provider's logs, including the JSON-RPC messages logged at trace level, and out of the diagnostics the script returns, eg: ['password', 'database.connection_string']. Nested keys are separated by dots and numeric segments are list indexes. Every string found at, or nested within, a path is redacted.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"state_merge": schema.StringAttribute{
+				Description: "How the state returned by the script's update is applied. 'replace' (the default) uses exactly what is returned, 'merge' overlays it onto the prior state so that keys the script does not return are kept.",
+				Optional:    true,
+			},
+			"no_config_discovery": schema.BoolAttribute{
+				Description: "Disable locating the closest deno config file relative to the script when config_file is not set. The script is then run with --no-config.",
+				Optional:    true,
+			},
+			"env_file": schema.StringAttribute{
+				Description: "Path to a .env file whose variables are loaded into the script's environment via --env-file. The script needs the env permission to read them.",
+				Optional:    true,
+			},
+			"env": schema.MapAttribute{
+				Description: "Environment variables set in the script's environment, on top of any set by the provider's env. The script needs the env permission to read them, which may be scoped to just these variables, eg: env=AWS_REGION.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"deno_flags": schema.ListAttribute{
+				Description: "Extra flags passed to deno run after the permissions, eg: --node-modules-dir=auto or --allow-scripts for scripts that import npm packages. Flags the provider controls, such as the config file and permissions, are rejected as they are set by their own attributes.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators:  []validator.List{denoFlagsValidator{}},
+			},
 			"permissions": schema.SingleNestedAttribute{
 				Description: "Deno runtime permissions for the script.",
 				Optional:    true,
+				Attributes:  permissionsAttributes(),
+			},
+			"serialize_key": schema.StringAttribute{
+				Description: "Resources sharing a serialize_key never run their create, read, update or delete at the same time, eg: when the backend they manage does not tolerate concurrent changes. Each operation waits for the one running to finish, and the time spent waiting counts towards its timeouts.",
+				Optional:    true,
+			},
+			"timeouts": schema.SingleNestedAttribute{
+				Description: "How long each operation may run for, as a Go duration (e.g., '10m'). An operation that runs for longer fails and its Deno process is stopped, eg: when the script hangs. Operations are unlimited by default.",
+				Optional:    true,
 				Attributes: map[string]schema.Attribute{
-					"all": schema.BoolAttribute{
-						Description: "Grant all permissions.",
+					"create": schema.StringAttribute{
+						Description: "How long create may run for, including waiting for the resource to become ready.",
+						Optional:    true,
+						Validators:  []validator.String{durationValidator{}},
+					},
+					"read": schema.StringAttribute{
+						Description: "How long read may run for.",
 						Optional:    true,
+						Validators:  []validator.String{durationValidator{}},
 					},
-					"allow": schema.ListAttribute{
-						Description: "List of permissions to allow (e.g., 'read', 'write', 'net').",
-						ElementType: types.StringType,
+					"update": schema.StringAttribute{
+						Description: "How long update may run for, including waiting for the resource to become ready.",
 						Optional:    true,
+						Validators:  []validator.String{durationValidator{}},
 					},
-					"deny": schema.ListAttribute{
-						Description: "List of permissions to deny.",
-						ElementType: types.StringType,
+					"delete": schema.StringAttribute{
+						Description: "How long delete may run for.",
 						Optional:    true,
+						Validators:  []validator.String{durationValidator{}},
 					},
 				},
 			},
+			"operation_config_files": schema.SingleNestedAttribute{
+				Description: "Deno config files for individual operations, replacing config_file while that operation runs. Useful when, eg: create needs a different import map to the rest of the lifecycle.",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"create": schema.StringAttribute{
+						Description: "File path to the deno config file to use for create.",
+						Optional:    true,
+					},
+					"read": schema.StringAttribute{
+						Description: "File path to the deno config file to use for read.",
+						Optional:    true,
+					},
+					"update": schema.StringAttribute{
+						Description: "File path to the deno config file to use for update.",
+						Optional:    true,
+					},
+					"delete": schema.StringAttribute{
+						Description: "File path to the deno config file to use for delete.",
+						Optional:    true,
+					},
+				},
+			},
+			"operation_permissions": schema.SingleNestedAttribute{
+				Description: "Deno runtime permissions for individual operations, replacing permissions while that operation runs. Allows one-off elevated permissions, eg: write during create, while the rest of the lifecycle runs with less.",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"create": schema.SingleNestedAttribute{
+						Description: "Deno runtime permissions for create.",
+						Optional:    true,
+						Attributes:  permissionsAttributes(),
+					},
+					"read": schema.SingleNestedAttribute{
+						Description: "Deno runtime permissions for read.",
+						Optional:    true,
+						Attributes:  permissionsAttributes(),
+					},
+					"update": schema.SingleNestedAttribute{
+						Description: "Deno runtime permissions for update.",
+						Optional:    true,
+						Attributes:  permissionsAttributes(),
+					},
+					"delete": schema.SingleNestedAttribute{
+						Description: "Deno runtime permissions for delete.",
+						Optional:    true,
+						Attributes:  permissionsAttributes(),
+					},
+				},
+			},
+		},
+	}
+}
+
+// permissionsAttributes returns the attributes of a set of Deno runtime permissions.
+func permissionsAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"all": schema.BoolAttribute{
+			Description: "Grant all permissions.",
+			Optional:    true,
 		},
+		"allow": schema.ListAttribute{
+			Description: "List of permissions to allow, optionally scoped to particular values (e.g., 'read', 'net', 'read=/etc,/tmp', 'net=api.github.com:443').",
+			ElementType: types.StringType,
+			Optional:    true,
+			Validators:  []validator.List{permissionEntriesValidator{}},
+		},
+		"deny": schema.ListAttribute{
+			Description: "List of permissions to deny, taking precedence over all and allow (e.g., 'write=/etc').",
+			ElementType: types.StringType,
+			Optional:    true,
+			Validators:  []validator.List{permissionEntriesValidator{}},
+		},
+		"learn": schema.BoolAttribute{
+			Description: "Learn mode. When the script is refused a permission, suggest a least-privilege allow list covering every permission observed so far.",
+			Optional:    true,
+		},
+	}
+}
+
+// ValidateConfig checks the props against the props_schema, when one is set.
+func (r *denoBridgeResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var propsSchema, props types.Dynamic
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("props_schema"), &propsSchema)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("props"), &props)...)
+	if resp.Diagnostics.HasError() || propsSchema.IsNull() || dynamic.ContainsUnknown(propsSchema) {
+		return
+	}
+
+	shape, err := parsePropsShape(dynamic.FromDynamic(propsSchema))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("props_schema"), "Invalid props_schema", err.Error())
+		return
+	}
+
+	// Parts of the props that are unknown until apply are not checked
+	for _, propErr := range shape.validate(dynamic.FromDynamic(props), nil) {
+		propPath := append([]string{"props"}, propErr.propPath...)
+		resp.Diagnostics.AddAttributeError(dynamic.PropPathToPath(&propPath), "Invalid props", propErr.message)
 	}
 }
 
@@ -153,11 +354,17 @@ func (r *denoBridgeResource) Create(ctx context.Context, req resource.CreateRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	writeOnlyProps := dynamic.FromDynamic(config.WriteOnlyProps)
+
+	// Secrets referenced with vault_ref are resolved here, so they are hashed and passed to the script but never stored
+	writeOnlyProps, err := resolveVaultRefs(ctx, dynamic.FromDynamic(config.WriteOnlyProps))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("write_only_props"), "Failed to resolve Vault reference", err.Error())
+		return
+	}
 
 	if writeOnlyProps != nil {
 		// Calculate hash of writeOnlyProps and store in private state
-		writeOnlyPropsHash := hashWriteOnlyProps(writeOnlyProps)
+		writeOnlyPropsHash := hashJSON(writeOnlyProps)
 		resp.Diagnostics.Append(
 			resp.Private.SetKey(ctx, "write_only_props_hash",
 				fmt.Appendf(nil, `{"hash":"%s"}`, writeOnlyPropsHash),
@@ -168,36 +375,94 @@ func (r *denoBridgeResource) Create(ctx context.Context, req resource.CreateRequ
 		}
 	}
 
+	// Record the replace triggers, so that ModifyPlan can tell when they change
+	resp.Diagnostics.Append(
+		resp.Private.SetKey(ctx, "replace_triggers_hash",
+			fmt.Appendf(nil, `{"hash":"%s"}`, hashJSON(dynamic.FromDynamic(plan.ReplaceTriggers))),
+		)...,
+	)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Record the idempotency key alongside the resource, a retried create is given the same key
+	idempotencyKey := createIdempotencyKey(&plan)
+	resp.Diagnostics.Append(
+		resp.Private.SetKey(ctx, "idempotency_key",
+			fmt.Appendf(nil, `{"key":"%s"}`, idempotencyKey),
+		)...,
+	)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Set the write-only props version to 1 on create
 	plan.WriteOnlyPropsVersion = types.Int64Value(1)
 
-	// Start the Deno server
-	c := deno.NewDenoClientResource(
-		r.providerConfig.DenoBinaryPath,
+	// Evaluate any props template functions
+	props, err := r.providerConfig.resourcePropsFor(plan.Props)
+	if err != nil {
+		addPropsError(&resp.Diagnostics, err)
+		return
+	}
+
+	// Limit how long the operation may run for, when a timeout is configured
+	ctx, cancel, timeout := plan.withOperationTimeout(ctx, "create", &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer cancel()
+	defer addTimeoutDiagnostic(ctx, &resp.Diagnostics, "create", timeout)
+
+	// Trace the operation, when an OTLP endpoint is configured
+	ctx, endSpan := traceOperation(ctx, "denobridge_resource", "create", plan.Path.ValueString())
+	defer endSpan(&resp.Diagnostics)
+
+	// Wait for the operations of other resources sharing the serialize_key to finish
+	unlock := r.providerConfig.serialize(ctx, plan.SerializeKey, &resp.Diagnostics)
+	if unlock == nil {
+		return
+	}
+	defer unlock()
+
+	// Run with the permissions for this operation
+	permissions := plan.permissionsFor(ctx, "create")
+	defer r.providerConfig.audit(ctx, "denobridge_resource", "create", plan.Path.ValueString(), permissions, time.Now(), &resp.Diagnostics)
+
+	// Start the Deno server, or reuse an idle one
+	c, release := r.providerConfig.startResourceClient(
+		ctx,
 		plan.Path.ValueString(),
-		plan.ConfigFile.ValueString(),
-		plan.Permissions.MapToDenoPermissions(),
+		plan.configFileFor(ctx, "create"),
+		permissions.MapToDenoPermissions(),
+		r.providerConfig.clientOptionsFor(plan.NoConfigDiscovery, plan.EnvFile, plan.Env, plan.DenoFlags),
+		&resp.Diagnostics,
 	)
-	if err := c.Client.Start(ctx); err != nil {
-		resp.Diagnostics.AddError("Failed to start Deno", err.Error())
+	if c == nil {
 		return
 	}
-	defer func() {
-		if err := c.Client.Stop(); err != nil {
-			resp.Diagnostics.AddWarning("Failed to stop Deno", err.Error())
-		}
-	}()
+	defer release()
+
+	// Keep the write-only props out of the logs, and out of any diagnostics the script echoes them back in
+	c.Client.AddSecrets(writeOnlySecrets(writeOnlyProps)...)
+	c.Client.AddSecrets(redactedPropSecrets(ctx, plan.RedactProps, plan.Props)...)
+	defer redactDiagnostics(&resp.Diagnostics, c.Client)
+
+	// Warn when the permissions do not match those the script declares that it needs
+	addDeclaredPermissionDiagnostics(&resp.Diagnostics, c.Client, permissions)
 
 	// Call the create endpoint
 	response, err := c.Create(ctx, &deno.CreateRequest{
-		Props:          dynamic.FromDynamic(plan.Props),
+		Props:          props,
 		WriteOnlyProps: writeOnlyProps,
+		IdempotencyKey: idempotencyKey,
 	})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to create resource",
 			fmt.Sprintf("Could not create resource via Deno script: %s", err.Error()),
 		)
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 
@@ -226,10 +491,56 @@ func (r *denoBridgeResource) Create(ctx context.Context, req resource.CreateRequ
 		}
 	}
 
+	// Scripts that do not return an id are given a generated one, as an empty id breaks imports
+	if response.ID == "" {
+		generated, err := uuid.GenerateUUID()
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to create resource", fmt.Sprintf("Could not generate an id for the resource: %s", err.Error()))
+			return
+		}
+		tflog.Debug(ctx, fmt.Sprintf("The Deno script returned no id, generated %s", generated))
+		response.ID = deno.ResourceID(generated)
+	}
+
+	// Show any advisories, recording them so that refreshes do not repeat them, and the steps left to the user
+	addAdvisoryWarnings(ctx, &resp.Diagnostics, resp.Private, response.Advisories, false)
+	addNextStepsWarning(&resp.Diagnostics, response.NextSteps)
+
+	// Keep the private state returned by the script, for its later operations
+	setScriptPrivate(ctx, resp.Private, response.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Wait for resources that are provisioned asynchronously to become ready. They are saved
+	// to state even if they never do, so that Terraform taints them rather than losing track of them.
+	nextState, nextSensitiveState, sensitivePaths, typedPaths := response.State, response.SensitiveState, response.SensitivePaths, response.TypedPaths
+	if response.PollUntilReady != nil {
+		polled := waitUntilReady(ctx, c, response.PollUntilReady, &deno.PollRequest{
+			ID:             response.ID,
+			Props:          props,
+			State:          response.State,
+			SensitiveState: response.SensitiveState,
+		}, &resp.Diagnostics)
+		nextState, nextSensitiveState, sensitivePaths, typedPaths = polledState(polled, nextState, nextSensitiveState, sensitivePaths, typedPaths)
+	}
+
 	// Set state
-	plan.ID = types.StringValue(response.ID)
-	plan.State = dynamic.ToDynamic(response.State)
-	plan.SensitiveState = dynamic.ToDynamic(response.SensitiveState)
+	plan.ID = types.StringValue(string(response.ID))
+	identifiers, diags := types.MapValueFrom(ctx, types.StringType, response.Identifiers)
+	resp.Diagnostics.Append(diags...)
+	plan.Identifiers = identifiers
+	stateValue, sensitiveStateValue := dynamic.MoveSensitivePaths(nextState, nextSensitiveState, sensitivePaths)
+	stateValue, sensitiveStateValue, diags = resolveStatePaths(ctx, &plan, stateValue, sensitiveStateValue)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	plan.State, plan.SensitiveState, diags = typedStateValues(stateValue, sensitiveStateValue, typedPaths)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
@@ -243,30 +554,66 @@ func (r *denoBridgeResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	// Start the Deno server
-	c := deno.NewDenoClientResource(
-		r.providerConfig.DenoBinaryPath,
+	// Limit how long the operation may run for, when a timeout is configured
+	ctx, cancel, timeout := state.withOperationTimeout(ctx, "read", &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer cancel()
+	defer addTimeoutDiagnostic(ctx, &resp.Diagnostics, "read", timeout)
+
+	// Trace the operation, when an OTLP endpoint is configured
+	ctx, endSpan := traceOperation(ctx, "denobridge_resource", "read", state.Path.ValueString())
+	defer endSpan(&resp.Diagnostics)
+
+	// Wait for the operations of other resources sharing the serialize_key to finish
+	unlock := r.providerConfig.serialize(ctx, state.SerializeKey, &resp.Diagnostics)
+	if unlock == nil {
+		return
+	}
+	defer unlock()
+
+	// Run with the permissions for this operation
+	permissions := state.permissionsFor(ctx, "read")
+	defer r.providerConfig.audit(ctx, "denobridge_resource", "read", state.Path.ValueString(), permissions, time.Now(), &resp.Diagnostics)
+
+	// Start the Deno server, or reuse an idle one
+	c, release := r.providerConfig.startResourceClient(
+		ctx,
 		state.Path.ValueString(),
-		state.ConfigFile.ValueString(),
-		state.Permissions.MapToDenoPermissions(),
+		state.configFileFor(ctx, "read"),
+		permissions.MapToDenoPermissions(),
+		r.providerConfig.clientOptionsFor(state.NoConfigDiscovery, state.EnvFile, state.Env, state.DenoFlags),
+		&resp.Diagnostics,
 	)
-	if err := c.Client.Start(ctx); err != nil {
-		resp.Diagnostics.AddError("Failed to start Deno", err.Error())
+	if c == nil {
+		return
+	}
+	defer release()
+
+	// Keep the props listed in redact_props out of the logs and diagnostics
+	c.Client.AddSecrets(redactedPropSecrets(ctx, state.RedactProps, state.Props)...)
+	defer redactDiagnostics(&resp.Diagnostics, c.Client)
+
+	// Give the script back its private state
+	private := scriptPrivate(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	defer func() {
-		if err := c.Client.Stop(); err != nil {
-			resp.Diagnostics.AddWarning("Failed to stop Deno", err.Error())
-		}
-	}()
 
 	// Call the read endpoint
-	response, err := c.Read(ctx, &deno.CreateReadRequest{ID: state.ID.ValueString(), Props: dynamic.FromDynamic(state.Props)})
+	response, err := c.Read(ctx, &deno.CreateReadRequest{
+		ID:      deno.ResourceID(state.ID.ValueString()),
+		Props:   r.providerConfig.withDefaultProps(dynamic.FromDynamic(state.Props)),
+		Private: private,
+		DryRun:  true,
+	})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to read resource",
 			fmt.Sprintf("Could not read resource via Deno script: %s", err.Error()),
 		)
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 
@@ -300,11 +647,54 @@ func (r *denoBridgeResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	// Set refreshed state
-	state.Props = dynamic.ToDynamic(response.Props)
-	state.State = dynamic.ToDynamic(response.State)
-	state.SensitiveState = dynamic.ToDynamic(response.SensitiveState)
+	// Show any advisories that differ from those last shown
+	addAdvisoryWarnings(ctx, &resp.Diagnostics, resp.Private, response.Advisories, true)
+
+	// Set refreshed state, keeping the known identifiers unless the script returned new ones
+	state.Props = dynamic.ToDynamic(r.providerConfig.withoutDefaultProps(response.Props, dynamic.FromDynamic(state.Props)))
+	if response.Identifiers != nil {
+		identifiers, diags := types.MapValueFrom(ctx, types.StringType, response.Identifiers)
+		resp.Diagnostics.Append(diags...)
+		state.Identifiers = identifiers
+	}
+	stateValue, sensitiveStateValue := dynamic.MoveSensitivePaths(response.State, response.SensitiveState, response.SensitivePaths)
+	stateValue, sensitiveStateValue, diags = resolveStatePaths(ctx, &state, stateValue, sensitiveStateValue)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.State, state.SensitiveState, diags = typedStateValues(stateValue, sensitiveStateValue, response.TypedPaths)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Run any read-only assertions the script makes about the resource.
+	// Failed assertions never block the read, they are only surfaced as warnings.
+	checkResponse, err := c.Check(ctx, &deno.CheckRequest{
+		ID:             deno.ResourceID(state.ID.ValueString()),
+		Props:          r.providerConfig.withDefaultProps(dynamic.FromDynamic(state.Props)),
+		State:          dynamic.FromDynamic(state.State),
+		SensitiveState: dynamic.FromDynamic(state.SensitiveState),
+		DryRun:         true,
+	})
+	if err != nil {
+		resp.Diagnostics.AddWarning("Failed to check resource", fmt.Sprintf("Could not check resource via Deno script: %s", err.Error()))
+		return
+	}
+	if checkResponse != nil && checkResponse.Diagnostics != nil {
+		for _, diag := range *checkResponse.Diagnostics {
+			if diag.PropPath != nil {
+				resp.Diagnostics.AddAttributeWarning(dynamic.PropPathToPath(diag.PropPath), diag.Summary, diag.Detail)
+			} else {
+				resp.Diagnostics.AddWarning(diag.Summary, diag.Detail)
+			}
+		}
+	}
 }
 
 // Update updates the resource and sets the updated Terraform state on success.
@@ -331,10 +721,16 @@ func (r *denoBridgeResource) Update(ctx context.Context, req resource.UpdateRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	nextWriteOnlyProps := dynamic.FromDynamic(config.WriteOnlyProps)
+
+	// Secrets referenced with vault_ref are resolved here, so they are hashed and passed to the script but never stored
+	nextWriteOnlyProps, err := resolveVaultRefs(ctx, dynamic.FromDynamic(config.WriteOnlyProps))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("write_only_props"), "Failed to resolve Vault reference", err.Error())
+		return
+	}
 
 	if nextWriteOnlyProps != nil {
-		newHash := hashWriteOnlyProps(nextWriteOnlyProps)
+		newHash := hashJSON(nextWriteOnlyProps)
 
 		// Get old hash from private state
 		oldHashBytes, diags := req.Private.GetKey(ctx, "write_only_props_hash")
@@ -375,37 +771,80 @@ func (r *denoBridgeResource) Update(ctx context.Context, req resource.UpdateRequ
 		plan.WriteOnlyPropsVersion = state.WriteOnlyPropsVersion
 	}
 
-	// Start the Deno server
-	c := deno.NewDenoClientResource(
-		r.providerConfig.DenoBinaryPath,
+	// Evaluate any props template functions
+	props, err := r.providerConfig.resourcePropsFor(plan.Props)
+	if err != nil {
+		addPropsError(&resp.Diagnostics, err)
+		return
+	}
+
+	// Limit how long the operation may run for, when a timeout is configured
+	ctx, cancel, timeout := plan.withOperationTimeout(ctx, "update", &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer cancel()
+	defer addTimeoutDiagnostic(ctx, &resp.Diagnostics, "update", timeout)
+
+	// Trace the operation, when an OTLP endpoint is configured
+	ctx, endSpan := traceOperation(ctx, "denobridge_resource", "update", plan.Path.ValueString())
+	defer endSpan(&resp.Diagnostics)
+
+	// Wait for the operations of other resources sharing the serialize_key to finish
+	unlock := r.providerConfig.serialize(ctx, plan.SerializeKey, &resp.Diagnostics)
+	if unlock == nil {
+		return
+	}
+	defer unlock()
+
+	// Run with the permissions for this operation
+	permissions := plan.permissionsFor(ctx, "update")
+	defer r.providerConfig.audit(ctx, "denobridge_resource", "update", plan.Path.ValueString(), permissions, time.Now(), &resp.Diagnostics)
+
+	// Start the Deno server, or reuse an idle one
+	c, release := r.providerConfig.startResourceClient(
+		ctx,
 		plan.Path.ValueString(),
-		plan.ConfigFile.ValueString(),
-		plan.Permissions.MapToDenoPermissions(),
+		plan.configFileFor(ctx, "update"),
+		permissions.MapToDenoPermissions(),
+		r.providerConfig.clientOptionsFor(plan.NoConfigDiscovery, plan.EnvFile, plan.Env, plan.DenoFlags),
+		&resp.Diagnostics,
 	)
-	if err := c.Client.Start(ctx); err != nil {
-		resp.Diagnostics.AddError("Failed to start Deno", err.Error())
+	if c == nil {
+		return
+	}
+	defer release()
+
+	// Keep the write-only props out of the logs, and out of any diagnostics the script echoes them back in
+	c.Client.AddSecrets(writeOnlySecrets(nextWriteOnlyProps)...)
+	c.Client.AddSecrets(redactedPropSecrets(ctx, plan.RedactProps, plan.Props, state.Props)...)
+	defer redactDiagnostics(&resp.Diagnostics, c.Client)
+
+	// Warn when the permissions do not match those the script declares that it needs
+	addDeclaredPermissionDiagnostics(&resp.Diagnostics, c.Client, permissions)
+
+	// Give the script back its private state
+	private := scriptPrivate(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	defer func() {
-		if err := c.Client.Stop(); err != nil {
-			resp.Diagnostics.AddWarning("Failed to stop Deno", err.Error())
-		}
-	}()
 
 	// Call the update endpoint
 	response, err := c.Update(ctx, &deno.UpdateRequest{
-		ID:                    state.ID.ValueString(),
-		NextProps:             dynamic.FromDynamic(plan.Props),
+		ID:                    deno.ResourceID(state.ID.ValueString()),
+		NextProps:             props,
 		NextWriteOnlyProps:    nextWriteOnlyProps,
-		CurrentProps:          dynamic.FromDynamic(state.Props),
+		CurrentProps:          r.providerConfig.withDefaultProps(dynamic.FromDynamic(state.Props)),
 		CurrentState:          dynamic.FromDynamic(state.State),
 		CurrentSensitiveState: dynamic.FromDynamic(state.SensitiveState),
+		Private:               private,
 	})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to update resource",
 			fmt.Sprintf("Could not update resource via Deno script: %s", err.Error()),
 		)
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 
@@ -434,12 +873,58 @@ func (r *denoBridgeResource) Update(ctx context.Context, req resource.UpdateRequ
 		}
 	}
 
-	// Keep the same ID
+	// Show any advisories, recording them so that refreshes do not repeat them, and the steps left to the user
+	addAdvisoryWarnings(ctx, &resp.Diagnostics, resp.Private, response.Advisories, false)
+	addNextStepsWarning(&resp.Diagnostics, response.NextSteps)
+
+	// Replace the private state of the script, when it returned any
+	setScriptPrivate(ctx, resp.Private, response.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Keep the same ID and identifiers
 	plan.ID = state.ID
+	plan.Identifiers = state.Identifiers
+
+	// Keep the prior state when the script says the update changed nothing it records, avoiding churn
+	if response.NoChanges != nil && *response.NoChanges {
+		plan.State, plan.SensitiveState = state.State, state.SensitiveState
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
+
+	// Wait for resources that are updated asynchronously to become ready, saving the update either way
+	var nextState, nextSensitiveState any = response.State, response.SensitiveState
+	sensitivePaths, typedPaths := response.SensitivePaths, response.TypedPaths
+	if response.PollUntilReady != nil {
+		polled := waitUntilReady(ctx, c, response.PollUntilReady, &deno.PollRequest{
+			ID:             deno.ResourceID(state.ID.ValueString()),
+			Props:          props,
+			State:          response.State,
+			SensitiveState: response.SensitiveState,
+		}, &resp.Diagnostics)
+		nextState, nextSensitiveState, sensitivePaths, typedPaths = polledState(polled, nextState, nextSensitiveState, sensitivePaths, typedPaths)
+	}
+
+	// Keep any state the script did not return, when asked to
+	if plan.StateMerge.ValueString() == stateMergeMerge {
+		nextState = dynamic.MergeState(dynamic.FromDynamic(state.State), nextState)
+		nextSensitiveState = dynamic.MergeState(dynamic.FromDynamic(state.SensitiveState), nextSensitiveState)
+	}
 
 	// Set updated state
-	plan.State = dynamic.ToDynamic(response.State)
-	plan.SensitiveState = dynamic.ToDynamic(response.SensitiveState)
+	stateValue, sensitiveStateValue := dynamic.MoveSensitivePaths(nextState, nextSensitiveState, sensitivePaths)
+	stateValue, sensitiveStateValue, diags = resolveStatePaths(ctx, &plan, stateValue, sensitiveStateValue)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	plan.State, plan.SensitiveState, diags = typedStateValues(stateValue, sensitiveStateValue, typedPaths)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
@@ -453,35 +938,67 @@ func (r *denoBridgeResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
-	// Start the Deno server
-	c := deno.NewDenoClientResource(
-		r.providerConfig.DenoBinaryPath,
+	// Limit how long the operation may run for, when a timeout is configured
+	ctx, cancel, timeout := state.withOperationTimeout(ctx, "delete", &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer cancel()
+	defer addTimeoutDiagnostic(ctx, &resp.Diagnostics, "delete", timeout)
+
+	// Trace the operation, when an OTLP endpoint is configured
+	ctx, endSpan := traceOperation(ctx, "denobridge_resource", "delete", state.Path.ValueString())
+	defer endSpan(&resp.Diagnostics)
+
+	// Wait for the operations of other resources sharing the serialize_key to finish
+	unlock := r.providerConfig.serialize(ctx, state.SerializeKey, &resp.Diagnostics)
+	if unlock == nil {
+		return
+	}
+	defer unlock()
+
+	// Run with the permissions for this operation
+	permissions := state.permissionsFor(ctx, "delete")
+	defer r.providerConfig.audit(ctx, "denobridge_resource", "delete", state.Path.ValueString(), permissions, time.Now(), &resp.Diagnostics)
+
+	// Start the Deno server, or reuse an idle one
+	c, release := r.providerConfig.startResourceClient(
+		ctx,
 		state.Path.ValueString(),
-		state.ConfigFile.ValueString(),
-		state.Permissions.MapToDenoPermissions(),
+		state.configFileFor(ctx, "delete"),
+		permissions.MapToDenoPermissions(),
+		r.providerConfig.clientOptionsFor(state.NoConfigDiscovery, state.EnvFile, state.Env, state.DenoFlags),
+		&resp.Diagnostics,
 	)
-	if err := c.Client.Start(ctx); err != nil {
-		resp.Diagnostics.AddError("Failed to start Deno", err.Error())
+	if c == nil {
+		return
+	}
+	defer release()
+
+	// Keep the props listed in redact_props out of the logs and diagnostics
+	c.Client.AddSecrets(redactedPropSecrets(ctx, state.RedactProps, state.Props)...)
+	defer redactDiagnostics(&resp.Diagnostics, c.Client)
+
+	// Give the script back its private state
+	private := scriptPrivate(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	defer func() {
-		if err := c.Client.Stop(); err != nil {
-			resp.Diagnostics.AddWarning("Failed to stop Deno", err.Error())
-		}
-	}()
 
 	// Call the delete endpoint
 	response, err := c.Delete(ctx, &deno.DeleteRequest{
-		ID:             state.ID.ValueString(),
-		Props:          dynamic.FromDynamic(state.Props),
+		ID:             deno.ResourceID(state.ID.ValueString()),
+		Props:          r.providerConfig.withDefaultProps(dynamic.FromDynamic(state.Props)),
 		State:          dynamic.FromDynamic(state.State),
 		SensitiveState: dynamic.FromDynamic(state.SensitiveState),
+		Private:        private,
 	})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to delete resource",
 			fmt.Sprintf("Could not delete resource via Deno script: %s", err.Error()),
 		)
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 
@@ -510,8 +1027,17 @@ func (r *denoBridgeResource) Delete(ctx context.Context, req resource.DeleteRequ
 		}
 	}
 
-	// Double check that the operation actually completed
-	if !response.Done {
+	// Scripts that clean up several things report each as a step, every one of which must be done
+	if incomplete := response.IncompleteSteps(); len(incomplete) > 0 {
+		resp.Diagnostics.AddError(
+			"Failed to delete resource",
+			fmt.Sprintf("Deno script did not complete every cleanup step, incomplete steps:\n\n- %s", strings.Join(incomplete, "\n- ")),
+		)
+		return
+	}
+
+	// Double check that the operation actually completed, only an explicit done: false fails unless strict_done is set
+	if !response.IsDone(r.providerConfig.StrictDone) {
 		resp.Diagnostics.AddError(
 			"Failed to delete resource",
 			"Deno script did not report the operation as done",
@@ -541,29 +1067,80 @@ func (r *denoBridgeResource) ModifyPlan(ctx context.Context, req resource.Modify
 		}
 	}
 
-	// Bail out early if nothing is actually changing for updates
+	// Replace the resource when any of its replace triggers have changed
+	if plan != nil && state != nil {
+		replace, diags := replaceTriggersChanged(ctx, req.Private, plan, state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if replace {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("replace_triggers"))
+		}
+	}
+
+	// Catch an invalid state_merge mode at plan time rather than after the script has run
+	if plan != nil && !plan.StateMerge.IsNull() && !plan.StateMerge.IsUnknown() {
+		if mode := plan.StateMerge.ValueString(); mode != stateMergeReplace && mode != stateMergeMerge {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("state_merge"),
+				"Invalid state_merge",
+				fmt.Sprintf("Expected '%s' or '%s', got: '%s'", stateMergeReplace, stateMergeMerge, mode),
+			)
+			return
+		}
+	}
+
+	// Bail out early if nothing is actually changing for updates,
+	// unless the script has asked to check for external changes on every plan.
 	if plan != nil && state != nil {
-		if plan.Props.Equal(state.Props) {
+		if plan.Props.Equal(state.Props) && !plan.CheckExternalOnPlan.ValueBool() {
 			return
 		}
 	}
 
+	// The script can not meaningfully plan props that will only be known at apply time
+	if plan != nil && dynamic.ContainsUnknown(plan.Props) {
+		tflog.Debug(ctx, "Skipping the Deno script's modifyPlan as the props contain values that are unknown until apply")
+		return
+	}
+
 	// Get the deno script from the plan for create & update operations.
 	// Otherwise for delete we get the details from the existing state.
 	var denoScriptPath string
 	var denoConfigPath string
+	var noConfigDiscovery types.Bool
+	var envFile types.String
+	var env types.Map
+	var flags types.List
+	var redactProps types.List
+	var props []types.Dynamic
 	var denoPermissions *deno.PermissionsTF
 	if plan != nil {
 		denoScriptPath = plan.Path.ValueString()
 		denoConfigPath = plan.ConfigFile.ValueString()
+		noConfigDiscovery = plan.NoConfigDiscovery
+		envFile = plan.EnvFile
+		env = plan.Env
+		flags = plan.DenoFlags
+		redactProps = plan.RedactProps
+		props = append(props, plan.Props)
 		denoPermissions = plan.Permissions
 	} else {
 		if state != nil {
 			denoScriptPath = state.Path.ValueString()
 			denoConfigPath = state.ConfigFile.ValueString()
+			noConfigDiscovery = state.NoConfigDiscovery
+			envFile = state.EnvFile
+			env = state.Env
+			flags = state.DenoFlags
+			redactProps = state.RedactProps
 			denoPermissions = state.Permissions
 		}
 	}
+	if state != nil {
+		props = append(props, state.Props)
+	}
 
 	// Bail out if we can't call deno
 	if denoScriptPath == "" || denoPermissions == nil {
@@ -571,27 +1148,33 @@ func (r *denoBridgeResource) ModifyPlan(ctx context.Context, req resource.Modify
 		return
 	}
 
-	// Start the Deno server
-	c := deno.NewDenoClientResource(
-		r.providerConfig.DenoBinaryPath,
+	// Warn if the script can not read the variables loaded from its env file, or set by env
+	addEnvFileDiagnostics(&resp.Diagnostics, envFile, denoPermissions)
+	addEnvDiagnostics(&resp.Diagnostics, env, denoPermissions)
+
+	// Start the Deno server, or reuse an idle one
+	c, release := r.providerConfig.startResourceClient(
+		ctx,
 		denoScriptPath,
 		denoConfigPath,
 		denoPermissions.MapToDenoPermissions(),
+		r.providerConfig.clientOptionsFor(noConfigDiscovery, envFile, env, flags),
+		&resp.Diagnostics,
 	)
-	if err := c.Client.Start(ctx); err != nil {
-		resp.Diagnostics.AddError("Failed to start Deno", err.Error())
+	if c == nil {
 		return
 	}
-	defer func() {
-		if err := c.Client.Stop(); err != nil {
-			resp.Diagnostics.AddWarning("Failed to stop Deno", err.Error())
-		}
-	}()
+	defer release()
+
+	// Keep the props listed in redact_props out of the logs and diagnostics
+	c.Client.AddSecrets(redactedPropSecrets(ctx, redactProps, props...)...)
+	defer redactDiagnostics(&resp.Diagnostics, c.Client)
 
 	// Build the request payload
-	var id *string
+	var id *deno.ResourceID
 	if state != nil {
-		id = state.ID.ValueStringPointer()
+		stateID := deno.ResourceID(state.ID.ValueString())
+		id = &stateID
 	}
 	planType := ""
 	var nextProps any
@@ -599,23 +1182,38 @@ func (r *denoBridgeResource) ModifyPlan(ctx context.Context, req resource.Modify
 	var currentState any
 	if plan != nil && state == nil {
 		planType = "create"
-		nextProps = dynamic.FromDynamic(plan.Props)
 	}
 	var currentSensitiveState any
 	if plan != nil && state != nil {
 		planType = "update"
-		nextProps = dynamic.FromDynamic(plan.Props)
-		currentProps = dynamic.FromDynamic(state.Props)
+		currentProps = r.providerConfig.withDefaultProps(dynamic.FromDynamic(state.Props))
 		currentState = dynamic.FromDynamic(state.State)
 		currentSensitiveState = dynamic.FromDynamic(state.SensitiveState)
 	}
 	if plan == nil && state != nil {
 		planType = "delete"
-		currentProps = dynamic.FromDynamic(state.Props)
+		currentProps = r.providerConfig.withDefaultProps(dynamic.FromDynamic(state.Props))
 		currentState = dynamic.FromDynamic(state.State)
 		currentSensitiveState = dynamic.FromDynamic(state.SensitiveState)
 	}
 
+	// The planned props are checked and have their template functions evaluated, the same as on create and update
+	if plan != nil {
+		var err error
+		if nextProps, err = r.providerConfig.resourcePropsFor(plan.Props); err != nil {
+			addPropsError(&resp.Diagnostics, err)
+			return
+		}
+	}
+
+	// Give the script a chance to block the plan when the environment does not allow the change
+	if planType == "create" || planType == "update" {
+		r.checkPreconditions(ctx, c, id, planType, nextProps, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	response, err := c.ModifyPlan(ctx, &deno.ModifyPlanRequest{
 		ID:                    id,
 		PlanType:              planType,
@@ -623,9 +1221,11 @@ func (r *denoBridgeResource) ModifyPlan(ctx context.Context, req resource.Modify
 		CurrentProps:          currentProps,
 		CurrentState:          currentState,
 		CurrentSensitiveState: currentSensitiveState,
+		DryRun:                true,
 	})
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to modify the plan", err.Error())
+		addDenoErrorDiagnostics(&resp.Diagnostics, c.Client, err)
 		return
 	}
 
@@ -634,15 +1234,55 @@ func (r *denoBridgeResource) ModifyPlan(ctx context.Context, req resource.Modify
 		return
 	}
 
+	// Surface the plan summary - purely informational, it does not change the plan
+	if response.Summary != nil && *response.Summary != "" {
+		resp.Diagnostics.AddWarning("Plan summary", *response.Summary)
+	}
+
+	// Only an existing resource is destroyed by a replacement, so there is nothing to confirm on create
+	requiresReplacement := response.RequiresReplacement != nil && *response.RequiresReplacement
+	replacements := propReplacementsChanged(response.Replacements, plan, state)
+	confirmationRequired := response.RequireConfirmation != nil && *response.RequireConfirmation
+	if (requiresReplacement || len(replacements) > 0) && confirmationRequired && plan != nil && state != nil && !plan.ConfirmReplace.ValueBool() {
+		detail := "The Deno script requires this resource to be replaced, destroying the existing resource, but has asked for the replacement to be confirmed first."
+		if requiresReplacement && response.ReplacementReason != nil && *response.ReplacementReason != "" {
+			detail += fmt.Sprintf("\n\nReason: %s", *response.ReplacementReason)
+		}
+		for _, replacement := range replacements {
+			detail += fmt.Sprintf("\n\nReason (%s): %s", strings.Join(replacement.Path, "."), replacement.Reason)
+		}
+		detail += "\n\nSet confirm_replace = true on the resource to allow the replacement, and remove it again once applied."
+		resp.Diagnostics.AddAttributeError(path.Root("confirm_replace"), "Resource replacement requires confirmation", detail)
+		return
+	}
+
+	// Handle replacements - marking the individual props whose changes require a replacement
+	for _, replacement := range replacements {
+		replacePath := dynamic.PropPathToPath(&replacement.Path)
+		resp.RequiresReplace = append(resp.RequiresReplace, replacePath)
+		resp.Diagnostics.AddAttributeWarning(replacePath, "Attribute requires replacement", replacement.Reason)
+	}
+
 	// Handle requiresReplacement - instructing tf to do a create then delete instead of an update
-	if response.RequiresReplacement != nil && *response.RequiresReplacement {
+	if requiresReplacement {
+		if response.ReplacementReason != nil && *response.ReplacementReason != "" {
+			resp.Diagnostics.AddWarning("Resource requires replacement", *response.ReplacementReason)
+		}
+		if plan != nil && state != nil && plan.Props.Equal(state.Props) {
+			// Terraform only honours RequiresReplace for attributes that actually change.
+			// When the props are unchanged the id is marked unknown to give the replacement a change to hang off.
+			plan.ID = types.StringUnknown()
+			resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("id"))
+			return
+		}
 		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("props"))
 		return
 	}
 
 	// Handle modified props - allows the script to modify the planned properties
 	if response.ModifiedProps != nil {
-		plan.Props = dynamic.ToDynamic(response.ModifiedProps)
+		plan.Props = dynamic.ToDynamic(r.providerConfig.withoutDefaultProps(response.ModifiedProps, dynamic.FromDynamic(plan.Props)))
 		resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
 		return
 	}
@@ -679,7 +1319,7 @@ func (r *denoBridgeResource) ModifyPlan(ctx context.Context, req resource.Modify
 // needed to uniquely identify the resource (resource-dependent).
 func (r *denoBridgeResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	var importConfig struct {
-		ID          string            `json:"id"`
+		ID          deno.ResourceID   `json:"id"`
 		Path        string            `json:"path"`
 		Props       *map[string]any   `json:"props,omitempty"`
 		ConfigFile  *string           `json:"config_file,omitempty"`
@@ -699,8 +1339,14 @@ func (r *denoBridgeResource) ImportState(ctx context.Context, req resource.Impor
 		props = dynamic.ToDynamic(importConfig.Props)
 	}
 
+	// Give the script a chance to refuse resources that are not ready to be imported
+	r.checkCanImport(ctx, importConfig.ID, importConfig.Path, importConfig.ConfigFile, importConfig.Props, importConfig.Permissions, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, denoBridgeResourceModel{
-		ID:          types.StringValue(importConfig.ID),
+		ID:          types.StringValue(string(importConfig.ID)),
 		Path:        types.StringValue(importConfig.Path),
 		Props:       props,
 		ConfigFile:  types.StringPointerValue(importConfig.ConfigFile),
@@ -708,15 +1354,211 @@ func (r *denoBridgeResource) ImportState(ctx context.Context, req resource.Impor
 	})...)
 }
 
-// hashWriteOnlyProps creates a SHA256 hash of the write-only properties for change detection.
-// Returns an empty string if props is nil.
-func hashWriteOnlyProps(props any) string {
-	if props == nil {
+// checkPreconditions calls the Deno script's optional precondition method, adding its diagnostics to diags.
+// Preconditions that are not met are reported as errors, blocking the plan. Scripts that do not implement
+// precondition allow every plan.
+func (r *denoBridgeResource) checkPreconditions(ctx context.Context, c *deno.DenoClientResource, id *deno.ResourceID, planType string, props any, diags *diag.Diagnostics) {
+	response, err := c.Precondition(ctx, &deno.PreconditionRequest{
+		ID:       id,
+		PlanType: planType,
+		Props:    props,
+		DryRun:   true,
+	})
+	if err != nil {
+		diags.AddError("Failed to check preconditions", err.Error())
+		addDenoErrorDiagnostics(diags, c.Client, err)
+		return
+	}
+
+	// Handle diagnostics - errors are preconditions that are not met
+	if response != nil && response.Diagnostics != nil {
+		for _, diag := range *response.Diagnostics {
+			switch diag.Severity {
+			case "error":
+				summary := "Precondition failed: " + diag.Summary
+				if diag.PropPath != nil {
+					diags.AddAttributeError(dynamic.PropPathToPath(diag.PropPath), summary, diag.Detail)
+				} else {
+					diags.AddError(summary, diag.Detail)
+				}
+			case "warning":
+				if diag.PropPath != nil {
+					diags.AddAttributeWarning(dynamic.PropPathToPath(diag.PropPath), diag.Summary, diag.Detail)
+				} else {
+					diags.AddWarning(diag.Summary, diag.Detail)
+				}
+			}
+		}
+	}
+}
+
+// checkCanImport calls the Deno script's optional canImport method, adding its diagnostics to diags.
+// Scripts that do not implement canImport accept every import.
+func (r *denoBridgeResource) checkCanImport(ctx context.Context, id deno.ResourceID, scriptPath string, configFile *string, props *map[string]any, permissions *deno.Permissions, diags *diag.Diagnostics) {
+	var configPath string
+	if configFile != nil {
+		configPath = *configFile
+	}
+	if permissions == nil {
+		permissions = &deno.Permissions{}
+	}
+
+	// Start the Deno server, or reuse an idle one
+	c, release := r.providerConfig.startResourceClient(
+		ctx,
+		scriptPath,
+		configPath,
+		permissions,
+		r.providerConfig.clientOptionsFor(types.BoolNull(), types.StringNull(), types.MapNull(types.StringType), types.ListNull(types.StringType)),
+		diags,
+	)
+	if c == nil {
+		return
+	}
+	defer release()
+
+	var importProps any
+	if props != nil {
+		importProps = *props
+	}
+	importProps = r.providerConfig.withDefaultProps(importProps)
+	response, err := c.CanImport(ctx, &deno.CanImportRequest{ID: id, Props: importProps})
+	if err != nil {
+		diags.AddError(
+			"Failed to import resource",
+			fmt.Sprintf("Could not check that the resource can be imported via Deno script: %s", err.Error()),
+		)
+		addDenoErrorDiagnostics(diags, c.Client, err)
+		return
+	}
+
+	// Handle diagnostics - errors explain why the resource can not be imported yet
+	if response != nil && response.Diagnostics != nil {
+		for _, diag := range *response.Diagnostics {
+			switch diag.Severity {
+			case "error":
+				if diag.PropPath != nil {
+					diags.AddAttributeError(dynamic.PropPathToPath(diag.PropPath), diag.Summary, diag.Detail)
+				} else {
+					diags.AddError(diag.Summary, diag.Detail)
+				}
+			case "warning":
+				if diag.PropPath != nil {
+					diags.AddAttributeWarning(dynamic.PropPathToPath(diag.PropPath), diag.Summary, diag.Detail)
+				} else {
+					diags.AddWarning(diag.Summary, diag.Detail)
+				}
+			}
+		}
+	}
+}
+
+// typedStateValues converts the state and sensitive state returned by the script to Terraform values, storing the
+// lists and objects found at each of typedPaths as a list or map with a single element type.
+func typedStateValues(stateValue, sensitiveStateValue any, typedPaths [][]string) (types.Dynamic, types.Dynamic, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	state, err := dynamic.ToTypedDynamic(stateValue, typedPaths)
+	if err != nil {
+		diags.AddAttributeError(path.Root("state"), "Invalid typed state", fmt.Sprintf("The state returned by the Deno script could not be typed: %s", err.Error()))
+	}
+	sensitiveState, err := dynamic.ToTypedDynamic(sensitiveStateValue, typedPaths)
+	if err != nil {
+		diags.AddAttributeError(path.Root("sensitive_state"), "Invalid typed state", fmt.Sprintf("The sensitive state returned by the Deno script could not be typed: %s", err.Error()))
+	}
+	return state, sensitiveState, diags
+}
+
+// resolveStatePaths makes the relative file paths found at the keys listed in resolve_paths absolute, against the
+// directory the script ran in, which is the working directory of the provider.
+func resolveStatePaths(ctx context.Context, model *denoBridgeResourceModel, stateValue, sensitiveStateValue any) (any, any, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if model.ResolvePaths.IsNull() || model.ResolvePaths.IsUnknown() {
+		return stateValue, sensitiveStateValue, diags
+	}
+
+	var keys []string
+	diags.Append(model.ResolvePaths.ElementsAs(ctx, &keys, false)...)
+	if diags.HasError() {
+		return stateValue, sensitiveStateValue, diags
+	}
+	baseDir, err := os.Getwd()
+	if err != nil {
+		diags.AddError("Failed to resolve paths in state", fmt.Sprintf("Could not determine the working directory: %s", err.Error()))
+		return stateValue, sensitiveStateValue, diags
+	}
+	return dynamic.ResolvePaths(stateValue, keys, baseDir), dynamic.ResolvePaths(sensitiveStateValue, keys, baseDir), diags
+}
+
+// replaceTriggersChanged reports whether the planned replace triggers differ from those the resource was created with.
+//
+// The triggers are compared against the hash recorded in private state on create. Resources created before
+// replace triggers were recorded fall back to comparing against the value in state. Unknown triggers are
+// treated as changed, as they can only be known at apply time.
+func replaceTriggersChanged(ctx context.Context, private privateStateGetter, plan, state *denoBridgeResourceModel) (bool, diag.Diagnostics) {
+	if plan.ReplaceTriggers.IsUnknown() || plan.ReplaceTriggers.IsUnderlyingValueUnknown() {
+		return true, nil
+	}
+
+	oldHashBytes, diags := private.GetKey(ctx, "replace_triggers_hash")
+	if diags.HasError() {
+		return false, diags
+	}
+	if oldHashBytes == nil {
+		return !plan.ReplaceTriggers.Equal(state.ReplaceTriggers), diags
+	}
+
+	var hashWrapper struct {
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(oldHashBytes, &hashWrapper); err != nil {
+		diags.AddError(
+			"Failed to read replace triggers hash",
+			fmt.Sprintf("Could not parse hash from private state: %s", err.Error()),
+		)
+		return false, diags
+	}
+	return hashWrapper.Hash != hashJSON(dynamic.FromDynamic(plan.ReplaceTriggers)), diags
+}
+
+// propReplacementsChanged returns the replacements a script asked for whose props are changing in an update, with
+// their paths prefixed by "props" so that they can be given to Terraform. A prop that is not changing can not cause
+// a replacement, so it is dropped rather than reported.
+func propReplacementsChanged(replacements *[]deno.PropReplacement, plan, state *denoBridgeResourceModel) []deno.PropReplacement {
+	if replacements == nil || plan == nil || state == nil {
+		return nil
+	}
+	nextProps := dynamic.FromDynamic(plan.Props)
+	currentProps := dynamic.FromDynamic(state.Props)
+
+	var changed []deno.PropReplacement
+	for _, replacement := range *replacements {
+		nextValue, nextFound := dynamic.ValueAt(nextProps, replacement.Path)
+		currentValue, currentFound := dynamic.ValueAt(currentProps, replacement.Path)
+		if nextFound == currentFound && reflect.DeepEqual(nextValue, currentValue) {
+			continue
+		}
+		changed = append(changed, deno.PropReplacement{
+			Path:   append([]string{"props"}, replacement.Path...),
+			Reason: replacement.Reason,
+		})
+	}
+	return changed
+}
+
+// privateStateGetter reads keys from a resource's private state.
+type privateStateGetter interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// hashJSON creates a SHA256 hash of the JSON encoding of v for change detection, eg: of the write-only props,
+// replace triggers or advisories recorded in private state. Returns an empty string if v is nil.
+func hashJSON(v any) string {
+	if v == nil {
 		return ""
 	}
 
 	// Serialize to JSON for consistent hashing
-	data, err := json.Marshal(props)
+	data, err := json.Marshal(v)
 	if err != nil {
 		// If we can't marshal, return empty string
 		return ""
@@ -726,3 +1568,20 @@ func hashWriteOnlyProps(props any) string {
 	hash := sha256.Sum256(data)
 	return hex.EncodeToString(hash[:])
 }
+
+// createIdempotencyKey derives the idempotency key for creating a resource.
+//
+// Private state is only persisted once a create succeeds, and the framework neither hands Create the private
+// state of its plan nor the resource's address, so a random key would be lost along with the response it is
+// meant to protect. Instead the key is derived from the script and the planned props, before any template
+// functions are evaluated, making it identical for every retry of the same create. Write-only props are
+// excluded as they typically hold credentials that may rotate between attempts.
+func createIdempotencyKey(plan *denoBridgeResourceModel) string {
+	return hashJSON(struct {
+		Path  string `json:"path"`
+		Props any    `json:"props"`
+	}{
+		Path:  plan.Path.ValueString(),
+		Props: dynamic.FromDynamic(plan.Props),
+	})
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// startResourceClient returns a started client for a resource script, the script's daemon when daemon is
+// set, or reusing an idle one from the provider's client pool when max_idle_processes allows it. Processes
+// that may not be reused, see deno.ClientOptions.Reusable, are always started for the one operation. It
+// returns nil after adding an error to diags when Deno could not be started.
+//
+// The returned release func must be called once the operation is done with the client. A client is
+// returned to the pool when the operation succeeded, otherwise it is stopped so that whatever state
+// the failure left the script in does not leak into the next operation.
+func (c *ProviderConfig) startResourceClient(ctx context.Context, scriptPath, configPath string, permissions *deno.Permissions, options *deno.ClientOptions, diags *diag.Diagnostics) (*deno.DenoClientResource, func()) {
+	key := deno.PoolKey(c.DenoBinaryPath, scriptPath, configPath, permissions, options)
+	if c.daemons != nil && options.Reusable() {
+		return c.acquireDaemon(ctx, key, scriptPath, configPath, permissions, options, diags)
+	}
+
+	client := c.clientPool.Take(key)
+	if client != nil {
+		tflog.Debug(ctx, fmt.Sprintf("Reusing an idle Deno process for %s", scriptPath))
+	} else {
+		client = deno.NewDenoClientResource(c.DenoBinaryPath, scriptPath, configPath, permissions, options)
+		if err := c.startPoolable(ctx, client.Client); err != nil {
+			diags.AddError("Failed to start Deno", err.Error())
+			addDenoErrorDiagnostics(diags, client.Client, err)
+			return nil, nil
+		}
+	}
+
+	release := func() {
+		if !diags.HasError() && c.clientPool.Put(key, client) {
+			return
+		}
+		if err := client.Client.Stop(); err != nil {
+			diags.AddWarning("Failed to stop Deno", err.Error())
+		}
+	}
+	return client, release
+}
+
+// acquireDaemon returns the daemon of a resource script, starting it when it is not already running.
+// It returns nil after adding an error to diags when Deno could not be started.
+//
+// The returned release func must be called once the operation is done with the daemon. When the
+// operation failed the daemon is replaced, and stopped once no other operation is using it.
+func (c *ProviderConfig) acquireDaemon(ctx context.Context, key, scriptPath, configPath string, permissions *deno.Permissions, options *deno.ClientOptions, diags *diag.Diagnostics) (*deno.DenoClientResource, func()) {
+	started := false
+	client, releaseDaemon, err := c.daemons.Acquire(ctx, key,
+		func() *deno.DenoClientResource {
+			return deno.NewDenoClientResource(c.DenoBinaryPath, scriptPath, configPath, permissions, options)
+		},
+		func(client *deno.DenoClient) error {
+			started = true
+			return c.startPoolable(ctx, client)
+		},
+	)
+	if err != nil {
+		diags.AddError("Failed to start Deno", err.Error())
+		if client != nil {
+			addDenoErrorDiagnostics(diags, client.Client, err)
+		}
+		return nil, nil
+	}
+	if started {
+		tflog.Debug(ctx, fmt.Sprintf("Started a Deno daemon for %s", scriptPath))
+	} else {
+		tflog.Debug(ctx, fmt.Sprintf("Reusing the Deno daemon for %s", scriptPath))
+	}
+
+	release := func() {
+		if err := releaseDaemon(diags.HasError()); err != nil {
+			diags.AddWarning("Failed to stop Deno", err.Error())
+		}
+	}
+	return client, release
+}
+
+// startPoolable starts the Deno child process. When pooling, the process may outlive the operation
+// that started it, so it is started with a context that is only cancelled when the operation is
+// cancelled before the process has finished starting.
+func (c *ProviderConfig) startPoolable(ctx context.Context, client *deno.DenoClient) error {
+	if c.clientPool == nil && c.daemons == nil {
+		return startDeno(ctx, client)
+	}
+	startCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stopCancel := context.AfterFunc(ctx, cancel)
+	err := startDeno(startCtx, client)
+	if !stopCancel() || err != nil {
+		cancel()
+	}
+	return err
+}
@@ -0,0 +1,38 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { ResourceProvider } from "@brad-jones/terraform-provider-denobridge";
+import phase from "phase";
+
+interface Props {
+  path: string;
+  version: number;
+}
+
+interface State {
+  phase: string;
+}
+
+// Records which config file each operation ran with, the "phase" import is mapped differently by each config file.
+new ResourceProvider<Props, State>({
+  async create({ path }) {
+    await Deno.writeTextFile(path, phase);
+    return { id: path, state: { phase } };
+  },
+  async read(id, props) {
+    try {
+      return { props, state: { phase: await Deno.readTextFile(id) } };
+    } catch (e) {
+      if (e instanceof Deno.errors.NotFound) {
+        return { exists: false };
+      }
+      throw e;
+    }
+  },
+  async update(id, nextProps, currentProps, currentState) {
+    await Deno.writeTextFile(id, phase);
+    return { phase };
+  },
+  async delete(id, props) {
+    await Deno.remove(id);
+  },
+});
@@ -0,0 +1,29 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { ResourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  name: string;
+}
+
+new ResourceProvider<Props>({
+  async create({ name }) {
+    return { id: name };
+  },
+  async read(id, props) {
+    return { props: { name: id } };
+  },
+  async update(id, nextProps, currentProps) {},
+  async delete(id, props) {},
+  async canImport(id, props) {
+    if (id === "provisioning") {
+      return {
+        diagnostics: [{
+          severity: "error",
+          summary: "Resource not ready",
+          detail: `resource ${id} is still provisioning`,
+        }],
+      };
+    }
+  },
+});
@@ -0,0 +1,39 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { keepState, ResourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  path: string;
+  content: string;
+}
+
+interface State {
+  revision: number;
+}
+
+// The revision only changes when the file is replaced, rewriting its content keeps the state as it was.
+new ResourceProvider<Props, State>({
+  async create({ path, content }) {
+    await Deno.writeTextFile(path, content);
+    return { id: path, state: { revision: 1 } };
+  },
+  async read(id, props) {
+    try {
+      return { props: { path: id, content: await Deno.readTextFile(id) }, state: { revision: 1 } };
+    } catch (e) {
+      if (e instanceof Deno.errors.NotFound) {
+        return { exists: false };
+      }
+      throw e;
+    }
+  },
+  async update(id, nextProps, currentProps, currentState) {
+    await Deno.writeTextFile(id, nextProps.content);
+    keepState();
+    // Ignored, the provider keeps the prior state
+    return { revision: currentState.revision + 1 };
+  },
+  async delete(id) {
+    await Deno.remove(id);
+  },
+});
@@ -0,0 +1,42 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { ResourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  requestId: string;
+}
+
+new ResourceProvider<Props>({
+  async create({ requestId }) {
+    return { id: requestId };
+  },
+  async read(id, props) {
+    return { props };
+  },
+  async update(id, nextProps, currentProps) {},
+  async delete(id, props) {},
+  async precondition(id, planType, props) {
+    if (props.requestId.includes("${")) {
+      return {
+        diagnostics: [{
+          severity: "error",
+          summary: "Unevaluated template",
+          detail: `The requestId ${props.requestId} was not evaluated before planning`,
+          propPath: ["props", "requestId"],
+        }],
+      };
+    }
+  },
+  async modifyPlan(id, planType, nextProps, currentProps) {
+    if (nextProps?.requestId.includes("${")) {
+      return {
+        diagnostics: [{
+          severity: "error",
+          summary: "Unevaluated template",
+          detail: `The requestId ${nextProps.requestId} was not evaluated before planning`,
+          propPath: ["props", "requestId"],
+        }],
+      };
+    }
+  },
+});
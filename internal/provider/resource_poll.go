@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Defaults for a deno.PollInstruction that leaves them unset.
+const (
+	defaultPollInterval    = time.Second
+	defaultPollMaxInterval = 30 * time.Second
+	defaultPollTimeout     = 20 * time.Minute
+)
+
+// pollUntilReady calls poll, waiting between each call, until it reports the resource is ready or returns an error
+// diagnostic. The wait starts at the instruction's interval and doubles after each call, up to its max interval.
+//
+// Polling gives up once the instruction's timeout has elapsed, or sooner when ctx is done, eg: when the operation
+// times out. Warnings returned while the resource is not ready are logged rather than shown, as there may be many.
+func pollUntilReady(ctx context.Context, instruction *deno.PollInstruction, poll func(context.Context) (*deno.PollResponse, error)) (*deno.PollResponse, error) {
+	interval := durationOrDefault(instruction.IntervalMs, defaultPollInterval)
+	maxInterval := max(durationOrDefault(instruction.MaxIntervalMs, defaultPollMaxInterval), interval)
+	timeout := durationOrDefault(instruction.TimeoutMs, defaultPollTimeout)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, fmt.Errorf("the resource was still not ready after polling %d times", attempt-1)
+			}
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		response, err := poll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if response == nil {
+			return nil, fmt.Errorf("the script's poll method returned no result")
+		}
+		if response.Ready || hasErrorDiagnostic(response) {
+			return response, nil
+		}
+		if response.Diagnostics != nil {
+			for _, d := range *response.Diagnostics {
+				tflog.Warn(ctx, fmt.Sprintf("Polling the resource: %s: %s", d.Summary, d.Detail))
+			}
+		}
+
+		interval = min(interval*2, maxInterval)
+		tflog.Info(ctx, fmt.Sprintf("The resource is not ready yet after %d polls, polling again in %s", attempt, interval))
+	}
+}
+
+// waitUntilReady polls the Deno script until the resource it just created or updated is ready, see pollUntilReady.
+// It returns the final poll response, or nil after adding an error to diags when the resource did not become ready.
+func waitUntilReady(ctx context.Context, c *deno.DenoClientResource, instruction *deno.PollInstruction, request *deno.PollRequest, diags *diag.Diagnostics) *deno.PollResponse {
+	request.DryRun = true
+	response, err := pollUntilReady(ctx, instruction, func(ctx context.Context) (*deno.PollResponse, error) {
+		return c.Poll(ctx, request)
+	})
+	if err != nil {
+		diags.AddError(
+			"Resource did not become ready",
+			fmt.Sprintf("Could not wait for the resource to become ready via Deno script: %s", err.Error()),
+		)
+		addDenoErrorDiagnostics(diags, c.Client, err)
+		return nil
+	}
+
+	// Handle diagnostics - allows the script to explain why the resource will never become ready
+	if response.Diagnostics != nil {
+		for _, d := range *response.Diagnostics {
+			switch d.Severity {
+			case "error":
+				if d.PropPath != nil {
+					diags.AddAttributeError(dynamic.PropPathToPath(d.PropPath), d.Summary, d.Detail)
+				} else {
+					diags.AddError(d.Summary, d.Detail)
+				}
+			case "warning":
+				if d.PropPath != nil {
+					diags.AddAttributeWarning(dynamic.PropPathToPath(d.PropPath), d.Summary, d.Detail)
+				} else {
+					diags.AddWarning(d.Summary, d.Detail)
+				}
+			}
+		}
+	}
+	if !response.Ready {
+		return nil
+	}
+	return response
+}
+
+// polledState returns the state to save for a resource that became ready, replacing what the create or update
+// returned with what the final poll returned, if anything. The sensitive state is kept unless the poll replaced it.
+func polledState(polled *deno.PollResponse, state, sensitiveState any, sensitivePaths, typedPaths [][]string) (any, any, [][]string, [][]string) {
+	if polled == nil || polled.State == nil {
+		return state, sensitiveState, sensitivePaths, typedPaths
+	}
+	if polled.SensitiveState != nil {
+		sensitiveState = *polled.SensitiveState
+	}
+	return *polled.State, sensitiveState, polled.SensitivePaths, polled.TypedPaths
+}
+
+// durationOrDefault converts ms to a duration, or returns fallback when ms is not positive.
+func durationOrDefault(ms int64, fallback time.Duration) time.Duration {
+	if ms <= 0 {
+		return fallback
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// hasErrorDiagnostic reports whether the poll response contains an error diagnostic.
+func hasErrorDiagnostic(response *deno.PollResponse) bool {
+	if response.Diagnostics == nil {
+		return false
+	}
+	for _, d := range *response.Diagnostics {
+		if d.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
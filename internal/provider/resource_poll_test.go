@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+)
+
+func TestPollUntilReady(t *testing.T) {
+	var waits []time.Duration
+	last := time.Now()
+	polls := 0
+	response, err := pollUntilReady(t.Context(), &deno.PollInstruction{IntervalMs: 10, MaxIntervalMs: 25}, func(context.Context) (*deno.PollResponse, error) {
+		waits = append(waits, time.Since(last))
+		last = time.Now()
+		polls++
+		return &deno.PollResponse{Ready: polls == 4}, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the resource to become ready, got %v", err)
+	}
+	if !response.Ready || polls != 4 {
+		t.Fatalf("Expected polling to stop once ready, polled %d times", polls)
+	}
+
+	// The wait doubles after each poll, up to the max interval
+	for i, minimum := range []time.Duration{10, 20, 25, 25} {
+		if waits[i] < minimum*time.Millisecond {
+			t.Errorf("Expected poll %d to wait at least %dms, waited %s", i+1, minimum, waits[i])
+		}
+	}
+}
+
+func TestPollUntilReady_ErrorDiagnostic(t *testing.T) {
+	polls := 0
+	response, err := pollUntilReady(t.Context(), &deno.PollInstruction{IntervalMs: 1}, func(context.Context) (*deno.PollResponse, error) {
+		polls++
+		diagnostics := []struct {
+			Severity string    `json:"severity"`
+			Summary  string    `json:"summary"`
+			Detail   string    `json:"detail"`
+			PropPath *[]string `json:"propPath,omitempty"`
+		}{{Severity: "error", Summary: "Provisioning failed", Detail: "quota exceeded"}}
+		return &deno.PollResponse{Diagnostics: &diagnostics}, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the diagnostics to be returned, got %v", err)
+	}
+	if polls != 1 || response.Ready || !hasErrorDiagnostic(response) {
+		t.Errorf("Expected polling to stop at the first error diagnostic, polled %d times", polls)
+	}
+}
+
+func TestPollUntilReady_Timeout(t *testing.T) {
+	_, err := pollUntilReady(t.Context(), &deno.PollInstruction{IntervalMs: 5, TimeoutMs: 50}, func(context.Context) (*deno.PollResponse, error) {
+		return &deno.PollResponse{Ready: false}, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "still not ready") {
+		t.Errorf("Expected polling to time out, got %v", err)
+	}
+
+	// The operation's own deadline also bounds polling
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	_, err = pollUntilReady(ctx, &deno.PollInstruction{IntervalMs: 5}, func(context.Context) (*deno.PollResponse, error) {
+		return &deno.PollResponse{Ready: false}, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "still not ready") {
+		t.Errorf("Expected polling to stop at the operation's deadline, got %v", err)
+	}
+}
+
+// TestPolledState_UpdateKeepsSensitiveState tests that a poll which omits the sensitive state, after an update
+// that returned one, keeps the sensitive state the update returned.
+func TestPolledState_UpdateKeepsSensitiveState(t *testing.T) {
+	var updated, secret, ready any = map[string]any{"status": "updating"}, map[string]any{"token": "s3cret"}, map[string]any{"status": "ready"}
+	update := &deno.UpdateResponse{State: &updated, SensitiveState: &secret}
+
+	state, sensitiveState, _, _ := polledState(&deno.PollResponse{Ready: true, State: &ready}, update.State, update.SensitiveState, nil, nil)
+	if got := dynamicString(t, state); got != `{"status":"ready"}` {
+		t.Errorf("Expected the state the poll returned, got %s", got)
+	}
+	if got := dynamicString(t, sensitiveState); got != `{"token":"s3cret"}` {
+		t.Errorf("Expected the sensitive state the update returned, got %s", got)
+	}
+
+	var rotated any = map[string]any{"token": "rotated"}
+	_, sensitiveState, _, _ = polledState(&deno.PollResponse{Ready: true, State: &ready, SensitiveState: &rotated}, update.State, update.SensitiveState, nil, nil)
+	if got := dynamicString(t, sensitiveState); got != `{"token":"rotated"}` {
+		t.Errorf("Expected the sensitive state the poll returned, got %s", got)
+	}
+}
+
+// dynamicString marshals v, following a pointer to it, to JSON.
+func dynamicString(t *testing.T, v any) string {
+	t.Helper()
+	if p, ok := v.(*any); ok {
+		v = *p
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
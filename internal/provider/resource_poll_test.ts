@@ -0,0 +1,44 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { pollUntilReady, ResourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  path: string;
+}
+
+interface State {
+  status: string;
+  readyAt?: number;
+}
+
+// Simulates a backend that accepts a create immediately but takes a little while to finish provisioning it.
+new ResourceProvider<Props, State>({
+  async create({ path }) {
+    await Deno.writeTextFile(path, "provisioning");
+    pollUntilReady({ intervalMs: 100 });
+    return { id: path, state: { status: "provisioning", readyAt: Date.now() + 300 } };
+  },
+  async poll(id, props, state) {
+    if (Date.now() < state.readyAt!) {
+      return { ready: false };
+    }
+    return { ready: true, state: { status: "ready" } };
+  },
+  async read(id, props) {
+    try {
+      await Deno.stat(id);
+      return { props: { path: id }, state: { status: "ready" } };
+    } catch (e) {
+      if (e instanceof Deno.errors.NotFound) {
+        return { exists: false };
+      }
+      throw e;
+    }
+  },
+  async update(id, nextProps, currentProps, currentState) {
+    return currentState;
+  },
+  async delete(id, props) {
+    await Deno.remove(id);
+  },
+});
@@ -0,0 +1,30 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { ResourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  count: number;
+}
+
+new ResourceProvider<Props>({
+  async create({ count }) {
+    return { id: `instances-${count}` };
+  },
+  async read(id, props) {
+    return { props };
+  },
+  async update(id, nextProps, currentProps) {},
+  async delete(id, props) {},
+  async precondition(id, planType, props) {
+    if (props.count > 2) {
+      return {
+        diagnostics: [{
+          severity: "error",
+          summary: "Not enough quota",
+          detail: `${props.count} instances were requested but only 2 are left in the quota`,
+          propPath: ["props", "count"],
+        }],
+      };
+    }
+  },
+});
@@ -0,0 +1,37 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { privateState, ResourceProvider, setPrivateState } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  name: string;
+}
+
+interface State {
+  token: string;
+}
+
+interface Private {
+  token: string;
+}
+
+// The token is only ever handed over through private state, the visible state echoes it so that it can be checked.
+new ResourceProvider<Props, State>({
+  async create({ name }) {
+    setPrivateState({ token: `${name}-1` });
+    return { id: name, state: { token: `${name}-1` } };
+  },
+  async read(id, props) {
+    const token = privateState<Private>()?.token;
+    if (!token) throw new Error("read was not given the private state");
+    return { props: props ?? { name: id }, state: { token } };
+  },
+  async update(id, nextProps, currentProps, currentState) {
+    const token = privateState<Private>()?.token;
+    if (!token) throw new Error("update was not given the private state");
+    setPrivateState({ token: `${token}-updated` });
+    return { token: `${token}-updated` };
+  },
+  async delete(id) {
+    if (!privateState<Private>()?.token) throw new Error("delete was not given the private state");
+  },
+});
@@ -0,0 +1,33 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { ResourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  name: string;
+  lockDir: string;
+}
+
+interface State {
+  status: string;
+}
+
+// Fails when another create sharing the lock dir is running at the same time.
+new ResourceProvider<Props, State>({
+  async create({ name, lockDir }) {
+    try {
+      await Deno.mkdir(lockDir);
+    } catch {
+      throw new Error(`${name} ran at the same time as another create`);
+    }
+    await new Promise((resolve) => setTimeout(resolve, 500));
+    await Deno.remove(lockDir);
+    return { id: name, state: { status: "created" } };
+  },
+  async read(id, props) {
+    return { props, state: { status: "created" } };
+  },
+  async update(id, nextProps, currentProps, currentState) {
+    return currentState;
+  },
+  async delete(id, props) {},
+});
@@ -0,0 +1,47 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { ResourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  dir: string;
+  name: string;
+  content: string;
+}
+
+interface FileKey {
+  dir: string;
+  name: string;
+}
+
+const pathOf = (id: FileKey) => {
+  if (typeof id !== "object") throw new Error(`Expected a structured id, got: ${JSON.stringify(id)}`);
+  return `${id.dir}/${id.name}`;
+};
+
+new ResourceProvider<Props, void, FileKey>({
+  async create({ dir, name, content }) {
+    await Deno.writeTextFile(`${dir}/${name}`, content);
+    return { id: { dir, name } };
+  },
+  async read(id, props) {
+    try {
+      const content = await Deno.readTextFile(pathOf(id));
+      return { props: { dir: id.dir, name: id.name, content } };
+    } catch (e) {
+      if (e instanceof Deno.errors.NotFound) {
+        return { exists: false };
+      }
+      throw e;
+    }
+  },
+  async update(id, nextProps, currentProps) {
+    await Deno.writeTextFile(pathOf(id), nextProps.content);
+  },
+  async delete(id, props) {
+    await Deno.remove(pathOf(id));
+  },
+  async modifyPlan(_id, planType, nextProps, currentProps) {
+    if (planType !== "update") return;
+    return { requiresReplacement: currentProps?.dir !== nextProps?.dir || currentProps?.name !== nextProps?.name };
+  },
+});
@@ -1,9 +1,18 @@
 package provider
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"testing"
 
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/brad-jones/terraform-provider-denobridge/internal/dynamic"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
@@ -611,3 +620,782 @@ func TestStatelessResourceWithZod(t *testing.T) {
 		},
 	})
 }
+
+// fakePrivateState is an in-memory stand in for a resource's private state.
+type fakePrivateState map[string][]byte
+
+func (p fakePrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func TestResourceCanImport(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "denobridge_resource" "test_import" {
+						path  = "./resource_import_test.ts"
+						props = {
+							name = "ready"
+						}
+					}
+				`,
+			},
+			// Import of a resource the script says is not ready yet
+			{
+				ResourceName: "denobridge_resource.test_import",
+				ImportState:  true,
+				ImportStateId: `{
+					"id": "provisioning",
+					"path": "./resource_import_test.ts"
+				}`,
+				ExpectError: regexp.MustCompile("still provisioning"),
+			},
+		},
+	})
+}
+
+func TestResourcePrecondition(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	config := func(count int) string {
+		return fmt.Sprintf(`
+			resource "denobridge_resource" "test_precondition" {
+				path  = "./resource_precondition_test.ts"
+				props = {
+					count = %d
+				}
+			}
+		`, count)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// A create the script says the environment can not support fails at plan time
+			{
+				Config:      config(3),
+				ExpectError: regexp.MustCompile("Precondition failed: Not enough quota"),
+			},
+			{
+				Config: config(2),
+			},
+			// As does an update
+			{
+				Config:      config(5),
+				ExpectError: regexp.MustCompile("only 2 are left in the quota"),
+			},
+		},
+	})
+}
+
+func TestResourcePlanTemplates(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The script fails the plan if it is given a template that has not been evaluated
+			{
+				Config: `
+					provider "denobridge" {
+						props_templates = true
+					}
+
+					resource "denobridge_resource" "test_plan_templates" {
+						path  = "./resource_plan_templates_test.ts"
+						props = {
+							requestId = "$${uuid()}"
+						}
+					}
+				`,
+			},
+		},
+	})
+}
+
+func TestResourceOperationConfigFiles(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	config := func(version int) string {
+		return fmt.Sprintf(`
+			resource "denobridge_resource" "test_config" {
+				path        = "./resource_config_test.ts"
+				config_file = "./resource_config_test.default.json"
+				operation_config_files = {
+					create = "./resource_config_test.create.json"
+				}
+				props = {
+					path    = "./test_config.txt"
+					version = %d
+				}
+				permissions = {
+					all = true
+				}
+			}
+		`, version)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create runs with its own config file
+			{
+				Config: config(1),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"denobridge_resource.test_config",
+						tfjsonpath.New("state").AtMapKey("phase"),
+						knownvalue.StringExact("create"),
+					),
+				},
+			},
+			// Update, and the reads around it, fall back to config_file
+			{
+				Config: config(2),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"denobridge_resource.test_config",
+						tfjsonpath.New("state").AtMapKey("phase"),
+						knownvalue.StringExact("default"),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestResourcePollUntilReady(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "denobridge_resource" "test_poll" {
+						path  = "./resource_poll_test.ts"
+						props = {
+							path = "./test_poll.txt"
+						}
+						permissions = {
+							all = true
+						}
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"denobridge_resource.test_poll",
+						tfjsonpath.New("state").AtMapKey("status"),
+						knownvalue.StringExact("ready"),
+					),
+				},
+			},
+		},
+	})
+}
+
+// TestCreateIdempotencyKey tests that a retried create of the same planned resource is given the same key.
+func TestCreateIdempotencyKey(t *testing.T) {
+	planFor := func(props, writeOnlyProps map[string]any) *denoBridgeResourceModel {
+		return &denoBridgeResourceModel{
+			Path:           types.StringValue("./bucket.ts"),
+			Props:          dynamic.ToDynamic(props),
+			WriteOnlyProps: dynamic.ToDynamic(writeOnlyProps),
+		}
+	}
+
+	// Each attempt decodes its own plan, templates are evaluated after the key is derived
+	first := createIdempotencyKey(planFor(map[string]any{"name": "logs", "tag": "${uuid()}"}, map[string]any{"token": "a"}))
+	retried := createIdempotencyKey(planFor(map[string]any{"name": "logs", "tag": "${uuid()}"}, map[string]any{"token": "b"}))
+	if first == "" || first != retried {
+		t.Errorf("Expected a retried create to be given the same key, got %q and %q", first, retried)
+	}
+
+	if other := createIdempotencyKey(planFor(map[string]any{"name": "metrics", "tag": "${uuid()}"}, nil)); other == first {
+		t.Errorf("Expected creates with different props to be given different keys, both got %q", first)
+	}
+}
+
+func TestReplaceTriggersChanged(t *testing.T) {
+	recorded := fakePrivateState{
+		"replace_triggers_hash": fmt.Appendf(nil, `{"hash":"%s"}`, hashJSON(map[string]any{"ami": "ami-1"})),
+	}
+	state := &denoBridgeResourceModel{ReplaceTriggers: dynamic.ToDynamic(map[string]any{"ami": "ami-0"})}
+
+	tests := []struct {
+		name     string
+		private  fakePrivateState
+		triggers types.Dynamic
+		expected bool
+	}{
+		{"unchanged", recorded, dynamic.ToDynamic(map[string]any{"ami": "ami-1"}), false},
+		{"changed", recorded, dynamic.ToDynamic(map[string]any{"ami": "ami-2"}), true},
+		{"unknown", recorded, types.DynamicUnknown(), true},
+		{"unrecorded falls back to state", fakePrivateState{}, dynamic.ToDynamic(map[string]any{"ami": "ami-0"}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &denoBridgeResourceModel{ReplaceTriggers: tt.triggers}
+			got, diags := replaceTriggersChanged(t.Context(), tt.private, plan, state)
+			if diags.HasError() {
+				t.Fatalf("Unexpected diagnostics: %v", diags)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPermissionsFor(t *testing.T) {
+	read := &deno.PermissionsTF{
+		All:   types.BoolValue(false),
+		Allow: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("read")}),
+		Deny:  types.ListNull(types.StringType),
+	}
+	write := &deno.PermissionsTF{
+		All:   types.BoolValue(false),
+		Allow: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("write=/opt/app")}),
+		Deny:  types.ListNull(types.StringType),
+	}
+	model := &denoBridgeResourceModel{
+		Permissions:          read,
+		OperationPermissions: &operationPermissionsTF{Create: write},
+	}
+
+	if got := model.permissionsFor(context.Background(), "create"); got != write {
+		t.Error("Expected create to use its own permissions")
+	}
+	for _, operation := range []string{"read", "update", "delete"} {
+		if got := model.permissionsFor(context.Background(), operation); got != read {
+			t.Errorf("Expected %s to fall back to the resource's permissions", operation)
+		}
+	}
+
+	model.OperationPermissions = nil
+	if got := model.permissionsFor(context.Background(), "create"); got != read {
+		t.Error("Expected create to use the resource's permissions without operation_permissions")
+	}
+}
+
+func TestConfigFileFor(t *testing.T) {
+	model := &denoBridgeResourceModel{
+		ConfigFile:           types.StringValue("./deno.json"),
+		OperationConfigFiles: &operationConfigFilesTF{Create: types.StringValue("./deno.create.json")},
+	}
+
+	if got := model.configFileFor(context.Background(), "create"); got != "./deno.create.json" {
+		t.Errorf("Expected create to use its own config file, got %q", got)
+	}
+	for _, operation := range []string{"read", "update", "delete"} {
+		if got := model.configFileFor(context.Background(), operation); got != "./deno.json" {
+			t.Errorf("Expected %s to fall back to the resource's config file, got %q", operation, got)
+		}
+	}
+
+	model.OperationConfigFiles = nil
+	if got := model.configFileFor(context.Background(), "create"); got != "./deno.json" {
+		t.Errorf("Expected create to use the resource's config file without operation_config_files, got %q", got)
+	}
+
+	model.ConfigFile = types.StringNull()
+	if got := model.configFileFor(context.Background(), "read"); got != "" {
+		t.Errorf("Expected no config file when none is set, got %q", got)
+	}
+}
+
+func TestResolveStatePaths(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %s", err)
+	}
+	model := &denoBridgeResourceModel{
+		ResolvePaths: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("file")}),
+	}
+
+	state, sensitiveState, diags := resolveStatePaths(context.Background(), model,
+		map[string]any{"file": "out.txt", "other": "in.txt"},
+		map[string]any{"file": "key.pem"},
+	)
+	if diags.HasError() {
+		t.Fatalf("Unexpected diagnostics: %v", diags)
+	}
+	if got := state.(map[string]any)["file"]; got != filepath.Join(wd, "out.txt") {
+		t.Errorf("Expected the state path to be made absolute, got '%v'", got)
+	}
+	if got := state.(map[string]any)["other"]; got != "in.txt" {
+		t.Errorf("Expected keys not listed in resolve_paths to be left alone, got '%v'", got)
+	}
+	if got := sensitiveState.(map[string]any)["file"]; got != filepath.Join(wd, "key.pem") {
+		t.Errorf("Expected the sensitive state path to be made absolute, got '%v'", got)
+	}
+
+	model.ResolvePaths = types.ListNull(types.StringType)
+	state, _, _ = resolveStatePaths(context.Background(), model, map[string]any{"file": "out.txt"}, nil)
+	if got := state.(map[string]any)["file"]; got != "out.txt" {
+		t.Errorf("Expected paths to be left alone without resolve_paths, got '%v'", got)
+	}
+}
+
+func TestPropReplacementsChanged(t *testing.T) {
+	state := &denoBridgeResourceModel{Props: dynamic.ToDynamic(map[string]any{
+		"region":  "us-east-1",
+		"network": map[string]any{"cidr": "10.0.0.0/16"},
+		"tags":    map[string]any{"team": "a"},
+	})}
+	plan := &denoBridgeResourceModel{Props: dynamic.ToDynamic(map[string]any{
+		"region":  "us-west-2",
+		"network": map[string]any{"cidr": "10.0.0.0/16"},
+		"tags":    map[string]any{"team": "a", "env": "prod"},
+	})}
+	replacements := &[]deno.PropReplacement{
+		{Path: []string{"region"}, Reason: "buckets can not move region"},
+		{Path: []string{"network", "cidr"}, Reason: "the cidr is immutable"},
+		{Path: []string{"tags", "env"}, Reason: "env tags are baked into the name"},
+	}
+
+	changed := propReplacementsChanged(replacements, plan, state)
+	if len(changed) != 2 {
+		t.Fatalf("Expected 2 changed replacements, got %v", changed)
+	}
+	if got := dynamic.PropPathToPath(&changed[0].Path).String(); got != `props["region"]` || changed[0].Reason != "buckets can not move region" {
+		t.Errorf("Expected the region replacement, got %s: %s", got, changed[0].Reason)
+	}
+	if got := dynamic.PropPathToPath(&changed[1].Path).String(); got != `props["tags"]["env"]` {
+		t.Errorf("Expected the added env tag to be a replacement, got %s", got)
+	}
+
+	if changed := propReplacementsChanged(replacements, plan, nil); changed != nil {
+		t.Errorf("Expected no replacements on create, got %v", changed)
+	}
+}
+
+func TestResourceMaxIdleProcesses(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "denobridge" {
+						max_idle_processes = 2
+					}
+
+					resource "denobridge_resource" "test_pool" {
+						count = 3
+						path  = "./resource_test.ts"
+						props = {
+							path    = "./test_pool_${count.index}.txt"
+							content = "Hello World ${count.index}"
+						}
+						permissions = {
+							all = true
+						}
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"denobridge_resource.test_pool[0]",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact("./test_pool_0.txt"),
+					),
+					statecheck.ExpectKnownValue(
+						"denobridge_resource.test_pool[2]",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact("./test_pool_2.txt"),
+					),
+				},
+			},
+			// Updates reuse the processes kept idle by the refresh
+			{
+				Config: `
+					provider "denobridge" {
+						max_idle_processes = 2
+					}
+
+					resource "denobridge_resource" "test_pool" {
+						count = 3
+						path  = "./resource_test.ts"
+						props = {
+							path    = "./test_pool_${count.index}.txt"
+							content = "Hello Again ${count.index}"
+						}
+						permissions = {
+							all = true
+						}
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"denobridge_resource.test_pool[1]",
+						tfjsonpath.New("props").AtMapKey("content"),
+						knownvalue.StringExact("Hello Again 1"),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestResourceTimeouts(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "denobridge_resource" "test_timeout" {
+						path  = "./resource_timeout_test.ts"
+						props = {
+							name = "hangs"
+						}
+						timeouts = {
+							create = "2s"
+						}
+					}
+				`,
+				ExpectError: regexp.MustCompile("create timed out after 2s"),
+			},
+			{
+				Config: `
+					resource "denobridge_resource" "test_timeout" {
+						path  = "./resource_timeout_test.ts"
+						props = {
+							name = "hangs"
+						}
+						timeouts = {
+							create = "soon"
+						}
+					}
+				`,
+				ExpectError: regexp.MustCompile("Invalid timeout"),
+			},
+		},
+	})
+}
+
+func TestResourcePropsSchema(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "denobridge_resource" "test_props_schema" {
+						path  = "./resource_test.ts"
+						props = {
+							path    = "./test_props_schema.txt"
+							content = 42
+						}
+						props_schema = {
+							path    = "string"
+							content = "string"
+						}
+						permissions = {
+							all = true
+						}
+					}
+				`,
+				ExpectError: regexp.MustCompile("Expected a string, got a number"),
+			},
+			{
+				Config: `
+					resource "denobridge_resource" "test_props_schema" {
+						path  = "./resource_test.ts"
+						props = {
+							path    = "./test_props_schema.txt"
+							content = "Hello World"
+						}
+						props_schema = {
+							path    = "string"
+							content = "string"
+						}
+						permissions = {
+							all = true
+						}
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"denobridge_resource.test_props_schema",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact("./test_props_schema.txt"),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestResourceStructuredID(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "denobridge_resource" "test_structured_id" {
+						path  = "./resource_structured_id_test.ts"
+						props = {
+							dir     = "."
+							name    = "test_structured_id.txt"
+							content = "Hello World"
+						}
+						permissions = {
+							all = true
+						}
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"denobridge_resource.test_structured_id",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(`{"dir":".","name":"test_structured_id.txt"}`),
+					),
+				},
+			},
+			{
+				Config: `
+					resource "denobridge_resource" "test_structured_id" {
+						path  = "./resource_structured_id_test.ts"
+						props = {
+							dir     = "."
+							name    = "test_structured_id.txt"
+							content = "Hello Again"
+						}
+						permissions = {
+							all = true
+						}
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"denobridge_resource.test_structured_id",
+						tfjsonpath.New("props").AtMapKey("content"),
+						knownvalue.StringExact("Hello Again"),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestResourceTypedState(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	config := func(names string) string {
+		return fmt.Sprintf(`
+			resource "denobridge_resource" "test_typed_state" {
+				path  = "./resource_typed_state_test.ts"
+				props = {
+					names = %s
+				}
+			}
+
+			output "instances" {
+				value = { for i in denobridge_resource.test_typed_state.state.instances : i.name => i.index }
+			}
+		`, names)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(`["web", "worker"]`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"denobridge_resource.test_typed_state",
+						tfjsonpath.New("state").AtMapKey("instances"),
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"name":  knownvalue.StringExact("web"),
+								"index": knownvalue.Int64Exact(0),
+								"zone":  knownvalue.StringExact("a"),
+							}),
+							knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"name":  knownvalue.StringExact("worker"),
+								"index": knownvalue.Int64Exact(1),
+								"zone":  knownvalue.Null(),
+							}),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue("instances", knownvalue.MapExact(map[string]knownvalue.Check{
+						"web":    knownvalue.Int64Exact(0),
+						"worker": knownvalue.Int64Exact(1),
+					})),
+				},
+			},
+			// The types are kept when the state is updated
+			{
+				Config: config(`["web", "worker", "cron"]`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("instances", knownvalue.MapExact(map[string]knownvalue.Check{
+						"web":    knownvalue.Int64Exact(0),
+						"worker": knownvalue.Int64Exact(1),
+						"cron":   knownvalue.Int64Exact(2),
+					})),
+				},
+			},
+		},
+	})
+}
+
+func TestResourceSerializeKey(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	// Both scripts fail if they find the other's lock dir, which is only there while a create is running
+	lockDir := filepath.Join(t.TempDir(), "lock")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "denobridge_resource" "first" {
+						path          = "./resource_serialize_test.ts"
+						serialize_key = "backend"
+						props = {
+							name    = "first"
+							lockDir = %[1]q
+						}
+						permissions = {
+							allow = ["read", "write"]
+						}
+					}
+					resource "denobridge_resource" "second" {
+						path          = "./resource_serialize_test.ts"
+						serialize_key = "backend"
+						props = {
+							name    = "second"
+							lockDir = %[1]q
+						}
+						permissions = {
+							allow = ["read", "write"]
+						}
+					}
+				`, lockDir),
+			},
+		},
+	})
+}
+
+// TestResourceUpdateKeepState tests that an update calling keepState leaves the prior state unchanged.
+func TestResourceUpdateKeepState(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	filePath := filepath.Join(t.TempDir(), "keep-state.txt")
+	config := func(content string) string {
+		return fmt.Sprintf(`
+			resource "denobridge_resource" "test" {
+				path = "./resource_keep_state_test.ts"
+				props = {
+					path    = %q
+					content = %q
+				}
+				permissions = {
+					allow = ["read", "write"]
+				}
+			}
+		`, filePath, content)
+	}
+	revision := statecheck.ExpectKnownValue(
+		"denobridge_resource.test",
+		tfjsonpath.New("state").AtMapKey("revision"),
+		knownvalue.Int64Exact(1),
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:            config("first"),
+				ConfigStateChecks: []statecheck.StateCheck{revision},
+			},
+			{
+				// The update still runs, but the revision it returns is ignored
+				Config: config("second"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					revision,
+					statecheck.ExpectKnownValue(
+						"denobridge_resource.test",
+						tfjsonpath.New("props").AtMapKey("content"),
+						knownvalue.StringExact("second"),
+					),
+				},
+			},
+		},
+	})
+}
+
+// TestResourcePrivateState tests that the private state returned by create and update is given back to later operations.
+func TestResourcePrivateState(t *testing.T) {
+	t.Setenv("TF_ACC", "1")
+	t.Setenv("TF_LOG", "DEBUG")
+
+	config := func(name string) string {
+		return fmt.Sprintf(`
+			resource "denobridge_resource" "test" {
+				path = "./resource_private_state_test.ts"
+				props = {
+					name = %q
+				}
+			}
+		`, name)
+	}
+	token := func(expected string) statecheck.StateCheck {
+		return statecheck.ExpectKnownValue(
+			"denobridge_resource.test",
+			tfjsonpath.New("state").AtMapKey("token"),
+			knownvalue.StringExact(expected),
+		)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:            config("feed"),
+				ConfigStateChecks: []statecheck.StateCheck{token("feed-1")},
+			},
+			{
+				// The id is kept from create, so the update is given the token create kept
+				Config:            config("renamed"),
+				ConfigStateChecks: []statecheck.StateCheck{token("feed-1-updated")},
+			},
+		},
+	})
+}
@@ -0,0 +1,26 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { ResourceProvider } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  name: string;
+}
+
+interface State {
+  status: string;
+}
+
+// Simulates a script whose create hangs, never returning a result.
+new ResourceProvider<Props, State>({
+  async create({ name }) {
+    await new Promise(() => {});
+    return { id: name, state: { status: "created" } };
+  },
+  async read(id, props) {
+    return { props, state: { status: "created" } };
+  },
+  async update(id, nextProps, currentProps, currentState) {
+    return currentState;
+  },
+  async delete(id, props) {},
+});
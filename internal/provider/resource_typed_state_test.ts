@@ -0,0 +1,31 @@
+// deno-lint-ignore-file require-await no-unused-vars
+
+import { ResourceProvider, withTypedPaths } from "@brad-jones/terraform-provider-denobridge";
+
+interface Props {
+  names: string[];
+}
+
+interface State {
+  instances: { name: string; index: number; zone?: string }[];
+}
+
+function instancesState({ names }: Props): State {
+  return withTypedPaths(
+    { instances: names.map((name, index) => index === 0 ? { name, index, zone: "a" } : { name, index }) },
+    ["instances"],
+  );
+}
+
+new ResourceProvider<Props, State>({
+  async create(props) {
+    return { id: "cluster", state: instancesState(props) };
+  },
+  async read(id, props) {
+    return { props, state: instancesState(props) };
+  },
+  async update(id, nextProps, currentProps, currentState) {
+    return instancesState(nextProps);
+  },
+  async delete(id, props, state) {},
+});
@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// scriptPrivateKey is the key of the private state that a script keeps for its resource, eg: an API cursor or an
+// opaque token that should not appear in the visible state.
+const scriptPrivateKey = "script_private"
+
+// scriptPrivate returns the private state the script last returned for its resource, nil when it never returned any.
+func scriptPrivate(ctx context.Context, private privateStateGetter, diags *diag.Diagnostics) json.RawMessage {
+	value, d := private.GetKey(ctx, scriptPrivateKey)
+	diags.Append(d...)
+	return value
+}
+
+// setScriptPrivate records the private state returned by a script. A script that returns none keeps what it had,
+// while one that returns null clears it.
+func setScriptPrivate(ctx context.Context, private privateState, value json.RawMessage, diags *diag.Diagnostics) {
+	if value == nil {
+		return
+	}
+	if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+		value = nil
+	}
+	diags.Append(private.SetKey(ctx, scriptPrivateKey, value)...)
+}
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestScriptPrivate(t *testing.T) {
+	private := fakePrivateState{}
+
+	// Nothing until the script returns some
+	var diags diag.Diagnostics
+	if value := scriptPrivate(t.Context(), private, &diags); value != nil {
+		t.Errorf("Expected no private state, got %s", value)
+	}
+
+	setScriptPrivate(t.Context(), private, json.RawMessage(`{"cursor":"abc"}`), &diags)
+	if value := scriptPrivate(t.Context(), private, &diags); string(value) != `{"cursor":"abc"}` {
+		t.Errorf("Expected the private state returned by the script, got %s", value)
+	}
+
+	// Kept when the script returns none
+	setScriptPrivate(t.Context(), private, nil, &diags)
+	if value := scriptPrivate(t.Context(), private, &diags); string(value) != `{"cursor":"abc"}` {
+		t.Errorf("Expected the private state to be kept, got %s", value)
+	}
+
+	// Cleared when the script returns null
+	setScriptPrivate(t.Context(), private, json.RawMessage(`null`), &diags)
+	if value := scriptPrivate(t.Context(), private, &diags); len(value) != 0 {
+		t.Errorf("Expected the private state to be cleared, got %s", value)
+	}
+	if diags.HasError() {
+		t.Errorf("Unexpected diagnostics: %v", diags)
+	}
+}
@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// serialize waits until no other operation holds the lock for the given serialize_key, then takes it. The returned
+// func releases the lock, it is nil when the lock could not be taken, in which case the reason has been added to diags.
+//
+// Resources without a serialize_key are never held up, a no-op func is returned for them.
+func (c *ProviderConfig) serialize(ctx context.Context, key types.String, diags *diag.Diagnostics) func() {
+	if key.ValueString() == "" {
+		return func() {}
+	}
+	unlock, err := c.serializeLocks.lock(ctx, key.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("serialize_key"),
+			"Failed to serialize operation",
+			fmt.Sprintf("Gave up waiting for the other operations sharing the serialize_key %q to finish: %s", key.ValueString(), err),
+		)
+		return nil
+	}
+	return unlock
+}
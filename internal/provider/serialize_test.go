@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// TestSerialize_SameKey tests that operations sharing a serialize_key never run at the same time.
+func TestSerialize_SameKey(t *testing.T) {
+	config := &ProviderConfig{}
+	var running, overlapped atomic.Int64
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var diags diag.Diagnostics
+			unlock := config.serialize(t.Context(), types.StringValue("backend"), &diags)
+			if unlock == nil {
+				t.Errorf("Unexpected diagnostics: %v", diags)
+				return
+			}
+			defer unlock()
+			if running.Add(1) > 1 {
+				overlapped.Add(1)
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	if overlapped.Load() > 0 {
+		t.Errorf("Expected operations sharing a key to run one at a time, %d overlapped", overlapped.Load())
+	}
+}
+
+// TestSerialize_OtherKeys tests that operations without a serialize_key, or with another one, are not held up.
+func TestSerialize_OtherKeys(t *testing.T) {
+	config := &ProviderConfig{}
+	var diags diag.Diagnostics
+	unlock := config.serialize(t.Context(), types.StringValue("backend"), &diags)
+	defer unlock()
+
+	for _, key := range []types.String{types.StringNull(), types.StringValue(""), types.StringValue("other")} {
+		other := config.serialize(t.Context(), key, &diags)
+		if other == nil {
+			t.Fatalf("Expected %s not to wait, got %v", key, diags)
+		}
+		other()
+	}
+
+	// The same key waits, until the operation gives up
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	if config.serialize(ctx, types.StringValue("backend"), &diags) != nil {
+		t.Fatal("Expected the same key to wait for the lock")
+	}
+	if !diags.HasError() || diags[0].Summary() != "Failed to serialize operation" {
+		t.Errorf("Expected an error explaining the operation gave up, got %v", diags)
+	}
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+)
+
+// propsTemplatePattern matches a call to a props template function, eg: ${uuid()}.
+var propsTemplatePattern = regexp.MustCompile(`\$\{\s*([A-Za-z_]+)\(\)\s*\}`)
+
+// propsTemplateFuncs are the only functions that may be called from a props template.
+//
+// The set is deliberately small, props templates are not meant to become a templating engine.
+var propsTemplateFuncs = map[string]func(now time.Time) (string, error){
+	"uuid": func(time.Time) (string, error) {
+		return uuid.GenerateUUID()
+	},
+	"timestamp": func(now time.Time) (string, error) {
+		return now.UTC().Format(time.RFC3339), nil
+	},
+}
+
+// evaluatePropsTemplates replaces every props template function call found in the string values of props with its result.
+//
+//	props = {
+//	  request_id = "$${uuid()}"
+//	  started_at = "$${timestamp()}"
+//	}
+//
+// The $$ escape stops Terraform from interpolating the call itself. Every call to timestamp within
+// the same props returns the same time, while every call to uuid returns a new UUID.
+func evaluatePropsTemplates(props any) (any, error) {
+	return evaluatePropsTemplatesAt(props, time.Now())
+}
+
+// evaluatePropsTemplatesAt walks value, evaluating any props template function calls as of now.
+func evaluatePropsTemplatesAt(value any, now time.Time) (any, error) {
+	switch v := value.(type) {
+	case string:
+		var evalErr error
+		result := propsTemplatePattern.ReplaceAllStringFunc(v, func(match string) string {
+			name := propsTemplatePattern.FindStringSubmatch(match)[1]
+			fn, ok := propsTemplateFuncs[name]
+			if !ok {
+				if evalErr == nil {
+					evalErr = fmt.Errorf("unsupported props template function %s(), expected one of uuid() or timestamp()", name)
+				}
+				return match
+			}
+			out, err := fn(now)
+			if err != nil && evalErr == nil {
+				evalErr = fmt.Errorf("failed to evaluate props template function %s(): %w", name, err)
+			}
+			return out
+		})
+		if evalErr != nil {
+			return nil, evalErr
+		}
+		return result, nil
+	case map[string]any:
+		for key, item := range v {
+			evaluated, err := evaluatePropsTemplatesAt(item, now)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = evaluated
+		}
+		return v, nil
+	case []any:
+		for i, item := range v {
+			evaluated, err := evaluatePropsTemplatesAt(item, now)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = evaluated
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluatePropsTemplates(t *testing.T) {
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("AEST", 10*60*60))
+	props := map[string]any{
+		"started_at": "${timestamp()}",
+		"nested": map[string]any{
+			"label": "run at ${ timestamp() }",
+			"ids":   []any{"${uuid()}", "${uuid()}"},
+		},
+		"plain": "${var.not_a_call}",
+		"count": 3.0,
+	}
+
+	result, err := evaluatePropsTemplatesAt(props, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	evaluated := result.(map[string]any)
+
+	if evaluated["started_at"] != "2024-05-05T21:08:09Z" {
+		t.Errorf("Expected the timestamp in UTC, got '%v'", evaluated["started_at"])
+	}
+	nested := evaluated["nested"].(map[string]any)
+	if nested["label"] != "run at 2024-05-05T21:08:09Z" {
+		t.Errorf("Expected the call to be replaced within the string, got '%v'", nested["label"])
+	}
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	ids := nested["ids"].([]any)
+	for _, id := range ids {
+		if !uuidPattern.MatchString(id.(string)) {
+			t.Errorf("Expected a UUID, got '%v'", id)
+		}
+	}
+	if ids[0] == ids[1] {
+		t.Error("Expected every uuid() call to return a new UUID")
+	}
+	if evaluated["plain"] != "${var.not_a_call}" {
+		t.Errorf("Expected text that is not a function call to be left alone, got '%v'", evaluated["plain"])
+	}
+	if evaluated["count"] != 3.0 {
+		t.Errorf("Expected non string values to be left alone, got '%v'", evaluated["count"])
+	}
+}
+
+func TestEvaluatePropsTemplates_UnsupportedFunction(t *testing.T) {
+	_, err := evaluatePropsTemplatesAt(map[string]any{"x": "${env()}"}, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "env()") {
+		t.Errorf("Expected an error naming the unsupported function, got %v", err)
+	}
+}
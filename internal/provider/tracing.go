@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceOperation starts a span around an operation, it is intended to be used at the start of the operation:
+//
+//	ctx, endSpan := traceOperation(ctx, "denobridge_resource", "create", path)
+//	defer endSpan(&resp.Diagnostics)
+//
+// The spans of starting, calling and stopping the Deno script are children of it. The span is marked as failed
+// with the summary of the first error when diags holds an error once it ends. When no OTLP endpoint is configured
+// the span is recorded by nothing, see deno.StartTracing.
+func traceOperation(ctx context.Context, resource, operation, script string) (context.Context, func(diags *diag.Diagnostics)) {
+	ctx, span := deno.Tracer().Start(ctx, resource+"."+operation, trace.WithAttributes(
+		deno.AttrOperation.String(operation),
+		deno.AttrScriptPath.String(script),
+	))
+	return ctx, func(diags *diag.Diagnostics) {
+		var err error
+		if errs := diags.Errors(); len(errs) > 0 {
+			err = errors.New(errs[0].Summary())
+		}
+		deno.EndSpan(span, err)
+	}
+}
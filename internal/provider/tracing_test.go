@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestTraceOperation tests that an operation span is marked as failed by the errors of the operation.
+func TestTraceOperation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	_, endSpan := traceOperation(t.Context(), "denobridge_resource", "create", "script.ts")
+	endSpan(&diag.Diagnostics{})
+
+	var diags diag.Diagnostics
+	diags.AddError("Failed to delete resource", "boom")
+	_, endSpan = traceOperation(t.Context(), "denobridge_resource", "delete", "script.ts")
+	endSpan(&diags)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "denobridge_resource.create" || spans[0].Status().Code != codes.Unset {
+		t.Errorf("Expected a successful denobridge_resource.create span, got %s with status %s", spans[0].Name(), spans[0].Status().Code)
+	}
+	if spans[1].Status().Code != codes.Error || spans[1].Status().Description != "Failed to delete resource" {
+		t.Errorf("Expected the delete span to fail with the first error, got %s: %s", spans[1].Status().Code, spans[1].Status().Description)
+	}
+}
@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// resolveVaultRefs replaces every vault_ref found in the given write-only props with the secret it refers to.
+//
+// A vault_ref is an object with a single vault_ref key, holding the path of a secret and the field to read from it:
+//
+//	write_only_props = {
+//	  password = { vault_ref = { path = "secret/data/db", field = "password" } }
+//	}
+//
+// Secrets are read from the Vault server at VAULT_ADDR using the token in VAULT_TOKEN (and VAULT_NAMESPACE when set).
+// Both KV version 1 and 2 secrets engines are supported. Each path is only read once per call.
+func resolveVaultRefs(ctx context.Context, props any) (any, error) {
+	r := &vaultResolver{secrets: map[string]map[string]any{}}
+	return r.resolve(ctx, props)
+}
+
+// vaultResolver resolves vault_refs, caching the secrets it has read.
+type vaultResolver struct {
+	secrets map[string]map[string]any
+}
+
+// resolve walks value, replacing any vault_refs it finds.
+func (r *vaultResolver) resolve(ctx context.Context, value any) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		if ref, ok := parseVaultRef(v); ok {
+			return r.read(ctx, ref.path, ref.field)
+		}
+		for key, item := range v {
+			resolved, err := r.resolve(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+		return v, nil
+	case []any:
+		for i, item := range v {
+			resolved, err := r.resolve(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// vaultRef identifies a single field of a secret stored in Vault.
+type vaultRef struct {
+	path  string
+	field string
+}
+
+// parseVaultRef reports whether v is a vault_ref and if so returns what it refers to.
+func parseVaultRef(v map[string]any) (vaultRef, bool) {
+	if len(v) != 1 {
+		return vaultRef{}, false
+	}
+	ref, ok := v["vault_ref"].(map[string]any)
+	if !ok {
+		return vaultRef{}, false
+	}
+	path, _ := ref["path"].(string)
+	field, _ := ref["field"].(string)
+	if path == "" || field == "" {
+		return vaultRef{}, false
+	}
+	return vaultRef{path: strings.Trim(path, "/"), field: field}, true
+}
+
+// read returns a single field of the secret at path.
+func (r *vaultResolver) read(ctx context.Context, path, field string) (any, error) {
+	secret, ok := r.secrets[path]
+	if !ok {
+		var err error
+		secret, err = readVaultSecret(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		r.secrets[path] = secret
+	}
+	value, ok := secret[field]
+	if !ok {
+		return nil, fmt.Errorf("field %q not found in vault secret %s", field, path)
+	}
+	return value, nil
+}
+
+// readVaultSecret reads the secret at path from Vault.
+func readVaultSecret(ctx context.Context, path string) (map[string]any, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set to read vault secret %s", path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/%s", strings.TrimRight(addr, "/"), path), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for vault secret %s: %w", path, err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read vault secret %s: %s", path, resp.Status)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode vault secret %s: %w", path, err)
+	}
+
+	// KV version 2 nests the secret alongside its metadata
+	if data, ok := body.Data["data"].(map[string]any); ok {
+		if _, ok := body.Data["metadata"]; ok {
+			return data, nil
+		}
+	}
+	return body.Data, nil
+}
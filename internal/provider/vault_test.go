@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestVault starts a fake Vault server holding a KV version 2 secret at secret/data/db.
+func newTestVault(t *testing.T) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/db" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2"},"metadata":{"version":1}}}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+}
+
+func TestResolveVaultRefs(t *testing.T) {
+	newTestVault(t)
+
+	props := map[string]any{
+		"username": "admin",
+		"password": map[string]any{
+			"vault_ref": map[string]any{"path": "secret/data/db", "field": "password"},
+		},
+	}
+	resolved, err := resolveVaultRefs(t.Context(), props)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	m := resolved.(map[string]any)
+	if m["password"] != "hunter2" {
+		t.Errorf("Expected password to be resolved from vault, got %v", m["password"])
+	}
+	if m["username"] != "admin" {
+		t.Errorf("Expected username to be unchanged, got %v", m["username"])
+	}
+}
+
+func TestResolveVaultRefs_MissingField(t *testing.T) {
+	newTestVault(t)
+
+	props := map[string]any{
+		"password": map[string]any{
+			"vault_ref": map[string]any{"path": "secret/data/db", "field": "token"},
+		},
+	}
+	_, err := resolveVaultRefs(t.Context(), props)
+	if err == nil || !strings.Contains(err.Error(), `field "token" not found`) {
+		t.Errorf("Expected a missing field error, got %v", err)
+	}
+}
+
+func TestResolveVaultRefs_NoVault(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+
+	props := map[string]any{"password": "plain"}
+	resolved, err := resolveVaultRefs(t.Context(), props)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if resolved.(map[string]any)["password"] != "plain" {
+		t.Errorf("Expected props without vault_refs to be unchanged, got %v", resolved)
+	}
+}
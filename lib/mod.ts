@@ -1,4 +1,12 @@
 export * from "./providers/action.ts";
+export {
+  cancellationSignal,
+  declarePermissions,
+  isDryRun,
+  type Meta,
+  meta,
+  PROTOCOL_VERSION,
+} from "./providers/base.ts";
 export * from "./providers/datasource.ts";
 export * from "./providers/ephemeral_resource.ts";
 export * from "./providers/resource.ts";
@@ -1,3 +1,4 @@
+import { JSONRPCMethodNotFoundError } from "@yieldray/json-rpc-ts";
 import type { z } from "@zod/zod";
 import { BaseJsonRpcProvider } from "./base.ts";
 import { type Diagnostics, isDiagnostics } from "./diagnostics.ts";
@@ -16,6 +17,15 @@ export type ActionProviderMethods<TProps> = {
    * @returns A promise that resolves when the action completes.
    */
   invoke(props: TProps, progressCallback: (message: string) => Promise<void>): Promise<Diagnostics | void>;
+
+  /**
+   * Validates the given properties without performing any side effects. This method is optional
+   * and backs the `provider::denobridge::validate_props` Terraform function.
+   *
+   * @param props - The properties/configuration to validate.
+   * @returns A promise that resolves to diagnostics describing any validation problems.
+   */
+  validate?(props: TProps): Promise<Diagnostics | void>;
 };
 
 /**
@@ -51,6 +61,12 @@ export class ActionProvider<TProps> extends BaseJsonRpcProvider<RemoteMethods> {
         if (isDiagnostics(result)) return result;
         return { done: true };
       },
+      async validate(params: { props: Record<string, unknown> }) {
+        if (!providerMethods.validate) throw new JSONRPCMethodNotFoundError();
+        const result = await providerMethods.validate(params.props as TProps);
+        if (isDiagnostics(result)) return result;
+        return { diagnostics: [] };
+      },
     }));
   }
 }
@@ -95,6 +111,23 @@ export class ZodActionProvider<
         // Catch any diagnostics and return them early
         if (isDiagnostics(result)) return result;
       },
+      async validate(props) {
+        // Validate props
+        const propsParsed = propsSchema.safeParse(props);
+        if (!propsParsed.success) {
+          return {
+            diagnostics: propsParsed.error.issues.map((i) => ({
+              severity: "error",
+              summary: "Zod Validation Issue",
+              detail: i.message,
+              propPath: i.path.length > 0 ? ["props", ...i.path.map((_) => String(_))] : undefined,
+            })),
+          };
+        }
+
+        // Call the method with validated props
+        return await providerMethods.validate?.(propsParsed.data);
+      },
     });
   }
 }
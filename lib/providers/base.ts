@@ -1,6 +1,92 @@
 import { type JSONRPCClient, JSONRPCError, type JSONRPCMethod, type JSONRPCMethods } from "@yieldray/json-rpc-ts";
+import { AsyncLocalStorage } from "node:async_hooks";
 import { createJSocket } from "../jsocket.ts";
 
+/**
+ * The version of the JSON-RPC contract between the provider and this library, it must match the provider's.
+ * It is bumped whenever either side changes in a way the other can not handle.
+ */
+export const PROTOCOL_VERSION = 1;
+
+/** The JSON-RPC error code health responds with when the provider speaks a different protocol version. */
+const PROTOCOL_VERSION_MISMATCH = -32001;
+
+/** Describes the environment the provider is running in. */
+export interface Meta {
+  /** The operating system the provider is running on, as reported by Go's runtime.GOOS, eg: "linux". */
+  os?: string;
+  /** The architecture the provider is running on, as reported by Go's runtime.GOARCH, eg: "amd64". */
+  arch?: string;
+  /** The platform artifacts should be generated for, from the providers target_platform, eg: "linux/arm64". */
+  targetPlatform?: string;
+}
+
+/**
+ * Details about the environment the provider is running in, populated before any other method is called.
+ *
+ * Scripts that generate platform specific artifacts can use this to pick the right binary or config,
+ * preferring `targetPlatform` when it is set. Note that `os` and `arch` use Go's naming, eg: "amd64"
+ * rather than Deno's "x86_64".
+ */
+export const meta: Meta = {};
+
+let declaredPermissions: string[] | undefined;
+
+/**
+ * Declares the Deno permissions the script needs, using the same format as the resource's permissions allow list,
+ * eg: `declarePermissions("env", "net=api.example.com")`. Call it with no arguments to declare that no permissions
+ * are needed.
+ *
+ * The declaration is reported to the provider, which warns when the configured permissions are insufficient or broader
+ * than declared. Call it at the top level of the script, before the provider is constructed.
+ */
+export function declarePermissions(...permissions: string[]): void {
+  declaredPermissions = permissions;
+}
+
+interface RequestContext {
+  method: string;
+  dryRun: boolean;
+  cancellation: AbortController;
+}
+
+const requestContext = new AsyncLocalStorage<RequestContext>();
+
+/** The requests currently being handled, so that cancel can abort the one Terraform gave up on. */
+const inFlight = new Set<RequestContext>();
+
+/** Handed out outside of a request, nothing ever aborts it. */
+const notCancelled = new AbortController().signal;
+
+/**
+ * Returns a signal that is aborted when Terraform cancels the request the script is currently handling,
+ * for example when the user presses Ctrl+C during an apply.
+ *
+ * Long running handlers can pass this to fetch or check it between steps to stop early
+ * and roll back any partial work. The process is killed if it has not exited within the
+ * providers stop grace period. Each request gets its own signal, so cancelling one does
+ * not affect any other request handled by the same process.
+ */
+export function cancellationSignal(): AbortSignal {
+  return requestContext.getStore()?.cancellation.signal ?? notCancelled;
+}
+
+/**
+ * Returns true while the script is handling a call that must not make any changes,
+ * such as modifyPlan or a read made while refreshing state during plan.
+ *
+ * Helpers shared between plan time and apply time methods can check this to preview
+ * their changes instead of performing real mutations.
+ */
+export function isDryRun(): boolean {
+  return requestContext.getStore()?.dryRun ?? false;
+}
+
+/** Returns the name of the JSON-RPC method the script is currently handling, if any. */
+export function currentMethod(): string | undefined {
+  return requestContext.getStore()?.method;
+}
+
 /**
  * Base class for all JSON-RPC provider implementations in the denobridge Terraform provider.
  * Handles the JSON-RPC communication layer over stdin/stdout and provides common functionality
@@ -32,8 +118,29 @@ export class BaseJsonRpcProvider<RemoteMethods extends JSONRPCMethods = JSONRPCM
       (client) =>
         wrapMethods({
           ...providerMethods(client),
-          health() {
-            return { ok: true };
+          health(params?: { meta?: Meta; protocolVersion?: number }) {
+            // Providers that predate protocol versioning do not send one
+            if (params?.protocolVersion !== undefined && params.protocolVersion !== PROTOCOL_VERSION) {
+              throw new JSONRPCError(
+                `the provider speaks protocol version ${params.protocolVersion} but the denobridge lib speaks version ${PROTOCOL_VERSION}, upgrade whichever is older so that they match`,
+                PROTOCOL_VERSION_MISMATCH,
+              );
+            }
+            Object.assign(meta, params?.meta);
+            return {
+              ok: true,
+              denoVersion: Deno.version.deno,
+              protocolVersion: PROTOCOL_VERSION,
+              permissions: declaredPermissions,
+            };
+          },
+          cancel(params?: { method?: string }) {
+            console.error(`Cancelling ${params?.method ?? "operation"}...`);
+            for (const request of inFlight) {
+              if (params?.method === undefined ? request.method !== "cancel" : request.method === params.method) {
+                request.cancellation.abort(new DOMException("The operation was cancelled by Terraform", "AbortError"));
+              }
+            }
           },
           shutdown() {
             console.error("Shutting down gracefully...");
@@ -44,15 +151,20 @@ export class BaseJsonRpcProvider<RemoteMethods extends JSONRPCMethods = JSONRPCM
   }
 }
 
-function wrapMethod<T, U>(fn: JSONRPCMethod<T, U>): JSONRPCMethod<T, U> {
+function wrapMethod<T, U>(method: string, fn: JSONRPCMethod<T, U>): JSONRPCMethod<T, U> {
   return async (arg) => {
+    const dryRun = (arg as { dryRun?: unknown } | undefined)?.dryRun === true;
+    const request = { method, dryRun, cancellation: new AbortController() };
+    inFlight.add(request);
     try {
-      return await fn(arg);
+      return await requestContext.run(request, () => fn(arg));
     } catch (e) {
       if (!(e instanceof JSONRPCError)) {
         console.error("uncaught error", e);
       }
       throw e;
+    } finally {
+      inFlight.delete(request);
     }
   };
 }
@@ -61,7 +173,7 @@ function wrapMethods(methods: JSONRPCMethods): JSONRPCMethods {
   return Object.fromEntries(
     Object.entries(methods).map(([name, fn]) => [
       name,
-      typeof fn === "function" ? wrapMethod(fn.bind(methods)) : fn,
+      typeof fn === "function" ? wrapMethod(name, fn.bind(methods)) : fn,
     ]),
   );
 }
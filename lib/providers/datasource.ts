@@ -1,7 +1,14 @@
+import { JSONRPCMethodNotFoundError } from "@yieldray/json-rpc-ts";
 import type { z } from "@zod/zod";
 import { BaseJsonRpcProvider } from "./base.ts";
 import { type Diagnostics, isDiagnostics } from "./diagnostics.ts";
 
+/**
+ * Return this from a datasource read to indicate that nothing has changed since the previous read.
+ * The provider will then reuse the result it cached from that read instead of a new result.
+ */
+export const SKIP: unique symbol = Symbol("denobridge.skip");
+
 /**
  * Defines the methods that must be implemented by a datasource provider.
  * Datasources are read-only resources that fetch data from external sources.
@@ -14,9 +21,19 @@ export interface DatasourceProviderMethods<TProps, TResult> {
    * Reads data from the datasource based on the provided properties.
    *
    * @param props - The properties/configuration for the datasource read operation.
-   * @returns A promise that resolves to the data fetched from the datasource.
+   * @returns A promise that resolves to the data fetched from the datasource,
+   *          or {@link SKIP} to reuse the result of the previous read.
+   */
+  read(props: TProps): Promise<Diagnostics | TResult | typeof SKIP>;
+
+  /**
+   * Validates the given properties without performing any side effects. This method is optional
+   * and backs the `provider::denobridge::validate_props` Terraform function.
+   *
+   * @param props - The properties/configuration to validate.
+   * @returns A promise that resolves to diagnostics describing any validation problems.
    */
-  read(props: TProps): Promise<Diagnostics | TResult>;
+  validate?(props: TProps): Promise<Diagnostics | void>;
 }
 
 /**
@@ -35,6 +52,7 @@ export class DatasourceProvider<TProps, TResult> extends BaseJsonRpcProvider {
     super(() => ({
       async read(params: { props: unknown }) {
         const result = await providerMethods.read(params.props as TProps);
+        if (result === SKIP) return { skip: true };
         if (isDiagnostics(result)) return result;
 
         // deno-lint-ignore no-explicit-any
@@ -48,6 +66,12 @@ export class DatasourceProvider<TProps, TResult> extends BaseJsonRpcProvider {
 
         return { result: resultData, sensitiveResult };
       },
+      async validate(params: { props: unknown }) {
+        if (!providerMethods.validate) throw new JSONRPCMethodNotFoundError();
+        const result = await providerMethods.validate(params.props as TProps);
+        if (isDiagnostics(result)) return result;
+        return { diagnostics: [] };
+      },
     }));
   }
 }
@@ -91,8 +115,8 @@ export class ZodDatasourceProvider<TProps extends z.ZodType, TResult extends z.Z
         // Call the method with validated props
         const result = await providerMethods.read(propsParsed.data);
 
-        // Catch any diagnostics and return them early
-        if (isDiagnostics(result)) return result;
+        // Catch any diagnostics or skips and return them early
+        if (result === SKIP || isDiagnostics(result)) return result;
 
         // Validate the results
         const resultParsed = resultSchema.safeParse(result);
@@ -109,6 +133,23 @@ export class ZodDatasourceProvider<TProps extends z.ZodType, TResult extends z.Z
 
         return resultParsed.data;
       },
+      async validate(props) {
+        // Validate props
+        const propsParsed = propsSchema.safeParse(props);
+        if (!propsParsed.success) {
+          return {
+            diagnostics: propsParsed.error.issues.map((i) => ({
+              severity: "error",
+              summary: "Zod Validation Issue",
+              detail: i.message,
+              propPath: i.path.length > 0 ? ["props", ...i.path.map((_) => String(_))] : undefined,
+            })),
+          };
+        }
+
+        // Call the method with validated props
+        return await providerMethods.validate?.(propsParsed.data);
+      },
     });
   }
 }
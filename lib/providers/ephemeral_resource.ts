@@ -55,6 +55,15 @@ export type EphemeralResourceProviderMethods<TProps, TResult, TPrivateData = nev
    * @returns A promise that resolves when the resource is closed.
    */
   close?(privateData: TPrivateData): Promise<Diagnostics | void>;
+
+  /**
+   * Validates the given properties without performing any side effects. This method is optional
+   * and backs the `provider::denobridge::validate_props` Terraform function.
+   *
+   * @param props - The properties/configuration to validate.
+   * @returns A promise that resolves to diagnostics describing any validation problems.
+   */
+  validate?(props: TProps): Promise<Diagnostics | void>;
 };
 
 /**
@@ -97,6 +106,12 @@ export class EphemeralResourceProvider<TProps, TResult, TPrivateData = never> ex
         const result = await providerMethods.close(params.privateData);
         if (isDiagnostics(result)) return result;
       },
+      async validate(params: { props: Record<string, unknown> }) {
+        if (!providerMethods.validate) throw new JSONRPCMethodNotFoundError();
+        const result = await providerMethods.validate(params.props as TProps);
+        if (isDiagnostics(result)) return result;
+        return { diagnostics: [] };
+      },
     }));
   }
 }
@@ -181,6 +196,23 @@ export class ZodEphemeralResourceProvider<
             ...(privateDataParsed ? { privateData: privateDataParsed.data } : {}),
           };
         },
+        async validate(props) {
+          // Validate props
+          const propsParsed = propsSchema.safeParse(props);
+          if (!propsParsed.success) {
+            return {
+              diagnostics: propsParsed.error.issues.map((i) => ({
+                severity: "error",
+                summary: "Zod Validation Issue",
+                detail: i.message,
+                propPath: i.path.length > 0 ? ["props", ...i.path.map((_) => String(_))] : undefined,
+              })),
+            };
+          }
+
+          // Call the method with validated props
+          return await providerMethods.validate?.(propsParsed.data);
+        },
       };
 
     if (providerMethods.renew) {
@@ -2,18 +2,115 @@
 
 import { JSONRPCMethodNotFoundError } from "@yieldray/json-rpc-ts";
 import type { z } from "@zod/zod";
-import { BaseJsonRpcProvider } from "./base.ts";
+import { AsyncLocalStorage } from "node:async_hooks";
+import { BaseJsonRpcProvider, currentMethod } from "./base.ts";
 import { type Diagnostics, isDiagnostics } from "./diagnostics.ts";
 
+/**
+ * Symbol under which a resource state carries the paths of its values that should be treated as sensitive.
+ * Use {@link withSensitivePaths} to set it.
+ */
+export const SENSITIVE_PATHS: unique symbol = Symbol("denobridge.sensitivePaths");
+
+/**
+ * Marks individual values within a resource state as sensitive. The provider moves each value into the
+ * `sensitive_state` attribute at the same path, so only those values are hidden from plan output and logs.
+ * In later calls the moved values are found under the `sensitive` key of the state.
+ *
+ * @param state - The state returned from create, read or update.
+ * @param paths - The paths of the values to treat as sensitive, eg: `["connection", "password"]`.
+ * @returns The same state, marked with the sensitive paths.
+ */
+export function withSensitivePaths<TState>(state: TState, ...paths: string[][]): TState {
+  return Object.assign(state as object, { [SENSITIVE_PATHS]: paths }) as TState;
+}
+
+/**
+ * Symbol under which a resource state carries the paths of its lists and objects that should be stored with a
+ * single element type. Use {@link withTypedPaths} to set it.
+ */
+export const TYPED_PATHS: unique symbol = Symbol("denobridge.typedPaths");
+
+/**
+ * Marks lists and objects within a resource state as typed collections. The provider stores each list as a Terraform
+ * list and each object as a Terraform map, with a single element type, rather than as values whose elements may each
+ * have a different type. This lets HCL iterate over them, eg: with `for_each`, knowing the type of every element.
+ * Every element of a typed collection must have the same type, objects with different keys are given the keys of all
+ * of them.
+ *
+ * @param state - The state returned from create, read or update.
+ * @param paths - The paths of the lists and objects to type, eg: `["instances"]`.
+ * @returns The same state, marked with the typed paths.
+ */
+export function withTypedPaths<TState>(state: TState, ...paths: string[][]): TState {
+  return Object.assign(state as object, { [TYPED_PATHS]: paths }) as TState;
+}
+
+/** Returns the sensitive paths a state was marked with, if any. */
+function sensitivePathsOf(state: unknown): string[][] | undefined {
+  return state && typeof state === "object" ? (state as any)[SENSITIVE_PATHS] : undefined;
+}
+
+/** Returns the typed paths a state was marked with, if any. */
+function typedPathsOf(state: unknown): string[][] | undefined {
+  return state && typeof state === "object" ? (state as any)[TYPED_PATHS] : undefined;
+}
+
+/** Copies the sensitive and typed paths from one state to another, eg: after the state has been parsed by Zod. */
+function keepStatePaths<TState>(from: unknown, to: TState): TState {
+  if (!to || typeof to !== "object") return to;
+  const paths = sensitivePathsOf(from);
+  if (paths) withSensitivePaths(to, ...paths);
+  const typedPaths = typedPathsOf(from);
+  if (typedPaths) withTypedPaths(to, ...typedPaths);
+  return to;
+}
+
+/** Additional details passed to a resource's create method. */
+export interface CreateOptions {
+  /**
+   * Identical for every attempt at creating the same resource, it is derived from the script path and planned props.
+   * If a create succeeded remotely but its response was lost, Terraform retries it with the same key.
+   * Pass it to backends that support idempotent requests, or look up an existing remote object by it,
+   * to avoid creating a duplicate. Resources with identical props share a key.
+   */
+  idempotencyKey: string;
+}
+
 /** The return type for the modifyPlan method. */
 type ModifyPlanReturn<TProps> = Promise<
   | {
     /** Modified properties to use instead of the originally planned properties. */
     modifiedProps?: TProps;
+    /** An optional human readable description of what the plan will do, shown to the user. */
+    summary?: string;
   }
   | {
     /** Whether the resource must be replaced (destroyed and recreated) instead of updated. */
     requiresReplacement: boolean;
+    /** An optional explanation, shown to the user, of why the resource must be replaced. */
+    replacementReason?: string;
+    /**
+     * When true the replacement fails the plan until the user sets `confirm_replace` on the resource,
+     * protecting critical resources from being destroyed unexpectedly.
+     */
+    requireConfirmation?: boolean;
+    /** An optional human readable description of what the plan will do, shown to the user. */
+    summary?: string;
+  }
+  | {
+    /**
+     * Props whose changes require the resource to be replaced, each with a reason shown to the user on that prop.
+     * Paths are relative to the props, eg: `["network", "cidr"]`. Props that are not changing are ignored.
+     */
+    replacements: { path: string[]; reason: string }[];
+    /**
+     * When true the replacement fails the plan until the user sets `confirm_replace` on the resource,
+     * protecting critical resources from being destroyed unexpectedly.
+     */
+    requireConfirmation?: boolean;
+    /** An optional human readable description of what the plan will do, shown to the user. */
+    summary?: string;
   }
   | Diagnostics
   | undefined
@@ -25,16 +122,21 @@ type ModifyPlanReturn<TProps> = Promise<
  *
  * @template TProps - The type of the properties/configuration for the resource.
  * @template TState - The type of the runtime state maintained by the resource.
- * @template TID - The type of the resource identifier (defaults to string).
+ * @template TID - The type of the resource identifier (defaults to string), an object for a composite key.
  */
 type StatefulResourceProviderMethods<TProps, TState, TID = string> = {
   /**
    * Creates a new resource with the provided properties.
    *
    * @param props - The properties/configuration for the new resource.
-   * @returns A promise that resolves to an object containing the resource ID and initial state.
+   * @param options - Additional details about the create, see {@link CreateOptions}.
+   * @returns A promise that resolves to an object containing the resource ID and initial state,
+   *          and optionally any additional identifiers of the resource such as an ARN.
    */
-  create(props: TProps): Promise<Diagnostics | { id: TID; state: TState }>;
+  create(
+    props: TProps,
+    options: CreateOptions,
+  ): Promise<Diagnostics | { id: TID; state: TState; identifiers?: Record<string, string> }>;
 
   /**
    * Reads an existing resource by its ID and validates it against the expected properties.
@@ -45,8 +147,12 @@ type StatefulResourceProviderMethods<TProps, TState, TID = string> = {
    *                they are given on a best effort basis.
    * @returns A promise that resolves to the current properties and state if the resource exists,
    *          or an object with exists: false if the resource no longer exists.
+   *          Returning identifiers replaces those previously returned by create.
    */
-  read(id: TID, props: TProps | null): Promise<Diagnostics | { props: TProps; state: TState } | { exists: false }>;
+  read(
+    id: TID,
+    props: TProps | null,
+  ): Promise<Diagnostics | { props: TProps; state: TState; identifiers?: Record<string, string> } | { exists: false }>;
 
   /**
    * Updates an existing resource with new properties.
@@ -70,9 +176,10 @@ type StatefulResourceProviderMethods<TProps, TState, TID = string> = {
    * @param id - The identifier of the resource to delete.
    * @param props - The current properties/configuration of the resource.
    * @param state - The current state of the resource.
-   * @returns A promise that resolves when the resource is deleted.
+   * @returns A promise that resolves when the resource is deleted. Resources that clean up several
+   *          things may resolve to the steps taken, the delete only succeeds if every step is done.
    */
-  delete(id: TID, props: TProps, state: TState): Promise<Diagnostics | void>;
+  delete(id: TID, props: TProps, state: TState): Promise<Diagnostics | void | DeleteSteps>;
 
   /**
    * Modifies a Terraform plan before execution. This method is optional and allows customizing
@@ -93,6 +200,64 @@ type StatefulResourceProviderMethods<TProps, TState, TID = string> = {
     currentProps: TProps | null,
     currentState: TState | null,
   ): ModifyPlanReturn<TProps>;
+
+  /**
+   * Makes read-only assertions about an existing resource, eg: that it does not violate a policy.
+   * This method is optional and is called after every successful read. Any returned diagnostics
+   * are shown to the user as warnings, they never block the read. It must not modify the resource.
+   *
+   * @param id - The identifier of the resource to check.
+   * @param props - The current properties/configuration of the resource.
+   * @param state - The current state of the resource.
+   * @returns A promise that resolves to diagnostics describing any failed assertions.
+   */
+  check?(id: TID, props: TProps, state: TState): Promise<Diagnostics | void>;
+
+  /**
+   * Asserts that the environment allows the resource to be created or updated, eg: that enough quota is available.
+   * This method is optional and is called while planning a create or update. Returning error diagnostics fails the
+   * plan with them, so that the apply does not fail part way through. It must not make any changes.
+   *
+   * @param id - The identifier of the resource being updated, or null when it is being created.
+   * @param planType - The operation being planned.
+   * @param props - The planned properties/configuration of the resource.
+   * @returns A promise that resolves to diagnostics describing any preconditions that are not met.
+   */
+  precondition?(id: TID | null, planType: "create" | "update", props: TProps): Promise<Diagnostics | void>;
+
+  /**
+   * Confirms that an existing resource can be imported, eg: that it exists and has finished provisioning.
+   * This method is optional and is called before a resource is imported. Returning error diagnostics
+   * fails the import with them, so that a half-provisioned resource is not imported. It must not modify
+   * the resource.
+   *
+   * @param id - The identifier of the resource being imported.
+   * @param props - The properties given with the import, if any.
+   * @returns A promise that resolves to diagnostics explaining why the resource can not be imported yet.
+   */
+  canImport?(id: TID, props: TProps | null): Promise<Diagnostics | void>;
+
+  /**
+   * Reports whether a resource whose create or update called {@link pollUntilReady} has become ready.
+   * This method is optional, but required by scripts that call pollUntilReady. It is called repeatedly,
+   * waiting longer between each call, until it reports the resource is ready. It must not modify the resource.
+   *
+   * @param id - The identifier of the resource being waited on.
+   * @param props - The properties/configuration the resource was created or updated with.
+   * @param state - The state returned by the create or update.
+   * @returns A promise that resolves to whether the resource is ready, once it is optionally with its final state.
+   *          Returning error diagnostics stops the waiting and fails the operation.
+   */
+  poll?(id: TID, props: TProps, state: TState): Promise<Diagnostics | { ready: boolean; state?: TState }>;
+
+  /**
+   * Validates the given properties without performing any side effects. This method is optional
+   * and backs the `provider::denobridge::validate_props` Terraform function.
+   *
+   * @param props - The properties/configuration to validate.
+   * @returns A promise that resolves to diagnostics describing any validation problems.
+   */
+  validate?(props: TProps): Promise<Diagnostics | void>;
 };
 
 /**
@@ -100,16 +265,21 @@ type StatefulResourceProviderMethods<TProps, TState, TID = string> = {
  * Resources that only need an ID and props, without additional computed state.
  *
  * @template TProps - The type of the properties/configuration for the resource.
- * @template TID - The type of the resource identifier (defaults to string).
+ * @template TID - The type of the resource identifier (defaults to string), an object for a composite key.
  */
 type StatelessResourceProviderMethods<TProps, TID = string> = {
   /**
    * Creates a new resource with the provided properties.
    *
    * @param props - The properties/configuration for the new resource.
-   * @returns A promise that resolves to an object containing the resource ID.
+   * @param options - Additional details about the create, see {@link CreateOptions}.
+   * @returns A promise that resolves to an object containing the resource ID,
+   *          and optionally any additional identifiers of the resource such as an ARN.
    */
-  create(props: TProps): Promise<Diagnostics | { id: TID }>;
+  create(
+    props: TProps,
+    options: CreateOptions,
+  ): Promise<Diagnostics | { id: TID; identifiers?: Record<string, string> }>;
 
   /**
    * Reads an existing resource by its ID and validates it against the expected properties.
@@ -120,8 +290,12 @@ type StatelessResourceProviderMethods<TProps, TID = string> = {
    *                they are given on a best effort basis.
    * @returns A promise that resolves to the current properties if the resource exists,
    *          or an object with exists: false if the resource no longer exists.
+   *          Returning identifiers replaces those previously returned by create.
    */
-  read(id: TID, props: TProps | null): Promise<Diagnostics | { props: TProps } | { exists: false }>;
+  read(
+    id: TID,
+    props: TProps | null,
+  ): Promise<Diagnostics | { props: TProps; identifiers?: Record<string, string> } | { exists: false }>;
 
   /**
    * Updates an existing resource with new properties.
@@ -142,9 +316,10 @@ type StatelessResourceProviderMethods<TProps, TID = string> = {
    *
    * @param id - The identifier of the resource to delete.
    * @param props - The current properties/configuration of the resource.
-   * @returns A promise that resolves when the resource is deleted.
+   * @returns A promise that resolves when the resource is deleted. Resources that clean up several
+   *          things may resolve to the steps taken, the delete only succeeds if every step is done.
    */
-  delete(id: TID, props: TProps): Promise<Diagnostics | void>;
+  delete(id: TID, props: TProps): Promise<Diagnostics | void | DeleteSteps>;
 
   /**
    * Modifies a Terraform plan before execution. This method is optional and allows customizing
@@ -163,6 +338,62 @@ type StatelessResourceProviderMethods<TProps, TID = string> = {
     nextProps: TProps | null,
     currentProps: TProps | null,
   ): ModifyPlanReturn<TProps>;
+
+  /**
+   * Makes read-only assertions about an existing resource, eg: that it does not violate a policy.
+   * This method is optional and is called after every successful read. Any returned diagnostics
+   * are shown to the user as warnings, they never block the read. It must not modify the resource.
+   *
+   * @param id - The identifier of the resource to check.
+   * @param props - The current properties/configuration of the resource.
+   * @returns A promise that resolves to diagnostics describing any failed assertions.
+   */
+  check?(id: TID, props: TProps): Promise<Diagnostics | void>;
+
+  /**
+   * Asserts that the environment allows the resource to be created or updated, eg: that enough quota is available.
+   * This method is optional and is called while planning a create or update. Returning error diagnostics fails the
+   * plan with them, so that the apply does not fail part way through. It must not make any changes.
+   *
+   * @param id - The identifier of the resource being updated, or null when it is being created.
+   * @param planType - The operation being planned.
+   * @param props - The planned properties/configuration of the resource.
+   * @returns A promise that resolves to diagnostics describing any preconditions that are not met.
+   */
+  precondition?(id: TID | null, planType: "create" | "update", props: TProps): Promise<Diagnostics | void>;
+
+  /**
+   * Confirms that an existing resource can be imported, eg: that it exists and has finished provisioning.
+   * This method is optional and is called before a resource is imported. Returning error diagnostics
+   * fails the import with them, so that a half-provisioned resource is not imported. It must not modify
+   * the resource.
+   *
+   * @param id - The identifier of the resource being imported.
+   * @param props - The properties given with the import, if any.
+   * @returns A promise that resolves to diagnostics explaining why the resource can not be imported yet.
+   */
+  canImport?(id: TID, props: TProps | null): Promise<Diagnostics | void>;
+
+  /**
+   * Reports whether a resource whose create or update called {@link pollUntilReady} has become ready.
+   * This method is optional, but required by scripts that call pollUntilReady. It is called repeatedly,
+   * waiting longer between each call, until it reports the resource is ready. It must not modify the resource.
+   *
+   * @param id - The identifier of the resource being waited on.
+   * @param props - The properties/configuration the resource was created or updated with.
+   * @returns A promise that resolves to whether the resource is ready.
+   *          Returning error diagnostics stops the waiting and fails the operation.
+   */
+  poll?(id: TID, props: TProps): Promise<Diagnostics | { ready: boolean }>;
+
+  /**
+   * Validates the given properties without performing any side effects. This method is optional
+   * and backs the `provider::denobridge::validate_props` Terraform function.
+   *
+   * @param props - The properties/configuration to validate.
+   * @returns A promise that resolves to diagnostics describing any validation problems.
+   */
+  validate?(props: TProps): Promise<Diagnostics | void>;
 };
 
 /**
@@ -175,12 +406,284 @@ type StatelessResourceProviderMethods<TProps, TID = string> = {
  *
  * @template TProps - The type of the properties/configuration for the resource.
  * @template TState - The type of the runtime state maintained by the resource (defaults to void for stateless resources).
- * @template TID - The type of the resource identifier (defaults to string).
+ * @template TID - The type of the resource identifier (defaults to string), an object for a composite key.
  */
 export type ResourceProviderMethods<TProps, TState = void, TID = string> = [TState] extends [void]
   ? StatelessResourceProviderMethods<TProps, TID>
   : StatefulResourceProviderMethods<TProps, TState, TID>;
 
+/**
+ * Internal type defining the remote methods available to the JSON-RPC client.
+ */
+type RemoteMethods = {
+  /**
+   * Notifies the remote client of progress while a resource is created.
+   *
+   * @param params - Object containing the progress message.
+   */
+  createProgress(params: { message: string }): void;
+
+  /**
+   * Notifies the remote client of progress while a resource is updated.
+   *
+   * @param params - Object containing the progress message.
+   */
+  updateProgress(params: { message: string }): void;
+
+  /**
+   * Notifies the remote client of progress while a resource is deleted.
+   *
+   * @param params - Object containing the progress message.
+   */
+  deleteProgress(params: { message: string }): void;
+};
+
+let notifyProgress: ((method: keyof RemoteMethods, message: string) => Promise<void>) | undefined;
+
+/**
+ * Reports the progress of a long running create, update or delete.
+ *
+ * Terraform has no way to show the progress of a resource in its UI, so the message is written
+ * to the Terraform logs at info level, visible with `TF_LOG=INFO`. Outside of create, update and
+ * delete the message is written to stderr instead.
+ *
+ * @param message - The progress message, eg: "Waiting for the cluster to become ready".
+ */
+export async function reportProgress(message: string): Promise<void> {
+  const method = currentMethod();
+  if (!notifyProgress || (method !== "create" && method !== "update" && method !== "delete")) {
+    console.error(message);
+    return;
+  }
+  await notifyProgress(`${method}Progress`, message);
+}
+
+/** One of several things cleaned up while deleting a resource. */
+export interface DeleteStep {
+  /** Name describes what the step cleans up, eg: "drain load balancer" */
+  name: string;
+
+  /** Done indicates whether the step completed */
+  done: boolean;
+
+  /** Detail optionally explains why the step did not complete */
+  detail?: string;
+}
+
+/** The steps taken to delete a resource that cleans up several things. */
+export interface DeleteSteps {
+  steps: DeleteStep[];
+}
+
+/** An advisory notice about a resource, see {@link advise}. */
+export interface Advisory {
+  /** Summary is a short description of the advisory */
+  summary: string;
+
+  /** Detail provides additional context about the advisory */
+  detail: string;
+}
+
+/**
+ * Returns a wrapper for the named methods that runs each with a new store in context, eg: the one {@link advise}
+ * adds to, and adds the value collected from the store to its result as field. Nothing is added when the value is
+ * undefined, nor to a result that is not an object or is diagnostics.
+ */
+function collectOptional<S>(
+  field: string,
+  names: string[],
+  context: AsyncLocalStorage<S>,
+  newStore: () => S,
+  collect: (store: S) => unknown,
+): <T extends Record<string, (params: any) => Promise<unknown>>>(methods: T) => T {
+  return (methods) => {
+    for (const name of names) {
+      const method = methods[name];
+      (methods as Record<string, unknown>)[name] = async (params: unknown) => {
+        const store = newStore();
+        const result = await context.run(store, () => method(params));
+        const value = collect(store);
+        if (value === undefined || !result || typeof result !== "object" || isDiagnostics(result)) return result;
+        return { ...result, [field]: value };
+      };
+    }
+    return methods;
+  };
+}
+
+const advisoryContext = new AsyncLocalStorage<Advisory[]>();
+
+/**
+ * Adds an advisory notice to the result of the current create, read or update,
+ * eg: "This API is deprecated, migrate by Q3".
+ *
+ * Advisories are shown as warnings after every create and update. While refreshing they are only shown
+ * when they differ from those last shown, so they stay visible without repeating on every plan.
+ * Outside of create, read and update the advisory is written to stderr instead.
+ *
+ * @param summary - A short description of the advisory.
+ * @param detail - Additional context, eg: how to migrate.
+ */
+export function advise(summary: string, detail = ""): void {
+  const advisories = advisoryContext.getStore();
+  if (!advisories) {
+    console.error(detail ? `${summary}: ${detail}` : summary);
+    return;
+  }
+  advisories.push({ summary, detail });
+}
+
+/** Wraps create, read and update so that any advisories given while they run are added to their results. */
+const collectAdvisories = collectOptional(
+  "advisories",
+  ["create", "read", "update"],
+  advisoryContext,
+  (): Advisory[] => [],
+  (advisories) => advisories.length > 0 ? advisories : undefined,
+);
+
+const nextStepsContext = new AsyncLocalStorage<string[]>();
+
+/**
+ * Adds a step the user must take by hand to finish the current create or update,
+ * eg: "Point your DNS at 203.0.113.10".
+ *
+ * Next steps are shown together as a numbered warning once the apply completes, so that
+ * required manual work is not missed. Outside of create and update the step is written to stderr instead.
+ *
+ * @param step - What the user must do.
+ */
+export function addNextStep(step: string): void {
+  const nextSteps = nextStepsContext.getStore();
+  if (!nextSteps) {
+    console.error(step);
+    return;
+  }
+  nextSteps.push(step);
+}
+
+/** Wraps create and update so that any next steps added while they run are added to their results. */
+const collectNextSteps = collectOptional(
+  "nextSteps",
+  ["create", "update"],
+  nextStepsContext,
+  (): string[] => [],
+  (nextSteps) => nextSteps.length > 0 ? nextSteps : undefined,
+);
+
+/** How the provider waits for a resource that is not ready yet, see {@link pollUntilReady}. */
+export interface PollOptions {
+  /** How long to wait before the first poll, the wait doubles after each poll. Defaults to 1000. */
+  intervalMs?: number;
+
+  /** The longest wait between polls. Defaults to 30000. */
+  maxIntervalMs?: number;
+
+  /** How long to keep polling before the operation fails. Defaults to 20 minutes, or until the operation times out. */
+  timeoutMs?: number;
+}
+
+const pollContext = new AsyncLocalStorage<{ options?: PollOptions }>();
+
+/**
+ * Asks the provider to wait for the resource being created or updated to become ready before the operation
+ * completes, eg: when a backend accepts a request immediately but provisions the resource later. Once the
+ * create or update returns, the provider calls the resource's `poll` method until it reports the resource is ready.
+ *
+ * A resource that never becomes ready is still saved to state, a create is tainted so that it is replaced.
+ *
+ * @param options - How often and for how long to poll.
+ */
+export function pollUntilReady(options: PollOptions = {}): void {
+  const store = pollContext.getStore();
+  if (!store) {
+    throw new Error("pollUntilReady can only be called during create or update");
+  }
+  store.options = options;
+}
+
+/** Wraps create and update so that a call to pollUntilReady while they run is added to their results. */
+const collectPollOptions = collectOptional(
+  "pollUntilReady",
+  ["create", "update"],
+  pollContext,
+  (): { options?: PollOptions } => ({}),
+  (store) => store.options,
+);
+
+const keepStateContext = new AsyncLocalStorage<{ keep: boolean }>();
+
+/**
+ * Tells the provider that the update being made changed nothing the state records, eg: only a description
+ * that is not read back changed. The state and sensitive state saved before the update are kept as they were,
+ * and whatever the update returns is ignored, so that the computed state does not churn between runs.
+ */
+export function keepState(): void {
+  const store = keepStateContext.getStore();
+  if (!store) {
+    throw new Error("keepState can only be called during update");
+  }
+  store.keep = true;
+}
+
+/** Wraps update so that a call to keepState while it runs is added to its result. */
+const collectKeepState = collectOptional(
+  "noChanges",
+  ["update"],
+  keepStateContext,
+  () => ({ keep: false }),
+  (store) => store.keep || undefined,
+);
+
+const privateStateContext = new AsyncLocalStorage<{ value: unknown; set: boolean }>();
+
+/**
+ * Returns the private state last given to {@link setPrivateState} for the resource being read, updated or deleted,
+ * or undefined when none was. Private state is kept by Terraform alongside the resource but never shown, eg: an API
+ * cursor or an opaque token.
+ */
+export function privateState<T = unknown>(): T | undefined {
+  const store = privateStateContext.getStore();
+  if (!store) {
+    throw new Error("privateState can only be called during create, read, update or delete");
+  }
+  return store.value as T | undefined;
+}
+
+/**
+ * Keeps a value in the private state of the resource being created or updated, it is given back by
+ * {@link privateState} in the resource's later reads, updates and deletes. The value must be JSON serializable,
+ * an update that does not call this keeps the private state as it was, while setting null clears it.
+ *
+ * @param value - The value to keep.
+ */
+export function setPrivateState(value: unknown): void {
+  const store = privateStateContext.getStore();
+  if (!store) {
+    throw new Error("setPrivateState can only be called during create or update");
+  }
+  store.value = value;
+  store.set = true;
+}
+
+/** Wraps create, read, update and delete so that they can read their private state, and create and update set it. */
+function collectPrivateState<T extends Record<string, (params: any) => Promise<unknown>>>(methods: T): T {
+  for (const name of ["create", "read", "update", "delete"]) {
+    const method = methods[name];
+    (methods as Record<string, unknown>)[name] = async (params: { private?: unknown }) => {
+      const store = { value: params?.private ?? undefined, set: false };
+      const result = await privateStateContext.run(store, () => method(params));
+      if (name === "read" || name === "delete") {
+        if (store.set) throw new Error(`setPrivateState can only be called during create or update, not ${name}`);
+        return result;
+      }
+      if (!store.set || !result || typeof result !== "object" || isDiagnostics(result)) return result;
+      return { ...result, private: store.value ?? null };
+    };
+  }
+  return methods;
+}
+
 /**
  * Base class for implementing Terraform resource providers with JSON-RPC communication.
  * Resources support full CRUD operations (create, read, update, delete) and can optionally
@@ -191,118 +694,220 @@ export type ResourceProviderMethods<TProps, TState = void, TID = string> = [TSta
  *
  * @template TProps - The type of the properties/configuration for the resource.
  * @template TState - The type of the runtime state maintained by the resource (defaults to void for stateless resources).
- * @template TID - The type of the resource identifier (defaults to string).
+ * @template TID - The type of the resource identifier (defaults to string), an object for a composite key.
  */
-export class ResourceProvider<TProps, TState = void, TID = string> extends BaseJsonRpcProvider {
+export class ResourceProvider<TProps, TState = void, TID = string> extends BaseJsonRpcProvider<RemoteMethods> {
   /**
    * Creates a new ResourceProvider instance.
    * @param providerMethods - The implementation of the resource provider methods.
    */
   constructor(providerMethods: ResourceProviderMethods<TProps, TState, TID>) {
-    super(() => ({
-      async create(params: { props: Record<string, unknown>; writeOnlyProps?: Record<string, unknown> }) {
-        const result = await providerMethods.create({ ...params.props, writeOnly: params.writeOnlyProps } as TProps);
+    super((client) => {
+      notifyProgress = (method, message) => client.notify(method, { message });
+      return collectAdvisories(collectNextSteps(collectPollOptions(collectKeepState(collectPrivateState({
+        async create(
+          params: { props: Record<string, unknown>; writeOnlyProps?: Record<string, unknown>; idempotencyKey: string },
+        ) {
+          const result = await providerMethods.create(
+            { ...params.props, writeOnly: params.writeOnlyProps } as TProps,
+            { idempotencyKey: params.idempotencyKey },
+          );
 
-        if (isDiagnostics(result)) return result;
+          if (isDiagnostics(result)) return result;
 
-        const sensitiveState = (result as any).state?.sensitive;
+          const sensitiveState = (result as any).state?.sensitive;
 
-        const state = (result as any).state;
-        if (state && typeof state === "object" && "sensitive" in state) {
-          delete state["sensitive"];
-        }
+          const state = (result as any).state;
+          if (state && typeof state === "object" && "sensitive" in state) {
+            delete state["sensitive"];
+          }
 
-        return { id: result.id, state, sensitiveState };
-      },
-      async read(params: { id: TID; props: Record<string, unknown> | null }) {
-        const result = await providerMethods.read(params.id, params.props as TProps | null);
+          return {
+            id: result.id,
+            state,
+            sensitiveState,
+            sensitivePaths: sensitivePathsOf(state),
+            typedPaths: typedPathsOf(state),
+            identifiers: result.identifiers,
+          };
+        },
+        async read(params: { id: TID; props: Record<string, unknown> | null }) {
+          const result = await providerMethods.read(params.id, params.props as TProps | null);
 
-        if ("exists" in result) return result;
+          if ("exists" in result) return result;
 
-        if (isDiagnostics(result)) return result;
+          if (isDiagnostics(result)) return result;
 
-        const sensitiveState = (result as any).state?.sensitive;
+          const sensitiveState = (result as any).state?.sensitive;
 
-        const state = (result as any).state;
-        if (state && typeof state === "object" && "sensitive" in state) {
-          delete state["sensitive"];
-        }
+          const state = (result as any).state;
+          if (state && typeof state === "object" && "sensitive" in state) {
+            delete state["sensitive"];
+          }
 
-        return { props: result.props, state, sensitiveState };
-      },
-      async update(
-        params: {
-          id: TID;
-          nextProps: Record<string, unknown>;
-          nextWriteOnlyProps?: Record<string, unknown>;
-          currentProps: Record<string, unknown>;
-          currentState: Record<string, unknown>;
-          currentSensitiveState?: Record<string, unknown>;
+          return {
+            props: result.props,
+            state,
+            sensitiveState,
+            sensitivePaths: sensitivePathsOf(state),
+            typedPaths: typedPathsOf(state),
+            identifiers: result.identifiers,
+          };
         },
-      ) {
-        const result = await providerMethods.update(
-          params.id,
-          { ...params.nextProps, writeOnly: params.nextWriteOnlyProps } as TProps,
-          params.currentProps as TProps,
-          { ...params.currentState, sensitive: params.currentSensitiveState } as TState,
-        );
+        async update(
+          params: {
+            id: TID;
+            nextProps: Record<string, unknown>;
+            nextWriteOnlyProps?: Record<string, unknown>;
+            currentProps: Record<string, unknown>;
+            currentState: Record<string, unknown>;
+            currentSensitiveState?: Record<string, unknown>;
+          },
+        ) {
+          const result = await providerMethods.update(
+            params.id,
+            { ...params.nextProps, writeOnly: params.nextWriteOnlyProps } as TProps,
+            params.currentProps as TProps,
+            { ...params.currentState, sensitive: params.currentSensitiveState } as TState,
+          );
 
-        if (isDiagnostics(result)) return result;
+          if (isDiagnostics(result)) return result;
 
-        const sensitiveState = (result as any)?.sensitive;
+          const sensitiveState = (result as any)?.sensitive;
 
-        const state = result as any;
-        if (state && typeof state === "object" && "sensitive" in state) {
-          delete state["sensitive"];
-        }
+          const state = result as any;
+          if (state && typeof state === "object" && "sensitive" in state) {
+            delete state["sensitive"];
+          }
 
-        return { state: result, sensitiveState };
-      },
-      async delete(
-        params: {
-          id: TID;
-          props: Record<string, unknown>;
-          state: Record<string, unknown>;
-          sensitiveState?: Record<string, unknown>;
+          return {
+            state: result,
+            sensitiveState,
+            sensitivePaths: sensitivePathsOf(state),
+            typedPaths: typedPathsOf(state),
+          };
         },
-      ) {
-        const result = await providerMethods.delete(
-          params.id,
-          params.props as TProps,
-          { ...params.state, sensitive: params.sensitiveState } as TState,
-        );
-        if (isDiagnostics(result)) return result;
-        return { done: true };
-      },
-      async modifyPlan(
-        params: {
-          id?: TID;
-          planType: "create" | "update" | "delete";
-          nextProps?: Record<string, unknown>;
-          currentProps?: Record<string, unknown>;
-          currentState?: Record<string, unknown>;
-          currentSensitiveState?: Record<string, unknown>;
+        async delete(
+          params: {
+            id: TID;
+            props: Record<string, unknown>;
+            state: Record<string, unknown>;
+            sensitiveState?: Record<string, unknown>;
+          },
+        ) {
+          const result = await providerMethods.delete(
+            params.id,
+            params.props as TProps,
+            { ...params.state, sensitive: params.sensitiveState } as TState,
+          );
+          if (isDiagnostics(result)) return result;
+          if (result && "steps" in result) {
+            return { done: result.steps.every((step) => step.done), steps: result.steps };
+          }
+          return { done: true };
         },
-      ) {
-        if (!providerMethods.modifyPlan) throw new JSONRPCMethodNotFoundError();
-
-        const result = await providerMethods.modifyPlan(
-          params?.id ?? null,
-          params.planType,
-          params.nextProps as TProps ?? null,
-          params.currentProps as TProps ?? null,
-          params.currentState || params.currentSensitiveState
-            ? { ...params.currentState, sensitive: params.currentSensitiveState } as TState
-            : null,
-        );
+        async validate(params: { props: Record<string, unknown> }) {
+          if (!providerMethods.validate) throw new JSONRPCMethodNotFoundError();
+          const result = await providerMethods.validate(params.props as TProps);
+          if (isDiagnostics(result)) return result;
+          return { diagnostics: [] };
+        },
+        async check(
+          params: {
+            id: TID;
+            props: Record<string, unknown>;
+            state?: Record<string, unknown>;
+            sensitiveState?: Record<string, unknown>;
+          },
+        ) {
+          if (!providerMethods.check) throw new JSONRPCMethodNotFoundError();
+          const result = await providerMethods.check(
+            params.id,
+            params.props as TProps,
+            { ...params.state, sensitive: params.sensitiveState } as TState,
+          );
+          if (isDiagnostics(result)) return result;
+          return { diagnostics: [] };
+        },
+        async precondition(params: { id?: TID; planType: "create" | "update"; props: Record<string, unknown> }) {
+          if (!providerMethods.precondition) throw new JSONRPCMethodNotFoundError();
+          const result = await providerMethods.precondition(
+            params.id ?? null,
+            params.planType,
+            params.props as TProps,
+          );
+          if (isDiagnostics(result)) return result;
+          return { diagnostics: [] };
+        },
+        async canImport(params: { id: TID; props?: Record<string, unknown> }) {
+          if (!providerMethods.canImport) throw new JSONRPCMethodNotFoundError();
+          const result = await providerMethods.canImport(params.id, (params.props ?? null) as TProps | null);
+          if (isDiagnostics(result)) return result;
+          return { diagnostics: [] };
+        },
+        async modifyPlan(
+          params: {
+            id?: TID;
+            planType: "create" | "update" | "delete";
+            nextProps?: Record<string, unknown>;
+            currentProps?: Record<string, unknown>;
+            currentState?: Record<string, unknown>;
+            currentSensitiveState?: Record<string, unknown>;
+          },
+        ) {
+          if (!providerMethods.modifyPlan) throw new JSONRPCMethodNotFoundError();
 
-        if (result) {
-          return result;
-        }
+          const result = await providerMethods.modifyPlan(
+            params?.id ?? null,
+            params.planType,
+            params.nextProps as TProps ?? null,
+            params.currentProps as TProps ?? null,
+            params.currentState || params.currentSensitiveState
+              ? { ...params.currentState, sensitive: params.currentSensitiveState } as TState
+              : null,
+          );
 
-        return { noChanges: true };
-      },
-    }));
+          if (result) {
+            return result;
+          }
+
+          return { noChanges: true };
+        },
+        async poll(
+          params: {
+            id: TID;
+            props: Record<string, unknown>;
+            state?: Record<string, unknown>;
+            sensitiveState?: Record<string, unknown>;
+          },
+        ) {
+          if (!providerMethods.poll) throw new JSONRPCMethodNotFoundError();
+          const result = await providerMethods.poll(
+            params.id,
+            params.props as TProps,
+            { ...params.state, sensitive: params.sensitiveState } as TState,
+          );
+
+          if (isDiagnostics(result)) return result;
+
+          const state = (result as any).state;
+          if (state === undefined) return { ready: result.ready };
+
+          const sensitiveState = state?.sensitive;
+          if (state && typeof state === "object" && "sensitive" in state) {
+            delete state["sensitive"];
+          }
+
+          return {
+            ready: result.ready,
+            state,
+            sensitiveState,
+            sensitivePaths: sensitivePathsOf(state),
+            typedPaths: typedPathsOf(state),
+          };
+        },
+      })))));
+    });
   }
 }
 
@@ -316,7 +921,7 @@ export class ResourceProvider<TProps, TState = void, TID = string> extends BaseJ
  *
  * @template TProps - A Zod schema type that defines the shape of the resource properties.
  * @template TState - A Zod schema type that defines the shape of the resource state (defaults to void for stateless resources).
- * @template TID - The type of the resource identifier (defaults to string).
+ * @template TID - The type of the resource identifier (defaults to string), an object for a composite key.
  */
 export class ZodResourceProvider<TProps extends z.ZodType, TState extends z.ZodType | void = void, TID = string>
   extends ResourceProvider<z.infer<TProps>, TState extends z.ZodType ? z.infer<TState> : void, TID> {
@@ -349,7 +954,7 @@ export class ZodResourceProvider<TProps extends z.ZodType, TState extends z.ZodT
       : args[0];
 
     const validatedMethods = {
-      async create(props: any) {
+      async create(props: any, options: CreateOptions) {
         // Validate props
         const propsParsed = propsSchema.safeParse(props);
         if (!propsParsed.success) {
@@ -364,7 +969,7 @@ export class ZodResourceProvider<TProps extends z.ZodType, TState extends z.ZodT
         }
 
         // Call the method with validated props
-        const result = await providerMethods.create(propsParsed.data);
+        const result = await providerMethods.create(propsParsed.data, options);
 
         // Catch any diagnostics and return them early
         if (isDiagnostics(result)) return result;
@@ -383,10 +988,14 @@ export class ZodResourceProvider<TProps extends z.ZodType, TState extends z.ZodT
             };
           }
 
-          return { id: result.id, state: stateParsed.data };
+          return {
+            id: result.id,
+            state: keepStatePaths((result as any).state, stateParsed.data),
+            identifiers: result.identifiers,
+          };
         }
 
-        return { id: result.id };
+        return { id: result.id, identifiers: result.identifiers };
       },
       async read(id: TID, props: any) {
         // Validate props
@@ -440,7 +1049,8 @@ export class ZodResourceProvider<TProps extends z.ZodType, TState extends z.ZodT
 
           return {
             props: resultPropsParsed.data,
-            state: resultStateParsed.data,
+            state: keepStatePaths((result as any).state, resultStateParsed.data),
+            identifiers: result.identifiers,
           };
         }
 
@@ -462,6 +1072,7 @@ export class ZodResourceProvider<TProps extends z.ZodType, TState extends z.ZodT
 
         return {
           props: resultPropsParsed.data,
+          identifiers: result.identifiers,
         };
       },
       async update(id: TID, nextProps: any, currentProps: any, currentState: any) {
@@ -526,7 +1137,7 @@ export class ZodResourceProvider<TProps extends z.ZodType, TState extends z.ZodT
               })),
             };
           }
-          return stateParsed.data;
+          return keepStatePaths(result, stateParsed.data);
         }
       },
       async delete(id: TID, props: any, state: any) {
@@ -559,8 +1170,25 @@ export class ZodResourceProvider<TProps extends z.ZodType, TState extends z.ZodT
         // Call the method with validated props
         const result = await providerMethods.delete(id, propsParsed.data, stateParsed?.data as any);
 
-        // Catch any diagnostics and return them early
-        if (isDiagnostics(result)) return result;
+        // Pass through any diagnostics or cleanup steps
+        return result;
+      },
+      async validate(props: any) {
+        // Validate props
+        const propsParsed = propsSchema.safeParse(props);
+        if (!propsParsed.success) {
+          return {
+            diagnostics: propsParsed.error.issues.map((i) => ({
+              severity: "error",
+              summary: "Zod Validation Issue",
+              detail: i.message,
+              propPath: i.path.length > 0 ? ["props", ...i.path.map((_) => String(_))] : undefined,
+            })),
+          };
+        }
+
+        // Call the method with validated props
+        return await providerMethods.validate?.(propsParsed.data);
       },
     };
     if (providerMethods.modifyPlan) {
@@ -624,8 +1252,8 @@ export class ZodResourceProvider<TProps extends z.ZodType, TState extends z.ZodT
         // Catch any diagnostics and return them early
         if (isDiagnostics(result)) return result;
 
-        // Catch the requiresReplacement case
-        if ("requiresReplacement" in result) return result;
+        // Catch the requiresReplacement and replacements cases
+        if ("requiresReplacement" in result || "replacements" in result) return result;
 
         // Validate the modified props
         const modifiedPropsParsed = result.modifiedProps ? propsSchema.safeParse(result.modifiedProps) : undefined;
@@ -643,6 +1271,127 @@ export class ZodResourceProvider<TProps extends z.ZodType, TState extends z.ZodT
         return { ...result, modifiedProps: modifiedPropsParsed?.data };
       };
     }
+    if (providerMethods.check) {
+      (validatedMethods as any)["check"] = async (id: TID, props: any, state: any) => {
+        // Validate props
+        const propsParsed = propsSchema.safeParse(props);
+        const stateParsed = stateSchema ? stateSchema.safeParse(state) : undefined;
+        if (!propsParsed.success || (stateParsed && !stateParsed.success)) {
+          return {
+            diagnostics: [
+              ...(!propsParsed.success
+                ? propsParsed.error.issues.map((i) => ({
+                  severity: "error",
+                  summary: "Zod Validation Issue",
+                  detail: i.message,
+                  propPath: i.path.length > 0 ? ["props", ...i.path.map((_) => String(_))] : undefined,
+                }))
+                : []),
+              ...((stateParsed && !stateParsed.success)
+                ? stateParsed.error.issues.map((i) => ({
+                  severity: "error",
+                  summary: "Zod Validation Issue",
+                  detail: i.message,
+                  propPath: i.path.length > 0 ? ["state", ...i.path.map((_) => String(_))] : undefined,
+                }))
+                : []),
+            ],
+          } as Diagnostics;
+        }
+
+        // Call the method with validated props
+        return await providerMethods.check!(id, propsParsed.data, stateParsed?.data as any);
+      };
+    }
+    if (providerMethods.precondition) {
+      (validatedMethods as any)["precondition"] = async (id: TID | null, planType: "create" | "update", props: any) => {
+        // Validate props
+        const propsParsed = propsSchema.safeParse(props);
+        if (!propsParsed.success) {
+          return {
+            diagnostics: propsParsed.error.issues.map((i) => ({
+              severity: "error",
+              summary: "Zod Validation Issue",
+              detail: i.message,
+              propPath: i.path.length > 0 ? ["props", ...i.path.map((_) => String(_))] : undefined,
+            })),
+          } as Diagnostics;
+        }
+
+        // Call the method with validated props
+        return await providerMethods.precondition!(id, planType, propsParsed.data);
+      };
+    }
+    if (providerMethods.canImport) {
+      (validatedMethods as any)["canImport"] = async (id: TID, props: any) => {
+        // Props are optional when importing, only validate them when given
+        if (props === null) return await providerMethods.canImport!(id, null);
+        const propsParsed = propsSchema.safeParse(props);
+        if (!propsParsed.success) {
+          return {
+            diagnostics: propsParsed.error.issues.map((i) => ({
+              severity: "error",
+              summary: "Zod Validation Issue",
+              detail: i.message,
+              propPath: i.path.length > 0 ? ["props", ...i.path.map((_) => String(_))] : undefined,
+            })),
+          } as Diagnostics;
+        }
+
+        // Call the method with validated props
+        return await providerMethods.canImport!(id, propsParsed.data);
+      };
+    }
+    if (providerMethods.poll) {
+      (validatedMethods as any)["poll"] = async (id: TID, props: any, state: any) => {
+        // Validate props
+        const propsParsed = propsSchema.safeParse(props);
+        const stateParsed = stateSchema ? stateSchema.safeParse(state) : undefined;
+        if (!propsParsed.success || (stateParsed && !stateParsed.success)) {
+          return {
+            diagnostics: [
+              ...(!propsParsed.success
+                ? propsParsed.error.issues.map((i) => ({
+                  severity: "error",
+                  summary: "Zod Validation Issue",
+                  detail: i.message,
+                  propPath: i.path.length > 0 ? ["props", ...i.path.map((_) => String(_))] : undefined,
+                }))
+                : []),
+              ...((stateParsed && !stateParsed.success)
+                ? stateParsed.error.issues.map((i) => ({
+                  severity: "error",
+                  summary: "Zod Validation Issue",
+                  detail: i.message,
+                  propPath: i.path.length > 0 ? ["state", ...i.path.map((_) => String(_))] : undefined,
+                }))
+                : []),
+            ],
+          } as Diagnostics;
+        }
+
+        // Call the method with validated props
+        const result = await providerMethods.poll!(id, propsParsed.data, stateParsed?.data as any);
+
+        // Catch any diagnostics and resources that are not ready yet and return them early
+        if (isDiagnostics(result) || !stateSchema || (result as any).state === undefined) return result;
+
+        // Validate the final state
+        const resultStateParsed = stateSchema.safeParse((result as any).state);
+        if (!resultStateParsed.success) {
+          return {
+            diagnostics: resultStateParsed.error.issues.map((i) => ({
+              severity: "error",
+              summary: "Zod Validation Issue",
+              detail: i.message,
+              propPath: i.path.length > 0 ? ["state", ...i.path.map((_) => String(_))] : undefined,
+            })),
+          } as Diagnostics;
+        }
+
+        return { ready: result.ready, state: keepStatePaths((result as any).state, resultStateParsed.data) };
+      };
+    }
     super(validatedMethods as any);
   }
 }
@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"log"
+	"os"
 
+	"github.com/brad-jones/terraform-provider-denobridge/internal/deno"
 	"github.com/brad-jones/terraform-provider-denobridge/internal/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 )
@@ -18,9 +21,41 @@ var (
 )
 
 func main() {
+	// Maintainer tooling, print the JSON-RPC contract expected of scripts instead of serving the provider
+	if os.Getenv("DENO_TOFU_BRIDGE_DUMP_CONTRACT") == "true" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(deno.RPCContract()); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	// Export spans over OTLP, when an endpoint is configured by the standard OTEL_EXPORTER_OTLP_* environment variables
+	stopTracing, tracingErr := deno.StartTracing(context.Background(), version)
+	if tracingErr != nil {
+		log.Printf("[WARN] Failed to start tracing: %s", tracingErr)
+	}
+
 	err := providerserver.Serve(context.Background(), provider.New(version), providerserver.ServeOpts{
 		Address: "registry.terraform.io/brad-jones/denobridge",
 	})
+
+	// Stop any Deno processes that were kept idle for reuse
+	if shutdownErr := provider.Shutdown(); shutdownErr != nil {
+		log.Printf("[WARN] Failed to stop idle Deno processes: %s", shutdownErr)
+	}
+
+	// Export any spans that are still buffered
+	if tracingErr := stopTracing(context.Background()); tracingErr != nil {
+		log.Printf("[WARN] Failed to export spans: %s", tracingErr)
+	}
+
+	// Summarize the work done by this process when collecting metrics
+	if metrics := deno.SharedMetrics(); metrics != nil {
+		_ = metrics.Dump(os.Stderr)
+	}
+
 	if err != nil {
 		log.Fatal(err.Error())
 	}